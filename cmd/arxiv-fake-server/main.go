@@ -0,0 +1,47 @@
+// Command arxiv-fake-server serves a stand-in for the arXiv API query endpoint
+// at /api/query, built from fixture entries, paper PDFs at /pdf/, paper
+// sources at /e-print/, announcement feeds at /rss/ and /atom/ and an
+// OAI-PMH interface at /oai. Point the MCP servers at it with
+// ARXIV_API_URL=http://localhost:8889/api/query,
+// ARXIV_SITE_URL=http://localhost:8889 and
+// ARXIV_FEED_URL=http://localhost:8889/atom, and arxiv-harvest with
+// ARXIV_OAI_URL=http://localhost:8889/oai.
+//
+// The fixtures shipped in internal/fakearxiv are served unless a directory of
+// *.xml fixture entries is given as the first argument.
+package main
+
+import (
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/fakearxiv"
+)
+
+func main() {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8889"
+	}
+	var fixtures fs.FS = fakearxiv.Fixtures()
+	if len(os.Args) > 1 {
+		fixtures = os.DirFS(os.Args[1])
+	}
+
+	server, err := fakearxiv.Load(fixtures)
+	if err != nil {
+		log.Fatalf("Error loading fixtures: %v", err)
+	}
+	http.Handle("/api/query", server)
+	http.Handle("/pdf/", server)
+	http.Handle("/e-print/", server)
+	http.Handle("/rss/", server)
+	http.Handle("/atom/", server)
+	http.Handle("/oai", server)
+	log.Printf("Serving fake arXiv API at http://localhost:%s/api/query", port)
+	if err := http.ListenAndServe(":"+port, nil); err != nil {
+		log.Fatal(err)
+	}
+}
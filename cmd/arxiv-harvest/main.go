@@ -0,0 +1,124 @@
+// Command arxiv-harvest copies arXiv paper metadata in bulk into a local store
+// through arXiv's OAI-PMH interface, which lists every paper in a set rather
+// than the few thousand results a search can return.
+//
+// Usage:
+//
+//	arxiv-harvest [-format arXiv|arXivRaw] [-set archive] [-from YYYY-MM-DD] [-until YYYY-MM-DD] [-dir dir] [-compact]
+//
+// Sets are archives, such as cs, math or hep-th, or OAI-PMH set specs such as
+// physics:hep-th; without one, every paper is harvested. A checkpoint is
+// saved after each page, so a harvest that is interrupted resumes where it
+// stopped when run again with the same format and set, and one that finished
+// is followed by harvesting only the records changed since. -from and -until
+// harvest the records changed between two dates instead; such a harvest
+// keeps its own checkpoint, resumed when run again with the same dates, and
+// does not move where the next incremental harvest starts.
+//
+// The store is kept in -dir, which defaults to ARXIV_STORE_DIR; the MCP
+// servers search it when ARXIV_STORE_DIR names the same directory. Requests
+// are configured by the same ARXIV_* environment variables as the MCP
+// servers, with ARXIV_OAI_URL for the interface.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivclient"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/oaipmh"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/store"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/taxonomy"
+)
+
+func main() {
+	format := flag.String("format", oaipmh.FormatArXiv, "metadata format: arXiv or arXivRaw")
+	set := flag.String("set", "", "archive, such as cs or hep-th, or OAI-PMH set spec to harvest; every paper if empty")
+	from := flag.String("from", "", "harvest records changed on or after this date, YYYY-MM-DD")
+	until := flag.String("until", "", "harvest records changed on or before this date, YYYY-MM-DD")
+	dir := flag.String("dir", "", "store directory; defaults to ARXIV_STORE_DIR")
+	compact := flag.Bool("compact", false, "rewrite the store with only the latest record and API entry of each paper after harvesting")
+	flag.Parse()
+
+	if *format != oaipmh.FormatArXiv && *format != oaipmh.FormatArXivRaw {
+		log.Fatalf("Invalid -format %q: must be arXiv or arXivRaw", *format)
+	}
+	if *set != "" && !strings.Contains(*set, ":") && len(taxonomy.Default().ArchiveCategories(*set)) == 0 {
+		log.Fatalf("Invalid -set %q: not an arXiv archive", *set)
+	}
+	for name, date := range map[string]string{"from": *from, "until": *until} {
+		if _, err := time.Parse(oaipmh.DateLayout, date); date != "" && err != nil {
+			log.Fatalf("Invalid -%s %q: must be YYYY-MM-DD", name, date)
+		}
+	}
+
+	if *dir == "" {
+		if *dir = store.DirFromEnv(); *dir == "" {
+			log.Fatal("No store directory; pass -dir or set ARXIV_STORE_DIR")
+		}
+	}
+	s, err := store.Open(*dir)
+	if err != nil {
+		log.Fatalf("Error opening store: %v", err)
+	}
+	config, err := arxivclient.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Error in arXiv client configuration: %v", err)
+	}
+	client := arxivclient.New(config)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts := store.HarvestOptions{
+		Format: *format,
+		Set:    oaipmh.SetSpec(*set),
+		From:   *from,
+		Until:  *until,
+		Progress: func(cp store.Checkpoint) {
+			if cp.Total > 0 {
+				log.Printf("Harvested %d of %d records", cp.Records, cp.Total)
+			} else {
+				log.Printf("Harvested %d records", cp.Records)
+			}
+		},
+	}
+	if cp, err := s.HarvestCheckpoint(opts); err == nil && cp.Unfinished() {
+		log.Printf("Resuming harvest of %s after %d records", describe(opts), cp.Records)
+	} else {
+		log.Printf("Harvesting %s into %s", describe(opts), s.Dir())
+	}
+
+	cp, err := s.Harvest(ctx, client, opts)
+	if err != nil {
+		log.Fatalf("Error harvesting after %d records: %v; run again to resume", cp.Records, err)
+	}
+	if cp.Range {
+		log.Printf("Harvested %d records", cp.Records)
+	} else {
+		log.Printf("Harvested %d records; the next harvest will list records changed from %s", cp.Records, cp.Next)
+	}
+
+	if *compact {
+		if err := s.Compact(opts.Format); err != nil {
+			log.Fatalf("Error compacting store: %v", err)
+		}
+		if err := s.CompactEntries(); err != nil {
+			log.Fatalf("Error compacting store: %v", err)
+		}
+		log.Printf("Compacted %s records and API entries", opts.Format)
+	}
+}
+
+func describe(opts store.HarvestOptions) string {
+	what := opts.Format + " records"
+	if opts.Set != "" {
+		what += " in " + opts.Set
+	}
+	return what
+}
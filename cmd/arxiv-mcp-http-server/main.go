@@ -5,6 +5,9 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivclient"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/cache"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/localsearch"
 	"github.com/Epistemic-Technology/arxiv-mcp/internal/server"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -14,12 +17,34 @@ func main() {
 	if port == "" {
 		port = "8888"
 	}
+	clientConfig, err := arxivclient.ConfigFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	cacheConfig, err := cache.ConfigFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Every session shares one client and cache, so that the server as a
+	// whole stays within arXiv's rate limit.
+	client := arxivclient.New(clientConfig)
+	cached, err := cache.New(client, cacheConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+	searcher, err := localsearch.SearcherFromEnv(cached)
+	if err != nil {
+		log.Fatal(err)
+	}
+	files, err := cache.NewFiles(client, cacheConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+	getServerForRequest := func(r *http.Request) *mcp.Server {
+		return server.CreateServer(searcher, files, client)
+	}
 	httpHandler := mcp.NewStreamableHTTPHandler(getServerForRequest, nil)
 	if err := http.ListenAndServe(":"+port, httpHandler); err != nil {
 		log.Fatal(err)
 	}
 }
-
-func getServerForRequest(r *http.Request) *mcp.Server {
-	return server.CreateServer()
-}
@@ -4,13 +4,37 @@ import (
 	"context"
 	"log"
 
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivclient"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/cache"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/localsearch"
 	"github.com/Epistemic-Technology/arxiv-mcp/internal/server"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 func main() {
-	server := server.CreateServer()
-	err := server.Run(context.Background(), &mcp.StdioTransport{})
+	clientConfig, err := arxivclient.ConfigFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	cacheConfig, err := cache.ConfigFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	client := arxivclient.New(clientConfig)
+	cached, err := cache.New(client, cacheConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+	searcher, err := localsearch.SearcherFromEnv(cached)
+	if err != nil {
+		log.Fatal(err)
+	}
+	files, err := cache.NewFiles(client, cacheConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+	server := server.CreateServer(searcher, files, client)
+	err = server.Run(context.Background(), &mcp.StdioTransport{})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -8,20 +8,10 @@ import (
 	"regexp"
 	"time"
 
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/taxonomy"
 	"github.com/PuerkitoBio/goquery"
 )
 
-type Field struct {
-	Title      string     `json:"title"`
-	Categories []Category `json:"categories"`
-}
-
-type Category struct {
-	Tag         string `json:"tag"`
-	Label       string `json:"label"`
-	Description string `json:"description"`
-}
-
 func main() {
 	outputFile := "arxiv-taxonomy.json"
 	if len(os.Args) > 1 {
@@ -58,12 +48,12 @@ func main() {
 		fieldHeadings = append(fieldHeadings, node.FirstChild.Data)
 	}
 
-	fields := make([]Field, 0)
+	fields := make([]taxonomy.Field, 0)
 	fieldSelections := doc.Find(".accordion-body").EachIter()
 	for i, fieldSelection := range fieldSelections {
-		field := Field{
+		field := taxonomy.Field{
 			Title:      fieldHeadings[i],
-			Categories: make([]Category, 0),
+			Categories: make([]taxonomy.Category, 0),
 		}
 
 		categorySelections := fieldSelection.Find(".columns.divided").EachIter()
@@ -80,7 +70,7 @@ func main() {
 				label = matches[2]
 			}
 			description := categorySelection.Find(".column:not(.is-one-fifth)").Find("p").First().Text()
-			category := Category{
+			category := taxonomy.Category{
 				Tag:         tag,
 				Label:       label,
 				Description: description,
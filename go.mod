@@ -6,6 +6,7 @@ require (
 	github.com/Epistemic-Technology/arxiv v1.0.1
 	github.com/PuerkitoBio/goquery v1.10.3
 	github.com/google/jsonschema-go v0.2.3
+	github.com/ledongthuc/pdf v0.0.0-20250511090121-5959a4027728
 	github.com/modelcontextprotocol/go-sdk v0.5.0
 )
 
@@ -0,0 +1,369 @@
+// Package arxivclient provides the arXiv API client shared by every tool in
+// the server.
+//
+// arXiv's API terms of use ask clients to make no more than one request every
+// three seconds and to use a single connection at a time. A Client enforces
+// both across all goroutines using it: requests wait in line for their turn,
+// and a request whose context is cancelled leaves the line.
+//
+// Requests that fail because arXiv is rate limiting, overloaded or unreachable
+// are retried with jittered exponential backoff, waiting at least as long as
+// arXiv asks in Retry-After. Failures are reported as RateLimitedError,
+// UnavailableError, RejectedError, BadQueryError or NotFoundError.
+//
+// Paper files, such as PDFs, are downloaded from the arXiv site, and
+// announcement feeds and OAI-PMH records fetched, through the same line of
+// requests, since the same terms apply to them. A download leaves the line
+// once its response headers arrive, and has a longer timeout, so that a
+// large file does not hold up other requests or fail part way.
+package arxivclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+const (
+	DefaultBaseURL         = "http://export.arxiv.org/api/query"
+	DefaultSiteURL         = "https://export.arxiv.org"
+	DefaultFeedURL         = "https://rss.arxiv.org/atom"
+	DefaultOAIURL          = "https://oaipmh.arxiv.org/oai"
+	DefaultRequestInterval = 3 * time.Second
+	DefaultTimeout         = 30 * time.Second
+	DefaultDownloadTimeout = 5 * time.Minute
+	DefaultUserAgent       = "arxiv-mcp/0.0.1 (+https://github.com/Epistemic-Technology/arxiv-mcp)"
+	DefaultMaxRetries      = 3
+	DefaultRetryBaseDelay  = 2 * time.Second
+	DefaultMaxRetryDelay   = time.Minute
+)
+
+type Config struct {
+	BaseURL         string        // arXiv API query endpoint
+	SiteURL         string        // arXiv site serving paper files under /pdf
+	FeedURL         string        // arXiv announcement feeds, one per category under it
+	OAIURL          string        // arXiv OAI-PMH interface for harvesting metadata in bulk
+	RequestInterval time.Duration // minimum time between the end of one request and the start of the next
+	Timeout         time.Duration // timeout for a single HTTP request
+	DownloadTimeout time.Duration // timeout for downloading a single paper file
+	UserAgent       string        // User-Agent header sent with every request
+	ContactEmail    string        // contact address sent in the From header, if set
+	MaxRetries      int           // retries after a failed request; 0 disables retrying
+	RetryBaseDelay  time.Duration // delay before the first retry, doubled for each one after
+	MaxRetryDelay   time.Duration // longest delay before a retry; 0 means no limit but an hour
+}
+
+func DefaultConfig() Config {
+	return Config{
+		BaseURL:         DefaultBaseURL,
+		SiteURL:         DefaultSiteURL,
+		FeedURL:         DefaultFeedURL,
+		OAIURL:          DefaultOAIURL,
+		RequestInterval: DefaultRequestInterval,
+		Timeout:         DefaultTimeout,
+		DownloadTimeout: DefaultDownloadTimeout,
+		UserAgent:       DefaultUserAgent,
+		MaxRetries:      DefaultMaxRetries,
+		RetryBaseDelay:  DefaultRetryBaseDelay,
+		MaxRetryDelay:   DefaultMaxRetryDelay,
+	}
+}
+
+type Client struct {
+	config         Config
+	httpClient     *http.Client
+	downloadClient *http.Client // for paper files, which take longer than API responses
+	throttle       *throttle
+}
+
+func New(config Config) *Client {
+	defaults := DefaultConfig()
+	if config.BaseURL == "" {
+		config.BaseURL = defaults.BaseURL
+	}
+	if config.SiteURL == "" {
+		config.SiteURL = defaults.SiteURL
+	}
+	if config.FeedURL == "" {
+		config.FeedURL = defaults.FeedURL
+	}
+	if config.OAIURL == "" {
+		config.OAIURL = defaults.OAIURL
+	}
+	if config.Timeout == 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.DownloadTimeout == 0 {
+		config.DownloadTimeout = defaults.DownloadTimeout
+	}
+	if config.UserAgent == "" {
+		config.UserAgent = defaults.UserAgent
+	}
+	return &Client{
+		config:         config,
+		httpClient:     &http.Client{Timeout: config.Timeout},
+		downloadClient: &http.Client{Timeout: config.DownloadTimeout},
+		throttle:       newThrottle(config.RequestInterval),
+	}
+}
+
+// Search queries the arXiv API, waiting for the client's turn to make a
+// request and retrying if arXiv is temporarily unable to answer.
+func (c *Client) Search(ctx context.Context, params arxiv.SearchParams) (arxiv.SearchResults, error) {
+	if err := params.Validate(); err != nil {
+		return arxiv.SearchResults{}, &BadQueryError{Message: err.Error()}
+	}
+	var results arxiv.SearchResults
+	err := c.retry(ctx, func() error {
+		var err error
+		results, err = c.search(ctx, params)
+		return err
+	})
+	if err != nil {
+		return arxiv.SearchResults{}, err
+	}
+	return results, nil
+}
+
+// retry calls attempt until it succeeds, fails with an error that should not
+// be retried, or has been retried MaxRetries times.
+func (c *Client) retry(ctx context.Context, attempt func() error) error {
+	for n := 0; ; n++ {
+		err := attempt()
+		if err == nil || n == c.config.MaxRetries {
+			return err
+		}
+		delay, ok := c.retryDelay(n, err)
+		if !ok {
+			return err
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+func (c *Client) search(ctx context.Context, params arxiv.SearchParams) (arxiv.SearchResults, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.config.BaseURL+"?"+encodeParams(params), nil)
+	if err != nil {
+		return arxiv.SearchResults{}, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return arxiv.SearchResults{}, ctx.Err()
+		}
+		return arxiv.SearchResults{}, &UnavailableError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return arxiv.SearchResults{}, &RateLimitedError{RetryAfter: retryAfter(resp.Header, time.Now())}
+	case resp.StatusCode == http.StatusNotFound && len(params.IdList) > 0:
+		return arxiv.SearchResults{}, &NotFoundError{IDs: params.IdList}
+	case resp.StatusCode == http.StatusBadRequest:
+		// arXiv describes the problem in an error entry, as it does for
+		// errors reported with a 200.
+		if results, err := arxiv.ParseResponse(resp.Body); err == nil {
+			if err := queryError(results); err != nil {
+				return arxiv.SearchResults{}, err
+			}
+		}
+		return arxiv.SearchResults{}, &BadQueryError{Message: resp.Status}
+	default:
+		return arxiv.SearchResults{}, statusError(resp)
+	}
+
+	results, err := arxiv.ParseResponse(resp.Body)
+	if err != nil {
+		return arxiv.SearchResults{}, &UnavailableError{Err: fmt.Errorf("parsing response: %w", err)}
+	}
+	if err := queryError(results); err != nil {
+		return arxiv.SearchResults{}, err
+	}
+	if len(params.IdList) > 0 && params.Query == "" && len(results.Entries) == 0 {
+		return arxiv.SearchResults{}, &NotFoundError{IDs: params.IdList}
+	}
+	results.Params = params
+	return results, nil
+}
+
+// statusError returns the error for a response with an unexpected status: an
+// UnavailableError, which is retried, for server errors and 408 Request
+// Timeout, and a RejectedError for any other.
+func statusError(resp *http.Response) error {
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout {
+		return &UnavailableError{Status: resp.Status, RetryAfter: retryAfter(resp.Header, time.Now())}
+	}
+	return &RejectedError{Status: resp.Status}
+}
+
+// queryError returns a BadQueryError if arXiv answered with error entries
+// only. arXiv reports a malformed query as a single entry whose ID is under
+// /api/errors and whose summary is the error message.
+func queryError(results arxiv.SearchResults) error {
+	if len(results.Entries) == 0 {
+		return nil
+	}
+	var messages []string
+	for _, entry := range results.Entries {
+		if !strings.Contains(entry.ID, "/api/errors") {
+			return nil
+		}
+		messages = append(messages, strings.TrimSpace(entry.Summary))
+	}
+	return &BadQueryError{Message: strings.Join(messages, "; ")}
+}
+
+// maxBackoff limits the doubled delay before a retry however MaxRetryDelay is
+// set, so that doubling it cannot overflow.
+const maxBackoff = time.Hour
+
+// retryDelay returns how long to wait before retrying after err, and false if
+// err should not be retried. The delay doubles with each attempt, with
+// jitter so that clients that failed together do not retry together, and is
+// never shorter than arXiv's Retry-After.
+func (c *Client) retryDelay(attempt int, err error) (time.Duration, bool) {
+	var asked time.Duration
+	var rateLimited *RateLimitedError
+	var unavailable *UnavailableError
+	switch {
+	case errors.As(err, &rateLimited):
+		asked = rateLimited.RetryAfter
+	case errors.As(err, &unavailable):
+		asked = unavailable.RetryAfter
+	default:
+		return 0, false
+	}
+
+	delay := maxBackoff
+	if base := max(c.config.RetryBaseDelay, 0); attempt < 32 && base <= maxBackoff>>attempt {
+		delay = base << attempt
+	}
+	if c.config.MaxRetryDelay > 0 {
+		delay = min(delay, c.config.MaxRetryDelay)
+	}
+	delay = delay/2 + rand.N(delay/2+1)
+	if asked > delay {
+		delay = asked
+	}
+	if c.config.MaxRetryDelay > 0 && delay > c.config.MaxRetryDelay {
+		// arXiv asked for a longer wait than the caller should be kept
+		// waiting.
+		return 0, false
+	}
+	return delay, true
+}
+
+// retryAfter parses a Retry-After header, given either in seconds or as an
+// HTTP date.
+func retryAfter(header http.Header, now time.Time) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// do sends req to the API once the throttle allows it. The throttle is held
+// until the response body is closed, so that only one connection is open at a
+// time.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	resp, release, err := c.send(c.httpClient, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// download sends req for a paper file once the throttle allows it. The
+// throttle is released when the response headers arrive, so that a large
+// file being read does not hold up other requests.
+func (c *Client) download(req *http.Request) (*http.Response, error) {
+	resp, release, err := c.send(c.downloadClient, req)
+	if err != nil {
+		return nil, err
+	}
+	release()
+	return resp, nil
+}
+
+// send sends req with httpClient once the throttle allows it, and returns
+// the function that releases the throttle.
+func (c *Client) send(httpClient *http.Client, req *http.Request) (*http.Response, func(), error) {
+	release, err := c.throttle.acquire(req.Context())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req.Header.Set("User-Agent", c.config.UserAgent)
+	if c.config.ContactEmail != "" {
+		req.Header.Set("From", c.config.ContactEmail)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return resp, release, nil
+}
+
+// releasingBody releases the throttle when the response body is closed.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+func encodeParams(params arxiv.SearchParams) string {
+	query := url.Values{}
+	if params.Query != "" {
+		query.Set("search_query", params.Query)
+	}
+	if len(params.IdList) > 0 {
+		query.Set("id_list", strings.Join(params.IdList, ","))
+	}
+	if params.Start > 0 {
+		query.Set("start", strconv.Itoa(params.Start))
+	}
+	if params.MaxResults > 0 {
+		query.Set("max_results", strconv.Itoa(params.MaxResults))
+	}
+	if params.SortBy != "" {
+		query.Set("sortBy", string(params.SortBy))
+	}
+	if params.SortOrder != "" {
+		query.Set("sortOrder", string(params.SortOrder))
+	}
+	return query.Encode()
+}
@@ -0,0 +1,537 @@
+package arxivclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+const testFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:opensearch="http://a9.com/-/spec/opensearch/1.1/" xmlns:arxiv="http://arxiv.org/schemas/atom">
+  <title type="html">ArXiv Query: search_query=ti:test</title>
+  <opensearch:totalResults>1</opensearch:totalResults>
+  <opensearch:startIndex>0</opensearch:startIndex>
+  <opensearch:itemsPerPage>1</opensearch:itemsPerPage>
+  <entry>
+    <id>http://arxiv.org/abs/2401.01234v1</id>
+    <title>A Test Paper</title>
+    <summary>An abstract.</summary>
+    <author><name>Jane Smith</name></author>
+    <published>2024-01-02T00:00:00Z</published>
+    <updated>2024-01-02T00:00:00Z</updated>
+    <arxiv:primary_category term="cs.LG" scheme="http://arxiv.org/schemas/atom"/>
+    <category term="cs.LG" scheme="http://arxiv.org/schemas/atom"/>
+  </entry>
+</feed>`
+
+func newTestServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func feedHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/atom+xml")
+	w.Write([]byte(testFeed))
+}
+
+func TestNew(t *testing.T) {
+	client := New(Config{})
+	if client.config.BaseURL != DefaultBaseURL {
+		t.Errorf("expected default base URL, got '%s'", client.config.BaseURL)
+	}
+	if client.config.UserAgent != DefaultUserAgent {
+		t.Errorf("expected default user agent, got '%s'", client.config.UserAgent)
+	}
+	if client.httpClient.Timeout != DefaultTimeout {
+		t.Errorf("expected default timeout, got %v", client.httpClient.Timeout)
+	}
+	if client.downloadClient.Timeout != DefaultDownloadTimeout {
+		t.Errorf("expected default download timeout, got %v", client.downloadClient.Timeout)
+	}
+	if client.config.RequestInterval != 0 {
+		t.Errorf("expected a zero request interval to be kept, got %v", client.config.RequestInterval)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	t.Run("parses results and sends query", func(t *testing.T) {
+		var gotQuery, gotUserAgent, gotFrom string
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			gotQuery = r.URL.Query().Get("search_query")
+			gotUserAgent = r.Header.Get("User-Agent")
+			gotFrom = r.Header.Get("From")
+			feedHandler(w, r)
+		})
+		client := New(Config{BaseURL: server.URL, ContactEmail: "someone@example.com"})
+
+		params := arxiv.SearchParams{Query: "ti:test", MaxResults: 1}
+		results, err := client.Search(context.Background(), params)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if gotQuery != "ti:test" {
+			t.Errorf("expected search_query 'ti:test', got '%s'", gotQuery)
+		}
+		if gotUserAgent != DefaultUserAgent {
+			t.Errorf("expected User-Agent '%s', got '%s'", DefaultUserAgent, gotUserAgent)
+		}
+		if gotFrom != "someone@example.com" {
+			t.Errorf("expected From 'someone@example.com', got '%s'", gotFrom)
+		}
+		if len(results.Entries) != 1 || results.Entries[0].Title != "A Test Paper" {
+			t.Errorf("expected one entry titled 'A Test Paper', got %+v", results.Entries)
+		}
+		if results.TotalResults != 1 {
+			t.Errorf("expected 1 total result, got %d", results.TotalResults)
+		}
+		if results.Params.Query != "ti:test" {
+			t.Errorf("expected params to be set on results, got %+v", results.Params)
+		}
+	})
+
+	t.Run("no From header without contact email", func(t *testing.T) {
+		var hasFrom bool
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			_, hasFrom = r.Header["From"]
+			feedHandler(w, r)
+		})
+		client := New(Config{BaseURL: server.URL})
+
+		if _, err := client.Search(context.Background(), arxiv.SearchParams{Query: "ti:test"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if hasFrom {
+			t.Error("expected no From header")
+		}
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		})
+		client := New(Config{BaseURL: server.URL})
+
+		_, err := client.Search(context.Background(), arxiv.SearchParams{Query: "ti:test"})
+		var unavailable *UnavailableError
+		if !errors.As(err, &unavailable) {
+			t.Errorf("expected UnavailableError for non-200 response, got %v", err)
+		}
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		requests := 0
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			feedHandler(w, r)
+		})
+		client := New(Config{BaseURL: server.URL})
+
+		_, err := client.Search(context.Background(), arxiv.SearchParams{Query: "ti:test", MaxResults: 5000})
+		var badQuery *BadQueryError
+		if !errors.As(err, &badQuery) {
+			t.Errorf("expected BadQueryError for invalid params, got %v", err)
+		}
+		if requests != 0 {
+			t.Errorf("expected no request for invalid params, got %d", requests)
+		}
+	})
+}
+
+func TestSearchRateLimit(t *testing.T) {
+	t.Run("spaces requests", func(t *testing.T) {
+		var mu sync.Mutex
+		var times []time.Time
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			times = append(times, time.Now())
+			mu.Unlock()
+			feedHandler(w, r)
+		})
+		interval := 50 * time.Millisecond
+		client := New(Config{BaseURL: server.URL, RequestInterval: interval})
+
+		var wg sync.WaitGroup
+		for range 3 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := client.Search(context.Background(), arxiv.SearchParams{Query: "ti:test"}); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if len(times) != 3 {
+			t.Fatalf("expected 3 requests, got %d", len(times))
+		}
+		for i := 1; i < len(times); i++ {
+			if gap := times[i].Sub(times[i-1]); gap < interval {
+				t.Errorf("expected requests at least %v apart, got %v", interval, gap)
+			}
+		}
+	})
+
+	t.Run("one request at a time", func(t *testing.T) {
+		var mu sync.Mutex
+		inFlight, maxInFlight := 0, 0
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			inFlight++
+			maxInFlight = max(maxInFlight, inFlight)
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			feedHandler(w, r)
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		})
+		client := New(Config{BaseURL: server.URL})
+
+		var wg sync.WaitGroup
+		for range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := client.Search(context.Background(), arxiv.SearchParams{Query: "ti:test"}); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if maxInFlight != 1 {
+			t.Errorf("expected at most 1 request in flight, got %d", maxInFlight)
+		}
+	})
+
+	t.Run("cancelled while waiting", func(t *testing.T) {
+		requests := 0
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			feedHandler(w, r)
+		})
+		client := New(Config{BaseURL: server.URL, RequestInterval: time.Hour})
+
+		if _, err := client.Search(context.Background(), arxiv.SearchParams{Query: "ti:test"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := client.Search(ctx, arxiv.SearchParams{Query: "ti:test"})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected deadline exceeded, got %v", err)
+		}
+		if requests != 1 {
+			t.Errorf("expected the cancelled search not to reach the server, got %d requests", requests)
+		}
+	})
+}
+
+const errorFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:opensearch="http://a9.com/-/spec/opensearch/1.1/">
+  <opensearch:totalResults>1</opensearch:totalResults>
+  <entry>
+    <id>http://arxiv.org/api/errors#incorrect_id_format_for_1234</id>
+    <title>Error</title>
+    <summary>incorrect id format for 1234</summary>
+  </entry>
+</feed>`
+
+const emptyFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:opensearch="http://a9.com/-/spec/opensearch/1.1/">
+  <opensearch:totalResults>0</opensearch:totalResults>
+</feed>`
+
+func TestSearchRetry(t *testing.T) {
+	retryConfig := func(url string) Config {
+		return Config{BaseURL: url, MaxRetries: 2, RetryBaseDelay: time.Millisecond, MaxRetryDelay: time.Second}
+	}
+
+	t.Run("recovers from unavailable", func(t *testing.T) {
+		requests := 0
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests < 3 {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			feedHandler(w, r)
+		})
+		client := New(retryConfig(server.URL))
+
+		results, err := client.Search(context.Background(), arxiv.SearchParams{Query: "ti:test"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(results.Entries) != 1 {
+			t.Errorf("expected 1 entry, got %d", len(results.Entries))
+		}
+		if requests != 3 {
+			t.Errorf("expected 3 requests, got %d", requests)
+		}
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		requests := 0
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+		})
+		client := New(retryConfig(server.URL))
+
+		_, err := client.Search(context.Background(), arxiv.SearchParams{Query: "ti:test"})
+		var unavailable *UnavailableError
+		if !errors.As(err, &unavailable) {
+			t.Errorf("expected UnavailableError, got %v", err)
+		}
+		if requests != 3 {
+			t.Errorf("expected 3 requests, got %d", requests)
+		}
+	})
+
+	t.Run("retries rate limiting", func(t *testing.T) {
+		requests := 0
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests == 1 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			feedHandler(w, r)
+		})
+		client := New(retryConfig(server.URL))
+
+		if _, err := client.Search(context.Background(), arxiv.SearchParams{Query: "ti:test"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if requests != 2 {
+			t.Errorf("expected 2 requests, got %d", requests)
+		}
+	})
+
+	t.Run("retry after longer than max delay", func(t *testing.T) {
+		requests := 0
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+		})
+		client := New(retryConfig(server.URL))
+
+		_, err := client.Search(context.Background(), arxiv.SearchParams{Query: "ti:test"})
+		var rateLimited *RateLimitedError
+		if !errors.As(err, &rateLimited) {
+			t.Fatalf("expected RateLimitedError, got %v", err)
+		}
+		if rateLimited.RetryAfter != 2*time.Minute {
+			t.Errorf("expected RetryAfter 2m, got %v", rateLimited.RetryAfter)
+		}
+		if requests != 1 {
+			t.Errorf("expected no retry, got %d requests", requests)
+		}
+	})
+
+	t.Run("bad query is not retried", func(t *testing.T) {
+		requests := 0
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(errorFeed))
+		})
+		client := New(retryConfig(server.URL))
+
+		_, err := client.Search(context.Background(), arxiv.SearchParams{Query: "ti:test"})
+		var badQuery *BadQueryError
+		if !errors.As(err, &badQuery) {
+			t.Fatalf("expected BadQueryError, got %v", err)
+		}
+		if badQuery.Message != "incorrect id format for 1234" {
+			t.Errorf("expected arXiv's error message, got '%s'", badQuery.Message)
+		}
+		if requests != 1 {
+			t.Errorf("expected no retry, got %d requests", requests)
+		}
+	})
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		for _, status := range []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound} {
+			requests := 0
+			server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.WriteHeader(status)
+			})
+			client := New(retryConfig(server.URL))
+
+			_, err := client.Search(context.Background(), arxiv.SearchParams{Query: "ti:test"})
+			var rejected *RejectedError
+			if !errors.As(err, &rejected) {
+				t.Errorf("%d: expected RejectedError, got %v", status, err)
+			}
+			if requests != 1 {
+				t.Errorf("%d: expected no retry, got %d requests", status, requests)
+			}
+		}
+	})
+
+	t.Run("request timeout is retried", func(t *testing.T) {
+		requests := 0
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests == 1 {
+				w.WriteHeader(http.StatusRequestTimeout)
+				return
+			}
+			feedHandler(w, r)
+		})
+		client := New(retryConfig(server.URL))
+
+		if _, err := client.Search(context.Background(), arxiv.SearchParams{Query: "ti:test"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if requests != 2 {
+			t.Errorf("expected 2 requests, got %d", requests)
+		}
+	})
+
+	t.Run("network error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(feedHandler))
+		server.Close()
+		client := New(Config{BaseURL: server.URL})
+
+		_, err := client.Search(context.Background(), arxiv.SearchParams{Query: "ti:test"})
+		var unavailable *UnavailableError
+		if !errors.As(err, &unavailable) {
+			t.Errorf("expected UnavailableError, got %v", err)
+		}
+	})
+
+	t.Run("cancelled while backing off", func(t *testing.T) {
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		})
+		client := New(Config{BaseURL: server.URL, MaxRetries: 2, RetryBaseDelay: time.Hour})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err := client.Search(ctx, arxiv.SearchParams{Query: "ti:test"})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected deadline exceeded, got %v", err)
+		}
+	})
+}
+
+func TestSearchErrorEntries(t *testing.T) {
+	t.Run("error entry in 200 response", func(t *testing.T) {
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(errorFeed))
+		})
+		client := New(Config{BaseURL: server.URL})
+
+		_, err := client.Search(context.Background(), arxiv.SearchParams{IdList: []string{"1234"}})
+		var badQuery *BadQueryError
+		if !errors.As(err, &badQuery) {
+			t.Errorf("expected BadQueryError, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "check the search fields") {
+			t.Errorf("expected error to suggest a next step, got '%s'", err)
+		}
+	})
+
+	t.Run("no papers for ids", func(t *testing.T) {
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(emptyFeed))
+		})
+		client := New(Config{BaseURL: server.URL})
+
+		_, err := client.Search(context.Background(), arxiv.SearchParams{IdList: []string{"2401.99999"}})
+		var notFound *NotFoundError
+		if !errors.As(err, &notFound) {
+			t.Fatalf("expected NotFoundError, got %v", err)
+		}
+		if len(notFound.IDs) != 1 || notFound.IDs[0] != "2401.99999" {
+			t.Errorf("expected IDs [2401.99999], got %v", notFound.IDs)
+		}
+	})
+
+	t.Run("no results for search", func(t *testing.T) {
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(emptyFeed))
+		})
+		client := New(Config{BaseURL: server.URL})
+
+		if _, err := client.Search(context.Background(), arxiv.SearchParams{Query: "ti:nothing"}); err != nil {
+			t.Errorf("expected empty search to succeed, got %v", err)
+		}
+	})
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+	}{
+		{name: "seconds", value: "30", expected: 30 * time.Second},
+		{name: "http date", value: now.Add(time.Minute).Format(http.TimeFormat), expected: time.Minute},
+		{name: "past date", value: now.Add(-time.Minute).Format(http.TimeFormat), expected: 0},
+		{name: "missing", value: "", expected: 0},
+		{name: "invalid", value: "soon", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.value != "" {
+				header.Set("Retry-After", tt.value)
+			}
+			if got := retryAfter(header, now); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	client := New(Config{RetryBaseDelay: time.Second, MaxRetryDelay: 10 * time.Second})
+
+	for attempt := range 5 {
+		delay, ok := client.retryDelay(attempt, &UnavailableError{Status: "503 Service Unavailable"})
+		if !ok {
+			t.Fatalf("expected attempt %d to be retried", attempt)
+		}
+		ceiling := min(time.Second<<attempt, 10*time.Second)
+		if delay < ceiling/2 || delay > ceiling {
+			t.Errorf("attempt %d: expected delay in [%v, %v], got %v", attempt, ceiling/2, ceiling, delay)
+		}
+	}
+
+	delay, ok := client.retryDelay(0, &RateLimitedError{RetryAfter: 5 * time.Second})
+	if !ok || delay != 5*time.Second {
+		t.Errorf("expected Retry-After to set the delay, got %v %v", delay, ok)
+	}
+
+	if _, ok := client.retryDelay(0, &BadQueryError{Message: "bad"}); ok {
+		t.Error("expected bad query not to be retried")
+	}
+
+	// Without a limit, the doubled delay is still kept from overflowing.
+	unlimited := New(Config{RetryBaseDelay: DefaultRetryBaseDelay})
+	for _, attempt := range []int{11, 40, 63, 100} {
+		delay, ok := unlimited.retryDelay(attempt, &UnavailableError{Status: "503 Service Unavailable"})
+		if !ok || delay < maxBackoff/2 || delay > maxBackoff {
+			t.Errorf("attempt %d: expected delay in [%v, %v], got %v %v", attempt, maxBackoff/2, maxBackoff, delay, ok)
+		}
+	}
+}
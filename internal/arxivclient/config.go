@@ -0,0 +1,87 @@
+package arxivclient
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/envconfig"
+)
+
+// ConfigFromEnv returns the default configuration overridden by environment
+// variables:
+//
+//	ARXIV_API_URL           arXiv API query endpoint, such as a mirror or arxiv-fake-server
+//	ARXIV_SITE_URL          arXiv site to download paper files from, such as arxiv-fake-server
+//	ARXIV_FEED_URL          arXiv announcement feeds, such as arxiv-fake-server's /atom
+//	ARXIV_OAI_URL           arXiv OAI-PMH interface, such as arxiv-fake-server's /oai
+//	ARXIV_REQUEST_INTERVAL  minimum time between requests, such as 3s
+//	ARXIV_TIMEOUT           timeout for a single request, such as 30s
+//	ARXIV_DOWNLOAD_TIMEOUT  timeout for downloading a single paper file, such as 5m
+//	ARXIV_USER_AGENT        User-Agent header
+//	ARXIV_CONTACT_EMAIL     contact address sent in the From header
+//	ARXIV_MAX_RETRIES       retries after a failed request; 0 disables retrying
+//	ARXIV_RETRY_BASE_DELAY  delay before the first retry, such as 2s
+//	ARXIV_MAX_RETRY_DELAY   longest delay before a retry, such as 1m
+func ConfigFromEnv() (Config, error) {
+	config := DefaultConfig()
+
+	if err := urlFromEnv("ARXIV_API_URL", &config.BaseURL); err != nil {
+		return Config{}, err
+	}
+	if err := urlFromEnv("ARXIV_SITE_URL", &config.SiteURL); err != nil {
+		return Config{}, err
+	}
+	if err := urlFromEnv("ARXIV_FEED_URL", &config.FeedURL); err != nil {
+		return Config{}, err
+	}
+	if err := urlFromEnv("ARXIV_OAI_URL", &config.OAIURL); err != nil {
+		return Config{}, err
+	}
+
+	if err := envconfig.Duration("ARXIV_REQUEST_INTERVAL", &config.RequestInterval); err != nil {
+		return Config{}, err
+	}
+	if err := envconfig.Duration("ARXIV_TIMEOUT", &config.Timeout); err != nil {
+		return Config{}, err
+	}
+	if err := envconfig.Duration("ARXIV_DOWNLOAD_TIMEOUT", &config.DownloadTimeout); err != nil {
+		return Config{}, err
+	}
+	if userAgent := os.Getenv("ARXIV_USER_AGENT"); userAgent != "" {
+		config.UserAgent = userAgent
+	}
+	config.ContactEmail = os.Getenv("ARXIV_CONTACT_EMAIL")
+	if value := os.Getenv("ARXIV_MAX_RETRIES"); value != "" {
+		retries, err := strconv.Atoi(value)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid ARXIV_MAX_RETRIES: %w", err)
+		}
+		if retries < 0 {
+			return Config{}, fmt.Errorf("invalid ARXIV_MAX_RETRIES: must not be negative")
+		}
+		config.MaxRetries = retries
+	}
+	if err := envconfig.Duration("ARXIV_RETRY_BASE_DELAY", &config.RetryBaseDelay); err != nil {
+		return Config{}, err
+	}
+	if err := envconfig.Duration("ARXIV_MAX_RETRY_DELAY", &config.MaxRetryDelay); err != nil {
+		return Config{}, err
+	}
+
+	return config, nil
+}
+
+func urlFromEnv(name string, u *string) error {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid %s: %q is not an http or https URL", name, value)
+	}
+	*u = value
+	return nil
+}
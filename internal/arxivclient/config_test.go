@@ -0,0 +1,119 @@
+package arxivclient
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("ARXIV_API_URL", "")
+		t.Setenv("ARXIV_SITE_URL", "")
+		t.Setenv("ARXIV_FEED_URL", "")
+		t.Setenv("ARXIV_OAI_URL", "")
+		t.Setenv("ARXIV_REQUEST_INTERVAL", "")
+		t.Setenv("ARXIV_TIMEOUT", "")
+		t.Setenv("ARXIV_DOWNLOAD_TIMEOUT", "")
+		t.Setenv("ARXIV_USER_AGENT", "")
+		t.Setenv("ARXIV_CONTACT_EMAIL", "")
+		t.Setenv("ARXIV_MAX_RETRIES", "")
+		t.Setenv("ARXIV_RETRY_BASE_DELAY", "")
+		t.Setenv("ARXIV_MAX_RETRY_DELAY", "")
+
+		config, err := ConfigFromEnv()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if config != DefaultConfig() {
+			t.Errorf("expected default config, got %+v", config)
+		}
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		t.Setenv("ARXIV_API_URL", "http://localhost:8889/api/query")
+		t.Setenv("ARXIV_SITE_URL", "http://localhost:8889")
+		t.Setenv("ARXIV_FEED_URL", "http://localhost:8889/atom")
+		t.Setenv("ARXIV_OAI_URL", "http://localhost:8889/oai")
+		t.Setenv("ARXIV_REQUEST_INTERVAL", "5s")
+		t.Setenv("ARXIV_TIMEOUT", "1m")
+		t.Setenv("ARXIV_DOWNLOAD_TIMEOUT", "10m")
+		t.Setenv("ARXIV_USER_AGENT", "test-agent")
+		t.Setenv("ARXIV_CONTACT_EMAIL", "someone@example.com")
+		t.Setenv("ARXIV_MAX_RETRIES", "0")
+		t.Setenv("ARXIV_RETRY_BASE_DELAY", "500ms")
+		t.Setenv("ARXIV_MAX_RETRY_DELAY", "10s")
+
+		config, err := ConfigFromEnv()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if config.BaseURL != "http://localhost:8889/api/query" {
+			t.Errorf("expected base URL 'http://localhost:8889/api/query', got '%s'", config.BaseURL)
+		}
+		if config.SiteURL != "http://localhost:8889" {
+			t.Errorf("expected site URL 'http://localhost:8889', got '%s'", config.SiteURL)
+		}
+		if config.FeedURL != "http://localhost:8889/atom" {
+			t.Errorf("expected feed URL 'http://localhost:8889/atom', got '%s'", config.FeedURL)
+		}
+		if config.OAIURL != "http://localhost:8889/oai" {
+			t.Errorf("expected OAI URL 'http://localhost:8889/oai', got '%s'", config.OAIURL)
+		}
+		if config.RequestInterval != 5*time.Second {
+			t.Errorf("expected request interval 5s, got %v", config.RequestInterval)
+		}
+		if config.Timeout != time.Minute {
+			t.Errorf("expected timeout 1m, got %v", config.Timeout)
+		}
+		if config.DownloadTimeout != 10*time.Minute {
+			t.Errorf("expected download timeout 10m, got %v", config.DownloadTimeout)
+		}
+		if config.UserAgent != "test-agent" {
+			t.Errorf("expected user agent 'test-agent', got '%s'", config.UserAgent)
+		}
+		if config.ContactEmail != "someone@example.com" {
+			t.Errorf("expected contact email 'someone@example.com', got '%s'", config.ContactEmail)
+		}
+		if config.MaxRetries != 0 {
+			t.Errorf("expected no retries, got %d", config.MaxRetries)
+		}
+		if config.RetryBaseDelay != 500*time.Millisecond {
+			t.Errorf("expected retry base delay 500ms, got %v", config.RetryBaseDelay)
+		}
+		if config.MaxRetryDelay != 10*time.Second {
+			t.Errorf("expected max retry delay 10s, got %v", config.MaxRetryDelay)
+		}
+	})
+
+	t.Run("invalid url", func(t *testing.T) {
+		for _, value := range []string{"export.arxiv.org/api/query", "ftp://example.com", "http://"} {
+			t.Setenv("ARXIV_API_URL", value)
+			if _, err := ConfigFromEnv(); err == nil {
+				t.Errorf("expected error for ARXIV_API_URL=%s", value)
+			}
+		}
+		t.Setenv("ARXIV_API_URL", "")
+		t.Setenv("ARXIV_SITE_URL", "arxiv.org")
+		if _, err := ConfigFromEnv(); err == nil {
+			t.Error("expected error for ARXIV_SITE_URL=arxiv.org")
+		}
+	})
+
+	t.Run("invalid retries", func(t *testing.T) {
+		for _, value := range []string{"many", "-1"} {
+			t.Setenv("ARXIV_MAX_RETRIES", value)
+			if _, err := ConfigFromEnv(); err == nil {
+				t.Errorf("expected error for ARXIV_MAX_RETRIES=%s", value)
+			}
+		}
+	})
+
+	t.Run("invalid duration", func(t *testing.T) {
+		for _, value := range []string{"soon", "-1s"} {
+			t.Setenv("ARXIV_REQUEST_INTERVAL", value)
+			if _, err := ConfigFromEnv(); err == nil {
+				t.Errorf("expected error for ARXIV_REQUEST_INTERVAL=%s", value)
+			}
+		}
+	})
+}
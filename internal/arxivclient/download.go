@@ -0,0 +1,88 @@
+package arxivclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// MaxDownloadSize is the largest paper file that will be downloaded.
+const MaxDownloadSize = 100 << 20
+
+// PDF downloads the PDF of the paper with the given ID. Without a version,
+// the latest version is downloaded.
+func (c *Client) PDF(ctx context.Context, id string) ([]byte, error) {
+	var data []byte
+	err := c.retry(ctx, func() error {
+		var err error
+		data, err = c.downloadFile(ctx, "/pdf/"+id, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Source downloads the source of the paper with the given ID, as arXiv
+// serves it: usually a gzipped tarball, a single gzipped file for papers
+// submitted as one, or a PDF for papers submitted without source.
+func (c *Client) Source(ctx context.Context, id string) ([]byte, error) {
+	var data []byte
+	err := c.retry(ctx, func() error {
+		var err error
+		data, err = c.downloadFile(ctx, "/e-print/"+id, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (c *Client) downloadFile(ctx context.Context, path, id string) ([]byte, error) {
+	return c.fetch(ctx, c.download, strings.TrimSuffix(c.config.SiteURL, "/")+path, &NotFoundError{IDs: []string{id}})
+}
+
+// fetch gets the file at rawURL with do, returning notFound if there is
+// none.
+func (c *Client) fetch(ctx context.Context, do func(*http.Request) (*http.Response, error), rawURL string, notFound error) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &UnavailableError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusTooManyRequests:
+		return nil, &RateLimitedError{RetryAfter: retryAfter(resp.Header, time.Now())}
+	case http.StatusNotFound, http.StatusGone:
+		return nil, notFound
+	default:
+		return nil, statusError(resp)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxDownloadSize+1))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &UnavailableError{Err: fmt.Errorf("reading %s: %w", req.URL.Path, err)}
+	}
+	if len(data) > MaxDownloadSize {
+		return nil, fmt.Errorf("%s is larger than %d MB", req.URL.Path, MaxDownloadSize>>20)
+	}
+	return data, nil
+}
@@ -0,0 +1,144 @@
+package arxivclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+func TestPDF(t *testing.T) {
+	t.Run("downloads from the site", func(t *testing.T) {
+		var path string
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			w.Header().Set("Content-Type", "application/pdf")
+			w.Write([]byte("%PDF-1.4 test"))
+		})
+		client := New(Config{SiteURL: server.URL})
+
+		data, err := client.PDF(context.Background(), "hep-th/9711200v3")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(data) != "%PDF-1.4 test" {
+			t.Errorf("unexpected body %q", data)
+		}
+		if path != "/pdf/hep-th/9711200v3" {
+			t.Errorf("expected path /pdf/hep-th/9711200v3, got %s", path)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			http.NotFound(w, r)
+		})
+		client := New(Config{SiteURL: server.URL})
+
+		_, err := client.PDF(context.Background(), "2401.99999")
+		var notFound *NotFoundError
+		if !errors.As(err, &notFound) {
+			t.Fatalf("expected NotFoundError, got %v", err)
+		}
+		if len(notFound.IDs) != 1 || notFound.IDs[0] != "2401.99999" {
+			t.Errorf("expected IDs [2401.99999], got %v", notFound.IDs)
+		}
+	})
+
+	t.Run("retries unavailable", func(t *testing.T) {
+		requests := 0
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests == 1 {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte("%PDF-1.4 test"))
+		})
+		client := New(Config{SiteURL: server.URL, MaxRetries: 1, RetryBaseDelay: time.Millisecond})
+
+		if _, err := client.PDF(context.Background(), "2401.01234"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if requests != 2 {
+			t.Errorf("expected 2 requests, got %d", requests)
+		}
+	})
+
+	t.Run("releases the throttle once headers arrive", func(t *testing.T) {
+		started, finish := make(chan struct{}), make(chan struct{})
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/api/query" {
+				feedHandler(w, r)
+				return
+			}
+			w.Write([]byte("%PDF-1.4 "))
+			w.(http.Flusher).Flush()
+			close(started)
+			<-finish
+			w.Write([]byte("test"))
+		})
+		t.Cleanup(func() {
+			select {
+			case <-finish:
+			default:
+				close(finish)
+			}
+		})
+		client := New(Config{BaseURL: server.URL + "/api/query", SiteURL: server.URL})
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := client.PDF(context.Background(), "2401.01234")
+			done <- err
+		}()
+		<-started
+		// The search waits its turn only while the PDF's headers are on the
+		// way, not while its body is.
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := client.Search(ctx, arxiv.SearchParams{Query: "all:electron"}); err != nil {
+			t.Fatalf("expected the search to run during the download, got %v", err)
+		}
+		close(finish)
+		if err := <-done; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("downloads outlast the API timeout", func(t *testing.T) {
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(100 * time.Millisecond)
+			w.Write([]byte("%PDF-1.4 test"))
+		})
+		client := New(Config{SiteURL: server.URL, Timeout: 20 * time.Millisecond, MaxRetries: 0})
+
+		if _, err := client.PDF(context.Background(), "2401.01234"); err != nil {
+			t.Fatalf("expected the download timeout to apply, got %v", err)
+		}
+	})
+}
+
+func TestSource(t *testing.T) {
+	var path string
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Write([]byte{0x1f, 0x8b})
+	})
+	client := New(Config{SiteURL: server.URL})
+
+	data, err := client.Source(context.Background(), "1706.03762v5")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(data) != 2 {
+		t.Errorf("unexpected body %q", data)
+	}
+	if path != "/e-print/1706.03762v5" {
+		t.Errorf("expected path /e-print/1706.03762v5, got %s", path)
+	}
+}
@@ -0,0 +1,72 @@
+package arxivclient
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// The errors below describe why a request to arXiv failed. Their messages end
+// with what the caller can do about it, since they are shown to the model
+// that made the tool call.
+
+// RateLimitedError reports that arXiv asked for fewer requests.
+type RateLimitedError struct {
+	RetryAfter time.Duration // how long arXiv asked to wait, if it said
+}
+
+func (e *RateLimitedError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("arXiv is rate limiting requests; wait %s before trying again", e.RetryAfter.Round(time.Second))
+	}
+	return "arXiv is rate limiting requests; wait a minute before trying again"
+}
+
+// UnavailableError reports that arXiv could not be reached or failed to
+// answer.
+type UnavailableError struct {
+	Status     string        // HTTP status, if arXiv answered
+	RetryAfter time.Duration // how long arXiv asked to wait, if it said
+	Err        error         // network error, if arXiv did not answer
+}
+
+func (e *UnavailableError) Error() string {
+	reason := e.Status
+	if e.Err != nil {
+		reason = e.Err.Error()
+	}
+	return fmt.Sprintf("arXiv API is unavailable (%s); try again in a few minutes", reason)
+}
+
+func (e *UnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// RejectedError reports that arXiv refused a request with a client error
+// status other than those with errors of their own, such as 403 Forbidden.
+// Unlike an UnavailableError, it is not retried.
+type RejectedError struct {
+	Status string // HTTP status
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("arXiv refused the request (%s); trying again will not help", e.Status)
+}
+
+// BadQueryError reports that arXiv rejected the search parameters.
+type BadQueryError struct {
+	Message string
+}
+
+func (e *BadQueryError) Error() string {
+	return fmt.Sprintf("arXiv rejected the query: %s; check the search fields, IDs and paging parameters", e.Message)
+}
+
+// NotFoundError reports that none of the requested papers exist.
+type NotFoundError struct {
+	IDs []string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("no arXiv papers found for %s; check the IDs, or search by title or author instead", strings.Join(e.IDs, ", "))
+}
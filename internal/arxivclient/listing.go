@@ -0,0 +1,35 @@
+package arxivclient
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/listing"
+)
+
+// Listing fetches the announcement feed of the given categories, which lists
+// the papers arXiv's latest announcement added to them.
+func (c *Client) Listing(ctx context.Context, categories []string) (listing.Feed, error) {
+	if len(categories) == 0 {
+		return listing.Feed{}, &BadQueryError{Message: "no categories given"}
+	}
+	joined := strings.Join(categories, "+")
+	var feed listing.Feed
+	err := c.retry(ctx, func() error {
+		data, err := c.fetch(ctx, c.do, strings.TrimSuffix(c.config.FeedURL, "/")+"/"+joined,
+			&BadQueryError{Message: fmt.Sprintf("arXiv has no announcement feed for %s", joined)})
+		if err != nil {
+			return err
+		}
+		feed, err = listing.Parse(data)
+		if err != nil {
+			return &UnavailableError{Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return listing.Feed{}, err
+	}
+	return feed, nil
+}
@@ -0,0 +1,58 @@
+package arxivclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestListing(t *testing.T) {
+	t.Run("fetches the feed of the categories", func(t *testing.T) {
+		var path string
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			w.Write([]byte(`<feed xmlns="http://www.w3.org/2005/Atom"><updated>2024-04-15T04:00:00Z</updated>` +
+				`<entry><id>oai:arXiv.org:2404.08001v1</id><title>A Paper</title><arxiv:announce_type xmlns:arxiv="http://arxiv.org/schemas/atom">new</arxiv:announce_type></entry></feed>`))
+		})
+		client := New(Config{FeedURL: server.URL + "/atom/"})
+
+		feed, err := client.Listing(context.Background(), []string{"cs.AI", "cs.LG"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if path != "/atom/cs.AI+cs.LG" {
+			t.Errorf("expected path /atom/cs.AI+cs.LG, got %s", path)
+		}
+		if len(feed.Items) != 1 || feed.Items[0].Entry.Title != "A Paper" {
+			t.Errorf("unexpected feed %+v", feed)
+		}
+	})
+
+	errorTests := []struct {
+		name    string
+		handler http.HandlerFunc
+		check   func(error) bool
+	}{
+		{
+			name:    "unknown category",
+			handler: http.NotFound,
+			check:   func(err error) bool { var e *BadQueryError; return errors.As(err, &e) },
+		},
+		{
+			name: "unreadable feed",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("<html>maintenance</html>"))
+			},
+			check: func(err error) bool { var e *UnavailableError; return errors.As(err, &e) },
+		},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := New(Config{FeedURL: newTestServer(t, tt.handler).URL})
+			if _, err := client.Listing(context.Background(), []string{"cs.XX"}); !tt.check(err) {
+				t.Errorf("unexpected error %v", err)
+			}
+		})
+	}
+}
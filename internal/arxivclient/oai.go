@@ -0,0 +1,35 @@
+package arxivclient
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/oaipmh"
+)
+
+// ListRecords fetches a page of records from arXiv's OAI-PMH interface.
+// Errors the interface reports, such as an expired resumption token, are
+// returned as *oaipmh.Error.
+func (c *Client) ListRecords(ctx context.Context, req oaipmh.Request) (oaipmh.Page, error) {
+	if req.ResumptionToken == "" && req.MetadataPrefix == "" {
+		return oaipmh.Page{}, &BadQueryError{Message: "no metadata format given"}
+	}
+	var page oaipmh.Page
+	err := c.retry(ctx, func() error {
+		data, err := c.fetch(ctx, c.do, c.config.OAIURL+"?"+req.Values().Encode(),
+			&BadQueryError{Message: "arXiv has no OAI-PMH interface at " + c.config.OAIURL})
+		if err != nil {
+			return err
+		}
+		page, err = oaipmh.Parse(data)
+		var oaiErr *oaipmh.Error
+		if err != nil && !errors.As(err, &oaiErr) {
+			return &UnavailableError{Err: err}
+		}
+		return err
+	})
+	if err != nil {
+		return oaipmh.Page{}, err
+	}
+	return page, nil
+}
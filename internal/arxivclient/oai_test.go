@@ -0,0 +1,102 @@
+package arxivclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/oaipmh"
+)
+
+const testOAIPage = `<OAI-PMH xmlns="http://www.openarchives.org/OAI/2.0/"><responseDate>2024-06-03T12:00:00Z</responseDate>` +
+	`<ListRecords><record><header><identifier>oai:arXiv.org:2404.08001</identifier><datestamp>2024-04-15</datestamp></header>` +
+	`<metadata><arXiv xmlns="http://arxiv.org/OAI/arXiv/"><id>2404.08001</id><title>A Paper</title></arXiv></metadata></record>` +
+	`<resumptionToken cursor="0" completeListSize="2">next</resumptionToken></ListRecords></OAI-PMH>`
+
+func TestListRecords(t *testing.T) {
+	t.Run("fetches a page of records", func(t *testing.T) {
+		var query string
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.RawQuery
+			w.Write([]byte(testOAIPage))
+		})
+		client := New(Config{OAIURL: server.URL + "/oai"})
+
+		page, err := client.ListRecords(context.Background(), oaipmh.Request{MetadataPrefix: oaipmh.FormatArXiv, Set: "cs"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if query != "metadataPrefix=arXiv&set=cs&verb=ListRecords" {
+			t.Errorf("unexpected query %s", query)
+		}
+		if len(page.Records) != 1 || page.Records[0].Title != "A Paper" || page.ResumptionToken != "next" {
+			t.Errorf("unexpected page %+v", page)
+		}
+	})
+
+	t.Run("retries when asked to wait", func(t *testing.T) {
+		requests := 0
+		server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if requests == 1 {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(testOAIPage))
+		})
+		client := New(Config{OAIURL: server.URL, MaxRetries: 1, RetryBaseDelay: time.Millisecond})
+
+		if _, err := client.ListRecords(context.Background(), oaipmh.Request{ResumptionToken: "next"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if requests != 2 {
+			t.Errorf("expected 2 requests, got %d", requests)
+		}
+	})
+
+	errorTests := []struct {
+		name    string
+		req     oaipmh.Request
+		handler http.HandlerFunc
+		check   func(error) bool
+	}{
+		{
+			name: "no format",
+			req:  oaipmh.Request{Set: "cs"},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				t.Error("expected no request")
+			},
+			check: func(err error) bool { var e *BadQueryError; return errors.As(err, &e) },
+		},
+		{
+			name: "expired resumption token",
+			req:  oaipmh.Request{ResumptionToken: "old"},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`<OAI-PMH xmlns="http://www.openarchives.org/OAI/2.0/"><error code="badResumptionToken">expired</error></OAI-PMH>`))
+			},
+			check: func(err error) bool {
+				var e *oaipmh.Error
+				return errors.As(err, &e) && e.Code == oaipmh.BadResumptionToken
+			},
+		},
+		{
+			name: "unreadable response",
+			req:  oaipmh.Request{MetadataPrefix: oaipmh.FormatArXiv},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("<html>maintenance</html>"))
+			},
+			check: func(err error) bool { var e *UnavailableError; return errors.As(err, &e) },
+		},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := New(Config{OAIURL: newTestServer(t, tt.handler).URL})
+			if _, err := client.ListRecords(context.Background(), tt.req); !tt.check(err) {
+				t.Errorf("unexpected error %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package arxivclient
+
+import (
+	"context"
+	"time"
+)
+
+// throttle lets one request proceed at a time, at least interval after the
+// previous one finished. Waiting requests are let through in arrival order.
+type throttle struct {
+	interval time.Duration
+	turn     chan struct{} // holds a token while a request is in progress
+	last     time.Time     // when the previous request finished; guarded by turn
+}
+
+func newThrottle(interval time.Duration) *throttle {
+	return &throttle{
+		interval: interval,
+		turn:     make(chan struct{}, 1),
+	}
+}
+
+// acquire waits for the caller's turn and returns a function that ends it.
+// It returns ctx's error if ctx is done first.
+func (t *throttle) acquire(ctx context.Context) (func(), error) {
+	select {
+	case t.turn <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if wait := time.Until(t.last.Add(t.interval)); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			<-t.turn
+			return nil, ctx.Err()
+		}
+	}
+
+	return func() {
+		t.last = time.Now()
+		<-t.turn
+	}, nil
+}
@@ -0,0 +1,190 @@
+// Package arxivid parses and normalizes arXiv identifiers.
+//
+// Identifiers are accepted in the forms people commonly paste: bare new-style
+// (2401.01234, 2401.01234v3) and old-style (hep-th/9901001) identifiers, the
+// arXiv: prefix, the arXiv DOI (10.48550/arXiv.2401.01234), and arxiv.org
+// abs, pdf and html URLs.
+package arxivid
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ID is a normalized arXiv identifier. Version is zero when the identifier
+// does not name a specific version.
+type ID struct {
+	Base    string
+	Version int
+}
+
+var (
+	newStyleRe = regexp.MustCompile(`^(\d{4}\.\d{4,5})(?:v(\d+))?$`)
+	// Old-style identifiers may carry a subject class (math.GT/0309136), which
+	// arXiv does not treat as part of the identifier.
+	oldStyleRe = regexp.MustCompile(`^([a-z][a-z\-]*)(?:\.[A-Za-z]{2})?/(\d{7})(?:v(\d+))?$`)
+	doiPrefix  = regexp.MustCompile(`(?i)^(?:https?://(?:dx\.)?doi\.org/)?(?:doi:)?10\.48550/arxiv\.`)
+)
+
+// Parse normalizes raw into an ID.
+func Parse(raw string) (ID, error) {
+	s := strings.TrimSpace(raw)
+	if s == "" {
+		return ID{}, fmt.Errorf("empty arXiv ID")
+	}
+
+	if loc := doiPrefix.FindStringIndex(s); loc != nil {
+		s = s[loc[1]:]
+	} else if strings.Contains(s, "arxiv.org/") {
+		path, err := urlPath(s)
+		if err != nil {
+			return ID{}, fmt.Errorf("invalid arXiv URL %q: %w", raw, err)
+		}
+		s = path
+	} else if len(s) > 6 && strings.EqualFold(s[:6], "arxiv:") {
+		s = s[6:]
+	}
+
+	s = strings.TrimSuffix(strings.TrimSpace(s), ".pdf")
+
+	if m := newStyleRe.FindStringSubmatch(s); m != nil {
+		return ID{Base: m[1], Version: atoi(m[2])}, nil
+	}
+	if m := oldStyleRe.FindStringSubmatch(s); m != nil {
+		return ID{Base: m[1] + "/" + m[2], Version: atoi(m[3])}, nil
+	}
+	return ID{}, fmt.Errorf("unrecognized arXiv ID %q", raw)
+}
+
+// Normalize returns the canonical string form of raw.
+func Normalize(raw string) (string, error) {
+	id, err := Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+// String returns the identifier with its version suffix, if any.
+func (id ID) String() string {
+	if id.Version > 0 {
+		return id.Base + "v" + strconv.Itoa(id.Version)
+	}
+	return id.Base
+}
+
+// Versioned reports whether the identifier names a specific version.
+func (id ID) Versioned() bool {
+	return id.Version > 0
+}
+
+// WithVersion returns the identifier for the given version of the same paper.
+func (id ID) WithVersion(version int) ID {
+	return ID{Base: id.Base, Version: version}
+}
+
+// Matches reports whether other identifies the same paper as id. When id is
+// unversioned any version of the paper matches.
+func (id ID) Matches(other ID) bool {
+	if id.Base != other.Base {
+		return false
+	}
+	return !id.Versioned() || id.Version == other.Version
+}
+
+var (
+	findNewStyleRe = regexp.MustCompile(`(\d{2})(\d{2})\.(\d{4,5})(?:v(\d+))?`)
+	findOldStyleRe = regexp.MustCompile(`([a-z][a-z\-]*)(?:\.[A-Za-z]{2})?/(\d{2})(\d{2})(\d{3})(?:v(\d+))?`)
+)
+
+// Find returns the identifiers mentioned in free text, such as a reference
+// list entry, in the order they first appear. Only numbers that arXiv could
+// have assigned are taken to be identifiers: a new-style number must have a
+// valid month and as many digits as arXiv used that month, and an old-style
+// one must be from 1991 to 2007.
+func Find(text string) []ID {
+	type found struct {
+		id  ID
+		pos int
+	}
+	var all []found
+	for _, m := range findNewStyleRe.FindAllStringSubmatchIndex(text, -1) {
+		// A dot may come before the number only in arXiv.2401.01234.
+		if m[0] > 0 && (isDigit(text[m[0]-1]) || text[m[0]-1] == '.' && (m[0] < 2 || !isLetter(text[m[0]-2]))) ||
+			m[1] < len(text) && isDigit(text[m[1]]) {
+			continue
+		}
+		yy, mm, number := atoi(text[m[2]:m[3]]), atoi(text[m[4]:m[5]]), text[m[6]:m[7]]
+		if mm < 1 || mm > 12 || (len(number) == 5) != (yy*100+mm >= 1501) {
+			continue
+		}
+		id := ID{Base: text[m[2]:m[7]]}
+		if m[8] >= 0 {
+			id.Version = atoi(text[m[8]:m[9]])
+		}
+		all = append(all, found{id, m[0]})
+	}
+	for _, m := range findOldStyleRe.FindAllStringSubmatchIndex(text, -1) {
+		if m[0] > 0 && (isLetter(text[m[0]-1]) || text[m[0]-1] == '-') || m[1] < len(text) && isDigit(text[m[1]]) {
+			continue
+		}
+		yy, mm := atoi(text[m[4]:m[5]]), atoi(text[m[6]:m[7]])
+		if mm < 1 || mm > 12 || (yy > 7 && yy < 91) {
+			continue
+		}
+		id := ID{Base: text[m[2]:m[3]] + "/" + text[m[4]:m[9]]}
+		if m[10] >= 0 {
+			id.Version = atoi(text[m[10]:m[11]])
+		}
+		all = append(all, found{id, m[0]})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].pos < all[j].pos })
+
+	var ids []ID
+	seen := make(map[ID]bool)
+	for _, f := range all {
+		if !seen[f.id] {
+			seen[f.id] = true
+			ids = append(ids, f.id)
+		}
+	}
+	return ids
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// urlPath extracts the identifier part of an arxiv.org abs, pdf or html URL.
+func urlPath(s string) (string, error) {
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", err
+	}
+	path := strings.Trim(u.Path, "/")
+	for _, prefix := range []string{"abs/", "pdf/", "html/", "format/", "ps/"} {
+		if strings.HasPrefix(path, prefix) {
+			return strings.TrimPrefix(path, prefix), nil
+		}
+	}
+	return "", fmt.Errorf("no paper ID in path %q", u.Path)
+}
+
+func atoi(s string) int {
+	if s == "" {
+		return 0
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,116 @@
+package arxivid
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		expected    ID
+		expectError bool
+	}{
+		{name: "new style", raw: "2401.01234", expected: ID{Base: "2401.01234"}},
+		{name: "new style with version", raw: "2401.01234v3", expected: ID{Base: "2401.01234", Version: 3}},
+		{name: "four digit sequence", raw: "0704.0001", expected: ID{Base: "0704.0001"}},
+		{name: "arxiv prefix", raw: "arXiv:2401.01234", expected: ID{Base: "2401.01234"}},
+		{name: "lowercase prefix", raw: "arxiv:2401.01234v2", expected: ID{Base: "2401.01234", Version: 2}},
+		{name: "surrounding whitespace", raw: "  2401.01234 ", expected: ID{Base: "2401.01234"}},
+		{name: "abs url", raw: "https://arxiv.org/abs/2401.01234v2", expected: ID{Base: "2401.01234", Version: 2}},
+		{name: "pdf url", raw: "https://arxiv.org/pdf/2401.01234v1.pdf", expected: ID{Base: "2401.01234", Version: 1}},
+		{name: "pdf url without extension", raw: "http://arxiv.org/pdf/2401.01234", expected: ID{Base: "2401.01234"}},
+		{name: "export url", raw: "http://export.arxiv.org/abs/2401.01234", expected: ID{Base: "2401.01234"}},
+		{name: "url without scheme", raw: "arxiv.org/abs/2401.01234", expected: ID{Base: "2401.01234"}},
+		{name: "url with query", raw: "https://arxiv.org/abs/2401.01234?context=cs", expected: ID{Base: "2401.01234"}},
+		{name: "html url", raw: "https://arxiv.org/html/2401.01234v1", expected: ID{Base: "2401.01234", Version: 1}},
+		{name: "old style", raw: "hep-th/9901001", expected: ID{Base: "hep-th/9901001"}},
+		{name: "old style with version", raw: "hep-th/9901001v2", expected: ID{Base: "hep-th/9901001", Version: 2}},
+		{name: "old style with subject class", raw: "math.GT/0309136", expected: ID{Base: "math/0309136"}},
+		{name: "old style url", raw: "https://arxiv.org/abs/hep-th/9901001", expected: ID{Base: "hep-th/9901001"}},
+		{name: "old style pdf url", raw: "https://arxiv.org/pdf/hep-th/9901001v1.pdf", expected: ID{Base: "hep-th/9901001", Version: 1}},
+		{name: "arxiv doi", raw: "10.48550/arXiv.2401.01234", expected: ID{Base: "2401.01234"}},
+		{name: "arxiv doi url", raw: "https://doi.org/10.48550/arXiv.2401.01234", expected: ID{Base: "2401.01234"}},
+		{name: "empty", raw: "", expectError: true},
+		{name: "garbage", raw: "not an id", expectError: true},
+		{name: "short sequence", raw: "2401.012", expectError: true},
+		{name: "non-paper url", raw: "https://arxiv.org/list/cs.AI/new", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := Parse(tt.raw)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error but got %v", id)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if id != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, id)
+			}
+		})
+	}
+}
+
+func TestIDString(t *testing.T) {
+	if s := (ID{Base: "2401.01234"}).String(); s != "2401.01234" {
+		t.Errorf("expected '2401.01234', got '%s'", s)
+	}
+	if s := (ID{Base: "hep-th/9901001", Version: 4}).String(); s != "hep-th/9901001v4" {
+		t.Errorf("expected 'hep-th/9901001v4', got '%s'", s)
+	}
+}
+
+func TestIDMatches(t *testing.T) {
+	unversioned := ID{Base: "2401.01234"}
+	v2 := ID{Base: "2401.01234", Version: 2}
+	v3 := ID{Base: "2401.01234", Version: 3}
+
+	if !unversioned.Matches(v3) {
+		t.Error("expected unversioned ID to match any version")
+	}
+	if !v2.Matches(v2) {
+		t.Error("expected versioned ID to match the same version")
+	}
+	if v2.Matches(v3) {
+		t.Error("expected versioned ID not to match a different version")
+	}
+	if unversioned.Matches(ID{Base: "2401.01235"}) {
+		t.Error("expected different papers not to match")
+	}
+}
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{name: "prefixed", text: "D. P. Kingma and J. Ba. Adam. arXiv preprint arXiv:1412.6980, 2014.", expected: []string{"1412.6980"}},
+		{name: "versioned and url", text: "See https://arxiv.org/abs/1706.03762v5 and arXiv:2401.01234.", expected: []string{"1706.03762v5", "2401.01234"}},
+		{name: "old style", text: "J. Maldacena. hep-th/9711200; also math.GT/0309136v2.", expected: []string{"hep-th/9711200", "math/0309136v2"}},
+		{name: "arXiv DOI", text: "doi:10.48550/arXiv.2312.00752", expected: []string{"2312.00752"}},
+		{name: "duplicates", text: "arXiv:1706.03762 (arXiv:1706.03762)", expected: []string{"1706.03762"}},
+		{name: "separated by a comma", text: "1706.03762,1412.6980", expected: []string{"1706.03762", "1412.6980"}},
+		{name: "invalid month", text: "pages 1713.0001 and 2413.12345", expected: nil},
+		{name: "wrong digit count", text: "1412.69801 or 1501.1234", expected: nil},
+		{name: "part of a longer number", text: "ISBN 91706.03762 and 1706.037621", expected: nil},
+		{name: "old style out of range", text: "foo/1234567", expected: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var found []string
+			for _, id := range Find(tt.text) {
+				found = append(found, id.String())
+			}
+			if strings.Join(found, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("expected %v, got %v", tt.expected, found)
+			}
+		})
+	}
+}
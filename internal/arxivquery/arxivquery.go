@@ -0,0 +1,366 @@
+// Package arxivquery parses arXiv API search_query strings and evaluates them
+// against documents, for serving searches without the arXiv API.
+//
+// The grammar is the one documented for the API: field:value terms, quoted
+// phrases, date ranges such as submittedDate:[202401010000 TO 202401312359],
+// the AND, OR and ANDNOT operators and parentheses. Operators are applied
+// left to right, and terms with no operator between them are combined with
+// AND. A word with no field prefix searches all fields.
+package arxivquery
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivid"
+)
+
+const (
+	OpAnd    = "AND"
+	OpOr     = "OR"
+	OpAndNot = "ANDNOT"
+)
+
+// Document is the searchable part of a paper's metadata.
+type Document struct {
+	ID         string
+	Title      string
+	Abstract   string
+	Comment    string
+	JournalRef string
+	Authors    []string
+	Categories []string
+	Submitted  time.Time
+	Updated    time.Time
+}
+
+// Node is a parsed query or part of one.
+type Node interface {
+	Match(doc Document) bool
+}
+
+// Term matches documents whose field contains every word of Value, or, for a
+// phrase, the words of Value in order. A trailing * matches any word with
+// that prefix.
+type Term struct {
+	Field  string
+	Value  string
+	Phrase bool
+}
+
+// DateRange matches documents submitted, or last updated, within a range of
+// minutes. Both ends are inclusive.
+type DateRange struct {
+	Field string
+	From  time.Time
+	To    time.Time
+}
+
+// Binary combines two nodes with AND, OR or ANDNOT.
+type Binary struct {
+	Op    string
+	Left  Node
+	Right Node
+}
+
+var textFields = map[string]bool{
+	"ti": true, "au": true, "abs": true, "co": true, "jr": true, "cat": true, "id": true, "all": true,
+}
+
+var dateFields = map[string]bool{
+	"submittedDate": true, "lastUpdatedDate": true,
+}
+
+// dateLayout is the minute-resolution format arXiv uses in date ranges.
+const dateLayout = "200601021504"
+
+// Parse parses a search_query.
+func Parse(query string) (Node, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty query")
+	}
+	p := &parser{tokens: tokens}
+	node, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	return node, nil
+}
+
+// tokenize splits a query into parentheses, operators and terms. Quoted
+// phrases and bracketed ranges are kept whole.
+func tokenize(query string) ([]string, error) {
+	var tokens []string
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, string(r))
+			i++
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' {
+				if close, ok := closers[runes[i]]; ok {
+					end := indexRune(runes, close, i+1)
+					if end < 0 {
+						return nil, fmt.Errorf("unclosed %q", runes[i])
+					}
+					i = end
+				}
+				i++
+			}
+			tokens = append(tokens, string(runes[start:i]))
+		}
+	}
+	return tokens, nil
+}
+
+var closers = map[rune]rune{'"': '"', '[': ']'}
+
+func indexRune(runes []rune, r rune, from int) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) expr() (Node, error) {
+	left, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for p.pos < len(p.tokens) && p.tokens[p.pos] != ")" {
+		op := OpAnd
+		switch p.tokens[p.pos] {
+		case OpAnd, OpOr, OpAndNot:
+			op = p.tokens[p.pos]
+			p.pos++
+		}
+		right, err := p.primary()
+		if err != nil {
+			return nil, err
+		}
+		left = Binary{Op: op, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) primary() (Node, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of query")
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	switch token {
+	case "(":
+		node, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos] != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return node, nil
+	case ")", OpAnd, OpOr, OpAndNot:
+		return nil, fmt.Errorf("unexpected %q", token)
+	}
+	return parseTerm(token)
+}
+
+func parseTerm(token string) (Node, error) {
+	field, value, ok := strings.Cut(token, ":")
+	if !ok {
+		field, value = "all", token
+	}
+	switch {
+	case dateFields[field]:
+		return parseDateRange(field, value)
+	case !textFields[field]:
+		return nil, fmt.Errorf("unknown field %q", field)
+	}
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		return Term{Field: field, Value: value[1 : len(value)-1], Phrase: true}, nil
+	}
+	if value == "" {
+		return nil, fmt.Errorf("missing value for %s", field)
+	}
+	return Term{Field: field, Value: value}, nil
+}
+
+func parseDateRange(field, value string) (Node, error) {
+	inner, ok := strings.CutPrefix(value, "[")
+	if ok {
+		inner, ok = strings.CutSuffix(inner, "]")
+	}
+	from, to, found := strings.Cut(inner, " TO ")
+	if !ok || !found {
+		return nil, fmt.Errorf("invalid date range %q for %s", value, field)
+	}
+	fromTime, err := time.Parse(dateLayout, strings.TrimSpace(from))
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q for %s", from, field)
+	}
+	toTime, err := time.Parse(dateLayout, strings.TrimSpace(to))
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q for %s", to, field)
+	}
+	return DateRange{Field: field, From: fromTime, To: toTime}, nil
+}
+
+func (b Binary) Match(doc Document) bool {
+	switch b.Op {
+	case OpOr:
+		return b.Left.Match(doc) || b.Right.Match(doc)
+	case OpAndNot:
+		return b.Left.Match(doc) && !b.Right.Match(doc)
+	default:
+		return b.Left.Match(doc) && b.Right.Match(doc)
+	}
+}
+
+func (d DateRange) Match(doc Document) bool {
+	t := doc.Submitted
+	if d.Field == "lastUpdatedDate" {
+		t = doc.Updated
+	}
+	return !t.Before(d.From) && t.Before(d.To.Add(time.Minute))
+}
+
+func (t Term) Match(doc Document) bool {
+	switch t.Field {
+	case "cat":
+		return matchCategory(doc.Categories, t.Value)
+	case "id":
+		want, err := arxivid.Parse(t.Value)
+		if err != nil {
+			return false
+		}
+		got, err := arxivid.Parse(doc.ID)
+		return err == nil && want.Matches(got)
+	case "all":
+		return matchCategory(doc.Categories, t.Value) || t.matchText(
+			doc.Title, doc.Abstract, doc.Comment, doc.JournalRef, strings.Join(doc.Authors, " ; "))
+	case "au":
+		// Each author is matched on their own, so that a phrase cannot span
+		// two names.
+		for _, author := range doc.Authors {
+			if t.matchText(author) {
+				return true
+			}
+		}
+		return false
+	}
+	return t.matchText(doc.fieldText(t.Field))
+}
+
+func (doc Document) fieldText(field string) string {
+	switch field {
+	case "ti":
+		return doc.Title
+	case "abs":
+		return doc.Abstract
+	case "co":
+		return doc.Comment
+	case "jr":
+		return doc.JournalRef
+	}
+	return ""
+}
+
+// matchText reports whether any of texts matches the term.
+func (t Term) matchText(texts ...string) bool {
+	want := Words(t.Value)
+	if len(want) == 0 {
+		return false
+	}
+	for _, text := range texts {
+		have := Words(text)
+		if t.Phrase && containsPhrase(have, want) {
+			return true
+		}
+		if !t.Phrase && containsAll(have, want) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchCategory(categories []string, value string) bool {
+	for _, category := range categories {
+		if prefix, ok := strings.CutSuffix(value, "*"); ok {
+			if strings.HasPrefix(strings.ToLower(category), strings.ToLower(prefix)) {
+				return true
+			}
+		} else if strings.EqualFold(category, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Words splits s into lowercase words as terms are matched, keeping a
+// trailing * as a wildcard.
+func Words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '*'
+	})
+}
+
+func wordMatches(have, want string) bool {
+	if prefix, ok := strings.CutSuffix(want, "*"); ok {
+		return strings.HasPrefix(have, prefix)
+	}
+	return have == want
+}
+
+func containsAll(have, want []string) bool {
+	for _, w := range want {
+		found := false
+		for _, h := range have {
+			if wordMatches(h, w) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func containsPhrase(have, want []string) bool {
+	for i := 0; i+len(want) <= len(have); i++ {
+		match := true
+		for j, w := range want {
+			if !wordMatches(have[i+j], w) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package arxivquery
+
+import (
+	"testing"
+	"time"
+)
+
+var testDoc = Document{
+	ID:         "http://arxiv.org/abs/1706.03762v7",
+	Title:      "Attention Is All You Need",
+	Abstract:   "We propose a new simple network architecture, the Transformer, based solely on attention mechanisms.",
+	Comment:    "15 pages, 5 figures",
+	JournalRef: "Advances in Neural Information Processing Systems 30 (2017)",
+	Authors:    []string{"Ashish Vaswani", "Noam Shazeer", "Niki Parmar"},
+	Categories: []string{"cs.CL", "cs.LG"},
+	Submitted:  time.Date(2017, 6, 12, 17, 57, 34, 0, time.UTC),
+	Updated:    time.Date(2023, 8, 2, 0, 41, 18, 0, time.UTC),
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		expectError bool
+		validate    func(t *testing.T, node Node)
+	}{
+		{
+			name:  "field term",
+			query: "ti:attention",
+			validate: func(t *testing.T, node Node) {
+				if node != (Term{Field: "ti", Value: "attention"}) {
+					t.Errorf("unexpected node %#v", node)
+				}
+			},
+		},
+		{
+			name:  "phrase",
+			query: `ti:"attention is all"`,
+			validate: func(t *testing.T, node Node) {
+				if node != (Term{Field: "ti", Value: "attention is all", Phrase: true}) {
+					t.Errorf("unexpected node %#v", node)
+				}
+			},
+		},
+		{
+			name:  "bare word searches all fields",
+			query: "transformer",
+			validate: func(t *testing.T, node Node) {
+				if node != (Term{Field: "all", Value: "transformer"}) {
+					t.Errorf("unexpected node %#v", node)
+				}
+			},
+		},
+		{
+			name:  "date range",
+			query: "submittedDate:[201706010000 TO 201706302359]",
+			validate: func(t *testing.T, node Node) {
+				expected := DateRange{
+					Field: "submittedDate",
+					From:  time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC),
+					To:    time.Date(2017, 6, 30, 23, 59, 0, 0, time.UTC),
+				}
+				if node != expected {
+					t.Errorf("unexpected node %#v", node)
+				}
+			},
+		},
+		{
+			name:  "operators apply left to right",
+			query: "ti:a OR ti:b AND ti:c",
+			validate: func(t *testing.T, node Node) {
+				b, ok := node.(Binary)
+				if !ok || b.Op != OpAnd {
+					t.Fatalf("expected AND at the root, got %#v", node)
+				}
+				if left, ok := b.Left.(Binary); !ok || left.Op != OpOr {
+					t.Errorf("expected OR on the left, got %#v", b.Left)
+				}
+			},
+		},
+		{
+			name:  "juxtaposed terms",
+			query: "ti:a au:b",
+			validate: func(t *testing.T, node Node) {
+				if b, ok := node.(Binary); !ok || b.Op != OpAnd {
+					t.Errorf("expected AND, got %#v", node)
+				}
+			},
+		},
+		{
+			name:  "parentheses",
+			query: "ti:a AND (au:b OR au:c)",
+			validate: func(t *testing.T, node Node) {
+				b, ok := node.(Binary)
+				if !ok || b.Op != OpAnd {
+					t.Fatalf("expected AND at the root, got %#v", node)
+				}
+				if right, ok := b.Right.(Binary); !ok || right.Op != OpOr {
+					t.Errorf("expected OR on the right, got %#v", b.Right)
+				}
+			},
+		},
+		{name: "empty", query: "  ", expectError: true},
+		{name: "unknown field", query: "xx:foo", expectError: true},
+		{name: "missing value", query: "ti:", expectError: true},
+		{name: "unclosed phrase", query: `ti:"attention`, expectError: true},
+		{name: "unclosed group", query: "(ti:a OR ti:b", expectError: true},
+		{name: "stray parenthesis", query: "ti:a)", expectError: true},
+		{name: "dangling operator", query: "ti:a AND", expectError: true},
+		{name: "invalid date", query: "submittedDate:[2017 TO 2018]", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := Parse(tt.query)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error, got %#v", node)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.validate != nil {
+				tt.validate(t, node)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{"ti:attention", true},
+		{"ti:Attention", true},
+		{"ti:transformer", false},
+		{"abs:transformer", true},
+		{"ti:atten*", true},
+		{`ti:"all you need"`, true},
+		{`ti:"need you all"`, false},
+		{"au:vaswani", true},
+		{`au:"ashish vaswani"`, true},
+		{`au:"vaswani noam"`, false},
+		{"cat:cs.LG", true},
+		{"cat:cs.lg", true},
+		{"cat:cs.*", true},
+		{"cat:cs.AI", false},
+		{"co:figures", true},
+		{"jr:neural", true},
+		{"id:1706.03762", true},
+		{"id:1706.03762v7", true},
+		{"id:1706.03762v1", false},
+		{"transformer", true},
+		{"all:shazeer", true},
+		{"ti:attention AND au:smith", false},
+		{"ti:attention OR au:smith", true},
+		{"ti:attention ANDNOT cat:cs.CL", false},
+		{"ti:attention ANDNOT (cat:cs.AI OR cat:stat.ML)", true},
+		{"submittedDate:[201706120000 TO 201706121757]", true},
+		{"submittedDate:[201706120000 TO 201706121756]", false},
+		{"submittedDate:[201801010000 TO 201812312359]", false},
+		{"lastUpdatedDate:[202308010000 TO 202308312359]", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := node.Match(testDoc); got != tt.expected {
+				t.Errorf("expected match %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "entry.json")
+	for _, data := range []string{"first", "second"} {
+		if err := Write(path, []byte(data)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got, err := os.ReadFile(path); err != nil || string(got) != data {
+			t.Errorf("expected %q, got %q and %v", data, got, err)
+		}
+	}
+	if files, err := os.ReadDir(dir); err != nil || len(files) != 1 {
+		t.Errorf("expected no temporary files left, got %v and %v", files, err)
+	}
+
+	if err := Write(filepath.Join(dir, "missing", "entry.json"), nil); err == nil {
+		t.Error("expected an error writing to a missing directory")
+	}
+}
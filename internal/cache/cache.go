@@ -0,0 +1,221 @@
+// Package cache caches arXiv API responses, in memory and optionally on disk,
+// and paper files downloaded from arXiv, on disk.
+//
+// Responses are keyed on normalized search parameters, so that requests
+// differing only in whitespace, ID form or defaulted fields share an entry.
+// How long a response is kept depends on how quickly it can go stale: ID
+// lookups change rarely, while searches bounded or sorted by date change as
+// papers are announced.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivid"
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+const (
+	DefaultSize    = 512
+	DefaultTTL     = time.Hour
+	DefaultIDTTL   = 7 * 24 * time.Hour
+	DefaultDateTTL = 10 * time.Minute
+)
+
+// Searcher runs arXiv API searches.
+type Searcher interface {
+	Search(ctx context.Context, params arxiv.SearchParams) (arxiv.SearchResults, error)
+}
+
+type Config struct {
+	Size    int           // maximum number of responses kept in memory; 0 keeps none
+	Dir     string        // directory for the on-disk store; empty disables it
+	TTL     time.Duration // lifetime of searches
+	IDTTL   time.Duration // lifetime of ID lookups
+	DateTTL time.Duration // lifetime of searches bounded or sorted by date
+
+	FilesDir string // directory for downloaded paper files; empty disables keeping them
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Size:    DefaultSize,
+		TTL:     DefaultTTL,
+		IDTTL:   DefaultIDTTL,
+		DateTTL: DefaultDateTTL,
+
+		FilesDir: defaultFilesDir(),
+	}
+}
+
+// defaultFilesDir returns the arxiv-mcp directory in the user's cache
+// directory, or the empty string if there is none.
+func defaultFilesDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "arxiv-mcp", "files")
+}
+
+// Cache is a Searcher that answers repeated searches from earlier responses.
+type Cache struct {
+	searcher Searcher
+	config   Config
+	now      func() time.Time
+
+	mu     sync.Mutex // guards memory
+	memory *lru
+	disk   *diskStore // nil if there is no on-disk store
+}
+
+// New returns a cache in front of searcher. It creates config.Dir if it does
+// not exist.
+func New(searcher Searcher, config Config) (*Cache, error) {
+	c := &Cache{
+		searcher: searcher,
+		config:   config,
+		now:      time.Now,
+		memory:   newLRU(config.Size),
+	}
+	if config.Dir != "" {
+		disk, err := newDiskStore(config.Dir)
+		if err != nil {
+			return nil, err
+		}
+		c.disk = disk
+	}
+	return c, nil
+}
+
+func (c *Cache) Search(ctx context.Context, params arxiv.SearchParams) (arxiv.SearchResults, error) {
+	results, _, err := c.SearchCached(ctx, params)
+	return results, err
+}
+
+// SearchCached is like Search, but also reports whether the results came
+// from the cache.
+func (c *Cache) SearchCached(ctx context.Context, params arxiv.SearchParams) (arxiv.SearchResults, bool, error) {
+	key := Key(params)
+	if results, ok := c.get(key); ok {
+		return results, true, nil
+	}
+
+	results, err := c.searcher.Search(ctx, params)
+	if err != nil {
+		return arxiv.SearchResults{}, false, err
+	}
+	if ttl := c.ttl(params); ttl > 0 {
+		c.put(entry{Key: key, Results: results, Expires: c.now().Add(ttl)})
+	}
+	return results, false, nil
+}
+
+func (c *Cache) get(key string) (arxiv.SearchResults, bool) {
+	now := c.now()
+	c.mu.Lock()
+	e, ok := c.memory.get(key)
+	if ok && !now.Before(e.Expires) {
+		c.memory.remove(key)
+		ok = false
+	}
+	c.mu.Unlock()
+	if ok {
+		return e.Results, true
+	}
+	if c.disk == nil {
+		return arxiv.SearchResults{}, false
+	}
+
+	// The disk is read without the lock, so that a slow disk does not hold
+	// up searches answered from memory.
+	e, ok = c.disk.load(key)
+	if !ok {
+		return arxiv.SearchResults{}, false
+	}
+	if !now.Before(e.Expires) {
+		c.disk.remove(key)
+		return arxiv.SearchResults{}, false
+	}
+	c.mu.Lock()
+	c.memory.add(e)
+	c.mu.Unlock()
+	return e.Results, true
+}
+
+func (c *Cache) put(e entry) {
+	c.mu.Lock()
+	c.memory.add(e)
+	c.mu.Unlock()
+
+	if c.disk != nil {
+		c.disk.sweep(c.now())
+		// A response that cannot be stored is still returned, so a failing
+		// disk only costs later cache misses.
+		_ = c.disk.save(e)
+	}
+}
+
+// ttl returns how long the response to params may be reused.
+func (c *Cache) ttl(params arxiv.SearchParams) time.Duration {
+	switch {
+	case len(params.IdList) > 0 && strings.TrimSpace(params.Query) == "":
+		return c.config.IDTTL
+	case strings.Contains(params.Query, "submittedDate:"),
+		strings.Contains(params.Query, "lastUpdatedDate:"),
+		params.SortBy == arxiv.SortBySubmittedDate,
+		params.SortBy == arxiv.SortByLastUpdatedDate:
+		return c.config.DateTTL
+	default:
+		return c.config.TTL
+	}
+}
+
+type entry struct {
+	Key     string              `json:"key"`
+	Results arxiv.SearchResults `json:"results"`
+	Expires time.Time           `json:"expires"`
+}
+
+// Key returns the cache key for params. Parameters that the arXiv API treats
+// the same have the same key.
+func Key(params arxiv.SearchParams) string {
+	normalized := arxiv.SearchParams{
+		Query:      strings.Join(strings.Fields(params.Query), " "),
+		Start:      params.Start,
+		MaxResults: params.MaxResults,
+		SortBy:     params.SortBy,
+		SortOrder:  params.SortOrder,
+	}
+	for _, id := range params.IdList {
+		if n, err := arxivid.Normalize(id); err == nil {
+			id = n
+		}
+		normalized.IdList = append(normalized.IdList, id)
+	}
+	if normalized.MaxResults == 0 {
+		normalized.MaxResults = 10
+	}
+	if normalized.SortBy == "" {
+		normalized.SortBy = arxiv.SortByRelevance
+	}
+	if normalized.SortOrder == "" {
+		normalized.SortOrder = arxiv.SortOrderDescending
+	}
+
+	data, err := json.Marshal(normalized)
+	if err != nil {
+		// SearchParams only holds strings and ints.
+		panic(err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
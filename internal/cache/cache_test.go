@@ -0,0 +1,331 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// countingSearcher returns a result titled with the query and counts calls.
+type countingSearcher struct {
+	calls int
+	err   error
+}
+
+func (s *countingSearcher) Search(ctx context.Context, params arxiv.SearchParams) (arxiv.SearchResults, error) {
+	s.calls++
+	if s.err != nil {
+		return arxiv.SearchResults{}, s.err
+	}
+	return arxiv.SearchResults{
+		TotalResults: 1,
+		Entries:      []arxiv.EntryMetadata{{Title: params.Query}},
+		Params:       params,
+	}, nil
+}
+
+// clock is a settable time source for expiry tests.
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newTestCache(t *testing.T, searcher Searcher, config Config) (*Cache, *clock) {
+	t.Helper()
+	c, err := New(searcher, config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clk := &clock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c.now = clk.Now
+	return c, clk
+}
+
+func TestSearchCached(t *testing.T) {
+	ctx := context.Background()
+	params := arxiv.SearchParams{Query: "ti:quantum"}
+
+	t.Run("miss then hit", func(t *testing.T) {
+		searcher := &countingSearcher{}
+		c, _ := newTestCache(t, searcher, DefaultConfig())
+
+		results, hit, err := c.SearchCached(ctx, params)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if hit {
+			t.Error("expected first search to miss")
+		}
+		if len(results.Entries) != 1 || results.Entries[0].Title != "ti:quantum" {
+			t.Errorf("unexpected results: %+v", results)
+		}
+
+		results, hit, err = c.SearchCached(ctx, params)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !hit {
+			t.Error("expected second search to hit")
+		}
+		if len(results.Entries) != 1 || results.Entries[0].Title != "ti:quantum" {
+			t.Errorf("unexpected cached results: %+v", results)
+		}
+		if searcher.calls != 1 {
+			t.Errorf("expected 1 upstream call, got %d", searcher.calls)
+		}
+	})
+
+	t.Run("expires", func(t *testing.T) {
+		searcher := &countingSearcher{}
+		c, clk := newTestCache(t, searcher, DefaultConfig())
+
+		c.Search(ctx, params)
+		clk.now = clk.now.Add(DefaultTTL - time.Second)
+		if _, hit, _ := c.SearchCached(ctx, params); !hit {
+			t.Error("expected hit before the TTL")
+		}
+		clk.now = clk.now.Add(time.Second)
+		if _, hit, _ := c.SearchCached(ctx, params); hit {
+			t.Error("expected miss once the TTL has passed")
+		}
+		if searcher.calls != 2 {
+			t.Errorf("expected 2 upstream calls, got %d", searcher.calls)
+		}
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		searcher := &countingSearcher{err: errors.New("unavailable")}
+		c, _ := newTestCache(t, searcher, DefaultConfig())
+
+		if _, _, err := c.SearchCached(ctx, params); err == nil {
+			t.Fatal("expected error")
+		}
+		searcher.err = nil
+		if _, hit, err := c.SearchCached(ctx, params); err != nil || hit {
+			t.Errorf("expected uncached success after error, got hit=%v err=%v", hit, err)
+		}
+	})
+
+	t.Run("zero ttl disables caching", func(t *testing.T) {
+		searcher := &countingSearcher{}
+		config := DefaultConfig()
+		config.TTL = 0
+		c, _ := newTestCache(t, searcher, config)
+
+		c.Search(ctx, params)
+		if _, hit, _ := c.SearchCached(ctx, params); hit {
+			t.Error("expected miss with a zero TTL")
+		}
+	})
+
+	t.Run("on-disk store outlives the cache", func(t *testing.T) {
+		config := DefaultConfig()
+		config.Dir = t.TempDir()
+
+		first, _ := newTestCache(t, &countingSearcher{}, config)
+		if _, err := first.Search(ctx, params); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		searcher := &countingSearcher{}
+		second, clk := newTestCache(t, searcher, config)
+		results, hit, err := second.SearchCached(ctx, params)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !hit {
+			t.Error("expected hit from the on-disk store")
+		}
+		if len(results.Entries) != 1 || results.Entries[0].Title != "ti:quantum" {
+			t.Errorf("unexpected results from disk: %+v", results)
+		}
+		if searcher.calls != 0 {
+			t.Errorf("expected no upstream calls, got %d", searcher.calls)
+		}
+
+		second.memory = newLRU(config.Size)
+		clk.now = clk.now.Add(DefaultTTL)
+		if _, hit, _ := second.SearchCached(ctx, params); hit {
+			t.Error("expected expired entry on disk to miss")
+		}
+	})
+}
+
+func TestDiskSweep(t *testing.T) {
+	ctx := context.Background()
+	config := DefaultConfig()
+	config.Dir = t.TempDir()
+	c, clk := newTestCache(t, &countingSearcher{}, config)
+
+	expired := arxiv.SearchParams{Query: "ti:quantum"}
+	if _, err := c.Search(ctx, expired); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	other := filepath.Join(config.Dir, "notes.json")
+	tmp := c.disk.path(Key(expired)) + ".123.tmp"
+	for _, path := range []string{other, tmp} {
+		if err := os.WriteFile(path, []byte("{}"), 0o644); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := os.Chtimes(tmp, clk.now, clk.now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	clk.now = clk.now.Add(sweepInterval + DefaultTTL)
+	fresh := arxiv.SearchParams{Query: "ti:graph"}
+	if _, err := c.Search(ctx, fresh); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for path, kept := range map[string]bool{
+		c.disk.path(Key(expired)): false,
+		tmp:                       false,
+		c.disk.path(Key(fresh)):   true,
+		other:                     true,
+	} {
+		if _, err := os.Stat(path); (err == nil) != kept {
+			t.Errorf("%s: expected kept %v, got %v", filepath.Base(path), kept, err)
+		}
+	}
+}
+
+// echoSearcher returns a result titled with the query, and is safe for
+// concurrent use.
+type echoSearcher struct{}
+
+func (echoSearcher) Search(ctx context.Context, params arxiv.SearchParams) (arxiv.SearchResults, error) {
+	return arxiv.SearchResults{Entries: []arxiv.EntryMetadata{{Title: params.Query}}}, nil
+}
+
+func TestConcurrentSearch(t *testing.T) {
+	ctx := context.Background()
+	config := DefaultConfig()
+	config.Dir = t.TempDir()
+	// A small memory cache sends most lookups to the disk.
+	config.Size = 2
+	c, _ := newTestCache(t, echoSearcher{}, config)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 20 {
+				query := fmt.Sprintf("ti:q%d", (i+j)%5)
+				results, err := c.Search(ctx, arxiv.SearchParams{Query: query})
+				if err != nil || results.Entries[0].Title != query {
+					t.Errorf("expected results for %s, got %+v, %v", query, results, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestTTL(t *testing.T) {
+	c, _ := newTestCache(t, &countingSearcher{}, DefaultConfig())
+
+	tests := []struct {
+		name     string
+		params   arxiv.SearchParams
+		expected time.Duration
+	}{
+		{
+			name:     "id lookup",
+			params:   arxiv.SearchParams{IdList: []string{"2401.01234"}},
+			expected: DefaultIDTTL,
+		},
+		{
+			name:     "date bounded search",
+			params:   arxiv.SearchParams{Query: "cat:cs.LG AND submittedDate:[202401010000 TO 202401312359]"},
+			expected: DefaultDateTTL,
+		},
+		{
+			name:     "sorted by date",
+			params:   arxiv.SearchParams{Query: "cat:cs.LG", SortBy: arxiv.SortBySubmittedDate},
+			expected: DefaultDateTTL,
+		},
+		{
+			name:     "ids filtered by query",
+			params:   arxiv.SearchParams{Query: "ti:quantum", IdList: []string{"2401.01234"}},
+			expected: DefaultTTL,
+		},
+		{
+			name:     "search",
+			params:   arxiv.SearchParams{Query: "ti:quantum"},
+			expected: DefaultTTL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ttl := c.ttl(tt.params); ttl != tt.expected {
+				t.Errorf("expected TTL %v, got %v", tt.expected, ttl)
+			}
+		})
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  arxiv.SearchParams
+		equal bool
+	}{
+		{
+			name:  "whitespace in query",
+			a:     arxiv.SearchParams{Query: "ti:quantum  AND au:Smith"},
+			b:     arxiv.SearchParams{Query: " ti:quantum AND au:Smith"},
+			equal: true,
+		},
+		{
+			name:  "defaults",
+			a:     arxiv.SearchParams{Query: "ti:quantum"},
+			b:     arxiv.SearchParams{Query: "ti:quantum", MaxResults: 10, SortBy: arxiv.SortByRelevance, SortOrder: arxiv.SortOrderDescending},
+			equal: true,
+		},
+		{
+			name:  "id forms",
+			a:     arxiv.SearchParams{IdList: []string{"arXiv:2401.01234v2"}},
+			b:     arxiv.SearchParams{IdList: []string{"https://arxiv.org/abs/2401.01234v2"}},
+			equal: true,
+		},
+		{
+			name:  "different versions",
+			a:     arxiv.SearchParams{IdList: []string{"2401.01234v1"}},
+			b:     arxiv.SearchParams{IdList: []string{"2401.01234v2"}},
+			equal: false,
+		},
+		{
+			name:  "different pages",
+			a:     arxiv.SearchParams{Query: "ti:quantum"},
+			b:     arxiv.SearchParams{Query: "ti:quantum", Start: 10},
+			equal: false,
+		},
+		{
+			name:  "different sort order",
+			a:     arxiv.SearchParams{Query: "ti:quantum", SortOrder: arxiv.SortOrderAscending},
+			b:     arxiv.SearchParams{Query: "ti:quantum"},
+			equal: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if equal := Key(tt.a) == Key(tt.b); equal != tt.equal {
+				t.Errorf("expected keys equal=%v for %+v and %+v", tt.equal, tt.a, tt.b)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/envconfig"
+)
+
+// ConfigFromEnv returns the default configuration overridden by environment
+// variables:
+//
+//	ARXIV_CACHE_SIZE      responses kept in memory; 0 disables the in-memory cache
+//	ARXIV_CACHE_DIR       directory for the on-disk store; unset disables it
+//	ARXIV_CACHE_TTL       lifetime of searches, such as 1h
+//	ARXIV_CACHE_ID_TTL    lifetime of ID lookups, such as 168h
+//	ARXIV_CACHE_DATE_TTL  lifetime of searches bounded or sorted by date, such as 10m
+//	ARXIV_FILES_DIR       directory for downloaded paper files; defaults to arxiv-mcp/files
+//	                      in the user's cache directory, and "off" disables keeping them
+//
+// A TTL of 0 disables caching for that kind of request.
+func ConfigFromEnv() (Config, error) {
+	config := DefaultConfig()
+
+	if value := os.Getenv("ARXIV_CACHE_SIZE"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid ARXIV_CACHE_SIZE: %w", err)
+		}
+		if size < 0 {
+			return Config{}, fmt.Errorf("invalid ARXIV_CACHE_SIZE: must not be negative")
+		}
+		config.Size = size
+	}
+	config.Dir = os.Getenv("ARXIV_CACHE_DIR")
+	switch dir := os.Getenv("ARXIV_FILES_DIR"); dir {
+	case "":
+	case "off":
+		config.FilesDir = ""
+	default:
+		config.FilesDir = dir
+	}
+	if err := envconfig.Duration("ARXIV_CACHE_TTL", &config.TTL); err != nil {
+		return Config{}, err
+	}
+	if err := envconfig.Duration("ARXIV_CACHE_ID_TTL", &config.IDTTL); err != nil {
+		return Config{}, err
+	}
+	if err := envconfig.Duration("ARXIV_CACHE_DATE_TTL", &config.DateTTL); err != nil {
+		return Config{}, err
+	}
+
+	return config, nil
+}
@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/atomicfile"
+)
+
+// sweepInterval is how often expired entries are swept from the on-disk
+// store.
+const sweepInterval = time.Hour
+
+// diskStore keeps entries as JSON files named by key, so that they outlive
+// the process. Expired files are removed when they are next looked up, and
+// by a sweep of the whole store on the first write and each sweepInterval
+// after. It is safe for concurrent use: files are replaced by renaming, so
+// a reader sees either the old entry or the new one.
+type diskStore struct {
+	dir string
+
+	mu    sync.Mutex
+	swept time.Time // when the store was last swept
+}
+
+func newDiskStore(dir string) (*diskStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &diskStore{dir: dir}, nil
+}
+
+func (s *diskStore) path(key string) string {
+	return filepath.Join(s.dir, key+".json")
+}
+
+// load returns the stored entry for key. Unreadable entries are treated as
+// missing.
+func (s *diskStore) load(key string) (entry, bool) {
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return entry{}, false
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil || e.Key != key {
+		return entry{}, false
+	}
+	return e, true
+}
+
+// save writes e to the store, replacing any earlier entry for its key.
+func (s *diskStore) save(e entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return atomicfile.Write(s.path(e.Key), data)
+}
+
+func (s *diskStore) remove(key string) {
+	os.Remove(s.path(key))
+}
+
+// keyLength is the length of a key: a hex-encoded SHA-256 sum.
+const keyLength = 64
+
+func isKey(name string) bool {
+	if len(name) != keyLength {
+		return false
+	}
+	_, err := hex.DecodeString(name)
+	return err == nil
+}
+
+// sweep removes the files of entries that expired by now, files that cannot
+// be read as entries, and temporary files left by writes cut short, if the
+// store was last swept more than sweepInterval ago.
+func (s *diskStore) sweep(now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.swept) < sweepInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.swept = now
+	s.mu.Unlock()
+
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		// Files not named by a key are not the store's to remove.
+		if !isKey(file.Name()[:min(len(file.Name()), keyLength)]) {
+			continue
+		}
+		path := filepath.Join(s.dir, file.Name())
+		switch {
+		case strings.HasSuffix(file.Name(), ".tmp"):
+			if info, err := file.Info(); err == nil && now.Sub(info.ModTime()) > sweepInterval {
+				os.Remove(path)
+			}
+		case file.Name() == file.Name()[:keyLength]+".json":
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			var e struct {
+				Expires time.Time `json:"expires"`
+			}
+			if err := json.Unmarshal(data, &e); err != nil || !now.Before(e.Expires) {
+				os.Remove(path)
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivid"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/atomicfile"
+)
+
+// Downloader downloads paper files from arXiv.
+type Downloader interface {
+	PDF(ctx context.Context, id string) ([]byte, error)
+	Source(ctx context.Context, id string) ([]byte, error)
+}
+
+// Files is a Downloader that keeps downloaded files on disk. A file for a
+// specific version of a paper never changes, so it is kept until removed. A
+// file for the latest version is downloaded again once it is older than the
+// ID lookup TTL, in case a new version has been announced since.
+type Files struct {
+	downloader Downloader
+	dir        string // empty if files are not kept
+	ttl        time.Duration
+	now        func() time.Time
+}
+
+// NewFiles returns a file cache in front of downloader, keeping files under
+// config.FilesDir. It creates the directory if it does not exist.
+func NewFiles(downloader Downloader, config Config) (*Files, error) {
+	f := &Files{
+		downloader: downloader,
+		dir:        config.FilesDir,
+		ttl:        config.IDTTL,
+		now:        time.Now,
+	}
+	if f.dir != "" {
+		if err := os.MkdirAll(f.dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func (f *Files) PDF(ctx context.Context, id string) ([]byte, error) {
+	data, _, err := f.PDFCached(ctx, id)
+	return data, err
+}
+
+// PDFCached is like PDF, but also reports whether the file came from the
+// cache.
+func (f *Files) PDFCached(ctx context.Context, id string) ([]byte, bool, error) {
+	return f.file(ctx, id, "pdf", f.downloader.PDF)
+}
+
+func (f *Files) Source(ctx context.Context, id string) ([]byte, error) {
+	data, _, err := f.SourceCached(ctx, id)
+	return data, err
+}
+
+// SourceCached is like Source, but also reports whether the file came from
+// the cache.
+func (f *Files) SourceCached(ctx context.Context, id string) ([]byte, bool, error) {
+	return f.file(ctx, id, "source", f.downloader.Source)
+}
+
+func (f *Files) file(ctx context.Context, rawID, kind string, download func(context.Context, string) ([]byte, error)) ([]byte, bool, error) {
+	id, err := arxivid.Parse(rawID)
+	if err != nil || f.dir == "" {
+		data, err := download(ctx, rawID)
+		return data, false, err
+	}
+
+	path := f.path(id, kind)
+	if data, ok := f.load(path, id.Versioned()); ok {
+		return data, true, nil
+	}
+	data, err := download(ctx, id.String())
+	if err != nil {
+		return nil, false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err == nil {
+		// A file that cannot be stored is still returned, so a failing disk
+		// only costs later downloads.
+		_ = atomicfile.Write(path, data)
+	}
+	return data, false, nil
+}
+
+// path returns where the file of the given kind is kept for id. Old-style
+// IDs contain a slash, which is replaced so that each file is in one
+// directory per kind.
+func (f *Files) path(id arxivid.ID, kind string) string {
+	return filepath.Join(f.dir, kind, strings.ReplaceAll(id.String(), "/", "_")+"."+kind)
+}
+
+func (f *Files) load(path string, versioned bool) ([]byte, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	if !versioned && !f.now().Before(info.ModTime().Add(f.ttl)) {
+		os.Remove(path)
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
@@ -0,0 +1,157 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// countingDownloader returns the requested ID as the file and counts calls.
+type countingDownloader struct {
+	calls int
+	err   error
+}
+
+func (d *countingDownloader) PDF(ctx context.Context, id string) ([]byte, error) {
+	d.calls++
+	if d.err != nil {
+		return nil, d.err
+	}
+	return []byte("pdf of " + id), nil
+}
+
+func (d *countingDownloader) Source(ctx context.Context, id string) ([]byte, error) {
+	d.calls++
+	if d.err != nil {
+		return nil, d.err
+	}
+	return []byte("source of " + id), nil
+}
+
+func newTestFiles(t *testing.T, downloader Downloader, dir string) (*Files, *clock) {
+	t.Helper()
+	config := DefaultConfig()
+	config.FilesDir = dir
+	f, err := NewFiles(downloader, config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	clk := &clock{now: time.Now()}
+	f.now = clk.Now
+	return f, clk
+}
+
+func TestPDFCached(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("miss then hit", func(t *testing.T) {
+		downloader := &countingDownloader{}
+		f, _ := newTestFiles(t, downloader, t.TempDir())
+
+		data, hit, err := f.PDFCached(ctx, "arXiv:hep-th/9711200v3")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if hit {
+			t.Error("expected first download to miss")
+		}
+		if string(data) != "pdf of hep-th/9711200v3" {
+			t.Errorf("expected the normalized ID to be downloaded, got %q", data)
+		}
+
+		data, hit, err = f.PDFCached(ctx, "hep-th/9711200v3")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !hit || string(data) != "pdf of hep-th/9711200v3" {
+			t.Errorf("expected cached file, got hit=%v data=%q", hit, data)
+		}
+		if downloader.calls != 1 {
+			t.Errorf("expected 1 download, got %d", downloader.calls)
+		}
+	})
+
+	t.Run("kept on disk", func(t *testing.T) {
+		dir := t.TempDir()
+		first, _ := newTestFiles(t, &countingDownloader{}, dir)
+		first.PDF(ctx, "1706.03762v7")
+
+		downloader := &countingDownloader{}
+		second, _ := newTestFiles(t, downloader, dir)
+		if _, hit, _ := second.PDFCached(ctx, "1706.03762v7"); !hit {
+			t.Error("expected a new cache on the same directory to hit")
+		}
+		if _, err := os.Stat(filepath.Join(dir, "pdf", "1706.03762v7.pdf")); err != nil {
+			t.Errorf("expected file on disk: %v", err)
+		}
+	})
+
+	t.Run("latest version expires", func(t *testing.T) {
+		downloader := &countingDownloader{}
+		f, clk := newTestFiles(t, downloader, t.TempDir())
+
+		f.PDF(ctx, "1706.03762")
+		f.PDF(ctx, "1706.03762v7")
+		clk.now = clk.now.Add(DefaultIDTTL + time.Minute)
+		if _, hit, _ := f.PDFCached(ctx, "1706.03762"); hit {
+			t.Error("expected the latest version to be downloaded again after the TTL")
+		}
+		if _, hit, _ := f.PDFCached(ctx, "1706.03762v7"); !hit {
+			t.Error("expected a specific version to be kept")
+		}
+		if downloader.calls != 3 {
+			t.Errorf("expected 3 downloads, got %d", downloader.calls)
+		}
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		downloader := &countingDownloader{err: errors.New("unavailable")}
+		f, _ := newTestFiles(t, downloader, t.TempDir())
+
+		if _, err := f.PDF(ctx, "1706.03762v7"); err == nil {
+			t.Fatal("expected error")
+		}
+		downloader.err = nil
+		if _, hit, err := f.PDFCached(ctx, "1706.03762v7"); err != nil || hit {
+			t.Errorf("expected uncached success after error, got hit=%v err=%v", hit, err)
+		}
+	})
+
+	t.Run("no directory", func(t *testing.T) {
+		downloader := &countingDownloader{}
+		f, _ := newTestFiles(t, downloader, "")
+
+		f.PDF(ctx, "1706.03762v7")
+		if _, hit, _ := f.PDFCached(ctx, "1706.03762v7"); hit {
+			t.Error("expected no caching without a directory")
+		}
+		if downloader.calls != 2 {
+			t.Errorf("expected 2 downloads, got %d", downloader.calls)
+		}
+	})
+}
+
+func TestSourceCached(t *testing.T) {
+	ctx := context.Background()
+	downloader := &countingDownloader{}
+	dir := t.TempDir()
+	f, _ := newTestFiles(t, downloader, dir)
+
+	f.PDF(ctx, "1706.03762v7")
+	data, hit, err := f.SourceCached(ctx, "1706.03762v7")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hit || string(data) != "source of 1706.03762v7" {
+		t.Errorf("expected the source to be downloaded apart from the PDF, got hit=%v data=%q", hit, data)
+	}
+	if _, hit, _ := f.SourceCached(ctx, "1706.03762v7"); !hit {
+		t.Error("expected the source to be cached")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "source", "1706.03762v7.source")); err != nil {
+		t.Errorf("expected file on disk: %v", err)
+	}
+}
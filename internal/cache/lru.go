@@ -0,0 +1,55 @@
+package cache
+
+import "container/list"
+
+// lru holds up to size entries, evicting the least recently used first.
+type lru struct {
+	size  int
+	order *list.List // front is most recently used
+	items map[string]*list.Element
+}
+
+func newLRU(size int) *lru {
+	return &lru{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (l *lru) get(key string) (entry, bool) {
+	elem, ok := l.items[key]
+	if !ok {
+		return entry{}, false
+	}
+	l.order.MoveToFront(elem)
+	return elem.Value.(entry), true
+}
+
+func (l *lru) add(e entry) {
+	if l.size <= 0 {
+		return
+	}
+	if elem, ok := l.items[e.Key]; ok {
+		elem.Value = e
+		l.order.MoveToFront(elem)
+		return
+	}
+	l.items[e.Key] = l.order.PushFront(e)
+	if l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.items, oldest.Value.(entry).Key)
+	}
+}
+
+func (l *lru) remove(key string) {
+	if elem, ok := l.items[key]; ok {
+		l.order.Remove(elem)
+		delete(l.items, key)
+	}
+}
+
+func (l *lru) len() int {
+	return l.order.Len()
+}
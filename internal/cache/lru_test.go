@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+func TestLRU(t *testing.T) {
+	t.Run("evicts least recently used", func(t *testing.T) {
+		l := newLRU(2)
+		l.add(entry{Key: "a"})
+		l.add(entry{Key: "b"})
+		l.get("a")
+		l.add(entry{Key: "c"})
+
+		if _, ok := l.get("b"); ok {
+			t.Error("expected b to be evicted")
+		}
+		if _, ok := l.get("a"); !ok {
+			t.Error("expected a to be kept")
+		}
+		if _, ok := l.get("c"); !ok {
+			t.Error("expected c to be kept")
+		}
+		if l.len() != 2 {
+			t.Errorf("expected 2 entries, got %d", l.len())
+		}
+	})
+
+	t.Run("replaces existing key", func(t *testing.T) {
+		l := newLRU(2)
+		l.add(entry{Key: "a", Results: resultsTitled("old")})
+		l.add(entry{Key: "a", Results: resultsTitled("new")})
+
+		e, ok := l.get("a")
+		if !ok || e.Results.Title != "new" {
+			t.Errorf("expected replaced entry, got %+v", e)
+		}
+		if l.len() != 1 {
+			t.Errorf("expected 1 entry, got %d", l.len())
+		}
+	})
+
+	t.Run("zero size keeps nothing", func(t *testing.T) {
+		l := newLRU(0)
+		l.add(entry{Key: "a"})
+		if _, ok := l.get("a"); ok {
+			t.Error("expected nothing to be kept")
+		}
+	})
+}
+
+func resultsTitled(title string) arxiv.SearchResults {
+	return arxiv.SearchResults{Title: title}
+}
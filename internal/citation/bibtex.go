@@ -0,0 +1,53 @@
+package citation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/textclean"
+)
+
+// bibTeX writes papers as BibTeX entries in the style arXiv uses: @misc with
+// eprint, archivePrefix and primaryClass for preprints, and @article with the
+// journal and DOI for published papers.
+func bibTeX(papers []paper) string {
+	var b strings.Builder
+	for i, p := range papers {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		kind := "misc"
+		if p.published() {
+			kind = "article"
+		}
+		fmt.Fprintf(&b, "@%s{%s,\n", kind, p.key)
+		field := func(name, value string) {
+			if value != "" {
+				fmt.Fprintf(&b, "  %s = {%s},\n", name, value)
+			}
+		}
+
+		field("title", escapeLaTeX(p.title))
+		authors := make([]string, len(p.authors))
+		for i, a := range p.authors {
+			authors[i] = escapeLaTeX(a.String())
+		}
+		field("author", strings.Join(authors, " and "))
+		if p.published() {
+			field("journal", escapeLaTeX(textclean.OneLine(p.entry.JournalReference)))
+			field("doi", p.entry.DOI)
+		}
+		if !p.entry.Published.IsZero() {
+			field("year", p.entry.Published.Format("2006"))
+			// Months are given as BibTeX's macros, which are not braced,
+			// so that styles can abbreviate them or spell them out.
+			fmt.Fprintf(&b, "  month = %s,\n", strings.ToLower(p.entry.Published.Format("Jan")))
+		}
+		field("eprint", p.id)
+		field("archivePrefix", "arXiv")
+		field("primaryClass", p.category)
+		field("url", p.url())
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
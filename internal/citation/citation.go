@@ -0,0 +1,219 @@
+// Package citation exports arXiv paper metadata as citations in BibTeX, RIS
+// and CSL-JSON, for LaTeX documents and reference managers.
+//
+// Papers published in a journal, as told by a journal reference or DOI, are
+// exported as journal articles; others as arXiv preprints. Citation keys are
+// made from the first author's family name, the year and the first word of
+// the title that is not a stop word, as in vaswani2017attention, so that the
+// same paper gets the same key in every export.
+package citation
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivid"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/textclean"
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// Citation formats.
+const (
+	BibTeX  = "bibtex"
+	RIS     = "ris"
+	CSLJSON = "csl-json"
+)
+
+// Formats lists the formats Export accepts.
+var Formats = []string{BibTeX, RIS, CSLJSON}
+
+// Export returns the entries as citations in the given format, with the keys
+// returned by Keys.
+func Export(format string, entries []arxiv.EntryMetadata) (string, error) {
+	keys := Keys(entries)
+	papers := make([]paper, len(entries))
+	for i, entry := range entries {
+		papers[i] = newPaper(entry)
+		papers[i].key = keys[i]
+	}
+
+	switch format {
+	case BibTeX:
+		return bibTeX(papers), nil
+	case RIS:
+		return ris(papers), nil
+	case CSLJSON:
+		return cslJSON(papers)
+	}
+	return "", fmt.Errorf("unknown citation format %q", format)
+}
+
+// Key returns the citation key of entry.
+func Key(entry arxiv.EntryMetadata) string {
+	return newPaper(entry).key
+}
+
+// Keys returns the citation keys of entries. Entries that share a key get
+// suffixes b, c, ..., z, aa, ab, ... after the first, so that keys are unique
+// among them.
+func Keys(entries []arxiv.EntryMetadata) []string {
+	keys := make([]string, len(entries))
+	used := make(map[string]int)
+	for i, entry := range entries {
+		key := Key(entry)
+		keys[i] = key
+		if n := used[key]; n > 0 {
+			keys[i] = key + keySuffix(n)
+		}
+		used[key]++
+	}
+	return keys
+}
+
+// keySuffix returns the suffix of the nth entry after the first with the same
+// key, counting the first as a.
+func keySuffix(n int) string {
+	var suffix []byte
+	for n++; n > 0; n /= 26 {
+		n--
+		suffix = append([]byte{byte('a' + n%26)}, suffix...)
+	}
+	return string(suffix)
+}
+
+// paper is the metadata of an entry, cleaned up for citing.
+type paper struct {
+	entry    arxiv.EntryMetadata
+	id       string // arXiv ID without version
+	title    string
+	authors  []name
+	key      string
+	category string
+}
+
+// published reports whether the paper is cited as a journal article.
+func (p paper) published() bool {
+	return p.entry.JournalReference != "" || p.entry.DOI != ""
+}
+
+func (p paper) url() string {
+	return "https://arxiv.org/abs/" + p.id
+}
+
+func newPaper(entry arxiv.EntryMetadata) paper {
+	p := paper{
+		entry:    entry,
+		id:       entry.ID,
+		title:    textclean.OneLine(entry.Title),
+		category: entry.PrimaryCategory.Term,
+	}
+	if id, err := arxivid.Parse(entry.ID); err == nil {
+		p.id = id.Base
+	}
+	if p.category == "" && len(entry.Categories) > 0 {
+		p.category = entry.Categories[0].Term
+	}
+	for _, author := range entry.Authors {
+		p.authors = append(p.authors, splitName(textclean.OneLine(author.Name)))
+	}
+
+	var key strings.Builder
+	if len(p.authors) > 0 {
+		key.WriteString(keyWord(p.authors[0].family))
+	}
+	if !entry.Published.IsZero() {
+		key.WriteString(entry.Published.Format("2006"))
+	}
+	for _, word := range strings.Fields(p.title) {
+		if strings.Contains(word, "$") {
+			continue // math
+		}
+		if w := keyWord(word); w != "" && !stopWords[w] {
+			key.WriteString(w)
+			break
+		}
+	}
+	p.key = key.String()
+	if p.key == "" {
+		p.key = "arxiv" + keyWord(p.id)
+	}
+	return p
+}
+
+// stopWords are title words skipped when choosing the one for a key.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "on": true, "of": true, "in": true,
+	"for": true, "to": true, "and": true, "with": true, "from": true, "at": true,
+	"by": true, "is": true, "are": true, "towards": true, "toward": true,
+}
+
+// keyWord lowercases s and keeps only ASCII letters and digits, with accents
+// removed from letters.
+func keyWord(s string) string {
+	var b strings.Builder
+	for _, r := range fold(s) {
+		r = unicode.ToLower(r)
+		if ('a' <= r && r <= 'z') || ('0' <= r && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// name is an author name split into given and family names.
+type name struct {
+	given, family string
+}
+
+func (n name) String() string {
+	if n.given == "" {
+		return n.family
+	}
+	return n.given + " " + n.family
+}
+
+// inverted returns the name as "Family, Given".
+func (n name) inverted() string {
+	if n.given == "" {
+		return n.family
+	}
+	return n.family + ", " + n.given
+}
+
+// particles are lowercase words that belong to the family name that follows
+// them.
+var particles = map[string]bool{
+	"van": true, "von": true, "der": true, "den": true, "de": true, "del": true,
+	"della": true, "di": true, "da": true, "dos": true, "du": true, "la": true,
+	"le": true, "ten": true, "ter": true, "bin": true, "al": true, "el": true,
+}
+
+var suffixes = map[string]bool{"Jr.": true, "Jr": true, "Sr.": true, "II": true, "III": true, "IV": true}
+
+// splitName splits a name as arXiv gives it, "Given Family", taking the last
+// word as the family name along with any particles before it. Names written
+// "Family, Given" are split at the comma.
+func splitName(full string) name {
+	if family, given, ok := strings.Cut(full, ","); ok && !suffixes[strings.TrimSpace(given)] {
+		return name{given: strings.TrimSpace(given), family: strings.TrimSpace(family)}
+	}
+	words := strings.Fields(full)
+	if len(words) <= 1 {
+		return name{family: full}
+	}
+	last := len(words) - 1
+	suffix := ""
+	if suffixes[strings.TrimSuffix(words[last], ",")] && last > 1 {
+		suffix = " " + words[last]
+		last--
+	}
+	start := last
+	for start > 1 && particles[words[start-1]] {
+		start--
+	}
+	return name{
+		given:  strings.TrimSuffix(strings.Join(words[:start], " "), ","),
+		family: strings.Join(words[start:last+1], " ") + suffix,
+	}
+}
@@ -0,0 +1,198 @@
+package citation
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+var (
+	attention = arxiv.EntryMetadata{
+		ID:              "http://arxiv.org/abs/1706.03762v7",
+		Title:           "Attention Is All\n  You Need",
+		Published:       time.Date(2017, 6, 12, 17, 57, 34, 0, time.UTC),
+		Summary:         "The dominant sequence transduction models...",
+		Authors:         []arxiv.Author{{Name: "Ashish Vaswani"}, {Name: "Noam Shazeer"}},
+		Categories:      []arxiv.Category{{Term: "cs.CL"}, {Term: "cs.LG"}},
+		PrimaryCategory: arxiv.Category{Term: "cs.CL"},
+	}
+	maldacena = arxiv.EntryMetadata{
+		ID:               "http://arxiv.org/abs/hep-th/9711200v3",
+		Title:            "The Large N Limit of Superconformal Field Theories & Supergravity",
+		Published:        time.Date(1997, 11, 27, 0, 0, 0, 0, time.UTC),
+		Authors:          []arxiv.Author{{Name: "Juan M. Maldacena"}},
+		PrimaryCategory:  arxiv.Category{Term: "hep-th"},
+		JournalReference: "Adv.Theor.Math.Phys.2:231-252,1998",
+		DOI:              "10.1023/A:1026654312961",
+	}
+)
+
+func TestExportBibTeX(t *testing.T) {
+	bib, err := Export(BibTeX, []arxiv.EntryMetadata{attention, maldacena})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `@misc{vaswani2017attention,
+  title = {Attention Is All You Need},
+  author = {Ashish Vaswani and Noam Shazeer},
+  year = {2017},
+  month = jun,
+  eprint = {1706.03762},
+  archivePrefix = {arXiv},
+  primaryClass = {cs.CL},
+  url = {https://arxiv.org/abs/1706.03762},
+}
+
+@article{maldacena1997large,
+  title = {The Large N Limit of Superconformal Field Theories \& Supergravity},
+  author = {Juan M. Maldacena},
+  journal = {Adv.Theor.Math.Phys.2:231-252,1998},
+  doi = {10.1023/A:1026654312961},
+  year = {1997},
+  month = nov,
+  eprint = {hep-th/9711200},
+  archivePrefix = {arXiv},
+  primaryClass = {hep-th},
+  url = {https://arxiv.org/abs/hep-th/9711200},
+}
+`
+	if bib != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, bib)
+	}
+}
+
+func TestExportRIS(t *testing.T) {
+	risText, err := Export(RIS, []arxiv.EntryMetadata{attention, maldacena})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	records := strings.Split(risText, "\n\n")
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %q", risText)
+	}
+	for _, line := range []string{"TY  - UNPB", "ID  - vaswani2017attention", "AU  - Vaswani, Ashish", "DA  - 2017/06/12", "PB  - arXiv", "KW  - cs.LG", "N1  - arXiv:1706.03762 [cs.CL]", "ER  - "} {
+		if !strings.Contains(records[0]+"\n", line+"\n") {
+			t.Errorf("expected %q in the first record:\n%s", line, records[0])
+		}
+	}
+	for _, line := range []string{"TY  - JOUR", "AU  - Maldacena, Juan M.", "JO  - Adv.Theor.Math.Phys.2:231-252,1998", "DO  - 10.1023/A:1026654312961"} {
+		if !strings.Contains(records[1], line+"\n") {
+			t.Errorf("expected %q in the second record:\n%s", line, records[1])
+		}
+	}
+}
+
+func TestExportCSLJSON(t *testing.T) {
+	data, err := Export(CSLJSON, []arxiv.EntryMetadata{attention, maldacena})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var items []map[string]any
+	if err := json.Unmarshal([]byte(data), &items); err != nil {
+		t.Fatalf("expected a JSON array: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(items))
+	}
+
+	preprint := items[0]
+	if preprint["id"] != "vaswani2017attention" || preprint["type"] != "article" || preprint["publisher"] != "arXiv" || preprint["DOI"] != "10.48550/arXiv.1706.03762" {
+		t.Errorf("unexpected preprint item %v", preprint)
+	}
+	authors := preprint["author"].([]any)
+	if first := authors[0].(map[string]any); first["family"] != "Vaswani" || first["given"] != "Ashish" {
+		t.Errorf("unexpected first author %v", first)
+	}
+	if issued, _ := json.Marshal(preprint["issued"]); string(issued) != `{"date-parts":[[2017,6,12]]}` {
+		t.Errorf("unexpected issued date %s", issued)
+	}
+
+	article := items[1]
+	if article["type"] != "article-journal" || article["container-title"] != "Adv.Theor.Math.Phys.2:231-252,1998" || article["DOI"] != "10.1023/A:1026654312961" {
+		t.Errorf("unexpected article item %v", article)
+	}
+}
+
+func TestExportUnknownFormat(t *testing.T) {
+	if _, err := Export("endnote", []arxiv.EntryMetadata{attention}); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		entry    arxiv.EntryMetadata
+		expected string
+	}{
+		{name: "stop words skipped", entry: maldacena, expected: "maldacena1997large"},
+		{name: "accents folded", entry: arxiv.EntryMetadata{Title: "Über die Quantenmechanik", Authors: []arxiv.Author{{Name: "Kurt Gödel"}}, Published: time.Date(1931, 1, 1, 0, 0, 0, 0, time.UTC)}, expected: "godel1931uber"},
+		{name: "particle and math", entry: arxiv.EntryMetadata{Title: "$O(n)$ models", Authors: []arxiv.Author{{Name: "Jan van der Berg"}}, Published: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}, expected: "vanderberg2020models"},
+		{name: "no authors", entry: arxiv.EntryMetadata{ID: "http://arxiv.org/abs/2401.01234v1"}, expected: "arxiv240101234"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if key := Key(tt.entry); key != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, key)
+			}
+		})
+	}
+
+	bib, _ := Export(BibTeX, []arxiv.EntryMetadata{attention, attention, attention})
+	for _, key := range []string{"{vaswani2017attention,", "{vaswani2017attentionb,", "{vaswani2017attentionc,"} {
+		if !strings.Contains(bib, key) {
+			t.Errorf("expected key %s in\n%s", key, bib)
+		}
+	}
+	same := make([]arxiv.EntryMetadata, 28)
+	for i := range same {
+		same[i] = attention
+	}
+	keys := Keys(same)
+	for i, suffix := range map[int]string{0: "", 1: "b", 25: "z", 26: "aa", 27: "ab"} {
+		if expected := "vaswani2017attention" + suffix; keys[i] != expected {
+			t.Errorf("expected key %d to be %s, got %s", i, expected, keys[i])
+		}
+	}
+}
+
+func TestEscapeLaTeX(t *testing.T) {
+	tests := []struct {
+		in, expected string
+	}{
+		{in: "Fast & Furious: 100% of #1_best", expected: `Fast \& Furious: 100\% of \#1\_best`},
+		{in: "An $O(n_1 \\log n)$ algorithm for x^2", expected: `An $O(n_1 \log n)$ algorithm for x\^{}2`},
+		{in: "Schrödinger, Erdős and Łukasiewicz", expected: `Schr{\"o}dinger, Erd{\H o}s and {\L}ukasiewicz`},
+		{in: `Already \"{o} and \& escaped`, expected: `Already \"{o} and \& escaped`},
+		{in: "Costs $5 and {unbalanced", expected: `Costs \$5 and \{unbalanced`},
+		{in: `A back\slash? No: a \ alone`, expected: `A back\slash? No: a \textbackslash{} alone`},
+	}
+	for _, tt := range tests {
+		if escaped := escapeLaTeX(tt.in); escaped != tt.expected {
+			t.Errorf("escapeLaTeX(%q): expected %q, got %q", tt.in, tt.expected, escaped)
+		}
+	}
+}
+
+func TestSplitName(t *testing.T) {
+	tests := []struct {
+		in     string
+		given  string
+		family string
+	}{
+		{in: "Ashish Vaswani", given: "Ashish", family: "Vaswani"},
+		{in: "Juan M. Maldacena", given: "Juan M.", family: "Maldacena"},
+		{in: "Jan van der Berg", given: "Jan", family: "van der Berg"},
+		{in: "Martin Luther King Jr.", given: "Martin Luther", family: "King Jr."},
+		{in: "Hinton, Geoffrey", given: "Geoffrey", family: "Hinton"},
+		{in: "Plato", family: "Plato"},
+	}
+	for _, tt := range tests {
+		if n := splitName(tt.in); n.given != tt.given || n.family != tt.family {
+			t.Errorf("splitName(%q): expected %q / %q, got %q / %q", tt.in, tt.given, tt.family, n.given, n.family)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package citation
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/textclean"
+)
+
+// cslItem is a CSL-JSON item, with the variables that arXiv metadata fills.
+type cslItem struct {
+	ID             string    `json:"id"`
+	Type           string    `json:"type"`
+	Title          string    `json:"title"`
+	Author         []cslName `json:"author,omitempty"`
+	Issued         *cslDate  `json:"issued,omitempty"`
+	Abstract       string    `json:"abstract,omitempty"`
+	ContainerTitle string    `json:"container-title,omitempty"`
+	Publisher      string    `json:"publisher,omitempty"`
+	Number         string    `json:"number,omitempty"`
+	DOI            string    `json:"DOI,omitempty"`
+	URL            string    `json:"URL"`
+	Keyword        string    `json:"keyword,omitempty"`
+}
+
+type cslName struct {
+	Family string `json:"family"`
+	Given  string `json:"given,omitempty"`
+}
+
+type cslDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+// cslJSON writes papers as a CSL-JSON array: article-journal items for
+// published papers and article items, with arXiv as the publisher, for
+// preprints. Preprints get their arXiv DOI.
+func cslJSON(papers []paper) (string, error) {
+	items := make([]cslItem, len(papers))
+	for i, p := range papers {
+		item := cslItem{
+			ID:       p.key,
+			Type:     "article",
+			Title:    p.title,
+			Abstract: textclean.OneLine(p.entry.Summary),
+			Number:   "arXiv:" + p.id,
+			URL:      p.url(),
+		}
+		for _, a := range p.authors {
+			item.Author = append(item.Author, cslName{Family: a.family, Given: a.given})
+		}
+		if published := p.entry.Published; !published.IsZero() {
+			item.Issued = &cslDate{DateParts: [][]int{{published.Year(), int(published.Month()), published.Day()}}}
+		}
+		if p.published() {
+			item.Type = "article-journal"
+			item.ContainerTitle = textclean.OneLine(p.entry.JournalReference)
+			item.DOI = p.entry.DOI
+		} else {
+			item.Publisher = "arXiv"
+			item.DOI = "10.48550/arXiv." + p.id
+		}
+		var keywords []string
+		for _, c := range p.entry.Categories {
+			keywords = append(keywords, c.Term)
+		}
+		item.Keyword = strings.Join(keywords, ", ")
+		items[i] = item
+	}
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
@@ -0,0 +1,139 @@
+package citation
+
+import (
+	"strings"
+)
+
+// accented maps letters with accents to the LaTeX that typesets them and to
+// the letters they are folded to in keys.
+var accented = map[rune]struct{ tex, ascii string }{}
+
+func init() {
+	accents := []struct {
+		command string
+		letters string // pairs of accented letter and base letter
+	}{
+		{`\'`, "áaéeíióoúuýyÁAÉEÍIÓOÚUÝYćcĆCńnŃNśsŚSźzŹZ"},
+		{"\\`", "àaèeìiòoùuÀAÈEÌIÒOÙU"},
+		{`\^`, "âaêeîiôoûuÂAÊEÎIÔOÛU"},
+		{`\"`, "äaëeïiöoüuÿyÄAËEÏIÖOÜU"},
+		{`\~`, "ãaõoñnÃAÕOÑN"},
+		{`\r`, "åaÅA"},
+		{`\c`, "çcÇCşsŞS"},
+		{`\v`, "čcČCšsŠSžzŽZřrŘRěeĚEňnŇN"},
+		{`\H`, "őoŐOűuŰU"},
+		{`\u`, "ğgĞGăaĂA"},
+		{`\k`, "ąaĄAęeĘE"},
+		{`\=`, "āaĀAēeĒEīiĪIōoŌOūuŪU"},
+		{`\.`, "żzŻZ"},
+	}
+	for _, a := range accents {
+		letters := []rune(a.letters)
+		for i := 0; i+1 < len(letters); i += 2 {
+			base := string(letters[i+1])
+			tex := "{" + a.command + base + "}"
+			if isASCIILetter(rune(a.command[1])) {
+				tex = "{" + a.command + " " + base + "}"
+			}
+			accented[letters[i]] = struct{ tex, ascii string }{tex, base}
+		}
+	}
+	for letter, tex := range map[rune][2]string{
+		'ß': {`{\ss}`, "ss"}, 'ø': {`{\o}`, "o"}, 'Ø': {`{\O}`, "O"}, 'æ': {`{\ae}`, "ae"}, 'Æ': {`{\AE}`, "AE"},
+		'œ': {`{\oe}`, "oe"}, 'Œ': {`{\OE}`, "OE"}, 'ł': {`{\l}`, "l"}, 'Ł': {`{\L}`, "L"}, 'ı': {`{\i}`, "i"},
+	} {
+		accented[letter] = struct{ tex, ascii string }{tex[0], tex[1]}
+	}
+}
+
+// fold replaces accented letters in s with their base letters.
+func fold(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if a, ok := accented[r]; ok {
+			b.WriteString(a.ascii)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// escapeLaTeX makes s safe to use as a BibTeX field value. arXiv titles and
+// names are often written in TeX already, so math between dollar signs and
+// commands such as \"o are kept. Characters special to LaTeX elsewhere are
+// escaped, accented letters are written as accent commands, and braces are
+// escaped if they do not balance.
+func escapeLaTeX(s string) string {
+	escapeBraces := !balanced(s)
+	var b strings.Builder
+	math := false
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '$' && (i == 0 || runes[i-1] != '\\') {
+			// An unmatched dollar sign is a literal one.
+			if !math && strings.Count(string(runes[i+1:]), "$") == 0 {
+				b.WriteString(`\$`)
+				continue
+			}
+			math = !math
+			b.WriteRune(r)
+			continue
+		}
+		if math {
+			b.WriteRune(r)
+			continue
+		}
+		switch {
+		case r == '\\':
+			if i+1 < len(runes) && strings.ContainsRune(`&%#_$`, runes[i+1]) {
+				b.WriteRune(r)
+				i++
+				b.WriteRune(runes[i])
+			} else if i+1 < len(runes) && (isASCIILetter(runes[i+1]) || strings.ContainsRune(`'"^~=.`+"`", runes[i+1])) {
+				b.WriteRune(r)
+			} else {
+				b.WriteString(`\textbackslash{}`)
+			}
+		case strings.ContainsRune(`&%#_`, r):
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case (r == '{' || r == '}') && escapeBraces:
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case r == '^':
+			b.WriteString(`\^{}`)
+		default:
+			if a, ok := accented[r]; ok {
+				b.WriteString(a.tex)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
+
+func balanced(s string) bool {
+	depth := 0
+	for i, r := range s {
+		if i > 0 && s[i-1] == '\\' {
+			continue
+		}
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth < 0 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
+func isASCIILetter(r rune) bool {
+	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z'
+}
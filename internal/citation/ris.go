@@ -0,0 +1,52 @@
+package citation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/textclean"
+)
+
+// ris writes papers as RIS records: JOUR for published papers and UNPB, for
+// unpublished work, for preprints.
+func ris(papers []paper) string {
+	var b strings.Builder
+	for i, p := range papers {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		tag := func(name, value string) {
+			if value != "" {
+				fmt.Fprintf(&b, "%s  - %s\n", name, value)
+			}
+		}
+		if p.published() {
+			tag("TY", "JOUR")
+		} else {
+			tag("TY", "UNPB")
+		}
+		tag("ID", p.key)
+		tag("TI", p.title)
+		for _, a := range p.authors {
+			tag("AU", a.inverted())
+		}
+		if !p.entry.Published.IsZero() {
+			tag("PY", p.entry.Published.Format("2006"))
+			tag("DA", p.entry.Published.Format("2006/01/02"))
+		}
+		if p.published() {
+			tag("JO", textclean.OneLine(p.entry.JournalReference))
+			tag("DO", p.entry.DOI)
+		} else {
+			tag("PB", "arXiv")
+		}
+		tag("AB", textclean.OneLine(p.entry.Summary))
+		for _, c := range p.entry.Categories {
+			tag("KW", c.Term)
+		}
+		tag("UR", p.url())
+		tag("N1", fmt.Sprintf("arXiv:%s [%s]", p.id, p.category))
+		b.WriteString("ER  - \n")
+	}
+	return b.String()
+}
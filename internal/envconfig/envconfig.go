@@ -0,0 +1,26 @@
+// Package envconfig reads configuration values from environment variables.
+package envconfig
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Duration sets d to the duration in the environment variable name, such as
+// 10m, if it is set. It is an error for the duration to be negative.
+func Duration(name string, d *time.Duration) error {
+	value := os.Getenv(name)
+	if value == "" {
+		return nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if parsed < 0 {
+		return fmt.Errorf("invalid %s: must not be negative", name)
+	}
+	*d = parsed
+	return nil
+}
@@ -0,0 +1,34 @@
+package envconfig
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{name: "unset", value: "", expected: time.Minute},
+		{name: "set", value: "90s", expected: 90 * time.Second},
+		{name: "zero", value: "0", expected: 0},
+		{name: "negative", value: "-1s", expected: time.Minute, wantErr: true},
+		{name: "not a duration", value: "soon", expected: time.Minute, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ARXIV_TEST_DURATION", tt.value)
+			d := time.Minute
+			err := Duration("ARXIV_TEST_DURATION", &d)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if d != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, d)
+			}
+		})
+	}
+}
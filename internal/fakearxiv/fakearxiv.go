@@ -0,0 +1,310 @@
+// Package fakearxiv is a stand-in for the arXiv API that serves Atom feeds
+// built from fixture entries, so that the server and its tests can run
+// without network access.
+//
+// Fixtures are files holding a single Atom <entry> as the arXiv API returns
+// it, one per paper version. Searches see only the latest version of each
+// paper; id_list lookups can ask for any version. Results are kept in fixture
+// file order unless sorted by date.
+//
+// Paper PDFs and LaTeX sources are served too, made up from each entry's
+// title, authors and abstract, announcement feeds that list the latest
+// version of every paper in a category, and an OAI-PMH interface that lists
+// the records of every paper.
+package fakearxiv
+
+import (
+	"bytes"
+	"embed"
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivid"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivquery"
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+//go:embed fixtures/*.xml
+var fixtures embed.FS
+
+// Fixtures returns the fixture entries shipped with the package.
+func Fixtures() fs.FS {
+	sub, err := fs.Sub(fixtures, "fixtures")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+type entry struct {
+	raw []byte
+	id  arxivid.ID
+	doc arxivquery.Document
+}
+
+// Server serves the arXiv API query endpoint from fixture entries.
+type Server struct {
+	entries []entry
+	latest  []entry // the latest version of each paper
+}
+
+// Load reads the *.xml fixture entries in the root of fsys.
+func Load(fsys fs.FS) (*Server, error) {
+	names, err := fs.Glob(fsys, "*.xml")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	s := &Server{}
+	latest := make(map[string]int) // base ID to index in s.latest
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		e, err := parseEntry(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		s.entries = append(s.entries, e)
+		if i, ok := latest[e.id.Base]; ok {
+			if e.id.Version > s.latest[i].id.Version {
+				s.latest[i] = e
+			}
+			continue
+		}
+		latest[e.id.Base] = len(s.latest)
+		s.latest = append(s.latest, e)
+	}
+	return s, nil
+}
+
+func parseEntry(data []byte) (entry, error) {
+	metadata, err := arxiv.ParseSingleEntry(bytes.NewReader(data))
+	if err != nil {
+		return entry{}, err
+	}
+	id, err := arxivid.Parse(metadata.ID)
+	if err != nil {
+		return entry{}, err
+	}
+	if id.Version == 0 {
+		return entry{}, fmt.Errorf("entry ID %s has no version", metadata.ID)
+	}
+
+	doc := arxivquery.Document{
+		ID:         metadata.ID,
+		Title:      metadata.Title,
+		Abstract:   metadata.Summary,
+		Comment:    metadata.Comment,
+		JournalRef: metadata.JournalReference,
+		Submitted:  metadata.Published,
+		Updated:    metadata.Updated,
+	}
+	for _, author := range metadata.Authors {
+		doc.Authors = append(doc.Authors, author.Name)
+	}
+	for _, category := range metadata.Categories {
+		doc.Categories = append(doc.Categories, category.Term)
+	}
+	return entry{raw: bytes.TrimSpace(data), id: id, doc: doc}, nil
+}
+
+// ServeHTTP serves paper PDFs under /pdf/, sources under /e-print/,
+// announcement feeds under /rss/ and /atom/, the OAI-PMH interface at /oai
+// and the API query endpoint at every other path.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/oai" {
+		s.serveOAI(w, r)
+		return
+	}
+	for _, format := range []string{"rss", "atom"} {
+		if categories, ok := strings.CutPrefix(r.URL.Path, "/"+format+"/"); ok {
+			s.serveListing(w, r, format, categories)
+			return
+		}
+	}
+	if id, ok := strings.CutPrefix(r.URL.Path, "/pdf/"); ok {
+		s.servePDF(w, r, id)
+		return
+	}
+	if id, ok := strings.CutPrefix(r.URL.Path, "/e-print/"); ok {
+		s.serveSource(w, r, id)
+		return
+	}
+	s.serveQuery(w, r)
+}
+
+// parseFileID parses the ID in the path of a paper file, which may end with
+// the file's extension.
+func parseFileID(raw, ext string) (arxivid.ID, error) {
+	return arxivid.Parse(strings.TrimSuffix(raw, ext))
+}
+
+func (s *Server) serveQuery(w http.ResponseWriter, r *http.Request) {
+	query := r.FormValue("search_query")
+	var idList []string
+	if ids := r.FormValue("id_list"); ids != "" {
+		idList = strings.Split(ids, ",")
+	}
+	start, err := intParam(r, "start", 0)
+	if err != nil {
+		writeError(w, "bad_start", err.Error())
+		return
+	}
+	maxResults, err := intParam(r, "max_results", 10)
+	if err != nil {
+		writeError(w, "bad_max_results", err.Error())
+		return
+	}
+	sortBy := arxiv.SortBy(r.FormValue("sortBy"))
+	switch sortBy {
+	case "", arxiv.SortByRelevance, arxiv.SortBySubmittedDate, arxiv.SortByLastUpdatedDate:
+	default:
+		writeError(w, "bad_sort_by", fmt.Sprintf("sortBy must be relevance, lastUpdatedDate or submittedDate, got %q", sortBy))
+		return
+	}
+	sortOrder := arxiv.SortOrder(r.FormValue("sortOrder"))
+	switch sortOrder {
+	case "", arxiv.SortOrderAscending, arxiv.SortOrderDescending:
+	default:
+		writeError(w, "bad_sort_order", fmt.Sprintf("sortOrder must be ascending or descending, got %q", sortOrder))
+		return
+	}
+
+	var candidates []entry
+	switch {
+	case len(idList) > 0:
+		for _, raw := range idList {
+			id, err := arxivid.Parse(raw)
+			if err != nil {
+				writeError(w, "incorrect_id_format_for_"+raw, "incorrect id format for "+raw)
+				return
+			}
+			if e, ok := s.lookup(id); ok {
+				candidates = append(candidates, e)
+			}
+		}
+	case query != "":
+		candidates = s.latest
+	default:
+		writeError(w, "missing_query", "either search_query or id_list must be given")
+		return
+	}
+
+	if query != "" {
+		node, err := arxivquery.Parse(query)
+		if err != nil {
+			writeError(w, "malformed_query", "malformed search_query: "+err.Error())
+			return
+		}
+		var matched []entry
+		for _, e := range candidates {
+			if node.Match(e.doc) {
+				matched = append(matched, e)
+			}
+		}
+		candidates = matched
+	}
+	candidates = sortEntries(candidates, sortBy, sortOrder)
+
+	page := candidates[min(start, len(candidates)):min(start+maxResults, len(candidates))]
+	writeFeed(w, r, len(candidates), start, page)
+}
+
+// lookup returns the entry for id, or the latest version if id has none.
+func (s *Server) lookup(id arxivid.ID) (entry, bool) {
+	entries := s.entries
+	if id.Version == 0 {
+		entries = s.latest
+	}
+	for _, e := range entries {
+		if id.Matches(e.id) {
+			return e, true
+		}
+	}
+	return entry{}, false
+}
+
+func sortEntries(entries []entry, sortBy arxiv.SortBy, sortOrder arxiv.SortOrder) []entry {
+	var date func(e entry) time.Time
+	switch sortBy {
+	case arxiv.SortBySubmittedDate:
+		date = func(e entry) time.Time { return e.doc.Submitted }
+	case arxiv.SortByLastUpdatedDate:
+		date = func(e entry) time.Time { return e.doc.Updated }
+	default:
+		return entries
+	}
+	sorted := append([]entry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sortOrder == arxiv.SortOrderAscending {
+			return date(sorted[i]).Before(date(sorted[j]))
+		}
+		return date(sorted[i]).After(date(sorted[j]))
+	})
+	return sorted
+}
+
+func intParam(r *http.Request, name string, fallback int) (int, error) {
+	value := r.FormValue(name)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer, got %q", name, value)
+	}
+	return n, nil
+}
+
+const feedHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:opensearch="http://a9.com/-/spec/opensearch/1.1/" xmlns:arxiv="http://arxiv.org/schemas/atom">
+`
+
+func writeFeed(w http.ResponseWriter, r *http.Request, total, start int, entries []entry) {
+	var b bytes.Buffer
+	b.WriteString(feedHeader)
+	b.WriteString(`  <title type="html">ArXiv Query: `)
+	xml.EscapeText(&b, []byte(r.URL.RawQuery))
+	b.WriteString("</title>\n")
+	fmt.Fprintf(&b, "  <id>http://arxiv.org/api/fake</id>\n")
+	fmt.Fprintf(&b, "  <updated>%s</updated>\n", time.Now().UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "  <opensearch:totalResults>%d</opensearch:totalResults>\n", total)
+	fmt.Fprintf(&b, "  <opensearch:startIndex>%d</opensearch:startIndex>\n", start)
+	fmt.Fprintf(&b, "  <opensearch:itemsPerPage>%d</opensearch:itemsPerPage>\n", len(entries))
+	for _, e := range entries {
+		b.Write(e.raw)
+		b.WriteByte('\n')
+	}
+	b.WriteString("</feed>\n")
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.Write(b.Bytes())
+}
+
+// writeError responds as arXiv does to a bad request: a 400 with a feed
+// holding a single error entry.
+func writeError(w http.ResponseWriter, slug, message string) {
+	var b bytes.Buffer
+	b.WriteString(feedHeader)
+	b.WriteString("  <opensearch:totalResults>1</opensearch:totalResults>\n")
+	b.WriteString("  <opensearch:startIndex>0</opensearch:startIndex>\n")
+	b.WriteString("  <opensearch:itemsPerPage>1</opensearch:itemsPerPage>\n")
+	b.WriteString("  <entry>\n    <id>http://arxiv.org/api/errors#")
+	xml.EscapeText(&b, []byte(slug))
+	b.WriteString("</id>\n    <title>Error</title>\n    <summary>")
+	xml.EscapeText(&b, []byte(message))
+	b.WriteString("</summary>\n  </entry>\n</feed>\n")
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write(b.Bytes())
+}
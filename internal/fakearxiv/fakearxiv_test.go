@@ -0,0 +1,446 @@
+package fakearxiv
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivclient"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/latex"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/listing"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/oaipmh"
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+func newTestClient(t *testing.T) *arxivclient.Client {
+	t.Helper()
+	s, err := Load(Fixtures())
+	if err != nil {
+		t.Fatalf("loading fixtures: %v", err)
+	}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	return arxivclient.New(arxivclient.Config{BaseURL: server.URL, SiteURL: server.URL, FeedURL: server.URL + "/atom", OAIURL: server.URL + "/oai"})
+}
+
+func entryIDs(results arxiv.SearchResults) []string {
+	var ids []string
+	for _, entry := range results.Entries {
+		ids = append(ids, entry.ID)
+	}
+	return ids
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("fixtures", func(t *testing.T) {
+		s, err := Load(Fixtures())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(s.latest) == 0 || len(s.latest) >= len(s.entries) {
+			t.Errorf("expected fewer papers than versions, got %d papers and %d entries", len(s.latest), len(s.entries))
+		}
+	})
+
+	t.Run("unversioned entry", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"bad.xml": {Data: []byte(`<entry><id>http://arxiv.org/abs/2401.01234</id></entry>`)},
+		}
+		if _, err := Load(fsys); err == nil {
+			t.Error("expected error for entry without a version")
+		}
+	})
+}
+
+func TestServer(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		params      arxiv.SearchParams
+		expectError bool
+		validate    func(t *testing.T, results arxiv.SearchResults)
+	}{
+		{
+			name:   "title search",
+			params: arxiv.SearchParams{Query: "ti:attention"},
+			validate: func(t *testing.T, results arxiv.SearchResults) {
+				if results.TotalResults != 1 || len(results.Entries) != 1 {
+					t.Fatalf("expected 1 result, got %d", results.TotalResults)
+				}
+				if results.Entries[0].ID != "http://arxiv.org/abs/1706.03762v7" {
+					t.Errorf("expected the latest version, got %s", results.Entries[0].ID)
+				}
+				if results.Entries[0].PDFUrl == "" {
+					t.Error("expected PDF link")
+				}
+			},
+		},
+		{
+			name:   "category and author",
+			params: arxiv.SearchParams{Query: "cat:quant-ph AND au:shor"},
+			validate: func(t *testing.T, results arxiv.SearchResults) {
+				if ids := entryIDs(results); len(ids) != 1 || ids[0] != "http://arxiv.org/abs/quant-ph/9508027v2" {
+					t.Errorf("expected Shor's paper, got %v", ids)
+				}
+			},
+		},
+		{
+			name:   "id list in requested order",
+			params: arxiv.SearchParams{IdList: []string{"1412.6980", "hep-th/9711200", "1706.03762v1"}},
+			validate: func(t *testing.T, results arxiv.SearchResults) {
+				expected := []string{
+					"http://arxiv.org/abs/1412.6980v9",
+					"http://arxiv.org/abs/hep-th/9711200v3",
+					"http://arxiv.org/abs/1706.03762v1",
+				}
+				ids := entryIDs(results)
+				if len(ids) != len(expected) {
+					t.Fatalf("expected %v, got %v", expected, ids)
+				}
+				for i := range expected {
+					if ids[i] != expected[i] {
+						t.Errorf("expected %v, got %v", expected, ids)
+						break
+					}
+				}
+			},
+		},
+		{
+			name:   "id list filtered by query",
+			params: arxiv.SearchParams{IdList: []string{"1412.6980", "1512.03385"}, Query: "cat:cs.CV"},
+			validate: func(t *testing.T, results arxiv.SearchResults) {
+				if ids := entryIDs(results); len(ids) != 1 || ids[0] != "http://arxiv.org/abs/1512.03385v1" {
+					t.Errorf("expected only the cs.CV paper, got %v", ids)
+				}
+			},
+		},
+		{
+			name:   "sorted by submitted date",
+			params: arxiv.SearchParams{Query: "cat:cs.*", SortBy: arxiv.SortBySubmittedDate, SortOrder: arxiv.SortOrderAscending},
+			validate: func(t *testing.T, results arxiv.SearchResults) {
+				for i := 1; i < len(results.Entries); i++ {
+					if results.Entries[i].Published.Before(results.Entries[i-1].Published) {
+						t.Errorf("expected ascending submitted dates, got %v", entryIDs(results))
+						break
+					}
+				}
+			},
+		},
+		{
+			name:   "paging",
+			params: arxiv.SearchParams{Query: "cat:cs.*", Start: 2, MaxResults: 2},
+			validate: func(t *testing.T, results arxiv.SearchResults) {
+				if results.StartIndex != 2 || results.ItemsPerPage != 2 || len(results.Entries) != 2 {
+					t.Errorf("expected entries 2 and 3, got start %d and %d entries", results.StartIndex, len(results.Entries))
+				}
+				if results.TotalResults <= 4 {
+					t.Errorf("expected more than 4 cs results in total, got %d", results.TotalResults)
+				}
+			},
+		},
+		{
+			name:   "date range",
+			params: arxiv.SearchParams{Query: "submittedDate:[201801010000 TO 201812312359]"},
+			validate: func(t *testing.T, results arxiv.SearchResults) {
+				if results.TotalResults != 2 {
+					t.Errorf("expected 2 papers from 2018, got %v", entryIDs(results))
+				}
+			},
+		},
+		{
+			name:   "no matches",
+			params: arxiv.SearchParams{Query: "ti:nonexistentword"},
+			validate: func(t *testing.T, results arxiv.SearchResults) {
+				if results.TotalResults != 0 || len(results.Entries) != 0 {
+					t.Errorf("expected no results, got %v", entryIDs(results))
+				}
+			},
+		},
+		{
+			name:        "malformed query",
+			params:      arxiv.SearchParams{Query: "ti:(attention"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := client.Search(ctx, tt.params)
+			if tt.expectError {
+				var badQuery *arxivclient.BadQueryError
+				if !errors.As(err, &badQuery) {
+					t.Errorf("expected BadQueryError, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.validate != nil {
+				tt.validate(t, results)
+			}
+		})
+	}
+
+	t.Run("unknown id", func(t *testing.T) {
+		_, err := client.Search(ctx, arxiv.SearchParams{IdList: []string{"2401.99999"}})
+		var notFound *arxivclient.NotFoundError
+		if !errors.As(err, &notFound) {
+			t.Errorf("expected NotFoundError, got %v", err)
+		}
+	})
+}
+
+func TestPDF(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"1706.03762", "1706.03762v1", "hep-th/9711200v3"} {
+		data, err := client.PDF(ctx, id)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", id, err)
+		}
+		if !bytes.HasPrefix(data, []byte("%PDF-")) || !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+			t.Errorf("%s: expected a PDF document", id)
+		}
+	}
+
+	_, err := client.PDF(ctx, "2401.99999")
+	var notFound *arxivclient.NotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("expected NotFoundError for an unknown paper, got %v", err)
+	}
+}
+
+func TestSource(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		id    string
+		files []string
+		err   error
+	}{
+		{id: "1706.03762v7", files: []string{"main.tex", "sections/intro.tex"}},
+		{id: "hep-th/9711200", files: []string{"main.tex"}},
+		{id: "math/0211159", err: latex.ErrNoSource},
+	}
+	for _, tt := range tests {
+		data, err := client.Source(ctx, tt.id)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.id, err)
+		}
+		archive, err := latex.Unpack(data)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%s: expected %v, got %v", tt.id, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.id, err)
+		}
+		if names := archive.Names(); !reflect.DeepEqual(names, tt.files) {
+			t.Errorf("%s: expected files %v, got %v", tt.id, tt.files, names)
+		}
+		text, err := archive.Resolve("main.tex")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.id, err)
+		}
+		if doc := latex.Parse(text); len(doc.Sections) != 3 || doc.Sections[0].Title != "Introduction" {
+			t.Errorf("%s: unexpected sections %+v", tt.id, doc.Sections)
+		}
+	}
+}
+
+func TestListing(t *testing.T) {
+	s, err := Load(Fixtures())
+	if err != nil {
+		t.Fatalf("loading fixtures: %v", err)
+	}
+	server := httptest.NewServer(s)
+	defer server.Close()
+	ctx := context.Background()
+
+	for _, format := range []string{"atom", "rss"} {
+		client := arxivclient.New(arxivclient.Config{FeedURL: server.URL + "/" + format})
+		feed, err := client.Listing(ctx, []string{"cs.CV", "astro-ph", "cs.CL"})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		var items []string
+		for _, item := range feed.Items {
+			items = append(items, string(item.Type)+" "+item.Entry.ID)
+		}
+		expected := []string{
+			"new http://arxiv.org/abs/1512.03385v1",
+			"cross http://arxiv.org/abs/1602.03837v1",
+			"replace http://arxiv.org/abs/1706.03762v7",
+			"replace http://arxiv.org/abs/1810.04805v2",
+		}
+		if !reflect.DeepEqual(items, expected) {
+			t.Errorf("%s: expected items %v, got %v", format, expected, items)
+		}
+		if feed.Date.Format("2006-01-02") != "2023-08-02" {
+			t.Errorf("%s: expected the latest update as the date, got %v", format, feed.Date)
+		}
+		if feed.Items[1].Entry.PrimaryCategory.Term != "gr-qc" || len(feed.Items[0].Entry.Authors) == 0 {
+			t.Errorf("%s: unexpected metadata %+v", format, feed.Items[1].Entry)
+		}
+	}
+
+	client := newTestClient(t)
+	feed, err := client.Listing(ctx, []string{"cs.LG"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, item := range feed.Items {
+		if item.Entry.ID == "http://arxiv.org/abs/1706.03762v7" && item.Type != listing.ReplacementCrossList {
+			t.Errorf("expected 1706.03762v7 to be a cross-listed replacement in cs.LG, got %s", item.Type)
+		}
+	}
+	if feed, err := client.Listing(ctx, []string{"math.AG"}); err != nil || len(feed.Items) != 0 || feed.Date.IsZero() {
+		t.Errorf("expected an empty feed with a date, got %+v and %v", feed, err)
+	}
+}
+
+// harvest lists every page of records for req.
+func harvest(t *testing.T, client *arxivclient.Client, req oaipmh.Request) []oaipmh.Record {
+	t.Helper()
+	var records []oaipmh.Record
+	for {
+		page, err := client.ListRecords(context.Background(), req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		records = append(records, page.Records...)
+		if page.ResumptionToken == "" {
+			return records
+		}
+		req = oaipmh.Request{ResumptionToken: page.ResumptionToken}
+	}
+}
+
+func recordIDs(records []oaipmh.Record) []string {
+	var ids []string
+	for _, record := range records {
+		ids = append(ids, record.ID)
+	}
+	return ids
+}
+
+func TestOAI(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	t.Run("pages", func(t *testing.T) {
+		page, err := client.ListRecords(ctx, oaipmh.Request{MetadataPrefix: oaipmh.FormatArXiv})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(page.Records) != oaiPageSize || page.ResumptionToken == "" || page.CompleteListSize != 11 {
+			t.Errorf("expected a first page of %d of 11 records, got %d of %d", oaiPageSize, len(page.Records), page.CompleteListSize)
+		}
+		records := harvest(t, client, oaipmh.Request{MetadataPrefix: oaipmh.FormatArXiv})
+		if len(records) != 11 {
+			t.Errorf("expected every paper, got %v", recordIDs(records))
+		}
+	})
+
+	t.Run("sets and dates", func(t *testing.T) {
+		tests := []struct {
+			req      oaipmh.Request
+			expected []string
+		}{
+			{
+				req:      oaipmh.Request{Set: "cs"},
+				expected: []string{"1412.6980", "1512.03385", "1706.03762", "1810.04805", "2006.11239", "2312.00752"},
+			},
+			{
+				req:      oaipmh.Request{Set: "physics"},
+				expected: []string{"1602.03837", "1801.00862", "hep-th/9711200", "quant-ph/9508027"},
+			},
+			{
+				req:      oaipmh.Request{Set: "physics:quant-ph"},
+				expected: []string{"1801.00862", "quant-ph/9508027"},
+			},
+			{
+				req:      oaipmh.Request{Set: "stat"},
+				expected: []string{"2006.11239"},
+			},
+			{
+				req:      oaipmh.Request{From: "2020-01-01"},
+				expected: []string{"1706.03762", "2006.11239", "2312.00752"},
+			},
+			{
+				req:      oaipmh.Request{Set: "cs", From: "2017-01-01", Until: "2020-12-31"},
+				expected: []string{"1412.6980", "1810.04805", "2006.11239"},
+			},
+			{
+				req: oaipmh.Request{Set: "math", From: "2020-01-01"},
+			},
+		}
+		for _, tt := range tests {
+			tt.req.MetadataPrefix = oaipmh.FormatArXiv
+			if ids := recordIDs(harvest(t, client, tt.req)); !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("%+v: expected %v, got %v", tt.req, tt.expected, ids)
+			}
+		}
+	})
+
+	t.Run("formats", func(t *testing.T) {
+		req := oaipmh.Request{MetadataPrefix: oaipmh.FormatArXiv, Set: "cs", From: "2023-01-01", Until: "2023-12-31"}
+		records := harvest(t, client, req)
+		if len(records) != 1 {
+			t.Fatalf("expected 1706.03762 only, got %v", recordIDs(records))
+		}
+		record := records[0]
+		if record.Datestamp != "2023-08-02" || record.Created != "2017-06-12" || record.Updated != "2023-08-02" {
+			t.Errorf("unexpected dates in %+v", record)
+		}
+		if !reflect.DeepEqual(record.Sets, []string{"cs"}) || !reflect.DeepEqual(record.Categories, []string{"cs.CL", "cs.LG"}) {
+			t.Errorf("unexpected sets %v or categories %v", record.Sets, record.Categories)
+		}
+		if len(record.Authors) != 8 || record.Authors[0] != "Ashish Vaswani" || record.Title != "Attention Is All You Need" {
+			t.Errorf("unexpected title %q or authors %v", record.Title, record.Authors)
+		}
+
+		req.MetadataPrefix = oaipmh.FormatArXivRaw
+		raw := harvest(t, client, req)[0]
+		var versions []string
+		for _, v := range raw.Versions {
+			versions = append(versions, v.Version+" "+v.Date.Format(oaipmh.DateLayout))
+		}
+		if expected := []string{"v1 2017-06-12", "v7 2023-08-02"}; !reflect.DeepEqual(versions, expected) {
+			t.Errorf("expected versions %v, got %v", expected, versions)
+		}
+		if raw.Submitter != "Ashish Vaswani" || !reflect.DeepEqual(raw.Authors, record.Authors) {
+			t.Errorf("unexpected submitter %q or authors %v", raw.Submitter, raw.Authors)
+		}
+	})
+
+	errorTests := []struct {
+		name string
+		req  oaipmh.Request
+		code string
+	}{
+		{"unknown format", oaipmh.Request{MetadataPrefix: "oai_dc"}, oaipmh.CannotDisseminateFormat},
+		{"bad date", oaipmh.Request{MetadataPrefix: oaipmh.FormatArXiv, From: "June"}, oaipmh.BadArgument},
+		{"bad resumption token", oaipmh.Request{ResumptionToken: "expired"}, oaipmh.BadResumptionToken},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.ListRecords(ctx, tt.req)
+			var oaiErr *oaipmh.Error
+			if !errors.As(err, &oaiErr) || oaiErr.Code != tt.code {
+				t.Errorf("expected %s, got %v", tt.code, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package fakearxiv
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivid"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/listing"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/textclean"
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// announced is a paper in a fake announcement.
+type announced struct {
+	id           arxivid.ID
+	metadata     arxiv.EntryMetadata
+	announceType listing.Type
+}
+
+// announcement makes up the announcement of the given categories, or
+// archives such as cs: the latest version of every paper in them, new if
+// it is the first version and a replacement otherwise, and cross-listed if
+// its primary category is not one of them. It is dated the latest update of
+// those papers, or of any paper if none is in them, since arXiv dates even an
+// empty feed.
+func (s *Server) announcement(categories []string) ([]announced, time.Time, error) {
+	in := func(term string) bool {
+		for _, c := range categories {
+			if term == c || strings.HasPrefix(term, c+".") {
+				return true
+			}
+		}
+		return false
+	}
+
+	var items []announced
+	var date, latest time.Time
+	for _, e := range s.latest {
+		metadata, err := arxiv.ParseSingleEntry(bytes.NewReader(e.raw))
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		if metadata.Updated.After(latest) {
+			latest = metadata.Updated
+		}
+		listed := false
+		for _, category := range metadata.Categories {
+			listed = listed || in(category.Term)
+		}
+		if !listed {
+			continue
+		}
+		item := announced{id: e.id, metadata: metadata, announceType: listing.New}
+		switch primary := in(metadata.PrimaryCategory.Term); {
+		case e.id.Version == 1 && !primary:
+			item.announceType = listing.CrossList
+		case e.id.Version > 1 && primary:
+			item.announceType = listing.Replacement
+		case e.id.Version > 1:
+			item.announceType = listing.ReplacementCrossList
+		}
+		items = append(items, item)
+		if metadata.Updated.After(date) {
+			date = metadata.Updated
+		}
+	}
+	if len(items) == 0 {
+		date = latest
+	}
+	return items, date, nil
+}
+
+// serveListing serves the announcement feed of the categories joined by +
+// in path, as RSS 2.0 for the rss format and Atom for atom.
+func (s *Server) serveListing(w http.ResponseWriter, r *http.Request, format, path string) {
+	categories := strings.Split(path, "+")
+	items, date, err := s.announcement(categories)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	title := strings.Join(categories, "+") + " updates on arXiv.org"
+
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	if format == "rss" {
+		b.WriteString(`<rss xmlns:arxiv="http://arxiv.org/schemas/atom" xmlns:dc="http://purl.org/dc/elements/1.1/" version="2.0">` + "\n  <channel>\n")
+		writeElement(&b, "    ", "title", title)
+		writeElement(&b, "    ", "pubDate", date.Format(time.RFC1123Z))
+		for _, item := range items {
+			m := item.metadata
+			id := item.id.String()
+			b.WriteString("    <item>\n")
+			writeElement(&b, "      ", "title", textclean.OneLine(m.Title))
+			writeElement(&b, "      ", "link", "https://arxiv.org/abs/"+item.id.Base)
+			writeElement(&b, "      ", "description", fmt.Sprintf("arXiv:%s Announce Type: %s \nAbstract: %s", id, item.announceType, textclean.OneLine(m.Summary)))
+			writeElement(&b, "      ", "guid", "oai:arXiv.org:"+id)
+			for _, category := range listingCategories(m) {
+				writeElement(&b, "      ", "category", category)
+			}
+			writeElement(&b, "      ", "pubDate", date.Format(time.RFC1123Z))
+			writeElement(&b, "      ", "arxiv:announce_type", string(item.announceType))
+			writeElement(&b, "      ", "dc:creator", authorNames(m))
+			if m.DOI != "" {
+				writeElement(&b, "      ", "arxiv:DOI", m.DOI)
+			}
+			if m.JournalReference != "" {
+				writeElement(&b, "      ", "arxiv:journal_reference", m.JournalReference)
+			}
+			b.WriteString("    </item>\n")
+		}
+		b.WriteString("  </channel>\n</rss>\n")
+		w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+		w.Write(b.Bytes())
+		return
+	}
+
+	b.WriteString(`<feed xmlns="http://www.w3.org/2005/Atom" xmlns:arxiv="http://arxiv.org/schemas/atom" xmlns:dc="http://purl.org/dc/elements/1.1/">` + "\n")
+	writeElement(&b, "  ", "id", "http://rss.arxiv.org/atom/"+path)
+	writeElement(&b, "  ", "title", title)
+	writeElement(&b, "  ", "updated", date.Format(time.RFC3339))
+	for _, item := range items {
+		m := item.metadata
+		id := item.id.String()
+		b.WriteString("  <entry>\n")
+		writeElement(&b, "    ", "id", "oai:arXiv.org:"+id)
+		writeElement(&b, "    ", "title", textclean.OneLine(m.Title))
+		writeElement(&b, "    ", "updated", date.Format(time.RFC3339))
+		fmt.Fprintf(&b, "    <link href=\"https://arxiv.org/abs/%s\" rel=\"alternate\" type=\"text/html\"/>\n", item.id.Base)
+		writeElement(&b, "    ", "summary", fmt.Sprintf("arXiv:%s Announce Type: %s \nAbstract: %s", id, item.announceType, textclean.OneLine(m.Summary)))
+		for _, category := range listingCategories(m) {
+			b.WriteString(`    <category term="`)
+			xml.EscapeText(&b, []byte(category))
+			b.WriteString(`" scheme="http://arxiv.org/schemas/atom"/>` + "\n")
+		}
+		writeElement(&b, "    ", "published", date.Format(time.RFC3339))
+		writeElement(&b, "    ", "arxiv:announce_type", string(item.announceType))
+		writeElement(&b, "    ", "dc:creator", authorNames(m))
+		if m.DOI != "" {
+			writeElement(&b, "    ", "arxiv:DOI", m.DOI)
+		}
+		if m.JournalReference != "" {
+			writeElement(&b, "    ", "arxiv:journal_reference", m.JournalReference)
+		}
+		b.WriteString("  </entry>\n")
+	}
+	b.WriteString("</feed>\n")
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.Write(b.Bytes())
+}
+
+// listingCategories returns the entry's categories with the primary one
+// first, as the feeds list them.
+func listingCategories(m arxiv.EntryMetadata) []string {
+	categories := []string{m.PrimaryCategory.Term}
+	for _, category := range m.Categories {
+		if category.Term != m.PrimaryCategory.Term {
+			categories = append(categories, category.Term)
+		}
+	}
+	return categories
+}
+
+func authorNames(m arxiv.EntryMetadata) string {
+	names := make([]string, len(m.Authors))
+	for i, author := range m.Authors {
+		names[i] = author.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+func writeElement(b *bytes.Buffer, indent, name, text string) {
+	b.WriteString(indent + "<" + name + ">")
+	xml.EscapeText(b, []byte(text))
+	b.WriteString("</" + name + ">\n")
+}
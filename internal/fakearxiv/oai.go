@@ -0,0 +1,250 @@
+package fakearxiv
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/oaipmh"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/textclean"
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// oaiPageSize is the number of records in each page of a ListRecords
+// response, small so that harvests of the fixtures need resumption tokens.
+const oaiPageSize = 4
+
+// oaiRecord is the latest version of a paper as the OAI-PMH interface lists
+// it, with the earlier versions there are fixtures for.
+type oaiRecord struct {
+	base      string
+	datestamp string
+	sets      []string
+	version   int
+	latest    arxiv.EntryMetadata
+	versions  []oaiVersion
+}
+
+type oaiVersion struct {
+	version int
+	date    time.Time
+}
+
+// oaiRecords returns a record for every paper, datestamped with the date of
+// its latest version.
+func (s *Server) oaiRecords() ([]oaiRecord, error) {
+	var records []oaiRecord
+	for _, e := range s.latest {
+		metadata, err := arxiv.ParseSingleEntry(bytes.NewReader(e.raw))
+		if err != nil {
+			return nil, err
+		}
+		record := oaiRecord{base: e.id.Base, version: e.id.Version, latest: metadata}
+		for _, v := range s.entries {
+			if v.id.Base == e.id.Base {
+				record.versions = append(record.versions, oaiVersion{v.id.Version, v.doc.Updated})
+			}
+		}
+		record.datestamp = record.latest.Updated.UTC().Format(oaipmh.DateLayout)
+		for _, category := range listingCategories(record.latest) {
+			if set := oaipmh.SetSpec(category); !slices.Contains(record.sets, set) {
+				record.sets = append(record.sets, set)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// inSet reports whether the record is in set or a set under it, as
+// physics:hep-th is under physics.
+func (r oaiRecord) inSet(set string) bool {
+	for _, s := range r.sets {
+		if s == set || strings.HasPrefix(s, set+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// serveOAI serves the ListRecords verb of arXiv's OAI-PMH interface.
+// Resumption tokens hold the arguments of the list and the number of records
+// listed before the next page.
+func (s *Server) serveOAI(w http.ResponseWriter, r *http.Request) {
+	if verb := r.FormValue("verb"); verb != "ListRecords" {
+		writeOAIError(w, r, oaipmh.BadVerb, fmt.Sprintf("verb %q is not supported", verb))
+		return
+	}
+	req := oaipmh.Request{
+		MetadataPrefix: r.FormValue("metadataPrefix"),
+		Set:            r.FormValue("set"),
+		From:           r.FormValue("from"),
+		Until:          r.FormValue("until"),
+	}
+	cursor := 0
+	if token := r.FormValue("resumptionToken"); token != "" {
+		if req != (oaipmh.Request{}) {
+			writeOAIError(w, r, oaipmh.BadArgument, "resumptionToken is an exclusive argument")
+			return
+		}
+		parts := strings.Split(token, "|")
+		n, err := strconv.Atoi(parts[len(parts)-1])
+		if len(parts) != 5 || err != nil || n < 0 {
+			writeOAIError(w, r, oaipmh.BadResumptionToken, fmt.Sprintf("resumptionToken %q is invalid or expired", token))
+			return
+		}
+		req = oaipmh.Request{MetadataPrefix: parts[0], Set: parts[1], From: parts[2], Until: parts[3]}
+		cursor = n
+	}
+	switch req.MetadataPrefix {
+	case oaipmh.FormatArXiv, oaipmh.FormatArXivRaw:
+	case "":
+		writeOAIError(w, r, oaipmh.BadArgument, "metadataPrefix is required")
+		return
+	default:
+		writeOAIError(w, r, oaipmh.CannotDisseminateFormat, fmt.Sprintf("format %q is not supported", req.MetadataPrefix))
+		return
+	}
+	for _, date := range []string{req.From, req.Until} {
+		if _, err := time.Parse(oaipmh.DateLayout, date); date != "" && err != nil {
+			writeOAIError(w, r, oaipmh.BadArgument, fmt.Sprintf("date %q is not in YYYY-MM-DD", date))
+			return
+		}
+	}
+
+	all, err := s.oaiRecords()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var records []oaiRecord
+	for _, record := range all {
+		if (req.Set == "" || record.inSet(req.Set)) &&
+			(req.From == "" || record.datestamp >= req.From) &&
+			(req.Until == "" || record.datestamp <= req.Until) {
+			records = append(records, record)
+		}
+	}
+	if len(records) == 0 {
+		writeOAIError(w, r, oaipmh.NoRecordsMatch, "no records match the arguments")
+		return
+	}
+	if cursor >= len(records) {
+		writeOAIError(w, r, oaipmh.BadResumptionToken, "resumptionToken is past the end of the list")
+		return
+	}
+
+	var b bytes.Buffer
+	writeOAIHeader(&b, r)
+	b.WriteString("  <ListRecords>\n")
+	end := min(cursor+oaiPageSize, len(records))
+	for _, record := range records[cursor:end] {
+		writeOAIRecord(&b, record, req.MetadataPrefix)
+	}
+	// A list split into pages ends each with a token; the last one's is
+	// empty.
+	if cursor > 0 || end < len(records) {
+		fmt.Fprintf(&b, `    <resumptionToken cursor="%d" completeListSize="%d">`, cursor, len(records))
+		if end < len(records) {
+			xml.EscapeText(&b, []byte(strings.Join([]string{req.MetadataPrefix, req.Set, req.From, req.Until, strconv.Itoa(end)}, "|")))
+		}
+		b.WriteString("</resumptionToken>\n")
+	}
+	b.WriteString("  </ListRecords>\n</OAI-PMH>\n")
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.Write(b.Bytes())
+}
+
+func writeOAIHeader(b *bytes.Buffer, r *http.Request) {
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString(`<OAI-PMH xmlns="http://www.openarchives.org/OAI/2.0/">` + "\n")
+	writeElement(b, "  ", "responseDate", time.Now().UTC().Format(time.RFC3339))
+	writeElement(b, "  ", "request", "http://"+r.Host+r.URL.Path)
+}
+
+// writeOAIError responds as OAI-PMH repositories do to a bad request: a 200
+// with an error element.
+func writeOAIError(w http.ResponseWriter, r *http.Request, code, message string) {
+	var b bytes.Buffer
+	writeOAIHeader(&b, r)
+	fmt.Fprintf(&b, `  <error code="%s">`, code)
+	xml.EscapeText(&b, []byte(message))
+	b.WriteString("</error>\n</OAI-PMH>\n")
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.Write(b.Bytes())
+}
+
+func writeOAIRecord(b *bytes.Buffer, record oaiRecord, format string) {
+	m := record.latest
+	b.WriteString("    <record>\n      <header>\n")
+	writeElement(b, "        ", "identifier", "oai:arXiv.org:"+record.base)
+	writeElement(b, "        ", "datestamp", record.datestamp)
+	for _, set := range record.sets {
+		writeElement(b, "        ", "setSpec", set)
+	}
+	b.WriteString("      </header>\n      <metadata>\n")
+
+	const indent = "          "
+	if format == oaipmh.FormatArXiv {
+		b.WriteString(`        <arXiv xmlns="http://arxiv.org/OAI/arXiv/">` + "\n")
+		writeElement(b, indent, "id", record.base)
+		writeElement(b, indent, "created", m.Published.UTC().Format(oaipmh.DateLayout))
+		if record.version > 1 {
+			writeElement(b, indent, "updated", m.Updated.UTC().Format(oaipmh.DateLayout))
+		}
+		b.WriteString(indent + "<authors>\n")
+		for _, author := range m.Authors {
+			name := textclean.OneLine(author.Name)
+			forenames, keyname := "", name
+			if i := strings.LastIndex(name, " "); i >= 0 {
+				forenames, keyname = name[:i], name[i+1:]
+			}
+			b.WriteString(indent + "  <author>")
+			b.WriteString("<keyname>")
+			xml.EscapeText(b, []byte(keyname))
+			b.WriteString("</keyname>")
+			if forenames != "" {
+				b.WriteString("<forenames>")
+				xml.EscapeText(b, []byte(forenames))
+				b.WriteString("</forenames>")
+			}
+			b.WriteString("</author>\n")
+		}
+		b.WriteString(indent + "</authors>\n")
+	} else {
+		b.WriteString(`        <arXivRaw xmlns="http://arxiv.org/OAI/arXivRaw/">` + "\n")
+		writeElement(b, indent, "id", record.base)
+		if len(m.Authors) > 0 {
+			writeElement(b, indent, "submitter", textclean.OneLine(m.Authors[0].Name))
+		}
+		for _, v := range record.versions {
+			fmt.Fprintf(b, "%s<version version=\"v%d\">\n", indent, v.version)
+			writeElement(b, indent+"  ", "date", v.date.UTC().Format("Mon, 2 Jan 2006 15:04:05 GMT"))
+			b.WriteString(indent + "</version>\n")
+		}
+		writeElement(b, indent, "authors", authorNames(m))
+	}
+	writeElement(b, indent, "title", textclean.OneLine(m.Title))
+	writeElement(b, indent, "categories", strings.Join(listingCategories(m), " "))
+	if m.Comment != "" {
+		writeElement(b, indent, "comments", textclean.OneLine(m.Comment))
+	}
+	if m.JournalReference != "" {
+		writeElement(b, indent, "journal-ref", textclean.OneLine(m.JournalReference))
+	}
+	if m.DOI != "" {
+		writeElement(b, indent, "doi", m.DOI)
+	}
+	writeElement(b, indent, "abstract", textclean.OneLine(m.Summary))
+	if format == oaipmh.FormatArXiv {
+		b.WriteString("        </arXiv>\n")
+	} else {
+		b.WriteString("        </arXivRaw>\n")
+	}
+	b.WriteString("      </metadata>\n    </record>\n")
+}
@@ -0,0 +1,193 @@
+package fakearxiv
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// PDFLine is a line of text in a PDF built by PDF.
+type PDFLine struct {
+	Text string
+	Size float64 // font size in points; 0 means 10
+	Bold bool
+}
+
+const (
+	pageWidth    = 612
+	pageHeight   = 792
+	pageMargin   = 72
+	charWidth    = 500 // width of every character, in thousandths of the font size
+	lineSpacing  = 1.4
+	blankSpacing = 0.8
+)
+
+// PDF returns a PDF document with a page of lines for each element of pages,
+// set in Helvetica. An empty line leaves a gap, as between paragraphs. Lines
+// that run off the bottom of a page are dropped.
+func PDF(pages [][]PDFLine) []byte {
+	var b bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	b.WriteString("%PDF-1.4\n")
+	// Objects 1 to 4 are the catalog, the page tree and the two fonts. Each
+	// page is followed by its content stream.
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	widths := strings.TrimSpace(strings.Repeat(fmt.Sprintf("%d ", charWidth), 256-32))
+	for _, font := range []string{"Helvetica", "Helvetica-Bold"} {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding /FirstChar 32 /LastChar 255 /Widths [%s] >>", font, widths))
+	}
+	for i, lines := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+2*i))
+		content := pageContent(lines)
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return b.Bytes()
+}
+
+func pageContent(lines []PDFLine) string {
+	var b strings.Builder
+	y := float64(pageHeight - pageMargin)
+	for _, line := range lines {
+		size := line.Size
+		if size == 0 {
+			size = 10
+		}
+		if line.Text == "" {
+			y -= size * blankSpacing
+			continue
+		}
+		y -= size * lineSpacing
+		if y < pageMargin {
+			break
+		}
+		font := "F1"
+		if line.Bold {
+			font = "F2"
+		}
+		fmt.Fprintf(&b, "BT /%s %g Tf %d %.2f Td (%s) Tj ET\n", font, size, pageMargin, y, pdfString(line.Text))
+	}
+	return b.String()
+}
+
+// pdfString escapes s for a PDF string literal in WinAnsiEncoding. Characters
+// outside Latin-1 are replaced with a question mark.
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < ' ' || r > 0xff:
+			b.WriteByte('?')
+		default:
+			b.WriteByte(byte(r))
+		}
+	}
+	return b.String()
+}
+
+// citedReference is cited by every fixture paper, in its PDF and its source,
+// so that there is a reference to an arXiv paper to resolve.
+const citedReference = "D. P. Kingma and J. Ba. Adam: A method for stochastic optimization. arXiv preprint arXiv:1412.6980, 2014."
+
+// paperPDF lays out a PDF for the fixture entry: the title, authors and
+// abstract, followed by numbered sections made up from the abstract and a
+// reference list.
+func paperPDF(e entry) []byte {
+	const width = 90 // characters in a line of body text
+	body := func(text string) []PDFLine {
+		var lines []PDFLine
+		for _, line := range wrap(text, width) {
+			lines = append(lines, PDFLine{Text: line})
+		}
+		return append(lines, PDFLine{})
+	}
+	heading := func(text string) []PDFLine {
+		return []PDFLine{{Text: text, Size: 12, Bold: true}}
+	}
+
+	var first []PDFLine
+	for _, line := range wrap(e.doc.Title, 60) {
+		first = append(first, PDFLine{Text: line, Size: 17})
+	}
+	first = append(first, PDFLine{Text: strings.Join(e.doc.Authors, ", "), Size: 11}, PDFLine{})
+	first = append(first, heading("Abstract")...)
+	first = append(first, body(e.doc.Abstract)...)
+	first = append(first, heading("1 Introduction")...)
+	first = append(first, body(e.doc.Title+". "+e.doc.Abstract)...)
+
+	var second []PDFLine
+	second = append(second, heading("2 Method")...)
+	second = append(second, body(e.doc.Abstract)...)
+	second = append(second, PDFLine{Text: "2.1 Setup", Size: 10, Bold: true})
+	second = append(second, body("The setup follows "+e.doc.ID+".")...)
+	second = append(second, heading("References")...)
+	references := make([]string, len(e.doc.Authors))
+	for i, author := range e.doc.Authors {
+		references[i] = fmt.Sprintf("%s. An earlier paper. 2001.", author)
+	}
+	references = append(references, citedReference)
+	for i, reference := range references {
+		for _, line := range wrap(fmt.Sprintf("[%d] %s", i+1, reference), width) {
+			second = append(second, PDFLine{Text: line})
+		}
+	}
+	return PDF([][]PDFLine{first, second})
+}
+
+// wrap breaks text into lines of at most width characters, at spaces.
+func wrap(text string, width int) []string {
+	var lines []string
+	var line strings.Builder
+	for _, word := range strings.Fields(text) {
+		if line.Len() > 0 && line.Len()+1+len(word) > width {
+			lines = append(lines, line.String())
+			line.Reset()
+		}
+		if line.Len() > 0 {
+			line.WriteByte(' ')
+		}
+		line.WriteString(word)
+	}
+	if line.Len() > 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// servePDF responds to /pdf/{id} as arXiv does, with a PDF made up for the
+// fixture entry.
+func (s *Server) servePDF(w http.ResponseWriter, r *http.Request, rawID string) {
+	id, err := parseFileID(rawID, ".pdf")
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	e, ok := s.lookup(id)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Write(paperPDF(e))
+}
@@ -0,0 +1,109 @@
+package fakearxiv
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// paperSource makes up the LaTeX source of the fixture entry, with the same
+// outline as its PDF: a main file that inputs its introduction from a
+// subdirectory, an equation, a figure and a bibliography.
+func paperSource(e entry) map[string]string {
+	var bib strings.Builder
+	for i, author := range e.doc.Authors {
+		fmt.Fprintf(&bib, "\\bibitem{ref%d} %s.\n\\newblock An earlier paper.\n\\newblock 2001.\n", i+1, latexEscape(author))
+	}
+	bib.WriteString("\\bibitem{kingma2014} D.~P. Kingma and J.~Ba.\n\\newblock {\\em Adam: A method for stochastic optimization}.\n\\newblock arXiv preprint arXiv:1412.6980, 2014.\n")
+	main := fmt.Sprintf(`\documentclass{article}
+\usepackage{amsmath}
+%% Source made up by the fake arXiv server.
+\title{%s}
+\author{%s}
+\begin{document}
+\maketitle
+\begin{abstract}
+%s
+\end{abstract}
+
+\input{sections/intro}
+
+\section{Method}
+\label{sec:method}
+%s
+\begin{equation}
+  y = f(x) \label{eq:model}
+\end{equation}
+
+\subsection{Setup}
+The setup follows %s.
+\begin{figure}[t]
+  \centering
+  \caption{Overview of the method.}
+  \label{fig:overview}
+\end{figure}
+
+\begin{thebibliography}{99}
+%s\end{thebibliography}
+\end{document}
+`, latexEscape(e.doc.Title), latexEscape(strings.Join(e.doc.Authors, ` \and `)), latexEscape(e.doc.Abstract),
+		latexEscape(e.doc.Abstract), e.doc.ID, bib.String())
+	intro := fmt.Sprintf("\\section{Introduction}\n\\label{sec:intro}\n%s. %s\n", latexEscape(e.doc.Title), latexEscape(e.doc.Abstract))
+	return map[string]string{"main.tex": main, "sections/intro.tex": intro}
+}
+
+// latexEscape escapes the characters that are special in LaTeX text, other
+// than those used for math in fixture abstracts.
+func latexEscape(s string) string {
+	return strings.NewReplacer("%", `\%`, "&", `\&`, "#", `\#`).Replace(s)
+}
+
+// serveSource responds to /e-print/{id} as arXiv does. Most papers get a
+// gzipped tarball; old-style hep-th papers get their main file alone,
+// gzipped, as for single-file submissions; and math papers get a PDF, as for
+// papers submitted without source.
+func (s *Server) serveSource(w http.ResponseWriter, r *http.Request, rawID string) {
+	id, err := parseFileID(rawID, "")
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	e, ok := s.lookup(id)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(id.Base, "math/"):
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write(paperPDF(e))
+	case strings.HasPrefix(id.Base, "hep-th/"):
+		files := paperSource(e)
+		main := strings.Replace(files["main.tex"], `\input{sections/intro}`, files["sections/intro.tex"], 1)
+		w.Header().Set("Content-Type", "application/x-gzip")
+		w.Write(gzipped([]byte(main)))
+	default:
+		var b bytes.Buffer
+		tw := tar.NewWriter(&b)
+		files := paperSource(e)
+		for _, name := range []string{"main.tex", "sections/intro.tex"} {
+			tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(files[name])), Typeflag: tar.TypeReg, Format: tar.FormatUSTAR})
+			tw.Write([]byte(files[name]))
+		}
+		tw.Close()
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Write(gzipped(b.Bytes()))
+	}
+}
+
+func gzipped(data []byte) []byte {
+	var b bytes.Buffer
+	zw := gzip.NewWriter(&b)
+	zw.Write(data)
+	zw.Close()
+	return b.Bytes()
+}
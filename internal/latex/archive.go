@@ -0,0 +1,225 @@
+package latex
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Limits on what Unpack accepts, so that a crafted archive cannot exhaust
+// memory.
+const (
+	MaxFiles        = 5000
+	MaxUnpackedSize = 256 << 20
+)
+
+var (
+	// ErrNoSource is returned by Unpack for an e-print that is a PDF, as
+	// arXiv serves for papers submitted without source.
+	ErrNoSource = errors.New("the paper has no LaTeX source, only a PDF")
+	// ErrNoMainFile is returned by MainFile if no file has a \documentclass.
+	ErrNoMainFile = errors.New("no main .tex file found")
+)
+
+// Archive is the unpacked source of a paper.
+type Archive struct {
+	Files   map[string][]byte // contents by slash-separated path relative to the archive root
+	Skipped []string          // entries not unpacked: links, devices and paths outside the root
+}
+
+// Unpack unpacks an arXiv e-print, which is a gzipped tarball for most
+// papers and a single gzipped .tex file for papers submitted as one file.
+// Plain tarballs and uncompressed .tex files are accepted too.
+//
+// Nothing is written to disk. Entries whose names are absolute or climb out
+// of the archive root are skipped, as are links and special files.
+func Unpack(data []byte) (*Archive, error) {
+	if bytes.HasPrefix(data, []byte("%PDF-")) {
+		return nil, ErrNoSource
+	}
+	name := "main.tex"
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("reading gzip: %w", err)
+		}
+		if ext := path.Ext(zr.Name); zr.Name != "" && (ext == ".tex" || ext == ".ltx") {
+			if clean, ok := safePath(zr.Name); ok && !strings.Contains(clean, "/") {
+				name = clean
+			}
+		}
+		data, err = readLimited(zr)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.HasPrefix(data, []byte("%PDF-")) {
+			return nil, ErrNoSource
+		}
+	}
+
+	if isTar(data) {
+		return unpackTar(data)
+	}
+	return &Archive{Files: map[string][]byte{name: data}}, nil
+}
+
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxUnpackedSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading gzip: %w", err)
+	}
+	if len(data) > MaxUnpackedSize {
+		return nil, fmt.Errorf("source is larger than %d MB unpacked", MaxUnpackedSize>>20)
+	}
+	return data, nil
+}
+
+// isTar reports whether data starts with a POSIX or GNU tar header.
+func isTar(data []byte) bool {
+	return len(data) >= 262 && string(data[257:262]) == "ustar"
+}
+
+func unpackTar(data []byte) (*Archive, error) {
+	archive := &Archive{Files: make(map[string][]byte)}
+	tr := tar.NewReader(bytes.NewReader(data))
+	total := 0
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading tar: %w", err)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			continue
+		case tar.TypeReg:
+		default:
+			archive.Skipped = append(archive.Skipped, header.Name)
+			continue
+		}
+		name, ok := safePath(header.Name)
+		if !ok {
+			archive.Skipped = append(archive.Skipped, header.Name)
+			continue
+		}
+		if len(archive.Files) == MaxFiles {
+			return nil, fmt.Errorf("source has more than %d files", MaxFiles)
+		}
+		contents, err := io.ReadAll(io.LimitReader(tr, int64(MaxUnpackedSize-total+1)))
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", header.Name, err)
+		}
+		total += len(contents)
+		if total > MaxUnpackedSize {
+			return nil, fmt.Errorf("source is larger than %d MB unpacked", MaxUnpackedSize>>20)
+		}
+		archive.Files[name] = contents
+	}
+	return archive, nil
+}
+
+// safePath cleans an archive entry name, reporting false if it is absolute
+// or leads outside the archive root.
+func safePath(name string) (string, bool) {
+	name = strings.ReplaceAll(name, `\`, "/")
+	if path.IsAbs(name) || (len(name) > 1 && name[1] == ':') {
+		return "", false
+	}
+	clean := path.Clean(name)
+	if clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", false
+	}
+	return clean, true
+}
+
+// Names returns the paths of the files in the archive, sorted.
+func (a *Archive) Names() []string {
+	names := make([]string, 0, len(a.Files))
+	for name := range a.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// conventionalMainNames are names often given to the main file, used to
+// choose between files that could each be it.
+var conventionalMainNames = map[string]bool{
+	"main.tex": true, "ms.tex": true, "paper.tex": true, "article.tex": true,
+}
+
+// MainFile returns the path of the file that LaTeX should be run on: the
+// top-level file named in arXiv's 00README, or else the file that has a
+// \documentclass and a document environment. Files with conventional main
+// file names are preferred, then larger ones.
+func (a *Archive) MainFile() (string, error) {
+	if name := a.readmeTopLevel(); name != "" {
+		return name, nil
+	}
+
+	var best string
+	bestScore := 0
+	for _, name := range a.Names() {
+		if ext := path.Ext(name); ext != ".tex" && ext != ".ltx" {
+			continue
+		}
+		text := StripComments(string(a.Files[name]))
+		if !strings.Contains(text, `\documentclass`) && !strings.Contains(text, `\documentstyle`) {
+			continue
+		}
+		score := 1
+		if strings.Contains(text, `\begin{document}`) {
+			score += 4
+		}
+		if conventionalMainNames[path.Base(name)] {
+			score += 2
+		}
+		if score > bestScore || (score == bestScore && len(a.Files[name]) > len(a.Files[best])) {
+			best, bestScore = name, score
+		}
+	}
+	if best == "" {
+		return "", ErrNoMainFile
+	}
+	return best, nil
+}
+
+// readmeTopLevel returns the top-level file named in 00README.json, or in
+// the older 00README.XXX, if it is in the archive.
+func (a *Archive) readmeTopLevel() string {
+	if data, ok := a.Files["00README.json"]; ok {
+		var readme struct {
+			Sources []struct {
+				Filename string `json:"filename"`
+				Usage    string `json:"usage"`
+			} `json:"sources"`
+		}
+		if json.Unmarshal(data, &readme) == nil {
+			for _, source := range readme.Sources {
+				if _, ok := a.Files[source.Filename]; ok && source.Usage == "toplevel" {
+					return source.Filename
+				}
+			}
+		}
+	}
+	if data, ok := a.Files["00README.XXX"]; ok {
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 2 && fields[1] == "toplevelfile" {
+				if _, ok := a.Files[fields[0]]; ok {
+					return fields[0]
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,242 @@
+package latex
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type tarEntry struct {
+	name     string
+	body     string
+	typeflag byte
+}
+
+func tarball(t *testing.T, entries ...tarEntry) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	tw := tar.NewWriter(&b)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Mode: 0o644, Size: int64(len(e.body)), Typeflag: e.typeflag}
+		if e.typeflag == 0 {
+			header.Typeflag = tar.TypeReg
+		}
+		if header.Typeflag != tar.TypeReg {
+			header.Size = 0
+			header.Linkname = e.body
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			tw.Write([]byte(e.body))
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func gzipped(t *testing.T, name string, data []byte) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	zw := gzip.NewWriter(&b)
+	zw.Name = name
+	zw.Write(data)
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestUnpack(t *testing.T) {
+	t.Run("gzipped tarball", func(t *testing.T) {
+		data := gzipped(t, "", tarball(t,
+			tarEntry{name: "main.tex", body: `\documentclass{article}`},
+			tarEntry{name: "./sections/intro.tex", body: "Intro."},
+			tarEntry{name: "figures/", typeflag: tar.TypeDir},
+		))
+		archive, err := Unpack(data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if names := archive.Names(); !reflect.DeepEqual(names, []string{"main.tex", "sections/intro.tex"}) {
+			t.Errorf("unexpected files %v", names)
+		}
+		if len(archive.Skipped) != 0 {
+			t.Errorf("expected nothing skipped, got %v", archive.Skipped)
+		}
+	})
+
+	t.Run("path traversal", func(t *testing.T) {
+		data := gzipped(t, "", tarball(t,
+			tarEntry{name: "main.tex", body: "ok"},
+			tarEntry{name: "../../etc/passwd", body: "root"},
+			tarEntry{name: "/etc/shadow", body: "root"},
+			tarEntry{name: "figs/../../escape.tex", body: "x"},
+			tarEntry{name: `..\windows.tex`, body: "x"},
+			tarEntry{name: "C:/autoexec.tex", body: "x"},
+			tarEntry{name: "link.tex", body: "/etc/passwd", typeflag: tar.TypeSymlink},
+			tarEntry{name: "hard.tex", body: "main.tex", typeflag: tar.TypeLink},
+			tarEntry{name: "figs/./../inside.tex", body: "fine"},
+		))
+		archive, err := Unpack(data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if names := archive.Names(); !reflect.DeepEqual(names, []string{"inside.tex", "main.tex"}) {
+			t.Errorf("unexpected files %v", names)
+		}
+		if len(archive.Skipped) != 7 {
+			t.Errorf("expected 7 skipped entries, got %v", archive.Skipped)
+		}
+	})
+
+	t.Run("gzipped single file", func(t *testing.T) {
+		archive, err := Unpack(gzipped(t, "paper.tex", []byte(`\documentclass{article}`)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(archive.Files["paper.tex"]) != `\documentclass{article}` {
+			t.Errorf("expected paper.tex, got %v", archive.Names())
+		}
+	})
+
+	t.Run("gzipped single file without a name", func(t *testing.T) {
+		archive, err := Unpack(gzipped(t, "../../evil.tex", []byte(`\documentclass{article}`)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := archive.Files["main.tex"]; !ok {
+			t.Errorf("expected main.tex, got %v", archive.Names())
+		}
+	})
+
+	t.Run("plain tarball", func(t *testing.T) {
+		archive, err := Unpack(tarball(t, tarEntry{name: "a.tex", body: "a"}))
+		if err != nil || len(archive.Files) != 1 {
+			t.Errorf("expected one file, got %v, %v", archive, err)
+		}
+	})
+
+	t.Run("pdf only", func(t *testing.T) {
+		for _, data := range [][]byte{[]byte("%PDF-1.5 ..."), gzipped(t, "", []byte("%PDF-1.5 ..."))} {
+			if _, err := Unpack(data); !errors.Is(err, ErrNoSource) {
+				t.Errorf("expected ErrNoSource, got %v", err)
+			}
+		}
+	})
+
+	t.Run("corrupt gzip", func(t *testing.T) {
+		if _, err := Unpack([]byte{0x1f, 0x8b, 0, 0}); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestSafePath(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		ok       bool
+	}{
+		{"main.tex", "main.tex", true},
+		{"./a/b.tex", "a/b.tex", true},
+		{"a/../b.tex", "b.tex", true},
+		{"../b.tex", "", false},
+		{"a/../../b.tex", "", false},
+		{"/abs.tex", "", false},
+		{`a\..\..\b.tex`, "", false},
+		{"D:x.tex", "", false},
+		{".", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := safePath(tt.name)
+		if got != tt.expected || ok != tt.ok {
+			t.Errorf("safePath(%q): expected %q, %v, got %q, %v", tt.name, tt.expected, tt.ok, got, ok)
+		}
+	}
+}
+
+func TestMainFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected string
+	}{
+		{
+			name: "only file with documentclass",
+			files: map[string]string{
+				"intro.tex": "Intro.",
+				"paper.tex": "\\documentclass{article}\n\\begin{document}\\input{intro}\\end{document}",
+			},
+			expected: "paper.tex",
+		},
+		{
+			name: "commented documentclass",
+			files: map[string]string{
+				"old.tex":   "% \\documentclass{article}\n\\begin{document}\\end{document}",
+				"main2.tex": "\\documentclass{revtex4}\n\\begin{document}\\end{document}",
+			},
+			expected: "main2.tex",
+		},
+		{
+			name: "prefers a document environment",
+			files: map[string]string{
+				"standalone.tex": "\\documentclass{standalone}\n",
+				"z.tex":          "\\documentclass{article}\n\\begin{document}\\end{document}",
+			},
+			expected: "z.tex",
+		},
+		{
+			name: "prefers conventional names",
+			files: map[string]string{
+				"supplement.tex": "\\documentclass{article}\n\\begin{document}A much longer supplement.\\end{document}",
+				"sub/main.tex":   "\\documentclass{article}\n\\begin{document}\\end{document}",
+			},
+			expected: "sub/main.tex",
+		},
+		{
+			name: "00README.json",
+			files: map[string]string{
+				"00README.json": `{"sources": [{"filename": "b.tex", "usage": "toplevel"}]}`,
+				"a.tex":         "\\documentclass{article}\n\\begin{document}\\end{document}",
+				"b.tex":         "\\documentclass{article}\n\\begin{document}\\end{document}",
+			},
+			expected: "b.tex",
+		},
+		{
+			name: "00README.XXX",
+			files: map[string]string{
+				"00README.XXX": "b.tex toplevelfile\nfig.eps ignore\n",
+				"main.tex":     "\\documentclass{article}\n\\begin{document}\\end{document}",
+				"b.tex":        "\\documentclass{article}\n\\begin{document}\\end{document}",
+			},
+			expected: "b.tex",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archive := &Archive{Files: make(map[string][]byte)}
+			for name, body := range tt.files {
+				archive.Files[name] = []byte(body)
+			}
+			main, err := archive.MainFile()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if main != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, main)
+			}
+		})
+	}
+
+	archive := &Archive{Files: map[string][]byte{"notes.txt": []byte("no tex")}}
+	if _, err := archive.MainFile(); !errors.Is(err, ErrNoMainFile) {
+		t.Errorf("expected ErrNoMainFile, got %v", err)
+	}
+}
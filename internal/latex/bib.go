@@ -0,0 +1,148 @@
+package latex
+
+import (
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/textclean"
+	"regexp"
+	"strings"
+)
+
+var bibEntryRe = regexp.MustCompile(`@\s*([A-Za-z]+)\s*[{(]`)
+
+// ParseBib parses the entries of a BibTeX database. @string, @preamble and
+// @comment entries are skipped, and @string abbreviations are not expanded.
+// The Text of each item is made up from its authors, title, venue and year.
+func ParseBib(bib string) []BibItem {
+	var items []BibItem
+	for _, m := range bibEntryRe.FindAllStringSubmatchIndex(bib, -1) {
+		kind := strings.ToLower(bib[m[2]:m[3]])
+		if kind == "string" || kind == "preamble" || kind == "comment" {
+			continue
+		}
+		body, ok := entryBody(bib, m[1]-1)
+		if !ok {
+			continue
+		}
+		key, fields, ok := strings.Cut(body, ",")
+		if !ok {
+			continue
+		}
+		item := BibItem{Key: strings.TrimSpace(key), Fields: parseBibFields(fields)}
+		item.Fields["type"] = kind
+		item.Text = bibText(item.Fields)
+		items = append(items, item)
+	}
+	return items
+}
+
+// entryBody returns the body of the entry whose opening delimiter, a brace
+// or parenthesis, is at bib[open].
+func entryBody(bib string, open int) (string, bool) {
+	if bib[open] == '{' {
+		body, _, ok := group(bib, open)
+		return body, ok
+	}
+	depth := 0
+	for i := open; i < len(bib); i++ {
+		switch bib[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ')':
+			if depth == 0 {
+				return bib[open+1 : i], true
+			}
+		}
+	}
+	return "", false
+}
+
+// parseBibFields parses name = value pairs, where a value is a braced group,
+// a quoted string, a number or an abbreviation, or several joined with #.
+func parseBibFields(s string) map[string]string {
+	fields := make(map[string]string)
+	for i := 0; i < len(s); {
+		eq := strings.IndexByte(s[i:], '=')
+		if eq < 0 {
+			break
+		}
+		name := strings.ToLower(strings.Trim(strings.TrimSpace(s[i:i+eq]), ","))
+		name = strings.TrimSpace(name)
+		i += eq + 1
+
+		var parts []string
+		for {
+			for i < len(s) && strings.ContainsRune(" \t\r\n", rune(s[i])) {
+				i++
+			}
+			if i >= len(s) {
+				break
+			}
+			switch s[i] {
+			case '{':
+				value, end, ok := group(s, i)
+				if !ok {
+					return fields
+				}
+				parts = append(parts, value)
+				i = end
+			case '"':
+				end := i + 1
+				depth := 0
+				for end < len(s) && (s[end] != '"' || depth > 0) {
+					switch s[end] {
+					case '{':
+						depth++
+					case '}':
+						depth--
+					}
+					end++
+				}
+				parts = append(parts, s[i+1:min(end, len(s))])
+				i = end + 1
+			default:
+				end := i
+				for end < len(s) && !strings.ContainsRune(",#} \t\r\n", rune(s[end])) {
+					end++
+				}
+				parts = append(parts, s[i:end])
+				i = end
+			}
+			for i < len(s) && strings.ContainsRune(" \t\r\n", rune(s[i])) {
+				i++
+			}
+			if i < len(s) && s[i] == '#' {
+				i++
+				continue
+			}
+			break
+		}
+		if name != "" {
+			fields[name] = textclean.OneLine(strings.Join(parts, ""))
+		}
+		if comma := strings.IndexByte(s[min(i, len(s)):], ','); comma >= 0 {
+			i += comma + 1
+		} else {
+			break
+		}
+	}
+	return fields
+}
+
+// bibText makes up a reference from BibTeX fields, in the order most styles
+// use.
+func bibText(fields map[string]string) string {
+	var parts []string
+	for _, name := range []string{"author", "title", "journal", "booktitle", "publisher", "year"} {
+		if value := fields[name]; value != "" {
+			if name == "author" {
+				value = strings.ReplaceAll(value, " and ", ", ")
+			}
+			parts = append(parts, value)
+		}
+	}
+	if eprint := fields["eprint"]; eprint != "" {
+		parts = append(parts, "arXiv:"+eprint)
+	}
+	return strings.Join(parts, ". ")
+}
@@ -0,0 +1,400 @@
+// Package latex unpacks the LaTeX source of arXiv papers and picks out their
+// structure: sections, displayed equations, figure and table captions, and
+// the bibliography.
+//
+// It does not run TeX. Macros are not expanded, and the text of each part is
+// returned as the LaTeX it was written in, with comments removed.
+package latex
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/textclean"
+)
+
+// Document is the structure of a paper found in its LaTeX source.
+type Document struct {
+	Title        string
+	Abstract     string
+	Sections     []Section
+	Equations    []Equation
+	Figures      []Figure
+	Bibliography []BibItem
+}
+
+// Section is a section, subsection or subsubsection.
+type Section struct {
+	Number string // such as 2.1 or A, empty for unnumbered sections
+	Title  string
+	Level  int // 1 for a section, 2 for a subsection and 3 for a subsubsection
+	Label  string
+	Text   string // text up to the next heading, subsections excluded
+}
+
+// Equation is a displayed equation.
+type Equation struct {
+	Environment string // such as equation or align*; \[ and $$ are given as displaymath
+	Label       string
+	Body        string
+	Section     string // number, or else title, of the section it is in
+}
+
+// Figure is a figure or table float.
+type Figure struct {
+	Environment string // such as figure, figure* or table
+	Label       string
+	Caption     string
+	Section     string // number, or else title, of the section it is in
+}
+
+// BibItem is an entry of the bibliography.
+type BibItem struct {
+	Key    string
+	Text   string            // the entry as typeset, in LaTeX
+	Fields map[string]string // BibTeX fields, for entries read from a .bib file
+}
+
+// Parse finds the structure of the paper whose main file is main, resolving
+// the files it inputs. The bibliography is read from a thebibliography
+// environment in the text, or else from the .bbl or .bib files named by
+// \bibliography.
+func (a *Archive) Parse(main string) (*Document, error) {
+	tex, err := a.Resolve(main)
+	if err != nil {
+		return nil, err
+	}
+	doc := Parse(tex)
+	if len(doc.Bibliography) == 0 {
+		doc.Bibliography = a.externalBibliography(main, tex)
+	}
+	return doc, nil
+}
+
+// Parse finds the structure of a paper in its resolved LaTeX source.
+func Parse(tex string) *Document {
+	doc := &Document{}
+	if title, ok := commandArg(tex, "title"); ok {
+		doc.Title = textclean.OneLine(title)
+	}
+	if abstract, ok := environmentBody(tex, "abstract"); ok {
+		doc.Abstract = strings.TrimSpace(abstract)
+	}
+
+	body := tex
+	if i := strings.Index(body, `\begin{document}`); i >= 0 {
+		body = body[i+len(`\begin{document}`):]
+	}
+	if i := strings.Index(body, `\end{document}`); i >= 0 {
+		body = body[:i]
+	}
+
+	var bibStart int
+	doc.Bibliography, bibStart = parseTheBibliography(body)
+	if bibStart >= 0 {
+		body = body[:bibStart]
+	}
+
+	doc.Sections = parseSections(body)
+	starts := sectionStarts(body)
+	sectionAt := func(offset int) string {
+		i := sort.SearchInts(starts, offset+1) - 1
+		if i < 0 {
+			return ""
+		}
+		if doc.Sections[i].Number != "" {
+			return doc.Sections[i].Number
+		}
+		return doc.Sections[i].Title
+	}
+	doc.Equations = parseEquations(body, sectionAt)
+	doc.Figures = parseFigures(body, sectionAt)
+	return doc
+}
+
+// sectionRe matches section headings up to the brace opening the title,
+// and \appendix.
+var sectionRe = regexp.MustCompile(`\\(?:(section|subsection|subsubsection)(\*?)\s*(?:\[[^\]]*\])?\s*\{|(appendix)\b)`)
+
+var sectionLevels = map[string]int{"section": 1, "subsection": 2, "subsubsection": 3}
+
+func parseSections(body string) []Section {
+	type heading struct {
+		section  Section
+		start    int // where the heading starts
+		textFrom int // where the text after the heading starts
+	}
+	var headings []heading
+	counters := [4]int{}
+	appendix := false
+	for _, m := range sectionRe.FindAllStringSubmatchIndex(body, -1) {
+		if m[6] >= 0 {
+			appendix = true
+			counters = [4]int{}
+			continue
+		}
+		title, end, ok := group(body, m[1]-1)
+		if !ok {
+			continue
+		}
+		level := sectionLevels[body[m[2]:m[3]]]
+		s := Section{Title: textclean.OneLine(title), Level: level}
+		if m[5] == m[4] { // not starred
+			counters[level]++
+			for l := level + 1; l < len(counters); l++ {
+				counters[l] = 0
+			}
+			s.Number = sectionNumber(counters[1:level+1], appendix)
+		}
+		headings = append(headings, heading{section: s, start: m[0], textFrom: end})
+	}
+
+	sections := make([]Section, len(headings))
+	for i, h := range headings {
+		end := len(body)
+		if i+1 < len(headings) {
+			end = headings[i+1].start
+		}
+		text := body[h.textFrom:end]
+		// A section's label is the one given straight after its heading.
+		if rest := strings.TrimSpace(text); strings.HasPrefix(rest, `\label`) {
+			if label, ok := commandArg(rest, "label"); ok {
+				h.section.Label = label
+			}
+		}
+		h.section.Text = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), `\appendix`))
+		sections[i] = h.section
+	}
+	return sections
+}
+
+// sectionStarts returns where each heading parsed by parseSections starts.
+func sectionStarts(body string) []int {
+	var starts []int
+	for _, m := range sectionRe.FindAllStringSubmatchIndex(body, -1) {
+		if m[6] >= 0 {
+			continue
+		}
+		if _, _, ok := group(body, m[1]-1); ok {
+			starts = append(starts, m[0])
+		}
+	}
+	return starts
+}
+
+// sectionNumber formats section counters as LaTeX's article class does, with
+// appendix sections lettered.
+func sectionNumber(counters []int, appendix bool) string {
+	parts := make([]string, len(counters))
+	for i, c := range counters {
+		parts[i] = strconv.Itoa(c)
+	}
+	if appendix && counters[0] > 0 && counters[0] <= 26 {
+		parts[0] = string(rune('A' + counters[0] - 1))
+	}
+	return strings.Join(parts, ".")
+}
+
+// equationRe matches the start of a displayed equation: an equation-like
+// environment, \[ not preceded by a backslash, or $$.
+var equationRe = regexp.MustCompile(`\\begin\{(equation|align|alignat|gather|multline|flalign|eqnarray|displaymath)(\*?)\}|(?:^|[^\\])(\\\[)|(\$\$)`)
+
+func parseEquations(body string, sectionAt func(int) string) []Equation {
+	var equations []Equation
+	for pos := 0; pos < len(body); {
+		m := equationRe.FindStringSubmatchIndex(body[pos:])
+		if m == nil {
+			break
+		}
+		var env, inner string
+		var end int
+		switch {
+		case m[2] >= 0:
+			env = body[pos+m[2]:pos+m[3]] + body[pos+m[4]:pos+m[5]]
+			closing := `\end{` + env + `}`
+			i := strings.Index(body[pos+m[1]:], closing)
+			if i < 0 {
+				return equations
+			}
+			inner = body[pos+m[1] : pos+m[1]+i]
+			end = pos + m[1] + i + len(closing)
+		default:
+			open, closing := `\[`, `\]`
+			start := pos + m[6]
+			if m[8] >= 0 {
+				open, closing, start = "$$", "$$", pos+m[8]
+			}
+			i := strings.Index(body[start+len(open):], closing)
+			if i < 0 {
+				return equations
+			}
+			env = "displaymath"
+			inner = body[start+len(open) : start+len(open)+i]
+			end = start + len(open) + i + len(closing)
+		}
+		equation := Equation{Environment: env, Section: sectionAt(pos + m[0])}
+		if label, ok := commandArg(inner, "label"); ok {
+			equation.Label = label
+		}
+		equation.Body = strings.TrimSpace(removeCommand(inner, "label"))
+		equations = append(equations, equation)
+		pos = end
+	}
+	return equations
+}
+
+var floatRe = regexp.MustCompile(`\\begin\{(figure|table|wrapfigure|wraptable|sidewaysfigure|sidewaystable)(\*?)\}`)
+
+func parseFigures(body string, sectionAt func(int) string) []Figure {
+	var figures []Figure
+	for _, m := range floatRe.FindAllStringSubmatchIndex(body, -1) {
+		env := body[m[2]:m[3]] + body[m[4]:m[5]]
+		closing := `\end{` + env + `}`
+		i := strings.Index(body[m[1]:], closing)
+		if i < 0 {
+			continue
+		}
+		inner := body[m[1] : m[1]+i]
+		figure := Figure{Environment: env, Section: sectionAt(m[0])}
+		if caption, ok := commandArg(inner, "caption"); ok {
+			figure.Caption = textclean.OneLine(removeCommand(caption, "label"))
+		}
+		if label, ok := commandArg(inner, "label"); ok {
+			figure.Label = label
+		}
+		figures = append(figures, figure)
+	}
+	return figures
+}
+
+var bibitemRe = regexp.MustCompile(`\\bibitem\s*(?:\[((?:[^\[\]]|\[[^\]]*\])*)\])?\s*\{`)
+
+// parseTheBibliography parses the items of a thebibliography environment,
+// returning where the environment starts, or -1 if there is none.
+func parseTheBibliography(tex string) ([]BibItem, int) {
+	start := strings.Index(tex, `\begin{thebibliography}`)
+	if start < 0 {
+		return nil, -1
+	}
+	body := tex[start:]
+	if end := strings.Index(body, `\end{thebibliography}`); end >= 0 {
+		body = body[:end]
+	}
+
+	var items []BibItem
+	matches := bibitemRe.FindAllStringSubmatchIndex(body, -1)
+	for i, m := range matches {
+		key, keyEnd, ok := group(body, m[1]-1)
+		if !ok {
+			continue
+		}
+		end := len(body)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		text := strings.ReplaceAll(body[keyEnd:end], `\newblock`, "")
+		items = append(items, BibItem{Key: strings.TrimSpace(key), Text: textclean.OneLine(text)})
+	}
+	return items, start
+}
+
+// externalBibliography reads the bibliography from the .bbl file BibTeX
+// would have written for main, which arXiv asks authors to include, or else
+// from the .bib files named by \bibliography.
+func (a *Archive) externalBibliography(main, tex string) []BibItem {
+	bbl := strings.TrimSuffix(main, path.Ext(main)) + ".bbl"
+	if data, ok := a.Files[bbl]; ok {
+		items, _ := parseTheBibliography(StripComments(string(data)))
+		if len(items) > 0 {
+			return items
+		}
+	}
+
+	names, ok := commandArg(tex, "bibliography")
+	if !ok {
+		return nil
+	}
+	var items []BibItem
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if path.Ext(name) != ".bib" {
+			name += ".bib"
+		}
+		file, ok := a.lookup(name, path.Dir(main))
+		if !ok {
+			continue
+		}
+		items = append(items, ParseBib(string(a.Files[file]))...)
+	}
+	return items
+}
+
+// group returns the contents of the brace group opening at tex[open], and
+// the index just after it closes. Escaped braces do not count.
+func group(tex string, open int) (string, int, bool) {
+	if open < 0 || open >= len(tex) || tex[open] != '{' {
+		return "", 0, false
+	}
+	depth := 0
+	for i := open; i < len(tex); i++ {
+		switch tex[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return tex[open+1 : i], i + 1, true
+			}
+		}
+	}
+	return "", 0, false
+}
+
+// commandArg returns the first mandatory argument of the first use of the
+// command in tex, skipping an optional argument before it.
+func commandArg(tex, command string) (string, bool) {
+	re := regexp.MustCompile(`\\` + command + `\*?\s*(?:\[[^\]]*\])?\s*\{`)
+	for _, m := range re.FindAllStringIndex(tex, -1) {
+		if arg, _, ok := group(tex, m[1]-1); ok {
+			return strings.TrimSpace(arg), true
+		}
+	}
+	return "", false
+}
+
+// removeCommand removes uses of a command with one argument from tex.
+func removeCommand(tex, command string) string {
+	re := regexp.MustCompile(`\\` + command + `\s*\{`)
+	for {
+		m := re.FindStringIndex(tex)
+		if m == nil {
+			return tex
+		}
+		_, end, ok := group(tex, m[1]-1)
+		if !ok {
+			return tex
+		}
+		tex = tex[:m[0]] + tex[end:]
+	}
+}
+
+// environmentBody returns the body of the first environment with the given
+// name.
+func environmentBody(tex, name string) (string, bool) {
+	begin := `\begin{` + name + `}`
+	start := strings.Index(tex, begin)
+	if start < 0 {
+		return "", false
+	}
+	start += len(begin)
+	end := strings.Index(tex[start:], `\end{`+name+`}`)
+	if end < 0 {
+		return "", false
+	}
+	return tex[start : start+end], true
+}
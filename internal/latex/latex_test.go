@@ -0,0 +1,201 @@
+package latex
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testPaper = `\documentclass{article}
+\title{Attention Is
+  All You Need}
+\begin{document}
+\maketitle
+\begin{abstract}
+We propose the Transformer.
+\end{abstract}
+
+\section{Introduction}
+\label{sec:intro}
+Recurrent models \cite{rnn} are slow. Line one\\[2pt] line two.
+
+\section[Short]{Model {Architecture}}
+The encoder maps inputs:
+\begin{equation}
+  z = f(x) \label{eq:encoder}
+\end{equation}
+and attention is
+\[ \mathrm{Attention}(Q, K, V) = \mathrm{softmax}(QK^T) V \]
+
+\subsection{Attention}
+\begin{figure*}[t]
+  \centering
+  \includegraphics{attention.pdf}
+  \caption{Scaled dot-product
+    attention.\label{fig:attention}}
+\end{figure*}
+\begin{align*}
+  a &= b \\
+  c &= d
+\end{align*}
+
+\subsection*{Notes}
+$$ e = mc^2 $$
+
+\appendix
+\section{Proofs}
+\begin{table}
+\caption{Results}
+\label{tab:results}
+\end{table}
+
+\begin{thebibliography}{9}
+\bibitem{rnn} S. Hochreiter and J. Schmidhuber.
+\newblock Long short-term memory.
+\newblock 1997.
+\bibitem[Bahdanau et al.(2014)]{bahdanau}
+D. Bahdanau. Neural machine translation. 2014.
+\end{thebibliography}
+\end{document}
+`
+
+func TestParse(t *testing.T) {
+	doc := Parse(testPaper)
+
+	if doc.Title != "Attention Is All You Need" {
+		t.Errorf("unexpected title %q", doc.Title)
+	}
+	if doc.Abstract != "We propose the Transformer." {
+		t.Errorf("unexpected abstract %q", doc.Abstract)
+	}
+
+	type heading struct {
+		Number, Title string
+		Level         int
+		Label         string
+	}
+	var headings []heading
+	for _, s := range doc.Sections {
+		headings = append(headings, heading{s.Number, s.Title, s.Level, s.Label})
+	}
+	expectedHeadings := []heading{
+		{"1", "Introduction", 1, "sec:intro"},
+		{"2", "Model {Architecture}", 1, ""},
+		{"2.1", "Attention", 2, ""},
+		{"", "Notes", 2, ""},
+		{"A", "Proofs", 1, ""},
+	}
+	if !reflect.DeepEqual(headings, expectedHeadings) {
+		t.Errorf("expected sections %+v, got %+v", expectedHeadings, headings)
+	}
+	if doc.Sections[0].Text != "\\label{sec:intro}\nRecurrent models \\cite{rnn} are slow. Line one\\\\[2pt] line two." {
+		t.Errorf("unexpected introduction text %q", doc.Sections[0].Text)
+	}
+
+	expectedEquations := []Equation{
+		{Environment: "equation", Label: "eq:encoder", Body: "z = f(x)", Section: "2"},
+		{Environment: "displaymath", Body: `\mathrm{Attention}(Q, K, V) = \mathrm{softmax}(QK^T) V`, Section: "2"},
+		{Environment: "align*", Body: "a &= b \\\\\n  c &= d", Section: "2.1"},
+		{Environment: "displaymath", Body: "e = mc^2", Section: "Notes"},
+	}
+	if !reflect.DeepEqual(doc.Equations, expectedEquations) {
+		t.Errorf("expected equations %+v, got %+v", expectedEquations, doc.Equations)
+	}
+
+	expectedFigures := []Figure{
+		{Environment: "figure*", Label: "fig:attention", Caption: "Scaled dot-product attention.", Section: "2.1"},
+		{Environment: "table", Label: "tab:results", Caption: "Results", Section: "A"},
+	}
+	if !reflect.DeepEqual(doc.Figures, expectedFigures) {
+		t.Errorf("expected figures %+v, got %+v", expectedFigures, doc.Figures)
+	}
+
+	expectedBibliography := []BibItem{
+		{Key: "rnn", Text: "S. Hochreiter and J. Schmidhuber. Long short-term memory. 1997."},
+		{Key: "bahdanau", Text: "D. Bahdanau. Neural machine translation. 2014."},
+	}
+	if !reflect.DeepEqual(doc.Bibliography, expectedBibliography) {
+		t.Errorf("expected bibliography %+v, got %+v", expectedBibliography, doc.Bibliography)
+	}
+}
+
+func TestParseArchiveBibliography(t *testing.T) {
+	main := "\\documentclass{article}\n\\begin{document}\n\\section{Intro}\nSee \\cite{vaswani}.\n\\bibliography{refs}\n\\end{document}\n"
+
+	t.Run("bbl", func(t *testing.T) {
+		archive := &Archive{Files: map[string][]byte{
+			"paper.tex": []byte(main),
+			"paper.bbl": []byte("\\begin{thebibliography}{1}\n\\bibitem{vaswani} A. Vaswani. Attention. 2017.\n\\end{thebibliography}\n"),
+			"refs.bib":  []byte("@article{other, title={Other}}"),
+		}}
+		doc, err := archive.Parse("paper.tex")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(doc.Bibliography) != 1 || doc.Bibliography[0].Key != "vaswani" {
+			t.Errorf("expected the .bbl bibliography, got %+v", doc.Bibliography)
+		}
+	})
+
+	t.Run("bib", func(t *testing.T) {
+		archive := &Archive{Files: map[string][]byte{
+			"paper.tex": []byte(main),
+			"refs.bib":  []byte("@article{vaswani, title={Attention Is All You Need}, author={Vaswani, Ashish and Shazeer, Noam}, year=2017}"),
+		}}
+		doc, err := archive.Parse("paper.tex")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(doc.Bibliography) != 1 || doc.Bibliography[0].Fields["title"] != "Attention Is All You Need" {
+			t.Errorf("expected the .bib bibliography, got %+v", doc.Bibliography)
+		}
+	})
+}
+
+func TestParseBib(t *testing.T) {
+	bib := `@string{nips = "NeurIPS"}
+@comment{ignored}
+@inproceedings{vaswani2017,
+  title     = {Attention Is {All} You Need},
+  author    = "Vaswani, Ashish and Shazeer, Noam",
+  booktitle = nips # " 2017",
+  year      = 2017,
+  eprint    = {1706.03762},
+}
+@Article(he2016,
+  title = {Deep Residual Learning},
+  journal = {CVPR}, year = {2016})
+`
+	items := ParseBib(bib)
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items, got %+v", items)
+	}
+
+	expected := map[string]string{
+		"type":      "inproceedings",
+		"title":     "Attention Is {All} You Need",
+		"author":    "Vaswani, Ashish and Shazeer, Noam",
+		"booktitle": "nips 2017",
+		"year":      "2017",
+		"eprint":    "1706.03762",
+	}
+	if items[0].Key != "vaswani2017" || !reflect.DeepEqual(items[0].Fields, expected) {
+		t.Errorf("unexpected first item %+v", items[0])
+	}
+	if items[0].Text != "Vaswani, Ashish, Shazeer, Noam. Attention Is {All} You Need. nips 2017. 2017. arXiv:1706.03762" {
+		t.Errorf("unexpected text %q", items[0].Text)
+	}
+	if items[1].Key != "he2016" || items[1].Fields["type"] != "article" || items[1].Fields["journal"] != "CVPR" {
+		t.Errorf("unexpected second item %+v", items[1])
+	}
+}
+
+func TestGroup(t *testing.T) {
+	tex := `\title{A {nested} \{brace\} title} rest`
+	content, end, ok := group(tex, 6)
+	if !ok || content != `A {nested} \{brace\} title` || tex[end:] != " rest" {
+		t.Errorf("unexpected group %q, %q, %v", content, tex[end:], ok)
+	}
+	if _, _, ok := group(`{unclosed`, 0); ok {
+		t.Error("expected an unclosed group to fail")
+	}
+}
@@ -0,0 +1,127 @@
+package latex
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// maxInputDepth limits how deeply \input files are followed.
+const maxInputDepth = 16
+
+// commentEnvRe matches comment environments, from the comment package.
+var commentEnvRe = regexp.MustCompile(`(?s)\\begin\{comment\}.*?\\end\{comment\}`)
+
+// StripComments removes comments: text from an unescaped % to the end of its
+// line, and comment environments.
+func StripComments(tex string) string {
+	lines := strings.Split(tex, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line[:commentStart(line)], " \t\r")
+	}
+	return commentEnvRe.ReplaceAllString(strings.Join(lines, "\n"), "")
+}
+
+// commentStart returns the index of the % starting a comment in line, or the
+// length of line if it has none. A % is escaped by an odd number of
+// backslashes before it.
+func commentStart(line string) int {
+	for i := 0; i < len(line); i++ {
+		if line[i] != '%' {
+			continue
+		}
+		backslashes := 0
+		for j := i - 1; j >= 0 && line[j] == '\\'; j-- {
+			backslashes++
+		}
+		if backslashes%2 == 0 {
+			return i
+		}
+	}
+	return len(line)
+}
+
+// inputRe matches \input{file}, \include{file} and \subfile{file}, and the
+// plain TeX form \input file.
+var inputRe = regexp.MustCompile(`\\(input|include|subfile)(?:\s*\{([^{}]*)\}|[ \t]+([^\s{}\\%]+))`)
+
+// Resolve returns the text of the named file with comments stripped and the
+// files it inputs or includes put in place, recursively. Inputs of files that
+// are not in the archive, or that would input themselves again, are left as
+// they are. Resolve fails if the text would be larger than MaxUnpackedSize,
+// as it could be for a few files that each input the next many times.
+func (a *Archive) Resolve(name string) (string, error) {
+	return a.resolveUpTo(name, MaxUnpackedSize)
+}
+
+// resolveUpTo is Resolve with a limit of limit bytes on the text.
+func (a *Archive) resolveUpTo(name string, limit int) (string, error) {
+	if _, ok := a.Files[name]; !ok {
+		return "", fmt.Errorf("%s is not in the source", name)
+	}
+	r := resolver{archive: a, main: name, open: map[string]bool{}, remaining: limit}
+	text := r.resolve(name, 0)
+	if r.remaining < 0 {
+		return "", fmt.Errorf("%s is larger than %d MB with the files it inputs put in place", name, limit>>20)
+	}
+	return text, nil
+}
+
+// resolver puts input files in place, counting the bytes of their text
+// against a limit.
+type resolver struct {
+	archive   *Archive
+	main      string // file being resolved, which LaTeX runs in the directory of
+	open      map[string]bool
+	remaining int // bytes left before the limit; negative once it is passed
+}
+
+func (r *resolver) resolve(name string, depth int) string {
+	r.open[name] = true
+	defer delete(r.open, name)
+
+	text := StripComments(string(r.archive.Files[name]))
+	r.remaining -= len(text)
+	if r.remaining < 0 {
+		return ""
+	}
+	return inputRe.ReplaceAllStringFunc(text, func(command string) string {
+		m := inputRe.FindStringSubmatch(command)
+		target := strings.TrimSpace(m[2] + m[3])
+		file, ok := r.archive.lookup(target, path.Dir(name), path.Dir(r.main))
+		if !ok || r.open[file] || depth == maxInputDepth || r.remaining < 0 {
+			return command
+		}
+		included := r.resolve(file, depth+1)
+		if m[1] == "include" {
+			// \include starts a new page, which is all that sets it apart.
+			return "\n" + included + "\n"
+		}
+		return included
+	})
+}
+
+// lookup finds the file that an input of target refers to, with .tex added
+// if target has no extension. It is looked for relative to each of dirs in
+// turn and then to the root of the archive: the including file's directory,
+// as the import and subfiles packages do, and then the main file's, which
+// LaTeX runs in.
+func (a *Archive) lookup(target string, dirs ...string) (string, bool) {
+	target = strings.Trim(target, `"`)
+	var candidates []string
+	for _, base := range append(dirs, ".") {
+		p := path.Join(base, target)
+		candidates = append(candidates, p+".tex", p)
+	}
+	for _, candidate := range candidates {
+		clean, ok := safePath(candidate)
+		if !ok {
+			continue
+		}
+		if _, ok := a.Files[clean]; ok {
+			return clean, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,102 @@
+package latex
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestStripComments(t *testing.T) {
+	tex := "Text % a comment\n50\\% of cases\\\\% comment after a line break\n" +
+		"\\begin{comment}\nhidden\n\\end{comment}\nkept"
+	expected := "Text\n50\\% of cases\\\\\n\nkept"
+	if got := StripComments(tex); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	archive := &Archive{Files: map[string][]byte{
+		"main.tex": []byte("\\documentclass{article}\n\\begin{document}\n" +
+			"\\input{sections/intro}\n" +
+			"% \\input{sections/unused}\n" +
+			"\\include{sections/method.tex}\n" +
+			"\\input macros\n" +
+			"\\input{missing}\n" +
+			"\\includegraphics{fig.png}\n" +
+			"\\end{document}\n"),
+		"sections/intro.tex":  []byte("Intro % comment\n\\input{sections/detail}"),
+		"sections/detail.tex": []byte("Detail."),
+		"sections/method.tex": []byte("Method, see \\input{loop}"),
+		"sections/unused.tex": []byte("Unused."),
+		"macros.tex":          []byte("\\newcommand{\\R}{\\mathbb{R}}"),
+		"loop.tex":            []byte("Loop \\input{loop}"),
+	}}
+
+	tex, err := archive.Resolve("main.tex")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{"Intro\nDetail.", "Method, see Loop \\input{loop}", "\\newcommand{\\R}", "\\input{missing}", "\\includegraphics{fig.png}"} {
+		if !strings.Contains(tex, expected) {
+			t.Errorf("expected %q in resolved text:\n%s", expected, tex)
+		}
+	}
+	if strings.Contains(tex, "Unused") || strings.Contains(tex, "comment") {
+		t.Errorf("expected comments to be stripped:\n%s", tex)
+	}
+
+	if _, err := archive.Resolve("absent.tex"); err == nil {
+		t.Error("expected error for a file not in the source")
+	}
+}
+
+func TestResolveSubdirectory(t *testing.T) {
+	archive := &Archive{Files: map[string][]byte{
+		"paper/main.tex":  []byte("\\input{intro}\\input{../../etc/passwd}"),
+		"paper/intro.tex": []byte("Intro."),
+		"etc/passwd":      []byte("secret"),
+	}}
+	tex, err := archive.Resolve("paper/main.tex")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tex != "Intro.\\input{../../etc/passwd}" {
+		t.Errorf("unexpected resolved text %q", tex)
+	}
+}
+
+func TestResolveRelativeToIncludingFile(t *testing.T) {
+	archive := &Archive{Files: map[string][]byte{
+		"paper/main.tex":            []byte("\\input{sections/intro}"),
+		"paper/sections/intro.tex":  []byte("Intro \\input{table} \\input{sections/detail} \\input{macros}"),
+		"paper/sections/table.tex":  []byte("Table."),
+		"paper/sections/detail.tex": []byte("Detail."),
+		"paper/table.tex":           []byte("Wrong table."),
+		"macros.tex":                []byte("Macros."),
+	}}
+	tex, err := archive.Resolve("paper/main.tex")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "Intro Table. Detail. Macros."; tex != expected {
+		t.Errorf("expected %q, got %q", expected, tex)
+	}
+}
+
+func TestResolveTooLarge(t *testing.T) {
+	// Each file inputs the next twice, so the last is put in place 2^16
+	// times.
+	archive := &Archive{Files: map[string][]byte{}}
+	for i := range maxInputDepth {
+		archive.Files[fmt.Sprintf("f%d.tex", i)] = []byte(fmt.Sprintf("\\input{f%d}\\input{f%d}", i+1, i+1))
+	}
+	archive.Files[fmt.Sprintf("f%d.tex", maxInputDepth)] = []byte(strings.Repeat("x", 100))
+
+	if _, err := archive.resolveUpTo("f0.tex", 1<<20); err == nil {
+		t.Error("expected an error for text larger than the limit")
+	}
+	if _, err := archive.resolveUpTo("f10.tex", 1<<20); err != nil {
+		t.Errorf("unexpected error for text within the limit: %v", err)
+	}
+}
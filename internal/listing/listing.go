@@ -0,0 +1,253 @@
+// Package listing parses arXiv's announcement feeds, the RSS and Atom feeds
+// at rss.arxiv.org that list what each category's daily announcement added.
+//
+// An announcement is made of new submissions to the category, cross-lists
+// of papers submitted to other categories, and replacements of papers
+// announced before, which arXiv tells apart in each item's announce_type.
+// Feeds are empty on days without an announcement, such as weekends.
+package listing
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivid"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/textclean"
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// Type is how an item came to be in an announcement.
+type Type string
+
+const (
+	New                  Type = "new"           // first announcement of a paper submitted to the category
+	CrossList            Type = "cross"         // first announcement of a paper cross-listed to the category
+	Replacement          Type = "replace"       // new version of a paper submitted to the category
+	ReplacementCrossList Type = "replace-cross" // new version of a paper cross-listed to the category
+)
+
+// Item is a paper in an announcement. Its entry has the paper's metadata as
+// the arXiv API would give it, except that Published, the date of the first
+// version, is not known; Updated is the date of the announcement.
+type Item struct {
+	Type  Type
+	Entry arxiv.EntryMetadata
+}
+
+// Feed is an announcement feed.
+type Feed struct {
+	Title string
+	Date  time.Time // date of the announcement
+	Items []Item
+}
+
+// Parse parses an RSS 2.0 or Atom announcement feed.
+func Parse(data []byte) (Feed, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return Feed{}, fmt.Errorf("parsing feed: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "rss":
+			var rss rssFeed
+			if err := decoder.DecodeElement(&rss, &start); err != nil {
+				return Feed{}, fmt.Errorf("parsing RSS feed: %w", err)
+			}
+			return rss.feed()
+		case "feed":
+			var atom atomFeed
+			if err := decoder.DecodeElement(&atom, &start); err != nil {
+				return Feed{}, fmt.Errorf("parsing Atom feed: %w", err)
+			}
+			return atom.feed()
+		default:
+			return Feed{}, fmt.Errorf("parsing feed: unexpected root element <%s>", start.Name.Local)
+		}
+	}
+}
+
+type rssFeed struct {
+	Channel struct {
+		Title   string    `xml:"title"`
+		PubDate string    `xml:"pubDate"`
+		Items   []rssItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type rssItem struct {
+	Title            string   `xml:"title"`
+	Link             string   `xml:"link"`
+	Description      string   `xml:"description"`
+	GUID             string   `xml:"guid"`
+	Categories       []string `xml:"category"`
+	PubDate          string   `xml:"pubDate"`
+	AnnounceType     string   `xml:"announce_type"`
+	Creator          string   `xml:"creator"`
+	DOI              string   `xml:"DOI"`
+	JournalReference string   `xml:"journal_reference"`
+}
+
+func (rss rssFeed) feed() (Feed, error) {
+	feed := Feed{Title: textclean.OneLine(rss.Channel.Title)}
+	if rss.Channel.PubDate != "" {
+		date, err := time.Parse(time.RFC1123Z, strings.TrimSpace(rss.Channel.PubDate))
+		if err != nil {
+			return Feed{}, fmt.Errorf("parsing RSS feed date: %w", err)
+		}
+		feed.Date = date
+	}
+	for _, item := range rss.Channel.Items {
+		date := feed.Date
+		if item.PubDate != "" {
+			if d, err := time.Parse(time.RFC1123Z, strings.TrimSpace(item.PubDate)); err == nil {
+				date = d
+			}
+		}
+		parsed, err := newItem(rawItem{
+			guid:             item.GUID,
+			title:            item.Title,
+			description:      item.Description,
+			categories:       item.Categories,
+			date:             date,
+			announceType:     item.AnnounceType,
+			authors:          textclean.SplitAuthors(item.Creator),
+			doi:              item.DOI,
+			journalReference: item.JournalReference,
+		})
+		if err != nil {
+			return Feed{}, err
+		}
+		feed.Items = append(feed.Items, parsed)
+	}
+	return feed, nil
+}
+
+type atomFeed struct {
+	Title   string      `xml:"title"`
+	Updated time.Time   `xml:"updated"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID         string `xml:"id"`
+	Title      string `xml:"title"`
+	Summary    string `xml:"summary"`
+	Categories []struct {
+		Term string `xml:"term,attr"`
+	} `xml:"category"`
+	Updated   time.Time `xml:"updated"`
+	Published time.Time `xml:"published"`
+	Authors   []struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+	Creator          string `xml:"creator"`
+	AnnounceType     string `xml:"announce_type"`
+	DOI              string `xml:"DOI"`
+	JournalReference string `xml:"journal_reference"`
+}
+
+func (atom atomFeed) feed() (Feed, error) {
+	feed := Feed{Title: textclean.OneLine(atom.Title), Date: atom.Updated}
+	for _, entry := range atom.Entries {
+		raw := rawItem{
+			guid:             entry.ID,
+			title:            entry.Title,
+			description:      entry.Summary,
+			date:             entry.Published,
+			announceType:     entry.AnnounceType,
+			doi:              entry.DOI,
+			journalReference: entry.JournalReference,
+		}
+		if raw.date.IsZero() {
+			raw.date = entry.Updated
+		}
+		for _, category := range entry.Categories {
+			raw.categories = append(raw.categories, category.Term)
+		}
+		// Authors come either as one author element per name or, as in the
+		// RSS feed, all in one.
+		for _, author := range entry.Authors {
+			raw.authors = append(raw.authors, textclean.SplitAuthors(author.Name)...)
+		}
+		if len(raw.authors) == 0 {
+			raw.authors = textclean.SplitAuthors(entry.Creator)
+		}
+		parsed, err := newItem(raw)
+		if err != nil {
+			return Feed{}, err
+		}
+		feed.Items = append(feed.Items, parsed)
+	}
+	return feed, nil
+}
+
+// rawItem is an item as either feed gives it.
+type rawItem struct {
+	guid, title, description string
+	categories               []string
+	date                     time.Time
+	announceType             string
+	authors                  []string
+	doi, journalReference    string
+}
+
+// descriptionRe matches the start of an item's description, which gives the
+// versioned ID and announce type before the abstract.
+var descriptionRe = regexp.MustCompile(`^\s*arXiv:(\S+)\s+Announce Type:\s*(\S+)\s*(?:Abstract:\s*)?`)
+
+func newItem(raw rawItem) (Item, error) {
+	abstract := raw.description
+	announceType := strings.TrimSpace(raw.announceType)
+	rawID := strings.TrimPrefix(strings.TrimSpace(raw.guid), "oai:arXiv.org:")
+	if m := descriptionRe.FindStringSubmatch(abstract); m != nil {
+		abstract = abstract[len(m[0]):]
+		if announceType == "" {
+			announceType = m[2]
+		}
+		if rawID == "" {
+			rawID = m[1]
+		}
+	}
+	id, err := arxivid.Parse(rawID)
+	if err != nil {
+		return Item{}, fmt.Errorf("parsing feed item %q: %w", textclean.OneLine(raw.title), err)
+	}
+
+	entry := arxiv.EntryMetadata{
+		ID:               "http://arxiv.org/abs/" + id.String(),
+		Title:            textclean.OneLine(raw.title),
+		Updated:          raw.date,
+		Summary:          textclean.OneLine(abstract),
+		DOI:              strings.TrimSpace(raw.doi),
+		JournalReference: textclean.OneLine(raw.journalReference),
+		AbstractUrl:      "https://arxiv.org/abs/" + id.String(),
+		PDFUrl:           "https://arxiv.org/pdf/" + id.String(),
+	}
+	entry.Links = []arxiv.Link{
+		{Href: entry.AbstractUrl, Rel: "alternate", Type: "text/html"},
+		{Href: entry.PDFUrl, Rel: "related", Type: "application/pdf", Title: "pdf"},
+	}
+	for _, name := range raw.authors {
+		entry.Authors = append(entry.Authors, arxiv.Author{Name: name})
+	}
+	for _, term := range raw.categories {
+		if term = strings.TrimSpace(term); term != "" {
+			entry.Categories = append(entry.Categories, arxiv.Category{Term: term})
+		}
+	}
+	// The primary category is listed first.
+	if len(entry.Categories) > 0 {
+		entry.PrimaryCategory = entry.Categories[0]
+	}
+	return Item{Type: Type(announceType), Entry: entry}, nil
+}
@@ -0,0 +1,183 @@
+package listing
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const rssSample = `<?xml version='1.0' encoding='UTF-8'?>
+<rss xmlns:arxiv="http://arxiv.org/schemas/atom" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:atom="http://www.w3.org/2005/Atom" xmlns:content="http://purl.org/rss/1.0/modules/content/" version="2.0">
+  <channel>
+    <title>cs.CL updates on arXiv.org</title>
+    <link>http://rss.arxiv.org/rss/cs.CL</link>
+    <description>cs.CL updates on the arXiv.org e-print archive.</description>
+    <atom:link href="http://rss.arxiv.org/rss/cs.CL" rel="self" type="application/rss+xml"/>
+    <lastBuildDate>Mon, 15 Apr 2024 00:00:00 -0400</lastBuildDate>
+    <pubDate>Mon, 15 Apr 2024 00:00:00 -0400</pubDate>
+    <skipDays>
+      <day>Saturday</day>
+      <day>Sunday</day>
+    </skipDays>
+    <item>
+      <title>Small Models Are Also
+ Few-Shot Learners</title>
+      <link>https://arxiv.org/abs/2404.08001</link>
+      <description>arXiv:2404.08001v1 Announce Type: new 
+Abstract: We show that small models learn from few examples.</description>
+      <guid isPermaLink="false">oai:arXiv.org:2404.08001v1</guid>
+      <category>cs.CL</category>
+      <category>cs.LG</category>
+      <pubDate>Mon, 15 Apr 2024 00:00:00 -0400</pubDate>
+      <arxiv:announce_type>new</arxiv:announce_type>
+      <dc:rights>http://creativecommons.org/licenses/by/4.0/</dc:rights>
+      <dc:creator>Ada Lovelace, Charles Babbage and Mary Somerville</dc:creator>
+    </item>
+    <item>
+      <title>Translation by Attention</title>
+      <link>https://arxiv.org/abs/2310.00002</link>
+      <description>arXiv:2310.00002v3 Announce Type: replace-cross 
+Abstract: Attention translates.</description>
+      <guid isPermaLink="false">oai:arXiv.org:2310.00002v3</guid>
+      <category>cs.LG</category>
+      <category>cs.CL</category>
+      <pubDate>Mon, 15 Apr 2024 00:00:00 -0400</pubDate>
+      <arxiv:announce_type>replace-cross</arxiv:announce_type>
+      <dc:creator>Alan Turing</dc:creator>
+      <arxiv:journal_reference>J. Mach. Transl. 1 (2024) 1-10</arxiv:journal_reference>
+      <arxiv:DOI>10.1000/jmt.2024.1</arxiv:DOI>
+    </item>
+  </channel>
+</rss>
+`
+
+const atomSample = `<?xml version='1.0' encoding='UTF-8'?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:arxiv="http://arxiv.org/schemas/atom" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <id>http://rss.arxiv.org/atom/cs.CL</id>
+  <title>cs.CL updates on arXiv.org</title>
+  <updated>2024-04-15T04:00:00Z</updated>
+  <link href="http://rss.arxiv.org/atom/cs.CL" rel="self" type="application/atom+xml"/>
+  <subtitle>cs.CL updates on the arXiv.org e-print archive.</subtitle>
+  <entry>
+    <id>oai:arXiv.org:2404.08001v1</id>
+    <title>Small Models Are Also Few-Shot Learners</title>
+    <updated>2024-04-15T04:00:00.000Z</updated>
+    <link href="https://arxiv.org/abs/2404.08001" rel="alternate" type="text/html"/>
+    <summary>arXiv:2404.08001v1 Announce Type: new 
+Abstract: We show that small models learn from few examples.</summary>
+    <category term="cs.CL" scheme="http://arxiv.org/schemas/atom"/>
+    <category term="cs.LG" scheme="http://arxiv.org/schemas/atom"/>
+    <published>2024-04-15T00:00:00-04:00</published>
+    <arxiv:announce_type>new</arxiv:announce_type>
+    <rights>http://creativecommons.org/licenses/by/4.0/</rights>
+    <dc:creator>Ada Lovelace, Charles Babbage, Mary Somerville</dc:creator>
+  </entry>
+  <entry>
+    <id>oai:arXiv.org:hep-th/9711200v4</id>
+    <title>The Large N Limit</title>
+    <updated>2024-04-15T04:00:00.000Z</updated>
+    <summary>arXiv:hep-th/9711200v4 Announce Type: cross 
+Abstract: We show that the large N limit is a string theory.</summary>
+    <category term="hep-th" scheme="http://arxiv.org/schemas/atom"/>
+    <published>2024-04-15T00:00:00-04:00</published>
+    <arxiv:announce_type>cross</arxiv:announce_type>
+    <author><name>Juan Maldacena</name></author>
+  </entry>
+</feed>
+`
+
+func TestParse(t *testing.T) {
+	date := time.Date(2024, 4, 15, 4, 0, 0, 0, time.UTC)
+
+	t.Run("rss", func(t *testing.T) {
+		feed, err := Parse([]byte(rssSample))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if feed.Title != "cs.CL updates on arXiv.org" || !feed.Date.Equal(date) || len(feed.Items) != 2 {
+			t.Fatalf("unexpected feed %q of %v with %d items", feed.Title, feed.Date, len(feed.Items))
+		}
+
+		first := feed.Items[0]
+		if first.Type != New || first.Entry.ID != "http://arxiv.org/abs/2404.08001v1" || first.Entry.Title != "Small Models Are Also Few-Shot Learners" {
+			t.Errorf("unexpected first item %+v", first)
+		}
+		if first.Entry.Summary != "We show that small models learn from few examples." {
+			t.Errorf("expected the abstract without its preamble, got %q", first.Entry.Summary)
+		}
+		var authors []string
+		for _, author := range first.Entry.Authors {
+			authors = append(authors, author.Name)
+		}
+		if !reflect.DeepEqual(authors, []string{"Ada Lovelace", "Charles Babbage", "Mary Somerville"}) {
+			t.Errorf("unexpected authors %v", authors)
+		}
+		if first.Entry.PrimaryCategory.Term != "cs.CL" || len(first.Entry.Categories) != 2 {
+			t.Errorf("unexpected categories %+v", first.Entry.Categories)
+		}
+		if !first.Entry.Updated.Equal(date) || !first.Entry.Published.IsZero() {
+			t.Errorf("expected the announcement date as Updated only, got %v and %v", first.Entry.Updated, first.Entry.Published)
+		}
+		if first.Entry.PDFUrl != "https://arxiv.org/pdf/2404.08001v1" {
+			t.Errorf("unexpected PDF URL %q", first.Entry.PDFUrl)
+		}
+
+		second := feed.Items[1]
+		if second.Type != ReplacementCrossList || second.Entry.PrimaryCategory.Term != "cs.LG" {
+			t.Errorf("expected a replacement cross-listed from cs.LG, got %+v", second)
+		}
+		if second.Entry.DOI != "10.1000/jmt.2024.1" || second.Entry.JournalReference != "J. Mach. Transl. 1 (2024) 1-10" {
+			t.Errorf("expected the DOI and journal reference, got %+v", second.Entry)
+		}
+	})
+
+	t.Run("atom", func(t *testing.T) {
+		feed, err := Parse([]byte(atomSample))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !feed.Date.Equal(date) || len(feed.Items) != 2 {
+			t.Fatalf("unexpected feed of %v with %d items", feed.Date, len(feed.Items))
+		}
+		first := feed.Items[0]
+		if first.Type != New || first.Entry.ID != "http://arxiv.org/abs/2404.08001v1" || len(first.Entry.Authors) != 3 {
+			t.Errorf("unexpected first item %+v", first)
+		}
+		if first.Entry.Summary != "We show that small models learn from few examples." || !first.Entry.Updated.Equal(date) {
+			t.Errorf("unexpected abstract %q or date %v", first.Entry.Summary, first.Entry.Updated)
+		}
+		second := feed.Items[1]
+		if second.Type != CrossList || second.Entry.ID != "http://arxiv.org/abs/hep-th/9711200v4" {
+			t.Errorf("unexpected second item %+v", second)
+		}
+		if len(second.Entry.Authors) != 1 || second.Entry.Authors[0].Name != "Juan Maldacena" {
+			t.Errorf("expected authors from author elements, got %+v", second.Entry.Authors)
+		}
+	})
+
+	t.Run("no announcement", func(t *testing.T) {
+		feed, err := Parse([]byte(`<rss version="2.0"><channel><title>math.AG updates on arXiv.org</title><pubDate>Sat, 13 Apr 2024 00:00:00 -0400</pubDate></channel></rss>`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(feed.Items) != 0 || feed.Date.IsZero() {
+			t.Errorf("expected a dated feed without items, got %+v", feed)
+		}
+	})
+
+	errorTests := []struct {
+		name string
+		data string
+	}{
+		{name: "not xml", data: "not a feed"},
+		{name: "html", data: "<html><body>Not Found</body></html>"},
+		{name: "item without id", data: `<feed xmlns="http://www.w3.org/2005/Atom"><entry><id>unknown</id><title>t</title></entry></feed>`},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.data)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,413 @@
+// Package localsearch answers arXiv API searches from paper metadata kept
+// locally, for when arXiv is slow or unreachable.
+//
+// An Index holds the latest version of each paper it is given, with an
+// inverted index of the words of their titles, abstracts, authors, comments
+// and journal references, and of their categories. A search_query is parsed
+// with arxivquery; the inverted index narrows it to the papers that could
+// match, and each of those is then matched against the query itself, so that
+// fields mean what they do in searches served by the fake arXiv server.
+package localsearch
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivclient"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivid"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivquery"
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// defaultMaxResults is the number of results the arXiv API returns when
+// max_results is not given.
+const defaultMaxResults = 10
+
+// textFields are the fields whose words are indexed.
+var textFields = []string{"ti", "abs", "au", "co", "jr"}
+
+type doc struct {
+	id    arxivid.ID
+	entry arxiv.EntryMetadata
+	query arxivquery.Document
+}
+
+// Index is an in-memory index of paper metadata. It is safe for concurrent
+// use.
+type Index struct {
+	mu       sync.RWMutex
+	docs     []doc
+	slots    map[string]int              // base ID to index in docs
+	postings map[string]map[string][]int // field to word to ascending indexes in docs
+}
+
+// NewIndex returns an empty index.
+func NewIndex() *Index {
+	return &Index{
+		slots:    make(map[string]int),
+		postings: make(map[string]map[string][]int),
+	}
+}
+
+// Len returns the number of papers in the index.
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+// DocumentFrequency returns the number of papers in the index with word in
+// their title or abstract, and the number of papers in the index, for
+// weighing words by how rare they are.
+func (ix *Index) DocumentFrequency(word string) (df, papers int) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(union(ix.postings["ti"][word], ix.postings["abs"][word])), len(ix.docs)
+}
+
+// Add adds entries to the index and returns those that were new to it. An
+// entry replaces the paper's earlier one if it was updated after it, or if it
+// was updated at the same time but has another ID, as an API entry with a
+// version does a harvested record without one. Entries without an arXiv ID
+// are ignored.
+func (ix *Index) Add(entries ...arxiv.EntryMetadata) []arxiv.EntryMetadata {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	var added []arxiv.EntryMetadata
+	for _, entry := range entries {
+		id, err := arxivid.Parse(entry.ID)
+		if err != nil {
+			continue
+		}
+		d := doc{id: id, entry: entry, query: document(entry)}
+		slot, ok := ix.slots[id.Base]
+		if ok {
+			if !replaces(entry, ix.docs[slot].entry) {
+				continue
+			}
+			ix.unpost(slot, ix.docs[slot].query)
+			ix.docs[slot] = d
+		} else {
+			slot = len(ix.docs)
+			ix.slots[id.Base] = slot
+			ix.docs = append(ix.docs, d)
+		}
+		ix.post(slot, d.query)
+		added = append(added, entry)
+	}
+	return added
+}
+
+// replaces reports whether entry replaces old, an entry for the same paper.
+func replaces(entry, old arxiv.EntryMetadata) bool {
+	return entry.Updated.After(old.Updated) || entry.Updated.Equal(old.Updated) && entry.ID != old.ID
+}
+
+func (ix *Index) post(slot int, q arxivquery.Document) {
+	forEachPosting(q, func(field, word string) { ix.addPosting(field, word, slot) })
+}
+
+// unpost removes the postings of the paper in slot made from q, before it is
+// replaced.
+func (ix *Index) unpost(slot int, q arxivquery.Document) {
+	forEachPosting(q, func(field, word string) { ix.removePosting(field, word, slot) })
+}
+
+// forEachPosting calls f with the field and word of every posting of q.
+func forEachPosting(q arxivquery.Document, f func(field, word string)) {
+	texts := map[string][]string{
+		"ti":  {q.Title},
+		"abs": {q.Abstract},
+		"au":  q.Authors,
+		"co":  {q.Comment},
+		"jr":  {q.JournalRef},
+	}
+	for field, values := range texts {
+		for _, value := range values {
+			for _, word := range arxivquery.Words(value) {
+				f(field, word)
+			}
+		}
+	}
+	for _, category := range q.Categories {
+		f("cat", strings.ToLower(category))
+	}
+}
+
+func (ix *Index) addPosting(field, word string, slot int) {
+	words := ix.postings[field]
+	if words == nil {
+		words = make(map[string][]int)
+		ix.postings[field] = words
+	}
+	list := words[word]
+	i := sort.SearchInts(list, slot)
+	if i < len(list) && list[i] == slot {
+		return
+	}
+	words[word] = append(list[:i], append([]int{slot}, list[i:]...)...)
+}
+
+func (ix *Index) removePosting(field, word string, slot int) {
+	words := ix.postings[field]
+	list := words[word]
+	i := sort.SearchInts(list, slot)
+	if i == len(list) || list[i] != slot {
+		return
+	}
+	if len(list) == 1 {
+		delete(words, word)
+		return
+	}
+	words[word] = append(list[:i], list[i+1:]...)
+}
+
+// document returns the searchable part of entry.
+func document(entry arxiv.EntryMetadata) arxivquery.Document {
+	q := arxivquery.Document{
+		ID:         entry.ID,
+		Title:      entry.Title,
+		Abstract:   entry.Summary,
+		Comment:    entry.Comment,
+		JournalRef: entry.JournalReference,
+		Submitted:  entry.Published,
+		Updated:    entry.Updated,
+	}
+	for _, author := range entry.Authors {
+		q.Authors = append(q.Authors, author.Name)
+	}
+	for _, category := range entry.Categories {
+		q.Categories = append(q.Categories, category.Term)
+	}
+	return q
+}
+
+// Search answers params as the arXiv API would, from the papers in the index.
+// Results sorted by relevance are ordered by last update, newest first, since
+// the index does not score matches. Like arxivclient.Client, it returns a
+// BadQueryError for parameters arXiv would reject and a NotFoundError when
+// none of the papers in an ID lookup are in the index.
+func (ix *Index) Search(ctx context.Context, params arxiv.SearchParams) (arxiv.SearchResults, error) {
+	if err := params.Validate(); err != nil {
+		return arxiv.SearchResults{}, &arxivclient.BadQueryError{Message: err.Error()}
+	}
+	var node arxivquery.Node
+	if params.Query != "" {
+		var err error
+		node, err = arxivquery.Parse(params.Query)
+		if err != nil {
+			return arxiv.SearchResults{}, &arxivclient.BadQueryError{Message: "malformed search_query: " + err.Error()}
+		}
+	}
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	var matched []doc
+	switch {
+	case len(params.IdList) > 0:
+		for _, raw := range params.IdList {
+			id, err := arxivid.Parse(raw)
+			if err != nil {
+				return arxiv.SearchResults{}, &arxivclient.BadQueryError{Message: "incorrect id format for " + raw}
+			}
+			if slot, ok := ix.slots[id.Base]; ok && id.Matches(ix.docs[slot].id) {
+				if d := ix.docs[slot]; node == nil || node.Match(d.query) {
+					matched = append(matched, d)
+				}
+			}
+		}
+		if node == nil && len(matched) == 0 {
+			return arxiv.SearchResults{}, &arxivclient.NotFoundError{IDs: params.IdList}
+		}
+	case node != nil:
+		slots, all := ix.candidates(node)
+		if all {
+			slots = make([]int, len(ix.docs))
+			for i := range slots {
+				slots[i] = i
+			}
+		}
+		for _, slot := range slots {
+			if d := ix.docs[slot]; node.Match(d.query) {
+				matched = append(matched, d)
+			}
+		}
+		if params.SortBy == "" || params.SortBy == arxiv.SortByRelevance {
+			sort.SliceStable(matched, func(i, j int) bool {
+				return matched[i].entry.Updated.After(matched[j].entry.Updated)
+			})
+		}
+	default:
+		return arxiv.SearchResults{}, &arxivclient.BadQueryError{Message: "either search_query or id_list must be given"}
+	}
+	sortDocs(matched, params.SortBy, params.SortOrder)
+
+	maxResults := params.MaxResults
+	if maxResults == 0 {
+		maxResults = defaultMaxResults
+	}
+	page := matched[min(params.Start, len(matched)):min(params.Start+maxResults, len(matched))]
+	results := arxiv.SearchResults{
+		TotalResults: len(matched),
+		StartIndex:   params.Start,
+		ItemsPerPage: len(page),
+		Entries:      make([]arxiv.EntryMetadata, len(page)),
+		Params:       params,
+	}
+	for i, d := range page {
+		results.Entries[i] = d.entry
+	}
+	return results, nil
+}
+
+// sortDocs sorts docs by date, if asked to.
+func sortDocs(docs []doc, sortBy arxiv.SortBy, sortOrder arxiv.SortOrder) {
+	var date func(d doc) time.Time
+	switch sortBy {
+	case arxiv.SortBySubmittedDate:
+		date = func(d doc) time.Time { return d.entry.Published }
+	case arxiv.SortByLastUpdatedDate:
+		date = func(d doc) time.Time { return d.entry.Updated }
+	default:
+		return
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		if sortOrder == arxiv.SortOrderAscending {
+			return date(docs[i]).Before(date(docs[j]))
+		}
+		return date(docs[i]).After(date(docs[j]))
+	})
+}
+
+// candidates returns the ascending indexes of the papers that could match
+// node, or all=true if any paper could.
+func (ix *Index) candidates(node arxivquery.Node) (slots []int, all bool) {
+	switch n := node.(type) {
+	case arxivquery.Binary:
+		left, leftAll := ix.candidates(n.Left)
+		if n.Op == arxivquery.OpAndNot {
+			// The right side only removes papers, and its candidates may
+			// include papers it does not match.
+			return left, leftAll
+		}
+		right, rightAll := ix.candidates(n.Right)
+		if n.Op == arxivquery.OpOr {
+			if leftAll || rightAll {
+				return nil, true
+			}
+			return union(left, right), false
+		}
+		switch {
+		case leftAll:
+			return right, rightAll
+		case rightAll:
+			return left, false
+		}
+		return intersect(left, right), false
+	case arxivquery.Term:
+		return ix.termCandidates(n), false
+	}
+	// Date ranges are matched against every paper.
+	return nil, true
+}
+
+func (ix *Index) termCandidates(t arxivquery.Term) []int {
+	switch t.Field {
+	case "id":
+		id, err := arxivid.Parse(t.Value)
+		if err != nil {
+			return nil
+		}
+		if slot, ok := ix.slots[id.Base]; ok {
+			return []int{slot}
+		}
+		return nil
+	case "cat":
+		return ix.lookup("cat", strings.ToLower(t.Value))
+	case "all":
+		slots := ix.lookup("cat", strings.ToLower(t.Value))
+		for _, field := range textFields {
+			slots = union(slots, ix.wordCandidates(field, t.Value))
+		}
+		return slots
+	}
+	return ix.wordCandidates(t.Field, t.Value)
+}
+
+// wordCandidates returns the papers with every word of value in field.
+func (ix *Index) wordCandidates(field, value string) []int {
+	words := arxivquery.Words(value)
+	if len(words) == 0 {
+		return nil
+	}
+	slots := ix.lookup(field, words[0])
+	for _, word := range words[1:] {
+		if len(slots) == 0 {
+			break
+		}
+		slots = intersect(slots, ix.lookup(field, word))
+	}
+	return slots
+}
+
+// lookup returns the postings of word in field. A word ending in * matches
+// every word with that prefix.
+func (ix *Index) lookup(field, word string) []int {
+	words := ix.postings[field]
+	prefix, ok := strings.CutSuffix(word, "*")
+	if !ok {
+		return words[word]
+	}
+	var slots []int
+	for w, list := range words {
+		if strings.HasPrefix(w, prefix) {
+			slots = union(slots, list)
+		}
+	}
+	return slots
+}
+
+// union returns the ascending slots in a or b.
+func union(a, b []int) []int {
+	out := make([]int, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			out = append(out, a[i])
+			i++
+		case a[i] > b[j]:
+			out = append(out, b[j])
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	out = append(out, a[i:]...)
+	return append(out, b[j:]...)
+}
+
+// intersect returns the ascending slots in both a and b.
+func intersect(a, b []int) []int {
+	var out []int
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
@@ -0,0 +1,231 @@
+package localsearch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivclient"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/fakearxiv"
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// fixtureEntries returns every fixture entry, each version of a paper in
+// order.
+func fixtureEntries(t *testing.T) []arxiv.EntryMetadata {
+	t.Helper()
+	fsys := fakearxiv.Fixtures()
+	names, err := fs.Glob(fsys, "*.xml")
+	if err != nil {
+		t.Fatalf("listing fixtures: %v", err)
+	}
+	var entries []arxiv.EntryMetadata
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			t.Fatalf("reading %s: %v", name, err)
+		}
+		entry, err := arxiv.ParseSingleEntry(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("parsing %s: %v", name, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func newTestClient(t *testing.T) *arxivclient.Client {
+	t.Helper()
+	fake, err := fakearxiv.Load(fakearxiv.Fixtures())
+	if err != nil {
+		t.Fatalf("loading fixtures: %v", err)
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return arxivclient.New(arxivclient.Config{BaseURL: server.URL})
+}
+
+func entryIDs(entries []arxiv.EntryMetadata) []string {
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+	}
+	return ids
+}
+
+// TestSearchMatchesFake checks that the index finds the papers the fake
+// arXiv server does, which matches every paper against the query.
+func TestSearchMatchesFake(t *testing.T) {
+	ix := NewIndex()
+	ix.Add(fixtureEntries(t)...)
+	client := newTestClient(t)
+
+	queries := []string{
+		"ti:learning",
+		`ti:"attention is all you need"`,
+		"ti:attent*",
+		"au:Vaswani",
+		`au:"Ashish Vaswani"`,
+		"abs:quantum",
+		"abs:quantum AND abs:entanglement",
+		"cat:cs.LG",
+		"cat:cs.*",
+		"cat:cs.LG OR cat:quant-ph",
+		"cat:cs.LG ANDNOT ti:diffusion",
+		"all:transformer",
+		"all:cs.CL",
+		"co:conference",
+		"jr:Phys",
+		"id:1706.03762",
+		"(ti:learning OR abs:learning) AND cat:cs.*",
+		"cat:cs.LG AND submittedDate:[201501010000 TO 202012312359]",
+		"lastUpdatedDate:[202301010000 TO 202412312359]",
+		"ANDNOT ti:learning",
+		"ti:nonexistentword",
+	}
+	for _, query := range queries {
+		for _, sortBy := range []arxiv.SortBy{arxiv.SortBySubmittedDate, arxiv.SortByLastUpdatedDate} {
+			params := arxiv.SearchParams{Query: query, MaxResults: 100, SortBy: sortBy, SortOrder: arxiv.SortOrderDescending}
+			expected, err := client.Search(context.Background(), params)
+			if err != nil {
+				if _, got := ix.Search(context.Background(), params); got == nil {
+					t.Errorf("%s: expected an error as the fake gave %v", query, err)
+				}
+				continue
+			}
+			results, err := ix.Search(context.Background(), params)
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", query, err)
+				continue
+			}
+			if results.TotalResults != expected.TotalResults || !reflect.DeepEqual(entryIDs(results.Entries), entryIDs(expected.Entries)) {
+				t.Errorf("%s by %s: expected %v, got %v", query, sortBy, entryIDs(expected.Entries), entryIDs(results.Entries))
+			}
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	ix := NewIndex()
+	ix.Add(fixtureEntries(t)...)
+
+	t.Run("keeps the latest version", func(t *testing.T) {
+		if ix.Len() != 11 {
+			t.Errorf("expected 11 papers, got %d", ix.Len())
+		}
+		results, err := ix.Search(ctx, arxiv.SearchParams{IdList: []string{"1706.03762"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(results.Entries) != 1 || results.Entries[0].ID != "http://arxiv.org/abs/1706.03762v7" {
+			t.Errorf("expected 1706.03762v7, got %v", entryIDs(results.Entries))
+		}
+		ix.Add(fixtureEntries(t)[3]) // 1706.03762v1
+		if results, _ := ix.Search(ctx, arxiv.SearchParams{IdList: []string{"1706.03762v7"}}); len(results.Entries) != 1 {
+			t.Errorf("expected an earlier version not to replace v7, got %v", entryIDs(results.Entries))
+		}
+	})
+
+	t.Run("id list in order", func(t *testing.T) {
+		results, err := ix.Search(ctx, arxiv.SearchParams{IdList: []string{"1810.04805", "hep-th/9711200", "2401.99999"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := []string{"http://arxiv.org/abs/1810.04805v2", "http://arxiv.org/abs/hep-th/9711200v3"}
+		if !reflect.DeepEqual(entryIDs(results.Entries), expected) {
+			t.Errorf("expected %v, got %v", expected, entryIDs(results.Entries))
+		}
+	})
+
+	t.Run("relevance is newest first", func(t *testing.T) {
+		results, err := ix.Search(ctx, arxiv.SearchParams{Query: "cat:cs.LG", MaxResults: 2, Start: 1})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// 2312.00752 and 1706.03762 were updated most recently, then
+		// 2006.11239.
+		expected := []string{"http://arxiv.org/abs/1706.03762v7", "http://arxiv.org/abs/2006.11239v2"}
+		if !reflect.DeepEqual(entryIDs(results.Entries), expected) {
+			t.Errorf("expected %v, got %v", expected, entryIDs(results.Entries))
+		}
+		if results.TotalResults != 4 || results.StartIndex != 1 || results.ItemsPerPage != 2 {
+			t.Errorf("unexpected paging %d, %d, %d", results.TotalResults, results.StartIndex, results.ItemsPerPage)
+		}
+	})
+
+	errorTests := []struct {
+		name   string
+		params arxiv.SearchParams
+		check  func(error) bool
+	}{
+		{
+			name:   "unknown paper",
+			params: arxiv.SearchParams{IdList: []string{"2401.99999"}},
+			check:  func(err error) bool { var e *arxivclient.NotFoundError; return errors.As(err, &e) },
+		},
+		{
+			name:   "malformed id",
+			params: arxiv.SearchParams{IdList: []string{"not-an-id"}},
+			check:  func(err error) bool { var e *arxivclient.BadQueryError; return errors.As(err, &e) },
+		},
+		{
+			name:   "malformed query",
+			params: arxiv.SearchParams{Query: "ti:(learning"},
+			check:  func(err error) bool { var e *arxivclient.BadQueryError; return errors.As(err, &e) },
+		},
+		{
+			name:   "no query",
+			params: arxiv.SearchParams{},
+			check:  func(err error) bool { var e *arxivclient.BadQueryError; return errors.As(err, &e) },
+		},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ix.Search(ctx, tt.params); !tt.check(err) {
+				t.Errorf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func TestAddReplacesWords(t *testing.T) {
+	ix := NewIndex()
+	updated := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ix.Add(arxiv.EntryMetadata{ID: "http://arxiv.org/abs/2401.00001v1", Title: "Old title", Updated: updated})
+	ix.Add(arxiv.EntryMetadata{ID: "http://arxiv.org/abs/2401.00001v2", Title: "New title", Updated: updated.AddDate(0, 1, 0)})
+
+	for query, expected := range map[string]int{"ti:old": 0, "ti:new": 1, "ti:title": 1} {
+		results, err := ix.Search(context.Background(), arxiv.SearchParams{Query: query})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if results.TotalResults != expected {
+			t.Errorf("%s: expected %d results, got %d", query, expected, results.TotalResults)
+		}
+	}
+	if df, _ := ix.DocumentFrequency("old"); df != 0 {
+		t.Errorf("expected no papers with the replaced title's words, got %d", df)
+	}
+}
+
+func TestDocumentFrequency(t *testing.T) {
+	ix := NewIndex()
+	ix.Add(fixtureEntries(t)...)
+	tests := map[string]int{
+		"attention": 2, // 1706.03762 and 2312.00752
+		"quantum":   2, // 1801.00862 and quant-ph/9508027
+		"vaswani":   0, // authors are not counted
+		"unheardof": 0,
+	}
+	for word, expected := range tests {
+		if df, papers := ix.DocumentFrequency(word); df != expected || papers != 11 {
+			t.Errorf("%s: expected %d of 11 papers, got %d of %d", word, expected, df, papers)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package localsearch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivid"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/store"
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// Remote runs arXiv API searches.
+type Remote interface {
+	Search(ctx context.Context, params arxiv.SearchParams) (arxiv.SearchResults, error)
+}
+
+// cachedRemote is a Remote that can report whether results came from a
+// cache, as cache.Cache does.
+type cachedRemote interface {
+	SearchCached(ctx context.Context, params arxiv.SearchParams) (arxiv.SearchResults, bool, error)
+}
+
+// Searcher searches arXiv through a Remote, adding the papers in every
+// response to an index, and answers searches from the index when asked.
+type Searcher struct {
+	remote Remote
+	index  *Index
+	loaded chan struct{} // closed once the store is in the index
+
+	mu      sync.Mutex // serializes writes to store, and guards the fields below
+	store   *store.Store
+	loading bool
+	pending []arxiv.EntryMetadata // papers found while the store was loading
+	loadErr error
+}
+
+// NewSearcher returns a Searcher in front of remote. If st is not nil, papers
+// from arXiv responses that are new to the index are added to it, and its API
+// entries are compacted and its papers added to the index in the background,
+// so that a large store does not hold up the caller. Local searches wait for
+// them.
+func NewSearcher(remote Remote, st *store.Store) *Searcher {
+	s := &Searcher{remote: remote, index: NewIndex(), loaded: make(chan struct{}), store: st}
+	if st == nil {
+		close(s.loaded)
+		return s
+	}
+	s.loading = true
+	go s.load()
+	return s
+}
+
+// SearcherFromEnv returns a Searcher in front of remote with the store in
+// ARXIV_STORE_DIR. If it is unset, it keeps no store, and searches only the
+// papers found since it started.
+func SearcherFromEnv(remote Remote) (*Searcher, error) {
+	dir := store.DirFromEnv()
+	if dir == "" {
+		return NewSearcher(remote, nil), nil
+	}
+	st, err := store.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	return NewSearcher(remote, st), nil
+}
+
+// load compacts the store and adds its papers to the index. Papers found on
+// arXiv meanwhile are held back until then, and only those the store does not
+// already have are appended to it.
+func (s *Searcher) load() {
+	defer close(s.loaded)
+	err := s.store.CompactEntries()
+	var entries []arxiv.EntryMetadata
+	if err == nil {
+		entries, err = s.store.Entries()
+	}
+	s.index.Add(entries...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loading = false
+	s.loadErr = err
+	if len(s.pending) > 0 {
+		// A stored paper added after the one found may have replaced it.
+		s.index.Add(s.pending...)
+		_ = s.store.AppendEntries(unstored(entries, s.pending))
+		s.pending = nil
+	}
+}
+
+// unstored returns the entries in found that replace the paper's entry in
+// stored, or that have none there.
+func unstored(stored, found []arxiv.EntryMetadata) []arxiv.EntryMetadata {
+	news := make(map[string]arxiv.EntryMetadata, len(found))
+	for _, entry := range found {
+		if id, err := arxivid.Parse(entry.ID); err == nil {
+			news[id.Base] = entry
+		}
+	}
+	for _, entry := range stored {
+		id, err := arxivid.Parse(entry.ID)
+		if err != nil {
+			continue
+		}
+		if found, ok := news[id.Base]; ok && !replaces(found, entry) {
+			delete(news, id.Base)
+		}
+	}
+	var entries []arxiv.EntryMetadata
+	for _, entry := range found {
+		if id, err := arxivid.Parse(entry.ID); err == nil && news[id.Base].ID == entry.ID {
+			entries = append(entries, entry)
+			delete(news, id.Base)
+		}
+	}
+	return entries
+}
+
+func (s *Searcher) Search(ctx context.Context, params arxiv.SearchParams) (arxiv.SearchResults, error) {
+	results, _, err := s.SearchCached(ctx, params)
+	return results, err
+}
+
+// SearchCached is like Search, but also reports whether the results came
+// from the remote's cache.
+func (s *Searcher) SearchCached(ctx context.Context, params arxiv.SearchParams) (arxiv.SearchResults, bool, error) {
+	var results arxiv.SearchResults
+	var cacheHit bool
+	var err error
+	if cached, ok := s.remote.(cachedRemote); ok {
+		results, cacheHit, err = cached.SearchCached(ctx, params)
+	} else {
+		results, err = s.remote.Search(ctx, params)
+	}
+	if err != nil {
+		return arxiv.SearchResults{}, false, err
+	}
+
+	// Only papers new to the index are stored, so that papers found again
+	// do not grow the store.
+	added := s.index.Add(results.Entries...)
+	if s.store != nil && len(added) > 0 {
+		// Results that cannot be stored are still returned, so a failing
+		// disk only costs papers missing from later offline searches.
+		s.mu.Lock()
+		if s.loading {
+			s.pending = append(s.pending, added...)
+		} else {
+			_ = s.store.AppendEntries(added)
+		}
+		s.mu.Unlock()
+	}
+	return results, cacheHit, nil
+}
+
+// SearchLocal answers params from the index, without arXiv, once the store
+// is loaded.
+func (s *Searcher) SearchLocal(ctx context.Context, params arxiv.SearchParams) (arxiv.SearchResults, error) {
+	select {
+	case <-s.loaded:
+	case <-ctx.Done():
+		return arxiv.SearchResults{}, ctx.Err()
+	}
+	s.mu.Lock()
+	err := s.loadErr
+	s.mu.Unlock()
+	if err != nil {
+		return arxiv.SearchResults{}, fmt.Errorf("loading the local store: %w", err)
+	}
+	return s.index.Search(ctx, params)
+}
+
+// DocumentFrequency returns the number of papers in the index with word in
+// their title or abstract, and the number of papers in the index. Until the
+// store is loaded, only the papers loaded so far are counted.
+func (s *Searcher) DocumentFrequency(word string) (df, papers int) {
+	return s.index.DocumentFrequency(word)
+}
@@ -0,0 +1,150 @@
+package localsearch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivclient"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/store"
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+type failingRemote struct{}
+
+func (failingRemote) Search(ctx context.Context, params arxiv.SearchParams) (arxiv.SearchResults, error) {
+	return arxiv.SearchResults{}, &arxivclient.UnavailableError{Status: "503 Service Unavailable"}
+}
+
+func TestSearcher(t *testing.T) {
+	ctx := context.Background()
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	searcher := NewSearcher(newTestClient(t), st)
+
+	params := arxiv.SearchParams{Query: "ti:attention"}
+	if results, _ := searcher.SearchLocal(ctx, params); results.TotalResults != 0 {
+		t.Errorf("expected an empty index, got %v", entryIDs(results.Entries))
+	}
+	if _, err := searcher.Search(ctx, arxiv.SearchParams{Query: "cat:cs.CL"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	results, err := searcher.SearchLocal(ctx, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results.Entries) != 1 || results.Entries[0].ID != "http://arxiv.org/abs/1706.03762v7" {
+		t.Errorf("expected the paper found on arXiv to be searchable, got %v", entryIDs(results.Entries))
+	}
+
+	// Papers found again are not stored again.
+	if _, err := searcher.Search(ctx, arxiv.SearchParams{Query: "all:attention"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(st.Dir(), "entries.jsonl"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 1706.03762 and 1810.04805 in cs.CL, and 2312.00752 with attention.
+	if lines := bytes.Count(data, []byte("\n")); lines != 3 {
+		t.Errorf("expected 3 stored entries, got %d", lines)
+	}
+
+	// A new searcher on the same store starts with the papers found before.
+	offline := NewSearcher(failingRemote{}, st)
+	var unavailable *arxivclient.UnavailableError
+	if _, err := offline.Search(ctx, params); !errors.As(err, &unavailable) {
+		t.Errorf("expected the remote's error, got %v", err)
+	}
+	results, err = offline.SearchLocal(ctx, arxiv.SearchParams{Query: "cat:cs.CL"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results.TotalResults != 2 {
+		t.Errorf("expected 1706.03762 and 1810.04805 from the store, got %v", entryIDs(results.Entries))
+	}
+}
+
+func TestSearcherWhileLoading(t *testing.T) {
+	ctx := context.Background()
+	st, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client := newTestClient(t)
+	results, err := client.Search(ctx, arxiv.SearchParams{IdList: []string{"1706.03762"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := st.AppendEntries(results.Entries); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The search may finish before or after the store is loaded; either way
+	// the stored paper is not stored again.
+	searcher := NewSearcher(client, st)
+	if _, err := searcher.Search(ctx, arxiv.SearchParams{Query: "cat:cs.CL"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	local, err := searcher.SearchLocal(ctx, arxiv.SearchParams{Query: "cat:cs.CL"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if local.TotalResults != 2 {
+		t.Errorf("expected 1706.03762 and 1810.04805, got %v", entryIDs(local.Entries))
+	}
+	data, err := os.ReadFile(filepath.Join(st.Dir(), "entries.jsonl"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lines := bytes.Count(data, []byte("\n")); lines != 2 {
+		t.Errorf("expected 2 stored entries, got %d", lines)
+	}
+}
+
+func TestUnstored(t *testing.T) {
+	entry := func(id string, day int) arxiv.EntryMetadata {
+		return arxiv.EntryMetadata{ID: "http://arxiv.org/abs/" + id, Updated: time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)}
+	}
+	stored := []arxiv.EntryMetadata{entry("2401.00001v1", 1), entry("2401.00002", 2), entry("2401.00003v2", 3)}
+	tests := []struct {
+		name     string
+		found    []arxiv.EntryMetadata
+		expected []string
+	}{
+		{name: "new paper", found: []arxiv.EntryMetadata{entry("2401.00004v1", 4)}, expected: []string{"http://arxiv.org/abs/2401.00004v1"}},
+		{name: "same entry", found: []arxiv.EntryMetadata{entry("2401.00001v1", 1)}, expected: []string{}},
+		{name: "older entry", found: []arxiv.EntryMetadata{entry("2401.00003v1", 1)}, expected: []string{}},
+		{name: "newer version", found: []arxiv.EntryMetadata{entry("2401.00001v2", 5)}, expected: []string{"http://arxiv.org/abs/2401.00001v2"}},
+		{name: "version of a record", found: []arxiv.EntryMetadata{entry("2401.00002v1", 2)}, expected: []string{"http://arxiv.org/abs/2401.00002v1"}},
+		{name: "found twice", found: []arxiv.EntryMetadata{entry("2401.00005v1", 1), entry("2401.00005v2", 2)}, expected: []string{"http://arxiv.org/abs/2401.00005v2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ids := entryIDs(unstored(stored, tt.found)); !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+}
+
+func TestSearcherFromEnv(t *testing.T) {
+	t.Setenv("ARXIV_STORE_DIR", "")
+	searcher, err := SearcherFromEnv(failingRemote{})
+	if err != nil || searcher.store != nil {
+		t.Errorf("expected no store unless ARXIV_STORE_DIR is set, got %v and %v", searcher.store, err)
+	}
+	dir := t.TempDir()
+	t.Setenv("ARXIV_STORE_DIR", dir)
+	searcher, err = SearcherFromEnv(failingRemote{})
+	if err != nil || searcher.store == nil || searcher.store.Dir() != dir {
+		t.Errorf("expected the store in %s, got %v and %v", dir, searcher.store, err)
+	}
+}
@@ -0,0 +1,314 @@
+// Package oaipmh parses arXiv's OAI-PMH interface, which lists the metadata
+// of every paper in bulk rather than by query.
+//
+// Only the ListRecords verb is supported, with arXiv's own metadata formats:
+// arXiv, which gives structured author names and the dates of the first and
+// latest versions, and arXivRaw, which gives the submitter and the date of
+// every version. Records are listed a page at a time; each page but the last
+// ends with a resumption token that asks for the next. Lists can be limited
+// to a set, one per archive such as cs or physics:hep-th, and to records
+// changed from or until a date.
+package oaipmh
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/taxonomy"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/textclean"
+)
+
+// Metadata formats arXiv disseminates.
+const (
+	FormatArXiv    = "arXiv"
+	FormatArXivRaw = "arXivRaw"
+)
+
+// DateLayout is the layout of dates in requests and record datestamps;
+// arXiv's datestamps have a granularity of a day.
+const DateLayout = "2006-01-02"
+
+// Request asks for a page of records. A request with a resumption token asks
+// for the page after the one that gave it and must have no other arguments.
+type Request struct {
+	MetadataPrefix  string
+	Set             string
+	From            string // earliest datestamp, in DateLayout
+	Until           string // latest datestamp, in DateLayout
+	ResumptionToken string
+}
+
+// Values returns the query parameters of a ListRecords request.
+func (r Request) Values() url.Values {
+	values := url.Values{"verb": {"ListRecords"}}
+	if r.ResumptionToken != "" {
+		values.Set("resumptionToken", r.ResumptionToken)
+		return values
+	}
+	values.Set("metadataPrefix", r.MetadataPrefix)
+	if r.Set != "" {
+		values.Set("set", r.Set)
+	}
+	if r.From != "" {
+		values.Set("from", r.From)
+	}
+	if r.Until != "" {
+		values.Set("until", r.Until)
+	}
+	return values
+}
+
+// Page is a page of records.
+type Page struct {
+	ResponseDate     time.Time
+	Records          []Record
+	ResumptionToken  string // empty on the last page
+	Cursor           int    // number of records listed before this page, if given
+	CompleteListSize int    // number of records in the whole list, if given
+}
+
+// Record is the metadata of a paper, as of its latest version. Deleted
+// records have only an ID, datestamp and sets.
+type Record struct {
+	ID         string    `json:"id"`
+	Datestamp  string    `json:"datestamp"`
+	Sets       []string  `json:"sets,omitempty"`
+	Deleted    bool      `json:"deleted,omitempty"`
+	Title      string    `json:"title,omitempty"`
+	Authors    []string  `json:"authors,omitempty"`
+	Abstract   string    `json:"abstract,omitempty"`
+	Categories []string  `json:"categories,omitempty"` // primary category first
+	Comments   string    `json:"comments,omitempty"`
+	JournalRef string    `json:"journal_ref,omitempty"`
+	DOI        string    `json:"doi,omitempty"`
+	ReportNo   string    `json:"report_no,omitempty"`
+	License    string    `json:"license,omitempty"`
+	Created    string    `json:"created,omitempty"`   // date of the first version; arXiv format only
+	Updated    string    `json:"updated,omitempty"`   // date of the latest version, if not the first; arXiv format only
+	Submitter  string    `json:"submitter,omitempty"` // arXivRaw format only
+	Versions   []Version `json:"versions,omitempty"`  // arXivRaw format only
+}
+
+// Version is a version of a paper as arXivRaw lists it.
+type Version struct {
+	Version string    `json:"version"`
+	Date    time.Time `json:"date"`
+	Size    string    `json:"size,omitempty"`
+}
+
+// Error codes defined by OAI-PMH.
+const (
+	BadArgument             = "badArgument"
+	BadResumptionToken      = "badResumptionToken"
+	BadVerb                 = "badVerb"
+	CannotDisseminateFormat = "cannotDisseminateFormat"
+	NoRecordsMatch          = "noRecordsMatch"
+	NoSetHierarchy          = "noSetHierarchy"
+)
+
+// Error is an error reported by an OAI-PMH repository.
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return "OAI-PMH error " + e.Code
+	}
+	return fmt.Sprintf("OAI-PMH error %s: %s", e.Code, e.Message)
+}
+
+// SetSpec returns the set of the archive or category tag, such as cs for
+// cs.LG and physics:hep-th for hep-th. arXiv's physics archives are sets
+// under physics; other archives are sets of their own. Set specs, such as
+// physics:hep-th, and the empty string are returned as they are.
+func SetSpec(tag string) string {
+	if tag == "" || strings.Contains(tag, ":") {
+		return tag
+	}
+	archive := taxonomy.Archive(tag)
+	switch archive {
+	case "cs", "econ", "eess", "math", "q-bio", "q-fin", "stat":
+		return archive
+	case "physics":
+		if strings.Contains(tag, ".") {
+			return "physics:physics"
+		}
+		return "physics"
+	}
+	return "physics:" + archive
+}
+
+// Parse parses a ListRecords response. A response reporting noRecordsMatch
+// is an empty page; other OAI-PMH errors are returned as *Error.
+func Parse(data []byte) (Page, error) {
+	var resp response
+	if err := xml.NewDecoder(bytes.NewReader(data)).Decode(&resp); err != nil {
+		return Page{}, fmt.Errorf("parsing OAI-PMH response: %w", err)
+	}
+	if resp.XMLName.Local != "OAI-PMH" {
+		return Page{}, fmt.Errorf("parsing OAI-PMH response: unexpected root element <%s>", resp.XMLName.Local)
+	}
+
+	var page Page
+	if resp.ResponseDate != "" {
+		date, err := time.Parse(time.RFC3339, strings.TrimSpace(resp.ResponseDate))
+		if err != nil {
+			return Page{}, fmt.Errorf("parsing OAI-PMH response date: %w", err)
+		}
+		page.ResponseDate = date
+	}
+	for _, e := range resp.Errors {
+		if e.Code == NoRecordsMatch {
+			continue
+		}
+		return Page{}, &Error{Code: e.Code, Message: textclean.OneLine(e.Message)}
+	}
+
+	list := resp.ListRecords
+	for _, raw := range list.Records {
+		record, err := raw.record()
+		if err != nil {
+			return Page{}, err
+		}
+		page.Records = append(page.Records, record)
+	}
+	page.ResumptionToken = strings.TrimSpace(list.ResumptionToken.Token)
+	page.Cursor, _ = strconv.Atoi(list.ResumptionToken.Cursor)
+	page.CompleteListSize, _ = strconv.Atoi(list.ResumptionToken.CompleteListSize)
+	return page, nil
+}
+
+type response struct {
+	XMLName      xml.Name
+	ResponseDate string `xml:"responseDate"`
+	Errors       []struct {
+		Code    string `xml:"code,attr"`
+		Message string `xml:",chardata"`
+	} `xml:"error"`
+	ListRecords struct {
+		Records         []rawRecord `xml:"record"`
+		ResumptionToken struct {
+			Token            string `xml:",chardata"`
+			Cursor           string `xml:"cursor,attr"`
+			CompleteListSize string `xml:"completeListSize,attr"`
+		} `xml:"resumptionToken"`
+	} `xml:"ListRecords"`
+}
+
+type rawRecord struct {
+	Header struct {
+		Status     string   `xml:"status,attr"`
+		Identifier string   `xml:"identifier"`
+		Datestamp  string   `xml:"datestamp"`
+		SetSpecs   []string `xml:"setSpec"`
+	} `xml:"header"`
+	Metadata struct {
+		ArXiv    *arXivMetadata    `xml:"arXiv"`
+		ArXivRaw *arXivRawMetadata `xml:"arXivRaw"`
+	} `xml:"metadata"`
+}
+
+// metadataFields are the fields both formats share.
+type metadataFields struct {
+	ID         string `xml:"id"`
+	Title      string `xml:"title"`
+	Categories string `xml:"categories"`
+	Comments   string `xml:"comments"`
+	ReportNo   string `xml:"report-no"`
+	JournalRef string `xml:"journal-ref"`
+	DOI        string `xml:"doi"`
+	License    string `xml:"license"`
+	Abstract   string `xml:"abstract"`
+}
+
+type arXivMetadata struct {
+	metadataFields
+	Created string `xml:"created"`
+	Updated string `xml:"updated"`
+	Authors []struct {
+		Keyname   string `xml:"keyname"`
+		Forenames string `xml:"forenames"`
+		Suffix    string `xml:"suffix"`
+	} `xml:"authors>author"`
+}
+
+type arXivRawMetadata struct {
+	metadataFields
+	Submitter string `xml:"submitter"`
+	Authors   string `xml:"authors"`
+	Versions  []struct {
+		Version string `xml:"version,attr"`
+		Date    string `xml:"date"`
+		Size    string `xml:"size"`
+	} `xml:"version"`
+}
+
+// versionDateLayout is the layout of arXivRaw version dates, such as
+// "Mon, 2 Apr 2007 19:18:42 GMT".
+const versionDateLayout = "Mon, 2 Jan 2006 15:04:05 MST"
+
+func (raw rawRecord) record() (Record, error) {
+	header := raw.Header
+	record := Record{
+		ID:        strings.TrimPrefix(strings.TrimSpace(header.Identifier), "oai:arXiv.org:"),
+		Datestamp: strings.TrimSpace(header.Datestamp),
+		Sets:      header.SetSpecs,
+		Deleted:   header.Status == "deleted",
+	}
+	if record.Deleted {
+		return record, nil
+	}
+
+	var fields metadataFields
+	switch {
+	case raw.Metadata.ArXiv != nil:
+		m := raw.Metadata.ArXiv
+		fields = m.metadataFields
+		record.Created = strings.TrimSpace(m.Created)
+		record.Updated = strings.TrimSpace(m.Updated)
+		for _, author := range m.Authors {
+			name := textclean.OneLine(author.Forenames + " " + author.Keyname + " " + author.Suffix)
+			if name != "" {
+				record.Authors = append(record.Authors, name)
+			}
+		}
+	case raw.Metadata.ArXivRaw != nil:
+		m := raw.Metadata.ArXivRaw
+		fields = m.metadataFields
+		record.Submitter = textclean.OneLine(m.Submitter)
+		record.Authors = textclean.SplitAuthors(m.Authors)
+		for _, v := range m.Versions {
+			version := Version{Version: v.Version, Size: strings.TrimSpace(v.Size)}
+			if date := strings.TrimSpace(v.Date); date != "" {
+				t, err := time.Parse(versionDateLayout, date)
+				if err != nil {
+					return Record{}, fmt.Errorf("parsing date of %s %s: %w", record.ID, v.Version, err)
+				}
+				version.Date = t
+			}
+			record.Versions = append(record.Versions, version)
+		}
+	default:
+		return Record{}, fmt.Errorf("record %s has no arXiv or arXivRaw metadata", record.ID)
+	}
+
+	if id := strings.TrimSpace(fields.ID); id != "" {
+		record.ID = id
+	}
+	record.Title = textclean.OneLine(fields.Title)
+	record.Abstract = textclean.OneLine(fields.Abstract)
+	record.Categories = strings.Fields(fields.Categories)
+	record.Comments = textclean.OneLine(fields.Comments)
+	record.JournalRef = textclean.OneLine(fields.JournalRef)
+	record.DOI = strings.TrimSpace(fields.DOI)
+	record.ReportNo = textclean.OneLine(fields.ReportNo)
+	record.License = strings.TrimSpace(fields.License)
+	return record, nil
+}
@@ -0,0 +1,247 @@
+package oaipmh
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const arXivPage = `<?xml version="1.0" encoding="UTF-8"?>
+<OAI-PMH xmlns="http://www.openarchives.org/OAI/2.0/">
+  <responseDate>2024-06-03T12:00:00Z</responseDate>
+  <request verb="ListRecords" metadataPrefix="arXiv" set="cs">http://export.arxiv.org/oai2</request>
+  <ListRecords>
+    <record>
+      <header>
+        <identifier>oai:arXiv.org:0704.0002</identifier>
+        <datestamp>2008-12-13</datestamp>
+        <setSpec>cs</setSpec>
+        <setSpec>math</setSpec>
+      </header>
+      <metadata>
+        <arXiv xmlns="http://arxiv.org/OAI/arXiv/">
+          <id>0704.0002</id>
+          <created>2007-03-30</created>
+          <updated>2008-12-13</updated>
+          <authors>
+            <author><keyname>Streinu</keyname><forenames>Ileana</forenames></author>
+            <author><keyname>Theran</keyname><forenames>Louis</forenames><suffix>Jr</suffix></author>
+          </authors>
+          <title>Sparsity-certifying Graph
+  Decompositions</title>
+          <categories>math.CO cs.CG</categories>
+          <comments>To appear in Graphs and Combinatorics</comments>
+          <license>http://arxiv.org/licenses/nonexclusive-distrib/1.0/</license>
+          <abstract>  We describe a new algorithm,
+  the $(k,\ell)$-pebble game.
+</abstract>
+        </arXiv>
+      </metadata>
+    </record>
+    <record>
+      <header status="deleted">
+        <identifier>oai:arXiv.org:hep-th/9901001</identifier>
+        <datestamp>2010-01-01</datestamp>
+        <setSpec>physics:hep-th</setSpec>
+      </header>
+    </record>
+    <resumptionToken cursor="0" completeListSize="3">6960524|1001</resumptionToken>
+  </ListRecords>
+</OAI-PMH>`
+
+const arXivRawPage = `<?xml version="1.0" encoding="UTF-8"?>
+<OAI-PMH xmlns="http://www.openarchives.org/OAI/2.0/">
+  <responseDate>2024-06-03T12:00:05Z</responseDate>
+  <request verb="ListRecords" resumptionToken="6960524|1001">http://export.arxiv.org/oai2</request>
+  <ListRecords>
+    <record>
+      <header>
+        <identifier>oai:arXiv.org:0704.0001</identifier>
+        <datestamp>2008-11-13</datestamp>
+        <setSpec>physics:hep-ph</setSpec>
+      </header>
+      <metadata>
+        <arXivRaw xmlns="http://arxiv.org/OAI/arXivRaw/">
+          <id>0704.0001</id>
+          <submitter>Pavel Nadolsky</submitter>
+          <version version="v1"><date>Mon, 2 Apr 2007 19:18:42 GMT</date><size>37kb</size><source_type>D</source_type></version>
+          <version version="v2"><date>Tue, 24 Jul 2007 20:10:27 GMT</date><size>37kb</size><source_type>D</source_type></version>
+          <title>Calculation of prompt diphoton production cross sections at Tevatron and LHC energies</title>
+          <authors>C. Bal\'azs, E. L. Berger, P. M. Nadolsky and C.-P. Yuan</authors>
+          <categories>hep-ph</categories>
+          <comments>37 pages, 15 figures</comments>
+          <report-no>ANL-HEP-PR-07-12</report-no>
+          <journal-ref>Phys.Rev.D76:013009,2007</journal-ref>
+          <doi>10.1103/PhysRevD.76.013009</doi>
+          <abstract>A fully differential calculation in perturbative quantum chromodynamics is presented.</abstract>
+        </arXivRaw>
+      </metadata>
+    </record>
+    <resumptionToken cursor="2" completeListSize="3"></resumptionToken>
+  </ListRecords>
+</OAI-PMH>`
+
+func TestParse(t *testing.T) {
+	t.Run("arXiv format", func(t *testing.T) {
+		page, err := Parse([]byte(arXivPage))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !page.ResponseDate.Equal(time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)) {
+			t.Errorf("unexpected response date %v", page.ResponseDate)
+		}
+		if page.ResumptionToken != "6960524|1001" || page.Cursor != 0 || page.CompleteListSize != 3 {
+			t.Errorf("unexpected resumption token %q, cursor %d, list size %d", page.ResumptionToken, page.Cursor, page.CompleteListSize)
+		}
+		if len(page.Records) != 2 {
+			t.Fatalf("expected 2 records, got %d", len(page.Records))
+		}
+		expected := Record{
+			ID:         "0704.0002",
+			Datestamp:  "2008-12-13",
+			Sets:       []string{"cs", "math"},
+			Title:      "Sparsity-certifying Graph Decompositions",
+			Authors:    []string{"Ileana Streinu", "Louis Theran Jr"},
+			Abstract:   `We describe a new algorithm, the $(k,\ell)$-pebble game.`,
+			Categories: []string{"math.CO", "cs.CG"},
+			Comments:   "To appear in Graphs and Combinatorics",
+			License:    "http://arxiv.org/licenses/nonexclusive-distrib/1.0/",
+			Created:    "2007-03-30",
+			Updated:    "2008-12-13",
+		}
+		if !reflect.DeepEqual(page.Records[0], expected) {
+			t.Errorf("expected %+v, got %+v", expected, page.Records[0])
+		}
+		deleted := Record{ID: "hep-th/9901001", Datestamp: "2010-01-01", Sets: []string{"physics:hep-th"}, Deleted: true}
+		if !reflect.DeepEqual(page.Records[1], deleted) {
+			t.Errorf("expected %+v, got %+v", deleted, page.Records[1])
+		}
+	})
+
+	t.Run("arXivRaw format", func(t *testing.T) {
+		page, err := Parse([]byte(arXivRawPage))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if page.ResumptionToken != "" || page.Cursor != 2 {
+			t.Errorf("expected the last page, got token %q at cursor %d", page.ResumptionToken, page.Cursor)
+		}
+		if len(page.Records) != 1 {
+			t.Fatalf("expected 1 record, got %d", len(page.Records))
+		}
+		record := page.Records[0]
+		if record.Submitter != "Pavel Nadolsky" {
+			t.Errorf("unexpected submitter %q", record.Submitter)
+		}
+		authors := []string{`C. Bal\'azs`, "E. L. Berger", "P. M. Nadolsky", "C.-P. Yuan"}
+		if !reflect.DeepEqual(record.Authors, authors) {
+			t.Errorf("expected authors %q, got %q", authors, record.Authors)
+		}
+		versions := []Version{
+			{Version: "v1", Date: time.Date(2007, 4, 2, 19, 18, 42, 0, time.UTC), Size: "37kb"},
+			{Version: "v2", Date: time.Date(2007, 7, 24, 20, 10, 27, 0, time.UTC), Size: "37kb"},
+		}
+		if len(record.Versions) != 2 {
+			t.Fatalf("expected 2 versions, got %+v", record.Versions)
+		}
+		for i, v := range versions {
+			if record.Versions[i].Version != v.Version || !record.Versions[i].Date.Equal(v.Date) || record.Versions[i].Size != v.Size {
+				t.Errorf("expected version %+v, got %+v", v, record.Versions[i])
+			}
+		}
+		if record.ReportNo != "ANL-HEP-PR-07-12" || record.JournalRef != "Phys.Rev.D76:013009,2007" || record.DOI != "10.1103/PhysRevD.76.013009" {
+			t.Errorf("unexpected report number, journal reference or DOI in %+v", record)
+		}
+		if record.Created != "" || record.Updated != "" {
+			t.Errorf("expected no created or updated dates, got %q and %q", record.Created, record.Updated)
+		}
+	})
+
+	t.Run("no records match", func(t *testing.T) {
+		page, err := Parse([]byte(`<OAI-PMH xmlns="http://www.openarchives.org/OAI/2.0/"><responseDate>2024-06-03T12:00:00Z</responseDate>` +
+			`<error code="noRecordsMatch">no records</error></OAI-PMH>`))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(page.Records) != 0 || page.ResumptionToken != "" {
+			t.Errorf("expected an empty last page, got %+v", page)
+		}
+	})
+
+	t.Run("protocol error", func(t *testing.T) {
+		_, err := Parse([]byte(`<OAI-PMH xmlns="http://www.openarchives.org/OAI/2.0/"><responseDate>2024-06-03T12:00:00Z</responseDate>` +
+			`<error code="badResumptionToken">The resumptionToken
+  has expired</error></OAI-PMH>`))
+		var oaiErr *Error
+		if !errors.As(err, &oaiErr) {
+			t.Fatalf("expected *Error, got %v", err)
+		}
+		if oaiErr.Code != BadResumptionToken || oaiErr.Message != "The resumptionToken has expired" {
+			t.Errorf("unexpected error %+v", oaiErr)
+		}
+	})
+
+	t.Run("not OAI-PMH", func(t *testing.T) {
+		for _, data := range []string{"<html>maintenance</html>", "not xml"} {
+			_, err := Parse([]byte(data))
+			var oaiErr *Error
+			if err == nil || errors.As(err, &oaiErr) {
+				t.Errorf("expected a parse error for %q, got %v", data, err)
+			}
+		}
+	})
+}
+
+func TestRequestValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      Request
+		expected string
+	}{
+		{
+			name:     "format only",
+			req:      Request{MetadataPrefix: FormatArXiv},
+			expected: "metadataPrefix=arXiv&verb=ListRecords",
+		},
+		{
+			name:     "set and dates",
+			req:      Request{MetadataPrefix: FormatArXivRaw, Set: "physics:hep-th", From: "2024-01-01", Until: "2024-01-31"},
+			expected: "from=2024-01-01&metadataPrefix=arXivRaw&set=physics%3Ahep-th&until=2024-01-31&verb=ListRecords",
+		},
+		{
+			name:     "resumption token is exclusive",
+			req:      Request{MetadataPrefix: FormatArXiv, Set: "cs", ResumptionToken: "6960524|1001"},
+			expected: "resumptionToken=6960524%7C1001&verb=ListRecords",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.req.Values().Encode(); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestSetSpec(t *testing.T) {
+	tests := map[string]string{
+		"":               "",
+		"cs":             "cs",
+		"cs.LG":          "cs",
+		"math.DG":        "math",
+		"q-bio.NC":       "q-bio",
+		"stat":           "stat",
+		"hep-th":         "physics:hep-th",
+		"astro-ph.HE":    "physics:astro-ph",
+		"quant-ph":       "physics:quant-ph",
+		"physics":        "physics",
+		"physics.optics": "physics:physics",
+		"physics:gr-qc":  "physics:gr-qc",
+	}
+	for tag, expected := range tests {
+		if got := SetSpec(tag); got != expected {
+			t.Errorf("SetSpec(%q): expected %q, got %q", tag, expected, got)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+// Package pdftext extracts the text of a paper from its PDF, page by page,
+// and finds its section headings.
+//
+// Text is read from the page content streams in the order it is drawn, which
+// for papers typeset with LaTeX is reading order, columns included. Glyphs
+// are joined into lines by position, and lines into paragraphs by the space
+// between them.
+//
+// Headings are taken from the PDF outline when it has one, as papers built
+// with hyperref do. Otherwise, lines are taken as headings if they are short
+// and set larger or bolder than the body text, and either numbered like
+// "3.2 Results" or named like "References".
+package pdftext
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/textclean"
+	"github.com/ledongthuc/pdf"
+)
+
+// ErrNotPDF is returned for data that is not a PDF document.
+var ErrNotPDF = errors.New("not a PDF document")
+
+// Document is the text of a PDF.
+type Document struct {
+	Pages    []string // text of each page
+	Headings []Heading
+}
+
+// Heading is a section heading found in a Document.
+type Heading struct {
+	Title  string `json:"title"`
+	Level  int    `json:"level"`  // 1 for a section, 2 for a subsection, and so on
+	Page   int    `json:"page"`   // page the heading is on, counting from 1
+	Offset int    `json:"offset"` // offset of the heading in the page text, in characters
+}
+
+// line is a line of text and how it is set.
+type line struct {
+	text string
+	size float64 // font size of most of the line
+	bold bool    // whether most of the line is bold
+	y    float64
+}
+
+// Extract returns the text of the PDF in data.
+func Extract(data []byte) (doc *Document, err error) {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return nil, ErrNotPDF
+	}
+	// The PDF reader panics on malformed documents.
+	defer func() {
+		if r := recover(); r != nil {
+			doc, err = nil, fmt.Errorf("reading PDF: %v", r)
+		}
+	}()
+
+	r, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("reading PDF: %w", err)
+	}
+
+	pages := make([][]line, r.NumPage())
+	for i := range pages {
+		pages[i] = pageLines(r.Page(i + 1).Content().Text)
+	}
+	headings := outlineHeadings(r.Outline(), pages)
+	if headings == nil {
+		headings = detectHeadings(pages, bodySize(pages))
+	}
+	return layout(pages, headings), nil
+}
+
+// pageLines joins the glyphs of a page into lines. A glyph starts a new line
+// if it is set lower or higher than the line so far, or back to its left.
+func pageLines(glyphs []pdf.Text) []line {
+	var lines []line
+	var b strings.Builder
+	var current line
+	sizes := make(map[float64]int)
+	bold := 0
+	chars := 0
+	end := 0.0 // where the last glyph ended
+
+	flush := func() {
+		text := clean(b.String())
+		if text != "" {
+			current.text = text
+			current.bold = bold*2 > chars
+			for size, n := range sizes {
+				if n > sizes[current.size] || (n == sizes[current.size] && size > current.size) {
+					current.size = size
+				}
+			}
+			lines = append(lines, current)
+		}
+		b.Reset()
+		clear(sizes)
+		bold, chars = 0, 0
+	}
+
+	for _, g := range glyphs {
+		if g.S == "" {
+			continue
+		}
+		size := math.Abs(g.FontSize)
+		newLine := b.Len() == 0 ||
+			math.Abs(g.Y-current.y) > size/2 ||
+			g.X < end-size
+		if newLine {
+			flush()
+			current = line{y: g.Y}
+		} else if g.X-end > size*0.15 {
+			// Words are often positioned rather than separated by spaces.
+			b.WriteByte(' ')
+		}
+		b.WriteString(g.S)
+		end = g.X + g.W
+		if strings.TrimSpace(g.S) != "" {
+			sizes[math.Round(size*10)/10]++
+			chars++
+			if isBoldFont(g.Font) {
+				bold++
+			}
+		}
+	}
+	flush()
+	return lines
+}
+
+func isBoldFont(font string) bool {
+	font = strings.ToLower(font)
+	return strings.Contains(font, "bold") || strings.Contains(font, "cmbx") ||
+		strings.Contains(font, "medi") || strings.HasSuffix(font, "-bd")
+}
+
+var ligatures = strings.NewReplacer(
+	"ﬀ", "ff", "ﬁ", "fi", "ﬂ", "fl", "ﬃ", "ffi", "ﬄ", "ffl",
+)
+
+// clean expands ligatures, drops control characters and collapses runs of
+// spaces.
+func clean(s string) string {
+	s = ligatures.Replace(s)
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == utf8.RuneError {
+			return ' '
+		}
+		return r
+	}, s)
+	return textclean.OneLine(s)
+}
+
+// bodySize returns the font size of most of the text.
+func bodySize(pages [][]line) float64 {
+	chars := make(map[float64]int)
+	for _, lines := range pages {
+		for _, l := range lines {
+			chars[l.size] += len(l.text)
+		}
+	}
+	body := 0.0
+	for size, n := range chars {
+		if n > chars[body] {
+			body = size
+		}
+	}
+	return body
+}
+
+// position is where a heading was found: the index of its page and of its
+// first line there.
+type position struct {
+	page, line int
+}
+
+type found struct {
+	Heading
+	position
+	lines int // lines the heading spans
+}
+
+// outlineHeadings finds the entries of the PDF outline in the page text. It
+// returns nil if fewer than half of them are found, as when the outline has
+// entries for figures or the document has no outline.
+func outlineHeadings(outline pdf.Outline, pages [][]line) []found {
+	type entry struct {
+		title string
+		level int
+	}
+	var entries []entry
+	var walk func(o pdf.Outline, level int)
+	walk = func(o pdf.Outline, level int) {
+		for _, child := range o.Child {
+			entries = append(entries, entry{title: clean(child.Title), level: level})
+			walk(child, level+1)
+		}
+	}
+	walk(outline, 1)
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var headings []found
+	next := position{}
+	for _, e := range entries {
+		key := headingKey(e.title)
+		if key == "" {
+			continue
+		}
+	search:
+		for p := next.page; p < len(pages); p++ {
+			start := 0
+			if p == next.page {
+				start = next.line
+			}
+			for i := start; i < len(pages[p]); i++ {
+				if headingKey(pages[p][i].text) == key {
+					headings = append(headings, found{
+						Heading:  Heading{Title: e.title, Level: e.level},
+						position: position{page: p, line: i},
+						lines:    1,
+					})
+					next = position{page: p, line: i + 1}
+					break search
+				}
+			}
+		}
+	}
+	if len(headings)*2 < len(entries) {
+		return nil
+	}
+	return headings
+}
+
+// headingKey normalizes a heading for matching, ignoring its number, case
+// and punctuation.
+func headingKey(s string) string {
+	if m := numberedRe.FindStringSubmatch(s); m != nil {
+		s = m[2]
+	}
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// numberedRe matches a numbered heading, such as "3 Results", "3.2. Setup" or
+// "A.1 Proofs".
+var numberedRe = regexp.MustCompile(`^((?:\d{1,2}|[A-Z](?:\.\d{1,2})+|[A-Z]\.)(?:\.\d{1,2})*)\.?\s+(\p{Lu}.*)$`)
+
+// namedHeadings are unnumbered headings common in papers.
+var namedHeadings = map[string]bool{
+	"abstract": true, "introduction": true, "references": true, "bibliography": true,
+	"acknowledgments": true, "acknowledgements": true, "acknowledgment": true,
+	"appendix": true, "conclusion": true, "conclusions": true, "related work": true,
+}
+
+// detectHeadings finds headings by how lines are set. Unnumbered lines set
+// larger than the body text are taken as headings too, with consecutive ones
+// of the same size joined, since long titles wrap.
+func detectHeadings(pages [][]line, body float64) []found {
+	var headings []found
+	for p, lines := range pages {
+		for i := 0; i < len(lines); i++ {
+			l := lines[i]
+			if !headingLike(l.text) {
+				continue
+			}
+			larger := l.size >= body*1.15
+			if !larger && !l.bold {
+				continue
+			}
+			if m := numberedRe.FindStringSubmatch(l.text); m != nil {
+				level := strings.Count(strings.TrimSuffix(m[1], "."), ".") + 1
+				headings = append(headings, found{Heading: Heading{Title: l.text, Level: level}, position: position{p, i}, lines: 1})
+				continue
+			}
+			if namedHeadings[headingKey(l.text)] {
+				headings = append(headings, found{Heading: Heading{Title: l.text, Level: 1}, position: position{p, i}, lines: 1})
+				continue
+			}
+			if larger {
+				title := l.text
+				n := 1
+				for i+n < len(lines) && lines[i+n].size == l.size && headingLike(title+" "+lines[i+n].text) {
+					title += " " + lines[i+n].text
+					n++
+				}
+				headings = append(headings, found{Heading: Heading{Title: title, Level: 1}, position: position{p, i}, lines: n})
+				i += n - 1
+			}
+		}
+	}
+	return headings
+}
+
+// headingLike reports whether text is short enough to be a heading and does
+// not end like a sentence.
+func headingLike(text string) bool {
+	letters := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	return letters >= 3 && utf8.RuneCountInString(text) <= 120 &&
+		len(strings.Fields(text)) <= 16 && !strings.ContainsAny(text[len(text)-1:], ".,;:")
+}
+
+// layout joins the lines of each page into text, with a blank line between
+// paragraphs and around headings, and sets the position of each heading.
+func layout(pages [][]line, headings []found) *Document {
+	starts := make(map[position]int) // heading index by position
+	for i, h := range headings {
+		starts[h.position] = i
+	}
+
+	doc := &Document{Pages: make([]string, len(pages))}
+	for p, lines := range pages {
+		var b strings.Builder
+		offset := 0 // in characters
+		write := func(s string) {
+			b.WriteString(s)
+			offset += utf8.RuneCountInString(s)
+		}
+		headingEnd := -1 // index of the last line of the current heading
+		for i, l := range lines {
+			h, isHeading := starts[position{p, i}]
+			if i > 0 {
+				prev := lines[i-1]
+				switch {
+				case isHeading || i == headingEnd+1 || prev.y-l.y > 1.8*math.Max(l.size, prev.size) || l.y > prev.y:
+					write("\n\n")
+				default:
+					write("\n")
+				}
+			}
+			if isHeading {
+				heading := headings[h].Heading
+				heading.Page = p + 1
+				heading.Offset = offset
+				doc.Headings = append(doc.Headings, heading)
+				headingEnd = i + headings[h].lines - 1
+			}
+			write(l.text)
+		}
+		doc.Pages[p] = b.String()
+	}
+	return doc
+}
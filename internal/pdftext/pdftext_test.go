@@ -0,0 +1,171 @@
+package pdftext
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/fakearxiv"
+	"github.com/ledongthuc/pdf"
+)
+
+var testPages = [][]fakearxiv.PDFLine{
+	{
+		{Text: "A Long Title About Attention", Size: 17},
+		{Text: "That Wraps Onto Two Lines", Size: 17},
+		{Text: "Jane Smith, John Doe", Size: 11},
+		{},
+		{Text: "Abstract", Size: 12, Bold: true},
+		{Text: "We study attention (in transformers) and find that it is"},
+		{Text: "all you need. Results are shown in Table 2."},
+		{},
+		{Text: "1 Introduction", Size: 12, Bold: true},
+		{Text: "Sequence models are everywhere."},
+	},
+	{
+		{Text: "2 Method", Size: 12, Bold: true},
+		{Text: "Our method has two parts."},
+		{},
+		{Text: "2.1. Setup", Bold: true},
+		{Text: "The setup is simple."},
+		{},
+		{Text: "References", Size: 12, Bold: true},
+		{Text: "[1] A. Author. An earlier paper. 2001."},
+	},
+}
+
+func TestExtract(t *testing.T) {
+	doc, err := Extract(fakearxiv.PDF(testPages))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(doc.Pages) != 2 {
+		t.Fatalf("expected 2 pages, got %d", len(doc.Pages))
+	}
+
+	expectedFirst := "A Long Title About Attention\nThat Wraps Onto Two Lines\n\nJane Smith, John Doe\n\n" +
+		"Abstract\n\nWe study attention (in transformers) and find that it is\nall you need. Results are shown in Table 2.\n\n" +
+		"1 Introduction\n\nSequence models are everywhere."
+	if doc.Pages[0] != expectedFirst {
+		t.Errorf("unexpected first page text:\n%s", doc.Pages[0])
+	}
+
+	expected := []Heading{
+		{Title: "A Long Title About Attention That Wraps Onto Two Lines", Level: 1, Page: 1},
+		{Title: "Abstract", Level: 1, Page: 1},
+		{Title: "1 Introduction", Level: 1, Page: 1},
+		{Title: "2 Method", Level: 1, Page: 2},
+		{Title: "2.1. Setup", Level: 2, Page: 2},
+		{Title: "References", Level: 1, Page: 2},
+	}
+	if len(doc.Headings) != len(expected) {
+		t.Fatalf("expected %d headings, got %+v", len(expected), doc.Headings)
+	}
+	for i, h := range doc.Headings {
+		if h.Title != expected[i].Title || h.Level != expected[i].Level || h.Page != expected[i].Page {
+			t.Errorf("heading %d: expected %+v, got %+v", i, expected[i], h)
+		}
+		page := []rune(doc.Pages[h.Page-1])
+		firstWord := strings.Fields(h.Title)[0]
+		if !strings.HasPrefix(string(page[h.Offset:]), firstWord) {
+			t.Errorf("heading %q: offset %d does not point at it", h.Title, h.Offset)
+		}
+	}
+}
+
+func TestExtractErrors(t *testing.T) {
+	if _, err := Extract([]byte("<html>not found</html>")); !errors.Is(err, ErrNotPDF) {
+		t.Errorf("expected ErrNotPDF, got %v", err)
+	}
+	if _, err := Extract([]byte("%PDF-1.4\ngarbage")); err == nil {
+		t.Error("expected error for a malformed PDF")
+	}
+}
+
+func TestOutlineHeadings(t *testing.T) {
+	pages := [][]line{
+		{{text: "Title"}, {text: "1 Introduction"}, {text: "Some text."}},
+		{{text: "2 Background and Notation"}, {text: "2.1 Notation"}, {text: "References"}},
+	}
+
+	t.Run("matches entries in order", func(t *testing.T) {
+		outline := pdf.Outline{Child: []pdf.Outline{
+			{Title: "Introduction"},
+			{Title: "Background and notation", Child: []pdf.Outline{{Title: "2.1 Notation"}}},
+			{Title: "References"},
+		}}
+		headings := outlineHeadings(outline, pages)
+		expected := []found{
+			{Heading: Heading{Title: "Introduction", Level: 1}, position: position{0, 1}},
+			{Heading: Heading{Title: "Background and notation", Level: 1}, position: position{1, 0}},
+			{Heading: Heading{Title: "2.1 Notation", Level: 2}, position: position{1, 1}},
+			{Heading: Heading{Title: "References", Level: 1}, position: position{1, 2}},
+		}
+		if len(headings) != len(expected) {
+			t.Fatalf("expected %d headings, got %+v", len(expected), headings)
+		}
+		for i, h := range headings {
+			if h.Heading != expected[i].Heading || h.position != expected[i].position {
+				t.Errorf("heading %d: expected %+v, got %+v", i, expected[i], h)
+			}
+		}
+	})
+
+	t.Run("mostly unmatched", func(t *testing.T) {
+		outline := pdf.Outline{Child: []pdf.Outline{{Title: "Introduction"}, {Title: "Figure 1"}, {Title: "Figure 2"}}}
+		if headings := outlineHeadings(outline, pages); headings != nil {
+			t.Errorf("expected nil, got %+v", headings)
+		}
+	})
+
+	t.Run("no outline", func(t *testing.T) {
+		if headings := outlineHeadings(pdf.Outline{}, pages); headings != nil {
+			t.Errorf("expected nil, got %+v", headings)
+		}
+	})
+}
+
+func TestDetectHeadings(t *testing.T) {
+	tests := []struct {
+		line  line
+		level int // 0 if not a heading
+	}{
+		{line{text: "3 Results", bold: true}, 1},
+		{line{text: "3.2. Setup", bold: true}, 2},
+		{line{text: "3.2.1 Data", size: 12}, 3},
+		{line{text: "A.1 Proofs", bold: true}, 2},
+		{line{text: "REFERENCES", bold: true}, 1},
+		{line{text: "Related Work", size: 14}, 1},
+		{line{text: "3 Results", size: 10}, 0},
+		{line{text: "Results are shown in Table 2.", bold: true}, 0},
+		{line{text: "2024 was a year of models", bold: true}, 0},
+		{line{text: "12", size: 14}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line.text, func(t *testing.T) {
+			if tt.line.size == 0 {
+				tt.line.size = 10
+			}
+			headings := detectHeadings([][]line{{tt.line}}, 10)
+			switch {
+			case tt.level == 0 && len(headings) > 0:
+				t.Errorf("expected no heading, got %+v", headings)
+			case tt.level > 0 && len(headings) != 1:
+				t.Errorf("expected a heading, got %+v", headings)
+			case tt.level > 0 && headings[0].Level != tt.level:
+				t.Errorf("expected level %d, got %d", tt.level, headings[0].Level)
+			}
+		})
+	}
+}
+
+func TestClean(t *testing.T) {
+	got := clean("  the ﬁrst  \x00 eﬃcient\tline ")
+	if got != "the first efficient line" {
+		t.Errorf("unexpected cleaned text %q", got)
+	}
+	if !utf8.ValidString(clean("caf\xe9")) {
+		t.Error("expected valid UTF-8")
+	}
+}
@@ -8,7 +8,7 @@ import (
 
 var CategoryPrompt = mcp.Prompt{
 	Name:        "recent-category",
-	Description: "Get articles from the last week for a specific category",
+	Description: "Get the latest new articles announced in a specific category",
 	Arguments: []*mcp.PromptArgument{
 		{
 			Name:        "category",
@@ -20,11 +20,11 @@ var CategoryPrompt = mcp.Prompt{
 
 func CategoryPromptHandler(_ context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
 	return &mcp.GetPromptResult{
-		Description: "Prompt to get articles from the last week for a specific category",
+		Description: "Prompt to get the latest new articles announced in a specific category",
 		Messages: []*mcp.PromptMessage{
 			{
 				Role:    "user",
-				Content: &mcp.TextContent{Text: "Find the arXiv category for " + req.Params.Arguments["category"] + ". If the category matches a general subject like math or computer science, get the category for general articles within that field. Search for 50 articles from the last week in that category. If none are found, try expanding the time range to the last month, 6 months, or a year. Display them in a table with columns for title, first author, ID, and PDF URL."},
+				Content: &mcp.TextContent{Text: "Use the arxiv-resolve-category tool to find the arXiv category for " + req.Params.Arguments["category"] + ". If the category matches a general subject like math or computer science, use the archive tag for that whole field, such as math or cs. Use the arxiv-new-listings tool to get the new submissions and cross-lists in the latest announcement for that category. If there is no current announcement, as on weekends, say so and search for articles from the last week in that category instead. Display the new submissions and the cross-lists in separate tables with columns for title, first author, ID, and PDF URL."},
 			},
 		},
 	}, nil
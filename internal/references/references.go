@@ -0,0 +1,238 @@
+// Package references parses the entries of a paper's reference list into
+// authors, title, year and venue, and finds the arXiv IDs and DOIs they
+// cite.
+//
+// Entries come either from BibTeX fields, which say what each part is, or
+// as typeset text, from a .bbl file or a PDF, which is split into parts by
+// the punctuation most reference styles use. The latter is a heuristic and
+// leaves parts empty when an entry does not follow it.
+package references
+
+import (
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/textclean"
+	"regexp"
+	"strings"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivid"
+)
+
+// Reference is an entry of a reference list.
+type Reference struct {
+	Key     string // citation key, for references from LaTeX source
+	Text    string // the entry as plain text
+	Authors []string
+	Title   string
+	Year    string
+	Venue   string // journal, proceedings or publisher
+	ArxivID string // normalized, empty if the entry cites no arXiv paper
+	DOI     string
+}
+
+// FromBibTeX makes a reference from the fields of a BibTeX entry.
+func FromBibTeX(key string, fields map[string]string) Reference {
+	ref := Reference{
+		Key:   key,
+		Title: PlainText(fields["title"]),
+		Year:  fields["year"],
+	}
+	for _, author := range splitBibAuthors(fields["author"]) {
+		ref.Authors = append(ref.Authors, PlainText(author))
+	}
+	for _, name := range []string{"journal", "booktitle", "publisher", "howpublished", "school", "institution"} {
+		if venue := PlainText(fields[name]); venue != "" {
+			ref.Venue = venue
+			break
+		}
+	}
+
+	var text []string
+	if len(ref.Authors) > 0 {
+		text = append(text, strings.Join(ref.Authors, ", "))
+	}
+	for _, part := range []string{ref.Title, ref.Venue, ref.Year} {
+		if part != "" {
+			text = append(text, part)
+		}
+	}
+	ref.Text = strings.Join(text, ". ")
+
+	ids := fields["eprint"] + " " + fields["arxivid"] + " " + fields["url"] + " " + fields["journal"] + " " + fields["note"]
+	if found := arxivid.Find(ids); len(found) > 0 {
+		ref.ArxivID = found[0].String()
+	}
+	ref.DOI = findDOI(fields["doi"] + " " + fields["url"])
+	if ref.ArxivID == "" {
+		ref.ArxivID = arxivDOIID(ref.DOI)
+	}
+	return ref
+}
+
+// splitBibAuthors splits a BibTeX author field at the "and"s that are not in
+// braces.
+func splitBibAuthors(field string) []string {
+	var authors []string
+	depth, start := 0, 0
+	for i := 0; i < len(field); i++ {
+		switch field[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		}
+		if depth == 0 && strings.HasPrefix(field[i:], " and ") {
+			authors = append(authors, strings.TrimSpace(field[start:i]))
+			start = i + len(" and ")
+			i = start - 1
+		}
+	}
+	if last := strings.TrimSpace(field[start:]); last != "" && last != "others" {
+		authors = append(authors, last)
+	}
+	return authors
+}
+
+var (
+	labelRe     = regexp.MustCompile(`^\s*(?:\[[^\]]{1,40}\]|\(\d{1,4}\)|\d{1,4}\.)\s+`)
+	yearRe      = regexp.MustCompile(`\b(?:19|20)\d{2}[a-z]?\b`)
+	parenYearRe = regexp.MustCompile(`\(((?:19|20)\d{2}[a-z]?)\)`)
+	quotedRe    = regexp.MustCompile(`[“"]([^”"]{3,})[”"]`)
+	doiRe       = regexp.MustCompile(`\b10\.\d{4,9}/[^\s"<>{}]+`)
+	initialsRe  = regexp.MustCompile(`^(?:[A-Z]\.?(?:\s+|-|$))+$`)
+	pagesRe     = regexp.MustCompile(`\b(?:pp?\.|pages)\s*[\d–-]+`)
+	urlRe       = regexp.MustCompile(`(?:https?://|doi:\s*)\S*`)
+	emptyRe     = regexp.MustCompile(`\(\s*\)|\s+(?:[,;]\s*)+|(?:,\s*){2,}`)
+)
+
+// Parse parses a typeset reference. The text is expected to be plain, with
+// any LaTeX already removed by PlainText.
+//
+// Most styles give the authors, title and venue as parts ending in a full
+// stop, with the year either after the authors in parentheses or near the
+// end. Quoted titles are recognized wherever they are.
+func Parse(text string) Reference {
+	text = textclean.OneLine(text)
+	text = labelRe.ReplaceAllString(text, "")
+	ref := Reference{Text: text, DOI: findDOI(text)}
+	if found := arxivid.Find(text); len(found) > 0 {
+		ref.ArxivID = found[0].String()
+	} else {
+		ref.ArxivID = arxivDOIID(ref.DOI)
+	}
+
+	// IDs and DOIs are taken out so that their digits are not read as years.
+	rest := doiRe.ReplaceAllString(text, "")
+	for _, id := range arxivid.Find(rest) {
+		rest = strings.ReplaceAll(rest, id.String(), "")
+	}
+	if m := parenYearRe.FindStringSubmatch(rest); m != nil {
+		ref.Year = m[1]
+	} else if years := yearRe.FindAllString(rest, -1); len(years) > 0 {
+		ref.Year = years[len(years)-1]
+	}
+
+	parts := splitParts(parenYearRe.ReplaceAllString(rest, ""))
+	if m := quotedRe.FindStringSubmatchIndex(text); m != nil {
+		ref.Title = strings.TrimRight(strings.TrimSpace(text[m[2]:m[3]]), ",.")
+		ref.Authors = splitAuthors(strings.TrimRight(strings.TrimSpace(text[:m[0]]), ",."))
+		ref.Venue = venue(text[m[1]:])
+		return ref
+	}
+	if len(parts) > 0 {
+		ref.Authors = splitAuthors(parts[0])
+	}
+	if len(parts) > 1 {
+		ref.Title = parts[1]
+	}
+	if len(parts) > 2 {
+		ref.Venue = venue(strings.Join(parts[2:], ". "))
+	}
+	return ref
+}
+
+// splitParts splits a reference at full stops that end a part: those after
+// a word, rather than after an initial, and followed by a space.
+func splitParts(text string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(text); i++ {
+		if text[i] != '.' || (i+1 < len(text) && text[i+1] != ' ') {
+			continue
+		}
+		word := strings.TrimLeft(text[strings.LastIndexAny(text[:i], " .")+1:i], "-")
+		if len(word) <= 1 && word != "" && word[0] >= 'A' && word[0] <= 'Z' {
+			continue // an initial
+		}
+		if part := strings.TrimSpace(text[start:i]); part != "" {
+			parts = append(parts, part)
+		}
+		start = i + 1
+	}
+	if part := strings.Trim(strings.TrimSpace(text[start:]), "."); part != "" {
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// splitAuthors splits an author list at commas, semicolons and "and",
+// keeping initials given after a surname, as in "Vaswani, A.", with it.
+func splitAuthors(list string) []string {
+	list = strings.ReplaceAll(list, " & ", ", ")
+	list = strings.ReplaceAll(list, ", and ", ", ")
+	list = strings.ReplaceAll(list, " and ", ", ")
+	var authors []string
+	for _, piece := range strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == ';' }) {
+		piece = strings.TrimSpace(piece)
+		switch {
+		case piece == "" || strings.EqualFold(strings.TrimSuffix(piece, "."), "et al"):
+		case initialsRe.MatchString(piece) && len(authors) > 0 && !strings.Contains(authors[len(authors)-1], ","):
+			authors[len(authors)-1] += ", " + piece
+		default:
+			authors = append(authors, piece)
+		}
+	}
+	return authors
+}
+
+// venue cleans up the part of a reference after its title: the leading "In"
+// of proceedings, and the year, pages, links and identifiers in it.
+func venue(text string) string {
+	text = strings.TrimPrefix(strings.TrimSpace(text), "In ")
+	text = doiRe.ReplaceAllString(text, "")
+	text = urlRe.ReplaceAllString(text, "")
+	for _, id := range arxivid.Find(text) {
+		text = strings.ReplaceAll(text, id.String(), "")
+	}
+	if i := strings.Index(strings.ToLower(text), "arxiv:"); i >= 0 {
+		text = text[:i]
+	}
+	text = parenYearRe.ReplaceAllString(text, "")
+	text = yearRe.ReplaceAllString(text, "")
+	text = pagesRe.ReplaceAllString(text, "")
+	for prev := ""; prev != text; {
+		prev = text
+		text = emptyRe.ReplaceAllStringFunc(text, func(s string) string {
+			if strings.Contains(s, ",") || strings.Contains(s, ";") {
+				return ", "
+			}
+			return ""
+		})
+	}
+	return strings.Trim(textclean.OneLine(text), " ,.;:")
+}
+
+// findDOI returns the first DOI in text, without trailing punctuation.
+func findDOI(text string) string {
+	return strings.TrimRight(doiRe.FindString(text), ".,;)")
+}
+
+// arxivDOIID returns the arXiv ID of an arXiv DOI, or an empty string for
+// any other DOI.
+func arxivDOIID(doi string) string {
+	if doi == "" {
+		return ""
+	}
+	if id, err := arxivid.Parse(doi); err == nil {
+		return id.String()
+	}
+	return ""
+}
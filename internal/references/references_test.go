@@ -0,0 +1,163 @@
+package references
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected Reference
+	}{
+		{
+			name: "numbered with arXiv ID",
+			text: "[12] D. P. Kingma and J. Ba. Adam: A method for stochastic optimization. arXiv preprint arXiv:1412.6980, 2014.",
+			expected: Reference{
+				Text:    "D. P. Kingma and J. Ba. Adam: A method for stochastic optimization. arXiv preprint arXiv:1412.6980, 2014.",
+				Authors: []string{"D. P. Kingma", "J. Ba"},
+				Title:   "Adam: A method for stochastic optimization",
+				Year:    "2014",
+				Venue:   "arXiv preprint",
+				ArxivID: "1412.6980",
+			},
+		},
+		{
+			name: "proceedings with DOI",
+			text: "Kaiming He, Xiangyu Zhang, Shaoqing Ren, and Jian Sun. Deep residual learning for image recognition. In CVPR, pages 770–778, 2016. doi:10.1109/CVPR.2016.90.",
+			expected: Reference{
+				Text:    "Kaiming He, Xiangyu Zhang, Shaoqing Ren, and Jian Sun. Deep residual learning for image recognition. In CVPR, pages 770–778, 2016. doi:10.1109/CVPR.2016.90.",
+				Authors: []string{"Kaiming He", "Xiangyu Zhang", "Shaoqing Ren", "Jian Sun"},
+				Title:   "Deep residual learning for image recognition",
+				Year:    "2016",
+				Venue:   "CVPR",
+				DOI:     "10.1109/CVPR.2016.90",
+			},
+		},
+		{
+			name: "author year",
+			text: "Hochreiter, S., & Schmidhuber, J. (1997). Long short-term memory. Neural Computation, 9(8).",
+			expected: Reference{
+				Text:    "Hochreiter, S., & Schmidhuber, J. (1997). Long short-term memory. Neural Computation, 9(8).",
+				Authors: []string{"Hochreiter, S.", "Schmidhuber, J."},
+				Title:   "Long short-term memory",
+				Year:    "1997",
+				Venue:   "Neural Computation, 9(8)",
+			},
+		},
+		{
+			name: "quoted title",
+			text: `J. Maldacena, "The large N limit of superconformal field theories and supergravity," Adv. Theor. Math. Phys. 2, 231 (1998), hep-th/9711200.`,
+			expected: Reference{
+				Text:    `J. Maldacena, "The large N limit of superconformal field theories and supergravity," Adv. Theor. Math. Phys. 2, 231 (1998), hep-th/9711200.`,
+				Authors: []string{"J. Maldacena"},
+				Title:   "The large N limit of superconformal field theories and supergravity",
+				Year:    "1998",
+				Venue:   "Adv. Theor. Math. Phys. 2, 231",
+				ArxivID: "hep-th/9711200",
+			},
+		},
+		{
+			name: "arXiv DOI only",
+			text: "A. Gu and T. Dao. Mamba. 2023. https://doi.org/10.48550/arXiv.2312.00752",
+			expected: Reference{
+				Text:    "A. Gu and T. Dao. Mamba. 2023. https://doi.org/10.48550/arXiv.2312.00752",
+				Authors: []string{"A. Gu", "T. Dao"},
+				Title:   "Mamba",
+				Year:    "2023",
+				ArxivID: "2312.00752",
+				DOI:     "10.48550/arXiv.2312.00752",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ref := Parse(tt.text); !reflect.DeepEqual(ref, tt.expected) {
+				t.Errorf("expected\n%+v\ngot\n%+v", tt.expected, ref)
+			}
+		})
+	}
+}
+
+func TestFromBibTeX(t *testing.T) {
+	ref := FromBibTeX("vaswani2017", map[string]string{
+		"type":          "inproceedings",
+		"title":         "Attention Is {All} You Need",
+		"author":        `Vaswani, Ashish and Shazeer, Noam and {Google Brain and Friends} and others`,
+		"booktitle":     `Advances in Neural Information Processing Systems`,
+		"year":          "2017",
+		"eprint":        "1706.03762",
+		"archiveprefix": "arXiv",
+	})
+	expected := Reference{
+		Key:     "vaswani2017",
+		Text:    "Vaswani, Ashish, Shazeer, Noam, Google Brain and Friends. Attention Is All You Need. Advances in Neural Information Processing Systems. 2017",
+		Authors: []string{"Vaswani, Ashish", "Shazeer, Noam", "Google Brain and Friends"},
+		Title:   "Attention Is All You Need",
+		Year:    "2017",
+		Venue:   "Advances in Neural Information Processing Systems",
+		ArxivID: "1706.03762",
+	}
+	if !reflect.DeepEqual(ref, expected) {
+		t.Errorf("expected\n%+v\ngot\n%+v", expected, ref)
+	}
+
+	if ref := FromBibTeX("he2016", map[string]string{"doi": "10.1109/CVPR.2016.90"}); ref.DOI != "10.1109/CVPR.2016.90" || ref.ArxivID != "" {
+		t.Errorf("expected a DOI and no arXiv ID, got %+v", ref)
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		tex      string
+		expected string
+	}{
+		{tex: `S.~Hochreiter and J.~Schmidhuber.\newblock {\em Long short-term memory}.`, expected: "S. Hochreiter and J. Schmidhuber. Long short-term memory."},
+		{tex: `G.~G\"{o}del and P.~Erd\H{o}s and J.~{\v C}ech`, expected: "G. Godel and P. Erdos and J. Cech"},
+		{tex: `\emph{Nature}, 521:436--444, 2015. \url{https://example.com/x_y}`, expected: "Nature, 521:436–444, 2015. https://example.com/x_y"},
+		{tex: `\href{https://arxiv.org/abs/1706.03762}{arXiv:1706.03762} \& more`, expected: "arXiv:1706.03762 & more"},
+		{tex: "plain  text", expected: "plain text"},
+	}
+	for _, tt := range tests {
+		if text := PlainText(tt.tex); text != tt.expected {
+			t.Errorf("PlainText(%q): expected %q, got %q", tt.tex, tt.expected, text)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{
+			name:     "bracket labels",
+			text:     "[1] A. Author. A title that is long and wraps onto the next\nline. 2001.\n[2] B. Author. Opti-\nmization. 2002.\n",
+			expected: []string{"[1] A. Author. A title that is long and wraps onto the next line. 2001.", "[2] B. Author. Optimization. 2002."},
+		},
+		{
+			name:     "numbered labels",
+			text:     "1. A. Author. Chapter 3. Some book. 2001.\n2. B. Author. Another. 2002.",
+			expected: []string{"1. A. Author. Chapter 3. Some book. 2001.", "2. B. Author. Another. 2002."},
+		},
+		{
+			name:     "paragraphs",
+			text:     "Author, A. (2001). First.\nJournal.\n\nAuthor, B. (2002). Second.",
+			expected: []string{"Author, A. (2001). First. Journal.", "Author, B. (2002). Second."},
+		},
+		{
+			name:     "lines",
+			text:     "Author, A. (2001). First title\ncontinued.\nAuthor, B. (2002). Second.",
+			expected: []string{"Author, A. (2001). First title continued.", "Author, B. (2002). Second."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if entries := Split(tt.text); !reflect.DeepEqual(entries, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, entries)
+			}
+		})
+	}
+}
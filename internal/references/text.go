@@ -0,0 +1,143 @@
+package references
+
+import (
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/textclean"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	// accentRe matches accent commands such as \"o, \'{e} and {\v c}, which
+	// are replaced by the bare letter. Accents named by a letter need a space
+	// or brace after them, so that \url is not read as \u on r.
+	accentRe = regexp.MustCompile(`\{?\\(?:['"^` + "`" + `~=.]\s*\{?|[uvHck](?:\s+|\{))\\?([A-Za-z])\}?\}?`)
+	// keepArgRe matches formatting commands whose argument is kept. For \href
+	// the link text is kept.
+	keepArgRe = regexp.MustCompile(`\\(?:emph|textit|textbf|textsc|textrm|textsf|texttt|textup|textnormal|mbox|hbox|url|path|doi|nolinkurl|bibinfo\{[^{}]*\}|bibfield\{[^{}]*\}|bibnamefont|bibfnamefont|citenamefont|href\{[^{}]*\})\s*\{([^{}]*)\}`)
+	// fontSwitchRe matches declarations such as {\em ...} and {\bf ...}.
+	fontSwitchRe = regexp.MustCompile(`\\(?:em|it|bf|sc|rm|sf|tt|sl|small|footnotesize)\b\s*`)
+	commandRe    = regexp.MustCompile(`\\[A-Za-z@]+\*?(?:\[[^\]]*\])?`)
+)
+
+// PlainText turns a fragment of LaTeX, such as a .bbl entry or a BibTeX
+// field, into plain text. Formatting commands are dropped but their text is
+// kept, accented letters lose their accents, and any other command is
+// removed.
+func PlainText(tex string) string {
+	if !strings.ContainsAny(tex, `\{}~$`) {
+		return textclean.OneLine(tex)
+	}
+	s := strings.NewReplacer(
+		`\\`, " ", `\newblock`, " ",
+		`\&`, "&", `\%`, "%", `\$`, "$", `\#`, "#", `\_`, "_",
+		`\ `, " ", `\,`, " ", `\/`, "", `\-`, "",
+		`---`, "—", `--`, "–", "``", `"`, "''", `"`, "~", " ",
+	).Replace(tex)
+	s = accentRe.ReplaceAllString(s, "$1")
+	for prev := ""; prev != s; {
+		prev = s
+		s = keepArgRe.ReplaceAllString(s, "$1")
+	}
+	s = fontSwitchRe.ReplaceAllString(s, "")
+	s = commandRe.ReplaceAllString(s, "")
+	s = strings.NewReplacer("{", "", "}", "", "$", "").Replace(s)
+	return textclean.OneLine(s)
+}
+
+var (
+	bracketLabelRe = regexp.MustCompile(`(?m)^\s*\[\d{1,4}\]\s`)
+	numberLabelRe  = regexp.MustCompile(`(?m)^\s*(\d{1,4})\.\s`)
+	blankLineRe    = regexp.MustCompile(`\n\s*\n`)
+)
+
+// Split splits the text of a reference list, as extracted from a PDF, into
+// entries. Entries labelled [1], [2], ... or 1., 2., ... at the start of a
+// line are split at their labels. Otherwise each paragraph is an entry, or,
+// if the list has no blank lines, each line that starts with a capital
+// letter after a line ending in a full stop starts one.
+func Split(text string) []string {
+	var entries []string
+	add := func(entry string) {
+		if entry = joinLines(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+
+	if starts := bracketLabelRe.FindAllStringIndex(text, -1); len(starts) >= 2 {
+		for i, start := range starts {
+			end := len(text)
+			if i+1 < len(starts) {
+				end = starts[i+1][0]
+			}
+			add(text[start[0]:end])
+		}
+		return entries
+	}
+	if starts := numberedStarts(text); len(starts) >= 2 {
+		for i, start := range starts {
+			end := len(text)
+			if i+1 < len(starts) {
+				end = starts[i+1]
+			}
+			add(text[start:end])
+		}
+		return entries
+	}
+
+	paragraphs := blankLineRe.Split(text, -1)
+	if len(paragraphs) > 1 {
+		for _, p := range paragraphs {
+			add(p)
+		}
+		return entries
+	}
+	var entry strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		prev := strings.TrimSpace(entry.String())
+		if prev != "" && strings.HasSuffix(prev, ".") && line != "" && unicode.IsUpper([]rune(line)[0]) {
+			add(entry.String())
+			entry.Reset()
+		}
+		entry.WriteString(line + "\n")
+	}
+	add(entry.String())
+	return entries
+}
+
+// numberedStarts returns where entries labelled 1., 2., ... start, following
+// the numbering so that numbers within entries are not taken as labels.
+func numberedStarts(text string) []int {
+	var starts []int
+	next := 1
+	for _, m := range numberLabelRe.FindAllStringSubmatchIndex(text, -1) {
+		if text[m[2]:m[3]] == strconv.Itoa(next) {
+			starts = append(starts, m[0])
+			next++
+		}
+	}
+	return starts
+}
+
+// joinLines joins the lines of an entry, rejoining words hyphenated at line
+// ends.
+func joinLines(entry string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(entry, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		s := b.String()
+		if strings.HasSuffix(s, "-") && len(s) > 1 && unicode.IsLower(rune(s[len(s)-2])) && unicode.IsLower([]rune(line)[0]) {
+			b.Reset()
+			b.WriteString(strings.TrimSuffix(s, "-"))
+		} else if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
@@ -2,8 +2,8 @@ package resources
 
 import (
 	"context"
-	_ "embed"
 
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/taxonomy"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -14,16 +14,13 @@ var TaxonomyResource = mcp.Resource{
 	URI:         "file://arxiv/taxonomy.json",
 }
 
-//go:embed arxiv-taxonomy.json
-var taxonomyData string
-
 func TaxonomyResourceHandler(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
 	return &mcp.ReadResourceResult{
 		Contents: []*mcp.ResourceContents{
 			{
 				URI:      req.Params.URI,
 				MIMEType: "application/json",
-				Text:     taxonomyData,
+				Text:     string(taxonomy.JSON()),
 			},
 		},
 	}, nil
@@ -8,9 +8,19 @@ import (
 	"github.com/Epistemic-Technology/arxiv-mcp/internal/tools"
 )
 
-func CreateServer() *mcp.Server {
+func CreateServer(searcher tools.Searcher, downloader tools.Downloader, lister tools.Lister) *mcp.Server {
 	server := mcp.NewServer(&mcp.Implementation{Name: "arxiv-mcp", Version: "v0.0.1"}, nil)
-	mcp.AddTool(server, tools.SearchTool(), tools.SearchHandler)
+	mcp.AddTool(server, tools.SearchTool(), tools.SearchHandler(searcher))
+	mcp.AddTool(server, tools.GetPaperTool(), tools.GetPaperHandler(searcher))
+	mcp.AddTool(server, tools.NewListingsTool(), tools.NewListingsHandler(lister))
+	mcp.AddTool(server, tools.CiteTool(), tools.CiteHandler(searcher))
+	mcp.AddTool(server, tools.VersionsTool(), tools.VersionsHandler(searcher))
+	mcp.AddTool(server, tools.DiffTool(), tools.DiffHandler(searcher))
+	mcp.AddTool(server, tools.ReadPaperTool(), tools.ReadPaperHandler(downloader))
+	mcp.AddTool(server, tools.ReadSourceTool(), tools.ReadSourceHandler(downloader))
+	mcp.AddTool(server, tools.ReferencesTool(), tools.ReferencesHandler(searcher, downloader))
+	mcp.AddTool(server, tools.RelatedTool(), tools.RelatedHandler(searcher))
+	mcp.AddTool(server, tools.ResolveCategoryTool(), tools.ResolveCategoryHandler)
 	server.AddResource(&resources.TaxonomyResource, resources.TaxonomyResourceHandler)
 	server.AddPrompt(&prompts.CategoryPrompt, prompts.CategoryPromptHandler)
 	return server
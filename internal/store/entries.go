@@ -0,0 +1,193 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivid"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/atomicfile"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/oaipmh"
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// entriesFile holds entries from arXiv API responses, one JSON object a line.
+const entriesFile = "entries.jsonl"
+
+// AppendEntries adds entries from arXiv API responses to the store.
+func (s *Store) AppendEntries(entries []arxiv.EntryMetadata) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	var b bytes.Buffer
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+	return appendFile(filepath.Join(s.dir, entriesFile), b.Bytes())
+}
+
+// Entries returns the metadata of every paper in the store, from both
+// harvested records and API entries, ordered by ID. When a paper has more
+// than one, the most recently updated wins, and of those an API entry is
+// preferred to an arXivRaw record and an arXivRaw record to an arXiv one.
+func (s *Store) Entries() ([]arxiv.EntryMetadata, error) {
+	latest := make(map[string]arxiv.EntryMetadata)
+	for _, format := range []string{oaipmh.FormatArXiv, oaipmh.FormatArXivRaw} {
+		records, err := s.Records(format)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			keepLatest(latest, recordEntry(record))
+		}
+	}
+	apiEntries, err := s.apiEntries()
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range apiEntries {
+		keepLatest(latest, entry)
+	}
+	return sortedEntries(latest), nil
+}
+
+// CompactEntries rewrites the entries from API responses with only the
+// latest entry of each paper, if any paper has more than one.
+func (s *Store) CompactEntries() error {
+	entries, err := s.apiEntries()
+	if err != nil {
+		return err
+	}
+	latest := make(map[string]arxiv.EntryMetadata)
+	for _, entry := range entries {
+		keepLatest(latest, entry)
+	}
+	if len(latest) == len(entries) {
+		return nil
+	}
+	var b bytes.Buffer
+	for _, entry := range sortedEntries(latest) {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+	return atomicfile.Write(filepath.Join(s.dir, entriesFile), b.Bytes())
+}
+
+// keepLatest adds entry to latest, by base ID, unless latest has an entry for
+// the paper updated after it. Entries without an arXiv ID are left out.
+func keepLatest(latest map[string]arxiv.EntryMetadata, entry arxiv.EntryMetadata) {
+	id, err := arxivid.Parse(entry.ID)
+	if err != nil {
+		return
+	}
+	if have, ok := latest[id.Base]; ok && have.Updated.After(entry.Updated) {
+		return
+	}
+	latest[id.Base] = entry
+}
+
+func sortedEntries(latest map[string]arxiv.EntryMetadata) []arxiv.EntryMetadata {
+	entries := make([]arxiv.EntryMetadata, 0, len(latest))
+	for _, entry := range latest {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries
+}
+
+// apiEntries reads the entries from API responses, skipping a partial last
+// line.
+func (s *Store) apiEntries() ([]arxiv.EntryMetadata, error) {
+	path := filepath.Join(s.dir, entriesFile)
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []arxiv.EntryMetadata
+	r := bufio.NewReader(f)
+	for n := 1; ; n++ {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var entry arxiv.EntryMetadata
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path, n, err)
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// recordEntry returns a harvested record as the API would give it. arXiv
+// records do not say which version is the latest, so their IDs have none.
+func recordEntry(record oaipmh.Record) arxiv.EntryMetadata {
+	id := record.ID
+	entry := arxiv.EntryMetadata{
+		Title:            record.Title,
+		Summary:          record.Abstract,
+		Comment:          record.Comments,
+		JournalReference: record.JournalRef,
+		DOI:              record.DOI,
+	}
+	if n := len(record.Versions); n > 0 {
+		id += record.Versions[n-1].Version
+		entry.Published = record.Versions[0].Date
+		entry.Updated = record.Versions[n-1].Date
+	} else {
+		entry.Published = parseDate(record.Created)
+		entry.Updated = entry.Published
+		if record.Updated != "" {
+			entry.Updated = parseDate(record.Updated)
+		}
+	}
+	entry.ID = "http://arxiv.org/abs/" + id
+	entry.AbstractUrl = entry.ID
+	entry.PDFUrl = "http://arxiv.org/pdf/" + id
+	entry.Links = []arxiv.Link{
+		{Href: entry.AbstractUrl, Rel: "alternate", Type: "text/html"},
+		{Href: entry.PDFUrl, Rel: "related", Type: "application/pdf", Title: "pdf"},
+	}
+	for _, name := range record.Authors {
+		entry.Authors = append(entry.Authors, arxiv.Author{Name: name})
+	}
+	for _, category := range record.Categories {
+		entry.Categories = append(entry.Categories, arxiv.Category{Term: category})
+	}
+	if len(entry.Categories) > 0 {
+		entry.PrimaryCategory = entry.Categories[0]
+	}
+	return entry
+}
+
+func parseDate(date string) time.Time {
+	t, _ := time.Parse(oaipmh.DateLayout, date)
+	return t
+}
@@ -0,0 +1,128 @@
+package store
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/oaipmh"
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+func TestEntries(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	v1 := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	v2 := time.Date(2024, 2, 5, 9, 30, 0, 0, time.UTC)
+	appendRecords(t, s, oaipmh.FormatArXiv,
+		oaipmh.Record{ID: "2401.00001", Title: "From arXiv", Authors: []string{"Ada Lovelace"}, Categories: []string{"cs.LG", "stat.ML"}, Created: "2024-01-02", Updated: "2024-02-05"},
+		oaipmh.Record{ID: "2401.00002", Title: "Only in arXiv", Created: "2024-01-03"},
+	)
+	appendRecords(t, s, oaipmh.FormatArXivRaw,
+		oaipmh.Record{ID: "2401.00001", Title: "From arXivRaw", Versions: []oaipmh.Version{{Version: "v1", Date: v1}, {Version: "v2", Date: v2}}},
+		oaipmh.Record{ID: "2401.00003", Title: "Only in arXivRaw", Versions: []oaipmh.Version{{Version: "v1", Date: v1}}},
+	)
+	if err := s.AppendEntries([]arxiv.EntryMetadata{
+		{ID: "http://arxiv.org/abs/2401.00003v1", Title: "From the API", Updated: v1},
+		{ID: "http://arxiv.org/abs/2401.00002v1", Title: "Older API entry", Updated: v1},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries, err := s.Entries()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 papers, got %+v", entries)
+	}
+	tests := []struct {
+		id, title string
+		updated   time.Time
+	}{
+		// The arXivRaw record has the exact time of the update.
+		{"http://arxiv.org/abs/2401.00001v2", "From arXivRaw", v2},
+		{"http://arxiv.org/abs/2401.00002", "Only in arXiv", time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)},
+		// An API entry wins over a record updated at the same time.
+		{"http://arxiv.org/abs/2401.00003v1", "From the API", v1},
+	}
+	for i, tt := range tests {
+		if entries[i].ID != tt.id || entries[i].Title != tt.title || !entries[i].Updated.Equal(tt.updated) {
+			t.Errorf("expected %s %q updated %v, got %s %q updated %v", tt.id, tt.title, tt.updated, entries[i].ID, entries[i].Title, entries[i].Updated)
+		}
+	}
+}
+
+func TestCompactEntries(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	v1 := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	v2 := time.Date(2024, 2, 5, 9, 30, 0, 0, time.UTC)
+	for _, entries := range [][]arxiv.EntryMetadata{
+		{{ID: "http://arxiv.org/abs/2401.00001v1", Updated: v1}, {ID: "http://arxiv.org/abs/2401.00002v1", Updated: v1}},
+		{{ID: "http://arxiv.org/abs/2401.00001v2", Updated: v2}},
+		{{ID: "http://arxiv.org/abs/2401.00001v1", Updated: v1}},
+	} {
+		if err := s.AppendEntries(entries); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	before, err := s.Entries()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := s.CompactEntries(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(s.Dir(), entriesFile))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lines := bytes.Count(data, []byte("\n")); lines != 2 {
+		t.Errorf("expected 2 lines after compacting, got %d", lines)
+	}
+	after, err := s.Entries()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(after) != 2 || after[0].ID != "http://arxiv.org/abs/2401.00001v2" || len(before) != len(after) {
+		t.Errorf("expected compacting to keep %+v, got %+v", before, after)
+	}
+}
+
+func TestRecordEntry(t *testing.T) {
+	entry := recordEntry(oaipmh.Record{
+		ID:         "hep-th/9711200",
+		Title:      "The Large N Limit",
+		Authors:    []string{"Juan M. Maldacena"},
+		Abstract:   "We show that...",
+		Categories: []string{"hep-th", "gr-qc"},
+		Comments:   "20 pages",
+		JournalRef: "Adv.Theor.Math.Phys.2:231-252,1998",
+		DOI:        "10.1023/A:1026654312961",
+		Created:    "1997-11-27",
+		Updated:    "1998-01-22",
+	})
+	if entry.ID != "http://arxiv.org/abs/hep-th/9711200" || entry.PDFUrl != "http://arxiv.org/pdf/hep-th/9711200" {
+		t.Errorf("unexpected ID %s or PDF URL %s", entry.ID, entry.PDFUrl)
+	}
+	if !entry.Published.Equal(time.Date(1997, 11, 27, 0, 0, 0, 0, time.UTC)) || !entry.Updated.Equal(time.Date(1998, 1, 22, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected dates %v and %v", entry.Published, entry.Updated)
+	}
+	if len(entry.Authors) != 1 || entry.Authors[0].Name != "Juan M. Maldacena" {
+		t.Errorf("unexpected authors %+v", entry.Authors)
+	}
+	if len(entry.Categories) != 2 || entry.PrimaryCategory.Term != "hep-th" {
+		t.Errorf("unexpected categories %+v and primary category %+v", entry.Categories, entry.PrimaryCategory)
+	}
+	if entry.Summary != "We show that..." || entry.Comment != "20 pages" || entry.JournalReference == "" || entry.DOI == "" {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+}
@@ -0,0 +1,224 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/atomicfile"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/oaipmh"
+)
+
+// Lister lists records from an OAI-PMH interface a page at a time, as
+// arxivclient.Client does.
+type Lister interface {
+	ListRecords(ctx context.Context, req oaipmh.Request) (oaipmh.Page, error)
+}
+
+// Checkpoint is the progress of the harvests of a metadata format and set.
+type Checkpoint struct {
+	Format          string    `json:"format"`
+	Set             string    `json:"set,omitempty"`
+	From            string    `json:"from,omitempty"`             // from date of the latest harvest
+	Until           string    `json:"until,omitempty"`            // until date of the latest harvest
+	ResumptionToken string    `json:"resumption_token,omitempty"` // asks for the next page of an unfinished harvest
+	Records         int       `json:"records"`                    // records stored by the latest harvest
+	Total           int       `json:"total,omitempty"`            // records in the latest harvest's list, if the interface said
+	Started         time.Time `json:"started,omitzero"`           // when the interface answered the first request
+	Finished        time.Time `json:"finished,omitzero"`          // when the interface answered the last request; zero if unfinished
+	Next            string    `json:"next,omitempty"`             // from date for the next harvest, once one has finished
+	Range           bool      `json:"range,omitempty"`            // whether the harvest was of a date range, kept apart from the incremental harvests
+}
+
+// Unfinished reports whether the latest harvest stopped before its last page.
+func (c Checkpoint) Unfinished() bool {
+	return c.ResumptionToken != ""
+}
+
+// HarvestOptions select what a harvest lists.
+type HarvestOptions struct {
+	Format string // oaipmh.FormatArXiv or oaipmh.FormatArXivRaw
+	Set    string // set spec, such as cs or physics:hep-th; empty for every paper
+	// From and Until limit the harvest to records changed between the two
+	// dates. Without either, an unfinished harvest is resumed, and otherwise
+	// records changed since the last finished harvest are listed. A harvest
+	// from an earlier date than that, without Until, is the same as one
+	// without dates; any other range is harvested apart, with its own
+	// checkpoint, and leaves the next incremental harvest where it was.
+	From, Until string
+	// Progress, if set, is called with the checkpoint after each page is
+	// stored.
+	Progress func(Checkpoint)
+}
+
+// HarvestCheckpoint returns the checkpoint a harvest with opts starts from:
+// that of an unfinished harvest it resumes, or a new one.
+func (s *Store) HarvestCheckpoint(opts HarvestOptions) (Checkpoint, error) {
+	cp, err := s.Checkpoint(opts.Format, opts.Set)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	if opts.Until == "" && (opts.From == "" || cp.Next != "" && opts.From <= cp.Next) {
+		if cp.Unfinished() && opts.From == "" {
+			return cp, nil
+		}
+		from := opts.From
+		if from == "" {
+			from = cp.Next
+		}
+		return Checkpoint{Format: opts.Format, Set: opts.Set, From: from, Next: cp.Next}, nil
+	}
+
+	fresh := Checkpoint{Format: opts.Format, Set: opts.Set, From: opts.From, Until: opts.Until, Range: true}
+	checkpoints, err := s.checkpoints()
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	if cp, ok := checkpoints[fresh.key()]; ok && cp.Unfinished() {
+		return cp, nil
+	}
+	return fresh, nil
+}
+
+// maxRestarts is how many times a harvest is started again after its
+// resumption token expires before it gives up.
+const maxRestarts = 3
+
+// Harvest lists records from lister into the store, saving a checkpoint
+// after each page, and returns the final checkpoint.
+func (s *Store) Harvest(ctx context.Context, lister Lister, opts HarvestOptions) (Checkpoint, error) {
+	cp, err := s.HarvestCheckpoint(opts)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	fresh := func() oaipmh.Request {
+		return oaipmh.Request{MetadataPrefix: cp.Format, Set: cp.Set, From: cp.From, Until: cp.Until}
+	}
+	req := fresh()
+	if cp.Unfinished() {
+		req = oaipmh.Request{ResumptionToken: cp.ResumptionToken}
+	}
+
+	restarts := 0
+	for {
+		page, err := lister.ListRecords(ctx, req)
+		var oaiErr *oaipmh.Error
+		if errors.As(err, &oaiErr) && oaiErr.Code == oaipmh.BadResumptionToken && req.ResumptionToken != "" {
+			// Tokens expire. The list is started again, and the records
+			// already stored are stored again, unless the tokens expire
+			// too often for a harvest to finish.
+			if restarts == maxRestarts {
+				return cp, fmt.Errorf("the harvest was restarted %d times after resumption tokens expired: %w", restarts, err)
+			}
+			restarts++
+			cp.Records, cp.Started = 0, time.Time{}
+			req = fresh()
+			continue
+		}
+		if err != nil {
+			return cp, err
+		}
+		if err := s.Append(cp.Format, page.Records); err != nil {
+			return cp, err
+		}
+
+		if cp.Started.IsZero() {
+			cp.Started = responseDate(page)
+		}
+		cp.Records += len(page.Records)
+		if page.CompleteListSize > 0 {
+			cp.Total = page.CompleteListSize
+		}
+		cp.ResumptionToken = page.ResumptionToken
+		if !cp.Unfinished() {
+			cp.Finished = responseDate(page)
+			if !cp.Range {
+				// Records changed during the harvest may not be in it, so
+				// the next one starts from the day it started.
+				cp.Next = cp.Started.UTC().Format(oaipmh.DateLayout)
+			}
+		}
+		if err := s.SaveCheckpoint(cp); err != nil {
+			return cp, err
+		}
+		if opts.Progress != nil {
+			opts.Progress(cp)
+		}
+		if !cp.Unfinished() {
+			return cp, nil
+		}
+		req = oaipmh.Request{ResumptionToken: cp.ResumptionToken}
+	}
+}
+
+func responseDate(page oaipmh.Page) time.Time {
+	if page.ResponseDate.IsZero() {
+		return time.Now().UTC()
+	}
+	return page.ResponseDate
+}
+
+func (s *Store) checkpointsPath() string {
+	return filepath.Join(s.dir, "checkpoints.json")
+}
+
+// key returns the key c is saved under: its format and set, and its dates
+// if it is of a date range.
+func (c Checkpoint) key() string {
+	key := c.Format
+	if c.Set != "" {
+		key += " " + c.Set
+	}
+	if c.Range {
+		key += " " + c.From + ".." + c.Until
+	}
+	return key
+}
+
+func (s *Store) checkpoints() (map[string]Checkpoint, error) {
+	checkpoints := make(map[string]Checkpoint)
+	data, err := os.ReadFile(s.checkpointsPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoints, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &checkpoints); err != nil {
+		return nil, err
+	}
+	return checkpoints, nil
+}
+
+// Checkpoint returns the checkpoint of the incremental harvests of a metadata
+// format and set, which is empty if there have been none.
+func (s *Store) Checkpoint(format, set string) (Checkpoint, error) {
+	checkpoints, err := s.checkpoints()
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	cp := Checkpoint{Format: format, Set: set}
+	if saved, ok := checkpoints[cp.key()]; ok {
+		return saved, nil
+	}
+	return cp, nil
+}
+
+// SaveCheckpoint saves cp, replacing the checkpoint of its format and set, or
+// of its date range.
+func (s *Store) SaveCheckpoint(cp Checkpoint) error {
+	checkpoints, err := s.checkpoints()
+	if err != nil {
+		return err
+	}
+	checkpoints[cp.key()] = cp
+	data, err := json.MarshalIndent(checkpoints, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.Write(s.checkpointsPath(), append(data, '\n'))
+}
@@ -0,0 +1,233 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivclient"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/fakearxiv"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/oaipmh"
+)
+
+func newTestClient(t *testing.T) *arxivclient.Client {
+	t.Helper()
+	s, err := fakearxiv.Load(fakearxiv.Fixtures())
+	if err != nil {
+		t.Fatalf("loading fixtures: %v", err)
+	}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	return arxivclient.New(arxivclient.Config{OAIURL: server.URL + "/oai"})
+}
+
+// failingLister fails after listing pages pages.
+type failingLister struct {
+	Lister
+	pages    int
+	requests []oaipmh.Request
+}
+
+func (l *failingLister) ListRecords(ctx context.Context, req oaipmh.Request) (oaipmh.Page, error) {
+	l.requests = append(l.requests, req)
+	if len(l.requests) > l.pages {
+		return oaipmh.Page{}, &arxivclient.UnavailableError{Status: "503 Service Unavailable"}
+	}
+	return l.Lister.ListRecords(ctx, req)
+}
+
+// expiringLister lists first pages, but rejects every resumption token as
+// expired.
+type expiringLister struct {
+	Lister
+	requests int
+}
+
+func (l *expiringLister) ListRecords(ctx context.Context, req oaipmh.Request) (oaipmh.Page, error) {
+	l.requests++
+	if req.ResumptionToken != "" {
+		return oaipmh.Page{}, &oaipmh.Error{Code: oaipmh.BadResumptionToken}
+	}
+	return l.Lister.ListRecords(ctx, req)
+}
+
+// The fake server lists 6 papers in cs, 4 to a page.
+var csOptions = HarvestOptions{Format: oaipmh.FormatArXiv, Set: "cs"}
+
+func TestHarvest(t *testing.T) {
+	ctx := context.Background()
+	today := time.Now().UTC().Format(oaipmh.DateLayout)
+
+	t.Run("full and incremental", func(t *testing.T) {
+		s, err := Open(t.TempDir())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		client := newTestClient(t)
+
+		var progress []int
+		opts := csOptions
+		opts.Progress = func(cp Checkpoint) { progress = append(progress, cp.Records) }
+		cp, err := s.Harvest(ctx, client, opts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cp.Records != 6 || cp.Total != 6 || cp.Unfinished() || cp.Finished.IsZero() {
+			t.Errorf("expected a finished harvest of 6 records, got %+v", cp)
+		}
+		if len(progress) != 2 || progress[0] != 4 || progress[1] != 6 {
+			t.Errorf("expected progress after each of 2 pages, got %v", progress)
+		}
+		if cp.Next != today {
+			t.Errorf("expected the next harvest from %s, got %q", today, cp.Next)
+		}
+		if records, err := s.Records(oaipmh.FormatArXiv); err != nil || len(records) != 6 {
+			t.Errorf("expected 6 stored records, got %d and %v", len(records), err)
+		}
+		if saved, err := s.Checkpoint(oaipmh.FormatArXiv, "cs"); err != nil || saved.Next != cp.Next {
+			t.Errorf("expected the checkpoint to be saved, got %+v and %v", saved, err)
+		}
+
+		cp, err = s.Harvest(ctx, client, csOptions)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cp.From != today || cp.Records != 0 {
+			t.Errorf("expected an incremental harvest from %s with no records, got %+v", today, cp)
+		}
+	})
+
+	t.Run("resumes an interrupted harvest", func(t *testing.T) {
+		s, err := Open(t.TempDir())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		client := newTestClient(t)
+
+		lister := &failingLister{Lister: client, pages: 1}
+		cp, err := s.Harvest(ctx, lister, csOptions)
+		var unavailable *arxivclient.UnavailableError
+		if !errors.As(err, &unavailable) {
+			t.Fatalf("expected the failure to be returned, got %v", err)
+		}
+		if !cp.Unfinished() || cp.Records != 4 {
+			t.Errorf("expected an unfinished harvest of 4 records, got %+v", cp)
+		}
+
+		lister = &failingLister{Lister: client, pages: 10}
+		cp, err = s.Harvest(ctx, lister, csOptions)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(lister.requests) != 1 || lister.requests[0].ResumptionToken == "" {
+			t.Errorf("expected one request with the saved resumption token, got %+v", lister.requests)
+		}
+		if cp.Records != 6 || cp.Unfinished() {
+			t.Errorf("expected a finished harvest of 6 records, got %+v", cp)
+		}
+		if records, err := s.Records(oaipmh.FormatArXiv); err != nil || len(records) != 6 {
+			t.Errorf("expected 6 stored records, got %d and %v", len(records), err)
+		}
+	})
+
+	t.Run("restarts after an expired resumption token", func(t *testing.T) {
+		s, err := Open(t.TempDir())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		err = s.SaveCheckpoint(Checkpoint{Format: oaipmh.FormatArXiv, Set: "cs", From: "2017-01-01", ResumptionToken: "expired", Records: 2})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		cp, err := s.Harvest(ctx, newTestClient(t), csOptions)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// 1412.6980, 1706.03762, 1810.04805, 2006.11239 and 2312.00752 were
+		// updated from 2017.
+		if cp.Records != 5 || cp.From != "2017-01-01" || cp.Unfinished() {
+			t.Errorf("expected the harvest from 2017-01-01 to be started again, got %+v", cp)
+		}
+	})
+
+	t.Run("gives up when resumption tokens keep expiring", func(t *testing.T) {
+		s, err := Open(t.TempDir())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		lister := &expiringLister{Lister: newTestClient(t)}
+		cp, err := s.Harvest(ctx, lister, csOptions)
+		var oaiErr *oaipmh.Error
+		if !errors.As(err, &oaiErr) || oaiErr.Code != oaipmh.BadResumptionToken {
+			t.Fatalf("expected the expired token to be returned, got %v", err)
+		}
+		// Each start lists a first page and has its token rejected.
+		if lister.requests != 2*(maxRestarts+1) {
+			t.Errorf("expected %d requests, got %d", 2*(maxRestarts+1), lister.requests)
+		}
+		if !cp.Unfinished() || cp.Records != 4 {
+			t.Errorf("expected an unfinished harvest of 4 records, got %+v", cp)
+		}
+	})
+
+	t.Run("date ranges keep their own checkpoint", func(t *testing.T) {
+		s, err := Open(t.TempDir())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		incremental := Checkpoint{Format: oaipmh.FormatArXiv, Set: "cs", From: "2024-01-01", ResumptionToken: "unfinished", Next: "2024-01-01"}
+		if err := s.SaveCheckpoint(incremental); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		opts := csOptions
+		opts.From, opts.Until = "2019-01-01", "2020-12-31"
+		lister := &failingLister{Lister: newTestClient(t), pages: 0}
+		if _, err := s.Harvest(ctx, lister, opts); err == nil {
+			t.Fatal("expected the failure to be returned")
+		}
+		cp, err := s.Harvest(ctx, newTestClient(t), opts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cp.Records != 2 || !cp.Range || cp.Next != "" {
+			t.Errorf("expected 1810.04805 and 2006.11239 in a range harvest, got %+v", cp)
+		}
+		// A later from date than the next incremental harvest's leaves out
+		// the records changed in between.
+		opts.From, opts.Until = "2025-01-01", ""
+		if cp, err := s.Harvest(ctx, newTestClient(t), opts); err != nil || !cp.Range || cp.Next != "" {
+			t.Errorf("expected a range harvest, got %+v and %v", cp, err)
+		}
+		if saved, err := s.Checkpoint(oaipmh.FormatArXiv, "cs"); err != nil || saved != incremental {
+			t.Errorf("expected the incremental checkpoint to be left alone, got %+v and %v", saved, err)
+		}
+		if other, err := s.Checkpoint(oaipmh.FormatArXivRaw, "cs"); err != nil || other.Records != 0 || other.Next != "" {
+			t.Errorf("expected no checkpoint for arXivRaw, got %+v and %v", other, err)
+		}
+	})
+
+	t.Run("an earlier from date is incremental", func(t *testing.T) {
+		s, err := Open(t.TempDir())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := s.SaveCheckpoint(Checkpoint{Format: oaipmh.FormatArXiv, Set: "cs", From: "2020-01-01", Next: "2020-01-01"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		opts := csOptions
+		opts.From = "2017-01-01"
+		cp, err := s.Harvest(ctx, newTestClient(t), opts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cp.Records != 5 || cp.Range || cp.Next != today {
+			t.Errorf("expected an incremental harvest of 5 records from 2017-01-01, got %+v", cp)
+		}
+	})
+}
@@ -0,0 +1,197 @@
+// Package store keeps paper metadata harvested from arXiv's OAI-PMH
+// interface, and entries from arXiv API responses, on disk, for use without
+// arXiv.
+//
+// Records are appended to one JSON Lines file per metadata format, so that an
+// interrupted harvest loses no more than the page it was writing. A paper
+// harvested again is appended again and its last line wins; Compact rewrites
+// a file with only those lines. API entries are appended to a file of their
+// own in the same way, and CompactEntries rewrites it.
+//
+// Harvests save their progress in checkpoints, one per format and set, so that
+// an interrupted harvest resumes where it stopped and a finished one is
+// followed by harvesting only the records changed since.
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/atomicfile"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/oaipmh"
+)
+
+// Store is a directory of harvested records. A store should be written by one
+// harvest at a time.
+type Store struct {
+	dir string
+}
+
+// Open opens the store in dir, creating the directory if it does not exist.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir}, nil
+}
+
+// DirFromEnv returns the store directory given by ARXIV_STORE_DIR, or the
+// empty string if it is unset or "off". A store is only kept when asked for,
+// since a harvested one can hold millions of papers.
+func DirFromEnv() string {
+	if dir := os.Getenv("ARXIV_STORE_DIR"); dir != "off" {
+		return dir
+	}
+	return ""
+}
+
+// Dir returns the store's directory.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+func (s *Store) path(format string) string {
+	return filepath.Join(s.dir, format+".jsonl")
+}
+
+// Append adds records in the given metadata format to the store.
+func (s *Store) Append(format string, records []oaipmh.Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	var b bytes.Buffer
+	for _, record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+	return appendFile(s.path(format), b.Bytes())
+}
+
+// appendFile appends whole lines to the file at path and syncs it.
+func appendFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := trimPartialLine(f); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// trimPartialLine removes the partial last line left by a write cut short,
+// so that it does not run into the records written after it.
+func trimPartialLine(f *os.File) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	end := info.Size()
+	buf := make([]byte, 4096)
+	for end > 0 {
+		start := max(end-int64(len(buf)), 0)
+		n, err := f.ReadAt(buf[:end-start], start)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			end = start + int64(i) + 1
+			break
+		}
+		end = start
+	}
+	if end == info.Size() {
+		return nil
+	}
+	return f.Truncate(end)
+}
+
+// Records returns the latest record of every paper in the given metadata
+// format, ordered by ID. Papers whose latest record is deleted are left out.
+func (s *Store) Records(format string) ([]oaipmh.Record, error) {
+	latest, err := s.latest(format)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]oaipmh.Record, 0, len(latest))
+	for _, record := range latest {
+		if !record.Deleted {
+			records = append(records, record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+	return records, nil
+}
+
+// latest reads the last record for each ID. A missing file holds no
+// records, and a partial last line, left by a write cut short, is skipped.
+func (s *Store) latest(format string) (map[string]oaipmh.Record, error) {
+	latest := make(map[string]oaipmh.Record)
+	f, err := os.Open(s.path(format))
+	if errors.Is(err, os.ErrNotExist) {
+		return latest, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for n := 1; ; n++ {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			return latest, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		var record oaipmh.Record
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", s.path(format), n, err)
+		}
+		latest[record.ID] = record
+	}
+}
+
+// Compact rewrites the records in the given metadata format with only the
+// latest record of each paper, leaving out deleted papers.
+func (s *Store) Compact(format string) error {
+	records, err := s.Records(format)
+	if err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	for _, record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+	return atomicfile.Write(s.path(format), b.Bytes())
+}
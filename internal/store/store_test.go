@@ -0,0 +1,131 @@
+package store
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/oaipmh"
+)
+
+func TestRecords(t *testing.T) {
+	t.Run("empty store", func(t *testing.T) {
+		s, err := Open(filepath.Join(t.TempDir(), "store"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		records, err := s.Records(oaipmh.FormatArXiv)
+		if err != nil || len(records) != 0 {
+			t.Errorf("expected no records, got %v and %v", records, err)
+		}
+	})
+
+	t.Run("latest record wins", func(t *testing.T) {
+		s, err := Open(t.TempDir())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		appendRecords(t, s, oaipmh.FormatArXiv,
+			oaipmh.Record{ID: "2401.00002", Title: "First"},
+			oaipmh.Record{ID: "2401.00001", Title: "Old title"},
+			oaipmh.Record{ID: "2401.00003", Title: "Withdrawn"},
+		)
+		appendRecords(t, s, oaipmh.FormatArXiv,
+			oaipmh.Record{ID: "2401.00001", Title: "New title"},
+			oaipmh.Record{ID: "2401.00003", Deleted: true},
+		)
+		appendRecords(t, s, oaipmh.FormatArXivRaw, oaipmh.Record{ID: "2401.00004", Title: "Raw"})
+
+		records, err := s.Records(oaipmh.FormatArXiv)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := []oaipmh.Record{{ID: "2401.00001", Title: "New title"}, {ID: "2401.00002", Title: "First"}}
+		if !reflect.DeepEqual(records, expected) {
+			t.Errorf("expected %+v, got %+v", expected, records)
+		}
+	})
+
+	t.Run("partial last line", func(t *testing.T) {
+		s, err := Open(t.TempDir())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		appendRecords(t, s, oaipmh.FormatArXiv, oaipmh.Record{ID: "2401.00001"})
+		f, err := os.OpenFile(s.path(oaipmh.FormatArXiv), os.O_APPEND|os.O_WRONLY, 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		f.WriteString(`{"id":"2401.000`)
+		f.Close()
+
+		if records, err := s.Records(oaipmh.FormatArXiv); err != nil || len(records) != 1 {
+			t.Errorf("expected the partial line to be skipped, got %+v and %v", records, err)
+		}
+		appendRecords(t, s, oaipmh.FormatArXiv, oaipmh.Record{ID: "2401.00002"})
+		records, err := s.Records(oaipmh.FormatArXiv)
+		if err != nil {
+			t.Fatalf("expected the partial line to be removed, got %v", err)
+		}
+		if len(records) != 2 || records[1].ID != "2401.00002" {
+			t.Errorf("expected 2 records, got %+v", records)
+		}
+	})
+}
+
+func TestCompact(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	appendRecords(t, s, oaipmh.FormatArXiv, oaipmh.Record{ID: "2401.00001", Title: "Old"}, oaipmh.Record{ID: "2401.00002"})
+	appendRecords(t, s, oaipmh.FormatArXiv, oaipmh.Record{ID: "2401.00001", Title: "New"}, oaipmh.Record{ID: "2401.00002", Deleted: true})
+	before, err := s.Records(oaipmh.FormatArXiv)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := s.Compact(oaipmh.FormatArXiv); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(s.path(oaipmh.FormatArXiv))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lines := bytes.Count(data, []byte("\n")); lines != 1 {
+		t.Errorf("expected 1 line after compacting, got %d", lines)
+	}
+	after, err := s.Records(oaipmh.FormatArXiv)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(before, after) {
+		t.Errorf("expected compacting to keep %+v, got %+v", before, after)
+	}
+}
+
+func TestDirFromEnv(t *testing.T) {
+	tests := []struct {
+		env      string
+		expected string
+	}{
+		{env: "/tmp/arxiv-store", expected: "/tmp/arxiv-store"},
+		{env: "off", expected: ""},
+		{env: "", expected: ""},
+	}
+	for _, tt := range tests {
+		t.Setenv("ARXIV_STORE_DIR", tt.env)
+		if dir := DirFromEnv(); dir != tt.expected {
+			t.Errorf("ARXIV_STORE_DIR=%q: expected %q, got %q", tt.env, tt.expected, dir)
+		}
+	}
+}
+
+func appendRecords(t *testing.T, s *Store, format string, records ...oaipmh.Record) {
+	t.Helper()
+	if err := s.Append(format, records); err != nil {
+		t.Fatalf("appending records: %v", err)
+	}
+}
@@ -0,0 +1,278 @@
+package taxonomy
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Match is a category found by Search or Suggest.
+type Match struct {
+	Category
+	Field string  `json:"field"`
+	Score float64 `json:"score"`
+}
+
+type searchDoc struct {
+	category    Category
+	field       string
+	tag         []string
+	label       []string
+	description map[string]int
+	text        string
+}
+
+func buildSearchIndex(fields []Field) []searchDoc {
+	var docs []searchDoc
+	for _, field := range fields {
+		for _, category := range field.Categories {
+			doc := searchDoc{
+				category:    category,
+				field:       field.Title,
+				tag:         terms(category.Tag),
+				label:       terms(category.Label),
+				description: make(map[string]int),
+				text:        strings.Join(words(category.Label+" "+category.Description), " "),
+			}
+			for _, term := range terms(category.Description) {
+				doc.description[term]++
+			}
+			docs = append(docs, doc)
+		}
+	}
+	return docs
+}
+
+// Search ranks categories by how well their tag, label and description match
+// the free text query. Query terms are weighted by how rare they are across
+// the taxonomy, label matches count more than description matches, and a
+// label or description containing the whole query scores a bonus. At most
+// limit matches are returned, best first.
+func (t *Taxonomy) Search(query string, limit int) []Match {
+	queryTerms := terms(splitCamelCase(query))
+	if len(queryTerms) == 0 {
+		return nil
+	}
+	phrase := strings.Join(words(query), " ")
+
+	var matches []Match
+	for _, doc := range t.searchIx {
+		score := 0.0
+		matched := 0
+		for _, term := range queryTerms {
+			idf := t.idf(term)
+			termScore := 0.0
+			if hasTerm(doc.label, term) {
+				termScore += 3 * idf
+			}
+			if hasTerm(doc.tag, term) || hasAbbreviation(doc.tag, term) {
+				termScore += 2 * idf
+			}
+			if tf := descriptionFrequency(doc.description, term); tf > 0 {
+				termScore += idf * (1 + math.Log(float64(tf)))
+			}
+			if termScore > 0 {
+				matched++
+				score += termScore
+			}
+		}
+		if score == 0 {
+			continue
+		}
+		// Favor categories that match every query term.
+		score *= float64(matched) / float64(len(queryTerms))
+		if len(queryTerms) > 1 && strings.Contains(doc.text, phrase) {
+			score *= 1.5
+		}
+		matches = append(matches, Match{Category: doc.category, Field: doc.field, Score: score})
+	}
+	return topMatches(matches, limit)
+}
+
+// idf is the inverse document frequency of term across categories.
+func (t *Taxonomy) idf(term string) float64 {
+	n := 0
+	for _, doc := range t.searchIx {
+		if hasTerm(doc.label, term) || hasTerm(doc.tag, term) || descriptionFrequency(doc.description, term) > 0 {
+			n++
+		}
+	}
+	return math.Log(1 + float64(len(t.searchIx))/float64(1+n))
+}
+
+// Suggest returns up to limit categories that the unknown tag may have been
+// meant as, best first. Candidates are ranked by edit distance to their tag,
+// by shared archive or subject, and by words of tag that appear in their label
+// or description.
+func (t *Taxonomy) Suggest(tag string, limit int) []Match {
+	var matches []Match
+	for _, doc := range t.searchIx {
+		if score := suggestionScore(tag, doc); score > 0 {
+			matches = append(matches, Match{Category: doc.category, Field: doc.field, Score: score})
+		}
+	}
+	return topMatches(matches, limit)
+}
+
+func suggestionScore(tag string, doc searchDoc) float64 {
+	input := strings.ToLower(strings.TrimSpace(tag))
+	candidate := strings.ToLower(doc.category.Tag)
+
+	score := 0.0
+	if distance := levenshtein(input, candidate); distance < 3 {
+		score += float64(3 - distance)
+	}
+	inArchive, inSubject, hasSubject := strings.Cut(input, ".")
+	archive, subject, _ := strings.Cut(candidate, ".")
+	switch {
+	case hasSubject && inSubject == subject:
+		score += 2
+	case hasSubject && inArchive == archive:
+		score++
+	case !hasSubject && len(input) > 1 && strings.HasPrefix(archive, input):
+		// A partial archive name, such as hep for hep-th.
+		score++
+	}
+
+	acronym := labelAcronym(words(doc.category.Label))
+	for _, word := range words(splitCamelCase(tag)) {
+		switch {
+		case len(word) < 2 || (hasSubject && word == inArchive):
+		case word == acronym:
+			score += 3
+		case hasTerm(doc.label, stem(word)):
+			score += 2
+		case descriptionFrequency(doc.description, stem(word)) > 0:
+			score += 0.5
+		}
+	}
+	return score
+}
+
+func topMatches(matches []Match, limit int) []Match {
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "in": true, "is": true,
+	"it": true, "of": true, "on": true, "or": true, "such": true, "that": true,
+	"the": true, "this": true, "to": true, "with": true,
+}
+
+// terms returns the stemmed words of s, without stop words.
+func terms(s string) []string {
+	var result []string
+	for _, word := range words(s) {
+		if !stopWords[word] {
+			result = append(result, stem(word))
+		}
+	}
+	return result
+}
+
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// splitCamelCase inserts spaces between words run together in camel case, as in
+// NumberTheory.
+func splitCamelCase(s string) string {
+	var b strings.Builder
+	var prev rune
+	for _, r := range s {
+		if unicode.IsUpper(r) && unicode.IsLower(prev) {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(r)
+		prev = r
+	}
+	return b.String()
+}
+
+// hasTerm reports whether term, or a longer form of it, is among terms, so
+// that "robot" matches "robotics".
+func hasTerm(terms []string, term string) bool {
+	for _, t := range terms {
+		if strings.HasPrefix(t, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAbbreviation reports whether one of the tag terms abbreviates term, as
+// quant does quantum and astro does astrophysics.
+func hasAbbreviation(tagTerms []string, term string) bool {
+	for _, t := range tagTerms {
+		if len(t) >= 3 && strings.HasPrefix(term, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// descriptionFrequency counts the description terms that term is a prefix of.
+func descriptionFrequency(description map[string]int, term string) int {
+	n := 0
+	for t, count := range description {
+		if strings.HasPrefix(t, term) {
+			n += count
+		}
+	}
+	return n
+}
+
+// stem strips plural endings.
+func stem(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && len(word) > 3:
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// labelAcronym returns the initials of the significant words in a label, such
+// as "ml" for Machine Learning.
+func labelAcronym(labelWords []string) string {
+	var b strings.Builder
+	for _, w := range labelWords {
+		if stopWords[w] {
+			continue
+		}
+		b.WriteByte(w[0])
+	}
+	return b.String()
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,101 @@
+package taxonomy
+
+import "testing"
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{query: "robotics", expected: []string{"cs.RO"}},
+		{query: "robot", expected: []string{"cs.RO"}},
+		{query: "number theory", expected: []string{"math.NT"}},
+		{query: "galaxy formation", expected: []string{"astro-ph.GA"}},
+		{query: "machine learning", expected: []string{"cs.LG", "stat.ML"}},
+		{query: "natural language processing", expected: []string{"cs.CL"}},
+		{query: "cryptography", expected: []string{"cs.CR"}},
+		{query: "computer vision", expected: []string{"cs.CV"}},
+		{query: "superconductivity", expected: []string{"cond-mat.supr-con"}},
+		{query: "string theory", expected: []string{"hep-th"}},
+		{query: "quantum computing", expected: []string{"quant-ph"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			matches := Default().Search(tt.query, 3)
+			for _, tag := range tt.expected {
+				found := false
+				for _, match := range matches {
+					found = found || match.Tag == tag
+				}
+				if !found {
+					t.Errorf("expected %s in top 3 for %q, got %+v", tag, tt.query, matches)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchRanking(t *testing.T) {
+	matches := Default().Search("number theory", 5)
+	if len(matches) == 0 || matches[0].Tag != "math.NT" {
+		t.Fatalf("expected math.NT first, got %+v", matches)
+	}
+	if matches[0].Field != "Mathematics" {
+		t.Errorf("expected field Mathematics, got '%s'", matches[0].Field)
+	}
+	for i := 1; i < len(matches); i++ {
+		if matches[i].Score > matches[i-1].Score {
+			t.Errorf("expected matches sorted by score, got %+v", matches)
+		}
+	}
+}
+
+func TestSearchNoMatch(t *testing.T) {
+	if matches := Default().Search("the of and", 5); matches != nil {
+		t.Errorf("expected no matches for stop words, got %+v", matches)
+	}
+	if matches := Default().Search("xyzzyplugh", 5); len(matches) != 0 {
+		t.Errorf("expected no matches for nonsense, got %+v", matches)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected string
+	}{
+		{tag: "cs.ML", expected: "cs.LG"},
+		{tag: "stat.ml", expected: "stat.ML"},
+		{tag: "math.NumberTheory", expected: "math.NT"},
+		{tag: "astro.GA", expected: "astro-ph.GA"},
+		{tag: "robotics", expected: "cs.RO"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			matches := Default().Suggest(tt.tag, 5)
+			if len(matches) == 0 || matches[0].Tag != tt.expected {
+				t.Errorf("expected %s as the top suggestion for %s, got %+v", tt.expected, tt.tag, matches)
+			}
+		})
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"cs.lg", "cs.lg", 0},
+		{"cs.ml", "cs.lg", 2},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+	}
+	for _, tt := range tests {
+		if d := levenshtein(tt.a, tt.b); d != tt.expected {
+			t.Errorf("levenshtein(%q, %q) = %d, expected %d", tt.a, tt.b, d, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Package taxonomy provides the arXiv category taxonomy as typed values.
+//
+// The taxonomy is scraped from https://arxiv.org/category_taxonomy by
+// cmd/arxiv-taxonomy-scraper and embedded from arxiv-taxonomy.json.
+package taxonomy
+
+import (
+	_ "embed"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Field is a top-level subject area, such as Computer Science, and the
+// categories within it.
+type Field struct {
+	Title      string     `json:"title"`
+	Categories []Category `json:"categories"`
+}
+
+// Category is a single arXiv category, such as cs.LG.
+type Category struct {
+	Tag         string `json:"tag"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+}
+
+// Taxonomy is a parsed category taxonomy with lookup indexes.
+type Taxonomy struct {
+	fields   []Field
+	byTag    map[string]Category // keyed by lowercase tag
+	fieldOf  map[string]string   // tag to field title
+	aliases  map[string][]string
+	searchIx []searchDoc
+}
+
+//go:embed arxiv-taxonomy.json
+var data []byte
+
+var defaultTaxonomy = sync.OnceValue(func() *Taxonomy {
+	t, err := Parse(data)
+	if err != nil {
+		panic(err)
+	}
+	return t
+})
+
+// Default returns the embedded taxonomy.
+func Default() *Taxonomy {
+	return defaultTaxonomy()
+}
+
+// JSON returns the embedded taxonomy as it was scraped.
+func JSON() []byte {
+	return data
+}
+
+// aliasRe matches the sentence arXiv uses to mark a category as an alias, as
+// in "math.IT is an alias for cs.IT."
+var aliasRe = regexp.MustCompile(`^(\S+) is an alias for (\S+?)\.(?:\s|$)`)
+
+// Parse parses a taxonomy in the JSON format written by
+// cmd/arxiv-taxonomy-scraper.
+func Parse(data []byte) (*Taxonomy, error) {
+	var fields []Field
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	t := &Taxonomy{
+		fields:  fields,
+		byTag:   make(map[string]Category),
+		fieldOf: make(map[string]string),
+		aliases: make(map[string][]string),
+	}
+	for _, field := range fields {
+		for _, category := range field.Categories {
+			t.byTag[strings.ToLower(category.Tag)] = category
+			t.fieldOf[category.Tag] = field.Title
+			if m := aliasRe.FindStringSubmatch(category.Description); m != nil && m[1] == category.Tag {
+				t.aliases[m[1]] = append(t.aliases[m[1]], m[2])
+				t.aliases[m[2]] = append(t.aliases[m[2]], m[1])
+			}
+		}
+	}
+	t.searchIx = buildSearchIndex(fields)
+	return t, nil
+}
+
+// Fields returns the subject areas in taxonomy order.
+func (t *Taxonomy) Fields() []Field {
+	return t.fields
+}
+
+// Lookup returns the category with the given tag. Tags are matched
+// case-insensitively, so the returned tag may differ in case from tag.
+func (t *Taxonomy) Lookup(tag string) (Category, bool) {
+	category, ok := t.byTag[strings.ToLower(strings.TrimSpace(tag))]
+	return category, ok
+}
+
+// FieldOf returns the title of the subject area containing the category tag.
+func (t *Taxonomy) FieldOf(tag string) (string, bool) {
+	title, ok := t.fieldOf[tag]
+	return title, ok
+}
+
+// FieldCategories returns the categories in the subject area with the given
+// title, matched case-insensitively.
+func (t *Taxonomy) FieldCategories(title string) []Category {
+	for _, field := range t.fields {
+		if strings.EqualFold(field.Title, title) {
+			return field.Categories
+		}
+	}
+	return nil
+}
+
+// Archive returns the archive a category tag belongs to, such as cs for cs.LG
+// and hep-th for hep-th.
+func Archive(tag string) string {
+	archive, _, _ := strings.Cut(tag, ".")
+	return archive
+}
+
+// ArchiveCategories returns the categories in an archive, such as cs or
+// astro-ph, in taxonomy order.
+func (t *Taxonomy) ArchiveCategories(archive string) []Category {
+	var categories []Category
+	for _, field := range t.fields {
+		for _, category := range field.Categories {
+			if Archive(category.Tag) == archive {
+				categories = append(categories, category)
+			}
+		}
+	}
+	return categories
+}
+
+// Aliases returns the tags that arXiv treats as the same category as tag, such
+// as cs.IT for math.IT and math-ph for math.MP.
+func (t *Taxonomy) Aliases(tag string) []string {
+	return t.aliases[tag]
+}
@@ -0,0 +1,131 @@
+package taxonomy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDefault(t *testing.T) {
+	fields := Default().Fields()
+	if len(fields) != 8 {
+		t.Fatalf("expected 8 fields, got %d", len(fields))
+	}
+	if fields[0].Title != "Computer Science" {
+		t.Errorf("expected first field 'Computer Science', got '%s'", fields[0].Title)
+	}
+}
+
+func TestParse(t *testing.T) {
+	taxonomy, err := Parse([]byte(`[{"title":"Statistics","categories":[
+		{"tag":"stat.ML","label":"Machine Learning","description":"Machine learning papers."},
+		{"tag":"stat.TH","label":"Statistics Theory","description":"stat.TH is an alias for math.ST. Asymptotics."}
+	]}]`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := taxonomy.Lookup("stat.ML"); !ok {
+		t.Error("expected stat.ML to be found")
+	}
+	if aliases := taxonomy.Aliases("math.ST"); !reflect.DeepEqual(aliases, []string{"stat.TH"}) {
+		t.Errorf("expected math.ST aliases [stat.TH], got %v", aliases)
+	}
+
+	if _, err := Parse([]byte(`{"title":`)); err == nil {
+		t.Error("expected error for malformed JSON")
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected string
+		found    bool
+	}{
+		{tag: "cs.LG", expected: "cs.LG", found: true},
+		{tag: "CS.lg", expected: "cs.LG", found: true},
+		{tag: " hep-th ", expected: "hep-th", found: true},
+		{tag: "cond-mat.str-el", expected: "cond-mat.str-el", found: true},
+		{tag: "cs.ML", found: false},
+		{tag: "cs", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			category, ok := Default().Lookup(tt.tag)
+			if ok != tt.found {
+				t.Fatalf("expected found=%v, got %v", tt.found, ok)
+			}
+			if ok && category.Tag != tt.expected {
+				t.Errorf("expected tag '%s', got '%s'", tt.expected, category.Tag)
+			}
+		})
+	}
+}
+
+func TestFieldOf(t *testing.T) {
+	if field, ok := Default().FieldOf("quant-ph"); !ok || field != "Physics" {
+		t.Errorf("expected quant-ph in Physics, got '%s'", field)
+	}
+	if _, ok := Default().FieldOf("cs.XX"); ok {
+		t.Error("expected unknown tag to have no field")
+	}
+}
+
+func TestFieldCategories(t *testing.T) {
+	categories := Default().FieldCategories("economics")
+	var tags []string
+	for _, category := range categories {
+		tags = append(tags, category.Tag)
+	}
+	if !reflect.DeepEqual(tags, []string{"econ.EM", "econ.GN", "econ.TH"}) {
+		t.Errorf("unexpected Economics categories %v", tags)
+	}
+	if categories := Default().FieldCategories("Alchemy"); categories != nil {
+		t.Errorf("expected no categories for unknown field, got %v", categories)
+	}
+}
+
+func TestArchive(t *testing.T) {
+	tests := map[string]string{
+		"cs.LG":           "cs",
+		"astro-ph.GA":     "astro-ph",
+		"hep-th":          "hep-th",
+		"cond-mat.str-el": "cond-mat",
+	}
+	for tag, expected := range tests {
+		if archive := Archive(tag); archive != expected {
+			t.Errorf("expected archive of %s to be %s, got %s", tag, expected, archive)
+		}
+	}
+}
+
+func TestArchiveCategories(t *testing.T) {
+	if n := len(Default().ArchiveCategories("cs")); n != 40 {
+		t.Errorf("expected 40 cs categories, got %d", n)
+	}
+	if n := len(Default().ArchiveCategories("physics")); n != 22 {
+		t.Errorf("expected 22 physics categories, got %d", n)
+	}
+	if categories := Default().ArchiveCategories("alchemy"); categories != nil {
+		t.Errorf("expected no categories for unknown archive, got %v", categories)
+	}
+}
+
+func TestAliases(t *testing.T) {
+	tests := map[string][]string{
+		"math.IT":  {"cs.IT"},
+		"cs.IT":    {"math.IT"},
+		"math.MP":  {"math-ph"},
+		"math-ph":  {"math.MP"},
+		"stat.TH":  {"math.ST"},
+		"q-fin.EC": {"econ.GN"},
+		"cs.SY":    {"eess.SY"},
+		"cs.NA":    {"math.NA"},
+		"cs.LG":    nil,
+	}
+	for tag, expected := range tests {
+		if aliases := Default().Aliases(tag); !reflect.DeepEqual(aliases, expected) {
+			t.Errorf("expected aliases of %s to be %v, got %v", tag, expected, aliases)
+		}
+	}
+}
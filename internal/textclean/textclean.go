@@ -0,0 +1,26 @@
+// Package textclean tidies text taken from arXiv metadata, which wraps long
+// fields over several lines and gives authors as a single list.
+package textclean
+
+import "strings"
+
+// OneLine joins the lines of s, collapsing runs of whitespace into a single
+// space.
+func OneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// SplitAuthors splits an author list in which names are separated by commas,
+// the last perhaps by "and", as in the feeds and arXivRaw records.
+func SplitAuthors(authors string) []string {
+	authors = OneLine(authors)
+	authors = strings.ReplaceAll(authors, ", and ", ", ")
+	authors = strings.ReplaceAll(authors, " and ", ", ")
+	var names []string
+	for _, name := range strings.Split(authors, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
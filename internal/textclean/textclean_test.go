@@ -0,0 +1,42 @@
+package textclean
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOneLine(t *testing.T) {
+	tests := []struct {
+		in, expected string
+	}{
+		{in: "Attention Is All\n  You Need", expected: "Attention Is All You Need"},
+		{in: "  \tpadded  ", expected: "padded"},
+		{in: "", expected: ""},
+	}
+	for _, tt := range tests {
+		if got := OneLine(tt.in); got != tt.expected {
+			t.Errorf("OneLine(%q): expected %q, got %q", tt.in, tt.expected, got)
+		}
+	}
+}
+
+func TestSplitAuthors(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		expected []string
+	}{
+		{name: "one", in: "Juan M. Maldacena", expected: []string{"Juan M. Maldacena"}},
+		{name: "two", in: "Diederik P. Kingma and Jimmy Ba", expected: []string{"Diederik P. Kingma", "Jimmy Ba"}},
+		{name: "serial comma", in: "Ashish Vaswani, Noam Shazeer, and Niki Parmar", expected: []string{"Ashish Vaswani", "Noam Shazeer", "Niki Parmar"}},
+		{name: "wrapped", in: "Ashish Vaswani,\n  Noam Shazeer", expected: []string{"Ashish Vaswani", "Noam Shazeer"}},
+		{name: "empty", in: " ", expected: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitAuthors(tt.in); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
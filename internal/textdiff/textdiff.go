@@ -0,0 +1,250 @@
+// Package textdiff computes word-level differences between two texts and
+// renders them as unified diff hunks.
+//
+// Texts are compared as sequences of tokens, usually the words of a title or
+// abstract or the names in an author list, so that a changed word shows as
+// that word rather than as a changed line.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op is what an edit does to the tokens it holds.
+type Op string
+
+const (
+	Equal  Op = "equal"
+	Delete Op = "delete"
+	Insert Op = "insert"
+)
+
+// Edit is a run of tokens that are kept, deleted from the old sequence or
+// inserted into the new one.
+type Edit struct {
+	Op     Op
+	Tokens []string
+}
+
+// Hunk is a group of nearby changes with the unchanged tokens around them.
+// Starts are 1-based token positions, as in a unified diff; a hunk that
+// holds no tokens of a sequence starts at the position before it.
+type Hunk struct {
+	OldStart, OldCount int
+	NewStart, NewCount int
+	Edits              []Edit
+}
+
+// Words splits text into words at white space.
+func Words(text string) []string {
+	return strings.Fields(text)
+}
+
+// Diff returns the edits that turn a into b, keeping a longest common
+// subsequence of the two. Consecutive edits with the same Op are merged, and
+// deletions come before insertions where both replace the same tokens.
+func Diff(a, b []string) []Edit {
+	// The common prefix and suffix are kept without filling in the table for
+	// them, which is most of a text with few changes.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var edits []Edit
+	add := func(op Op, token string) {
+		if n := len(edits); n > 0 && edits[n-1].Op == op {
+			edits[n-1].Tokens = append(edits[n-1].Tokens, token)
+			return
+		}
+		edits = append(edits, Edit{Op: op, Tokens: []string{token}})
+	}
+	for _, token := range a[:prefix] {
+		add(Equal, token)
+	}
+
+	x, y := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	// lcs[i][j] is the length of a longest common subsequence of x[i:] and
+	// y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var inserted []string
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			for _, token := range inserted {
+				add(Insert, token)
+			}
+			inserted = inserted[:0]
+			add(Equal, x[i])
+			i++
+			j++
+		case j < len(y) && (i == len(x) || lcs[i][j+1] >= lcs[i+1][j]):
+			// Insertions wait for the deletions they replace.
+			inserted = append(inserted, y[j])
+			j++
+		default:
+			add(Delete, x[i])
+			i++
+		}
+	}
+	for _, token := range inserted {
+		add(Insert, token)
+	}
+
+	for _, token := range a[len(a)-suffix:] {
+		add(Equal, token)
+	}
+	return edits
+}
+
+// Changed reports whether edits change anything.
+func Changed(edits []Edit) bool {
+	for _, edit := range edits {
+		if edit.Op != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+// Hunks groups the changes in edits into hunks with up to context unchanged
+// tokens before and after each change. Changes separated by no more than
+// twice context unchanged tokens share a hunk.
+func Hunks(edits []Edit, context int) []Hunk {
+	var hunks []Hunk
+	var hunk *Hunk
+	oldPos, newPos := 0, 0 // tokens before the current edit
+	for i, edit := range edits {
+		n := len(edit.Tokens)
+		if edit.Op != Equal {
+			if hunk == nil {
+				hunks = append(hunks, Hunk{OldStart: oldPos, NewStart: newPos})
+				hunk = &hunks[len(hunks)-1]
+			}
+			hunk.Edits = append(hunk.Edits, edit)
+			if edit.Op == Delete {
+				hunk.OldCount += n
+				oldPos += n
+			} else {
+				hunk.NewCount += n
+				newPos += n
+			}
+			continue
+		}
+
+		last := i == len(edits)-1
+		switch {
+		case hunk == nil:
+			// Context before the next change. Edits alternate between
+			// Equal and the others, so one follows unless this is the last.
+			if !last {
+				lead := min(n, context)
+				hunks = append(hunks, Hunk{
+					OldStart: oldPos + n - lead,
+					NewStart: newPos + n - lead,
+					OldCount: lead,
+					NewCount: lead,
+				})
+				hunk = &hunks[len(hunks)-1]
+				if lead > 0 {
+					hunk.Edits = append(hunk.Edits, Edit{Op: Equal, Tokens: edit.Tokens[n-lead:]})
+				}
+			}
+		case n <= 2*context && !last:
+			hunk.Edits = append(hunk.Edits, edit)
+			hunk.OldCount += n
+			hunk.NewCount += n
+		default:
+			trail := min(n, context)
+			if trail > 0 {
+				hunk.Edits = append(hunk.Edits, Edit{Op: Equal, Tokens: edit.Tokens[:trail]})
+			}
+			hunk.OldCount += trail
+			hunk.NewCount += trail
+			hunk = nil
+			if !last {
+				lead := min(n-trail, context)
+				hunks = append(hunks, Hunk{
+					OldStart: oldPos + n - lead,
+					NewStart: newPos + n - lead,
+					OldCount: lead,
+					NewCount: lead,
+				})
+				hunk = &hunks[len(hunks)-1]
+				if lead > 0 {
+					hunk.Edits = append(hunk.Edits, Edit{Op: Equal, Tokens: edit.Tokens[n-lead:]})
+				}
+			}
+		}
+		oldPos += n
+		newPos += n
+	}
+
+	// Starts so far count the tokens before each hunk; a hunk that holds
+	// tokens starts at the one after.
+	for i := range hunks {
+		if hunks[i].OldCount > 0 {
+			hunks[i].OldStart++
+		}
+		if hunks[i].NewCount > 0 {
+			hunks[i].NewStart++
+		}
+	}
+	return hunks
+}
+
+// Unified renders hunks as a unified diff between the texts named oldName
+// and newName. Each hunk is one line after its header, with deleted tokens
+// in [-...-] and inserted ones in {+...+}, as git diff --word-diff writes
+// them, and tokens joined by sep.
+func Unified(oldName, newName string, hunks []Hunk, sep string) string {
+	if len(hunks) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks {
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(h.OldStart, h.OldCount), hunkRange(h.NewStart, h.NewCount))
+		for i, edit := range h.Edits {
+			if i > 0 {
+				b.WriteString(sep)
+			}
+			text := strings.Join(edit.Tokens, sep)
+			switch edit.Op {
+			case Delete:
+				b.WriteString("[-" + text + "-]")
+			case Insert:
+				b.WriteString("{+" + text + "+}")
+			default:
+				b.WriteString(text)
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
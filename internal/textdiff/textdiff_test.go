@@ -0,0 +1,155 @@
+package textdiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected []Edit
+	}{
+		{
+			name:     "equal",
+			a:        "a b c",
+			b:        "a b c",
+			expected: []Edit{{Equal, []string{"a", "b", "c"}}},
+		},
+		{
+			name: "replaced word",
+			a:    "we prove the bound",
+			b:    "we conjecture the bound",
+			expected: []Edit{
+				{Equal, []string{"we"}},
+				{Delete, []string{"prove"}},
+				{Insert, []string{"conjecture"}},
+				{Equal, []string{"the", "bound"}},
+			},
+		},
+		{
+			name: "insertions and deletions",
+			a:    "one two three four",
+			b:    "zero one three four five",
+			expected: []Edit{
+				{Insert, []string{"zero"}},
+				{Equal, []string{"one"}},
+				{Delete, []string{"two"}},
+				{Equal, []string{"three", "four"}},
+				{Insert, []string{"five"}},
+			},
+		},
+		{
+			name:     "from empty",
+			a:        "",
+			b:        "new text",
+			expected: []Edit{{Insert, []string{"new", "text"}}},
+		},
+		{
+			name:     "to empty",
+			a:        "old text",
+			b:        "",
+			expected: []Edit{{Delete, []string{"old", "text"}}},
+		},
+		{
+			name: "deletions before insertions",
+			a:    "x a b y",
+			b:    "x c d y",
+			expected: []Edit{
+				{Equal, []string{"x"}},
+				{Delete, []string{"a", "b"}},
+				{Insert, []string{"c", "d"}},
+				{Equal, []string{"y"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edits := Diff(Words(tt.a), Words(tt.b))
+			if !reflect.DeepEqual(edits, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, edits)
+			}
+			if Changed(edits) != (tt.a != tt.b) {
+				t.Errorf("expected Changed to be %v", tt.a != tt.b)
+			}
+
+			// Applying the edits gives back both texts.
+			var a, b []string
+			for _, edit := range edits {
+				if edit.Op != Insert {
+					a = append(a, edit.Tokens...)
+				}
+				if edit.Op != Delete {
+					b = append(b, edit.Tokens...)
+				}
+			}
+			if strings.Join(a, " ") != tt.a || strings.Join(b, " ") != tt.b {
+				t.Errorf("edits give %q and %q", a, b)
+			}
+		})
+	}
+}
+
+func TestHunks(t *testing.T) {
+	a := Words("1 2 3 4 5 6 7 8 9 10 11 12 13 14 15")
+	b := Words("1 2 3 4 X 6 7 8 9 10 11 12 13 Y 14 15")
+
+	hunks := Hunks(Diff(a, b), 2)
+	expected := []Hunk{
+		{OldStart: 3, OldCount: 5, NewStart: 3, NewCount: 5, Edits: []Edit{
+			{Equal, []string{"3", "4"}},
+			{Delete, []string{"5"}},
+			{Insert, []string{"X"}},
+			{Equal, []string{"6", "7"}},
+		}},
+		{OldStart: 12, OldCount: 4, NewStart: 12, NewCount: 5, Edits: []Edit{
+			{Equal, []string{"12", "13"}},
+			{Insert, []string{"Y"}},
+			{Equal, []string{"14", "15"}},
+		}},
+	}
+	if !reflect.DeepEqual(hunks, expected) {
+		t.Errorf("expected %+v, got %+v", expected, hunks)
+	}
+
+	// Changes close together share a hunk.
+	if hunks := Hunks(Diff(a, b), 3); len(hunks) != 2 {
+		t.Errorf("expected 2 hunks with 3 words of context, got %d", len(hunks))
+	}
+	if hunks := Hunks(Diff(a, b), 4); len(hunks) != 1 || hunks[0].OldStart != 1 || hunks[0].OldCount != 15 || hunks[0].NewCount != 16 {
+		t.Errorf("expected one hunk of all the words with 4 words of context, got %+v", hunks)
+	}
+	if hunks := Hunks(Diff(a, a), 2); hunks != nil {
+		t.Errorf("expected no hunks for equal texts, got %+v", hunks)
+	}
+
+	// An insertion with no context starts after the token before it.
+	hunks = Hunks(Diff(Words("a b"), Words("a x b")), 0)
+	if len(hunks) != 1 || hunks[0].OldStart != 1 || hunks[0].OldCount != 0 || hunks[0].NewStart != 2 || hunks[0].NewCount != 1 {
+		t.Errorf("unexpected hunk for a pure insertion %+v", hunks)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	a := Words("We prove that the bound is tight for all graphs.")
+	b := Words("We conjecture that the bound is tight for planar graphs.")
+
+	expected := "--- v1\n+++ v2\n" +
+		"@@ -1,4 +1,4 @@\n" +
+		"We [-prove-] {+conjecture+} that the\n" +
+		"@@ -7,4 +7,4 @@\n" +
+		"tight for [-all-] {+planar+} graphs.\n"
+	if text := Unified("v1", "v2", Hunks(Diff(a, b), 2), " "); text != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, text)
+	}
+	if text := Unified("v1", "v2", nil, " "); text != "" {
+		t.Errorf("expected no diff for no hunks, got %q", text)
+	}
+
+	authors := Unified("v1", "v2", Hunks(Diff([]string{"Ada Lovelace"}, []string{"Ada Lovelace", "Mary Somerville"}), 1), ", ")
+	if !strings.Contains(authors, "@@ -1 +1,2 @@\nAda Lovelace, {+Mary Somerville+}\n") {
+		t.Errorf("unexpected author diff:\n%s", authors)
+	}
+}
@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/citation"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type CiteQuery struct {
+	IDs    []string `json:"ids" jsonschema:"arXiv IDs of the papers to cite"`
+	Format string   `json:"format,omitempty" jsonschema:"bibtex, ris or csl-json. Defaults to bibtex"`
+}
+
+type CiteResults struct {
+	Format    string     `json:"format"`
+	Citations string     `json:"citations,omitempty" jsonschema:"the papers in the requested format, in the order requested"`
+	Keys      []string   `json:"keys,omitempty" jsonschema:"citation keys of the papers, in the same order"`
+	NotFound  []string   `json:"notFound,omitempty" jsonschema:"normalized IDs that arXiv has no paper for"`
+	Invalid   []string   `json:"invalid,omitempty" jsonschema:"inputs that could not be recognized as arXiv IDs"`
+	CacheHit  bool       `json:"cache_hit" jsonschema:"whether the metadata was served from the cache"`
+	Error     *ToolError `json:"error,omitempty" jsonschema:"why the citations could not be made, if they could not"`
+}
+
+func CiteTool() *mcp.Tool {
+	inputSchema, err := jsonschema.For[CiteQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	formats := make([]any, len(citation.Formats))
+	for i, format := range citation.Formats {
+		formats[i] = format
+	}
+	inputSchema.Properties["format"].Enum = formats
+
+	citeTool := mcp.Tool{
+		Name:        "arxiv-cite",
+		Description: "Makes citations for papers by arXiv ID, as BibTeX for LaTeX or as RIS or CSL-JSON for reference managers. Citation keys are stable, such as vaswani2017attention",
+		InputSchema: inputSchema,
+	}
+	return &citeTool
+}
+
+func CiteHandler(searcher Searcher) mcp.ToolHandlerFor[CiteQuery, CiteResults] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, query CiteQuery) (*mcp.CallToolResult, CiteResults, error) {
+		result, citeResults, err := cite(ctx, searcher, query)
+		if toolErr := asToolError(err); toolErr != nil {
+			return errorResult(toolErr), CiteResults{Error: toolErr}, nil
+		}
+		return result, citeResults, err
+	}
+}
+
+func cite(ctx context.Context, searcher Searcher, query CiteQuery) (*mcp.CallToolResult, CiteResults, error) {
+	format := query.Format
+	if format == "" {
+		format = citation.BibTeX
+	}
+	if !isCitationFormat(format) {
+		return nil, CiteResults{}, invalidInput("format", citation.BibTeX, "must be %s, %s or %s, got %q", citation.BibTeX, citation.RIS, citation.CSLJSON, format)
+	}
+	ids, invalid := parseIDs(query.IDs)
+	if len(ids) == 0 {
+		return nil, CiteResults{}, invalidInput("ids", `["1706.03762"]`, "no valid arXiv IDs given: %q", query.IDs)
+	}
+
+	found, err := lookupPapers(ctx, searcher, ids, "ids")
+	if err != nil {
+		return nil, CiteResults{}, err
+	}
+	citeResults := CiteResults{
+		Format:   format,
+		NotFound: found.notFound,
+		Invalid:  invalid,
+		CacheHit: found.cacheHit,
+	}
+	citeResults.Citations, err = citation.Export(format, found.entries)
+	if err != nil {
+		return nil, CiteResults{}, err
+	}
+	citeResults.Keys = citation.Keys(found.entries)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: citeResults.Citations}},
+	}, citeResults, nil
+}
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestCiteTool(t *testing.T) {
+	tool := CiteTool()
+	if tool.Name != "arxiv-cite" {
+		t.Errorf("expected tool name 'arxiv-cite', got '%s'", tool.Name)
+	}
+	if tool.InputSchema == nil {
+		t.Fatal("expected InputSchema to be non-nil")
+	}
+	if enum := tool.InputSchema.Properties["format"].Enum; len(enum) != 3 {
+		t.Errorf("expected 3 formats, got %v", enum)
+	}
+}
+
+func TestCiteHandler(t *testing.T) {
+	handler := CiteHandler(newTestClient(t))
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		query    CiteQuery
+		keys     []string
+		notFound []string
+		contains []string
+	}{
+		{
+			name:     "bibtex by default",
+			query:    CiteQuery{IDs: []string{"1706.03762", "hep-th/9711200"}},
+			keys:     []string{"vaswani2017attention", "maldacena1997large"},
+			contains: []string{"@misc{vaswani2017attention,", "eprint = {1706.03762}", "primaryClass = {cs.CL}", "@article{maldacena1997large,", "journal = {Adv.Theor.Math.Phys.2:231-252,1998}"},
+		},
+		{
+			name:     "ris",
+			query:    CiteQuery{IDs: []string{"1412.6980"}, Format: "ris"},
+			keys:     []string{"kingma2014adam"},
+			contains: []string{"TY  - UNPB", "AU  - Kingma, Diederik P.", "ER  - "},
+		},
+		{
+			name:     "csl-json with a missing paper",
+			query:    CiteQuery{IDs: []string{"1412.6980", "2401.99999"}, Format: "csl-json"},
+			keys:     []string{"kingma2014adam"},
+			notFound: []string{"2401.99999"},
+			contains: []string{`"id": "kingma2014adam"`, `"family": "Kingma"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, results, err := handler(ctx, &mcp.CallToolRequest{}, tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.IsError {
+				t.Fatalf("unexpected tool error: %+v", results.Error)
+			}
+			if !reflect.DeepEqual(results.Keys, tt.keys) || !reflect.DeepEqual(results.NotFound, tt.notFound) {
+				t.Errorf("expected keys %v and not found %v, got %v and %v", tt.keys, tt.notFound, results.Keys, results.NotFound)
+			}
+			text := result.Content[0].(*mcp.TextContent).Text
+			if text != results.Citations {
+				t.Error("expected the text content to be the citations")
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(text, s) {
+					t.Errorf("expected %q in:\n%s", s, text)
+				}
+			}
+		})
+	}
+
+	errorTests := []struct {
+		name  string
+		query CiteQuery
+		kind  string
+		field string
+	}{
+		{name: "unknown format", query: CiteQuery{IDs: []string{"1706.03762"}, Format: "endnote"}, kind: errorInvalidInput, field: "format"},
+		{name: "no valid ids", query: CiteQuery{IDs: []string{"attention"}}, kind: errorInvalidInput, field: "ids"},
+		{name: "no papers found", query: CiteQuery{IDs: []string{"2401.99999"}}, kind: errorNotFound, field: "ids"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, results, err := handler(ctx, &mcp.CallToolRequest{}, tt.query)
+			if err != nil {
+				t.Fatalf("expected a tool error, got %v", err)
+			}
+			if !result.IsError || results.Error == nil {
+				t.Fatal("expected IsError and an error in the results")
+			}
+			if results.Error.Kind != tt.kind || results.Error.Field != tt.field {
+				t.Errorf("expected %s error on %q, got %+v", tt.kind, tt.field, results.Error)
+			}
+		})
+	}
+}
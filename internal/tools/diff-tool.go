@@ -0,0 +1,188 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivid"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/textdiff"
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// diffContext is the number of unchanged words or names shown around each
+// change.
+const diffContext = 5
+
+type DiffQuery struct {
+	From string `json:"from" jsonschema:"arXiv ID of the older version or paper. Without a version, and without to, v1 is compared with the current version"`
+	To   string `json:"to,omitempty" jsonschema:"arXiv ID to compare with, a version of the same paper or another paper. Defaults to the current version of from"`
+}
+
+type DiffResults struct {
+	From      string      `json:"from" jsonschema:"versioned arXiv ID compared from"`
+	To        string      `json:"to" jsonschema:"versioned arXiv ID compared to"`
+	SamePaper bool        `json:"same_paper" jsonschema:"whether from and to are versions of the same paper"`
+	Changed   []string    `json:"changed,omitempty" jsonschema:"fields that differ"`
+	Fields    []FieldDiff `json:"fields,omitempty" jsonschema:"word-level changes to each field that differs"`
+	Diff      string      `json:"diff,omitempty" jsonschema:"the changes as a unified diff, with deleted words in [-...-] and inserted ones in {+...+}"`
+	CacheHit  bool        `json:"cache_hit" jsonschema:"whether the metadata was served from the cache"`
+	Error     *ToolError  `json:"error,omitempty" jsonschema:"why the papers could not be compared, if they could not"`
+}
+
+type FieldDiff struct {
+	Field string     `json:"field" jsonschema:"title, authors, abstract, categories or comment"`
+	Hunks []DiffHunk `json:"hunks"`
+}
+
+// DiffHunk is a group of nearby changes. Positions count words, or names for
+// authors and categories, from 1.
+type DiffHunk struct {
+	OldStart int        `json:"old_start"`
+	OldCount int        `json:"old_count"`
+	NewStart int        `json:"new_start"`
+	NewCount int        `json:"new_count"`
+	Edits    []DiffEdit `json:"edits"`
+}
+
+type DiffEdit struct {
+	Op   string `json:"op" jsonschema:"equal, delete or insert"`
+	Text string `json:"text"`
+}
+
+func DiffTool() *mcp.Tool {
+	inputSchema, err := jsonschema.For[DiffQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+
+	diffTool := mcp.Tool{
+		Name:        "arxiv-diff",
+		Description: "Compares two versions of a paper, or two papers, showing word by word how the title, authors, abstract, categories and comments differ",
+		InputSchema: inputSchema,
+	}
+	return &diffTool
+}
+
+func DiffHandler(searcher Searcher) mcp.ToolHandlerFor[DiffQuery, DiffResults] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, query DiffQuery) (*mcp.CallToolResult, DiffResults, error) {
+		result, diffResults, err := diffPapers(ctx, searcher, query)
+		if toolErr := asToolError(err); toolErr != nil {
+			return errorResult(toolErr), DiffResults{Error: toolErr}, nil
+		}
+		return result, diffResults, err
+	}
+}
+
+func diffPapers(ctx context.Context, searcher Searcher, query DiffQuery) (*mcp.CallToolResult, DiffResults, error) {
+	from, err := arxivid.Parse(query.From)
+	if err != nil {
+		return nil, DiffResults{}, invalidInput("from", "1706.03762v1", "%q is not an arXiv ID", query.From)
+	}
+	var to arxivid.ID
+	if query.To == "" {
+		to = from.WithVersion(0)
+		if !from.Versioned() {
+			from = from.WithVersion(1)
+		}
+	} else if to, err = arxivid.Parse(query.To); err != nil {
+		return nil, DiffResults{}, invalidInput("to", "1706.03762v2", "%q is not an arXiv ID", query.To)
+	}
+
+	fromFound, err := lookupPapers(ctx, searcher, []arxivid.ID{from}, "from")
+	if err != nil {
+		return nil, DiffResults{}, err
+	}
+	toFound, err := lookupPapers(ctx, searcher, []arxivid.ID{to}, "to")
+	if err != nil {
+		return nil, DiffResults{}, err
+	}
+	fromEntry, toEntry := fromFound.entries[0], toFound.entries[0]
+
+	results := DiffResults{
+		From:     displayID(fromEntry.ID),
+		To:       displayID(toEntry.ID),
+		CacheHit: fromFound.cacheHit && toFound.cacheHit,
+	}
+	fromID, _ := arxivid.Parse(fromEntry.ID)
+	toID, _ := arxivid.Parse(toEntry.ID)
+	results.SamePaper = fromID.Base == toID.Base
+
+	var diff strings.Builder
+	for _, field := range diffFields(fromEntry, toEntry) {
+		edits := textdiff.Diff(field.before, field.after)
+		if !textdiff.Changed(edits) {
+			continue
+		}
+		hunks := textdiff.Hunks(edits, diffContext)
+		results.Changed = append(results.Changed, field.name)
+		results.Fields = append(results.Fields, FieldDiff{Field: field.name, Hunks: diffHunks(hunks, field.sep)})
+		diff.WriteString(textdiff.Unified(results.From+" "+field.name, results.To+" "+field.name, hunks, field.sep))
+	}
+	results.Diff = diff.String()
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: renderDiff(results)}},
+	}, results, nil
+}
+
+type diffField struct {
+	name          string
+	before, after []string
+	sep           string // joins the tokens back into text
+}
+
+// diffFields splits the compared fields into tokens: words of the title,
+// abstract and comment, and whole names of authors and categories.
+func diffFields(from, to arxiv.EntryMetadata) []diffField {
+	return []diffField{
+		{"title", textdiff.Words(from.Title), textdiff.Words(to.Title), " "},
+		{"authors", authorNames(from), authorNames(to), ", "},
+		{"abstract", textdiff.Words(from.Summary), textdiff.Words(to.Summary), " "},
+		{"categories", categoryTerms(from), categoryTerms(to), ", "},
+		{"comment", textdiff.Words(from.Comment), textdiff.Words(to.Comment), " "},
+	}
+}
+
+func categoryTerms(entry arxiv.EntryMetadata) []string {
+	terms := make([]string, len(entry.Categories))
+	for i, category := range entry.Categories {
+		terms[i] = category.Term
+	}
+	return terms
+}
+
+func diffHunks(hunks []textdiff.Hunk, sep string) []DiffHunk {
+	views := make([]DiffHunk, len(hunks))
+	for i, h := range hunks {
+		views[i] = DiffHunk{
+			OldStart: h.OldStart,
+			OldCount: h.OldCount,
+			NewStart: h.NewStart,
+			NewCount: h.NewCount,
+			Edits:    make([]DiffEdit, len(h.Edits)),
+		}
+		for j, edit := range h.Edits {
+			views[i].Edits[j] = DiffEdit{Op: string(edit.Op), Text: strings.Join(edit.Tokens, sep)}
+		}
+	}
+	return views
+}
+
+func renderDiff(results DiffResults) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Comparing arXiv:%s with arXiv:%s", results.From, results.To)
+	if !results.SamePaper {
+		b.WriteString(", which are different papers")
+	}
+	b.WriteString(".\n")
+	if len(results.Changed) == 0 {
+		b.WriteString("The title, authors, abstract, categories and comment are the same.\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "Changed: %s.\n\n", strings.Join(results.Changed, ", "))
+	b.WriteString(results.Diff)
+	return b.String()
+}
@@ -0,0 +1,143 @@
+package tools
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestDiffTool(t *testing.T) {
+	tool := DiffTool()
+	if tool.Name != "arxiv-diff" {
+		t.Errorf("expected tool name 'arxiv-diff', got '%s'", tool.Name)
+	}
+	if tool.InputSchema == nil {
+		t.Error("expected InputSchema to be non-nil")
+	}
+}
+
+func TestDiffHandler(t *testing.T) {
+	handler := DiffHandler(newTestClient(t))
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		query     DiffQuery
+		from, to  string
+		samePaper bool
+		changed   []string
+		contains  []string
+	}{
+		{
+			name:      "first and current version",
+			query:     DiffQuery{From: "1706.03762"},
+			from:      "1706.03762v1",
+			to:        "1706.03762v7",
+			samePaper: true,
+			changed:   []string{"abstract"},
+			contains: []string{
+				"--- 1706.03762v1 abstract\n+++ 1706.03762v7 abstract\n",
+				"{+The best performing models also connect the encoder and decoder through an attention mechanism.+}",
+			},
+		},
+		{
+			name:      "versions given",
+			query:     DiffQuery{From: "1706.03762v7", To: "arXiv:1706.03762v1"},
+			from:      "1706.03762v7",
+			to:        "1706.03762v1",
+			samePaper: true,
+			changed:   []string{"abstract"},
+			contains:  []string{"[-The best performing"},
+		},
+		{
+			name:      "same version",
+			query:     DiffQuery{From: "1706.03762v7"},
+			from:      "1706.03762v7",
+			to:        "1706.03762v7",
+			samePaper: true,
+			contains:  []string{"are the same"},
+		},
+		{
+			name:     "different papers",
+			query:    DiffQuery{From: "1706.03762", To: "1810.04805"},
+			from:     "1706.03762v7",
+			to:       "1810.04805v2",
+			changed:  []string{"title", "authors", "abstract", "categories", "comment"},
+			contains: []string{"which are different papers", "--- 1706.03762v7 categories\n+++ 1810.04805v2 categories\n@@ -1,2 +1 @@\ncs.CL, [-cs.LG-]\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, results, err := handler(ctx, &mcp.CallToolRequest{}, tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.IsError {
+				t.Fatalf("unexpected tool error: %+v", results.Error)
+			}
+			if results.From != tt.from || results.To != tt.to || results.SamePaper != tt.samePaper {
+				t.Errorf("expected %s to %s (same paper %v), got %s to %s (%v)", tt.from, tt.to, tt.samePaper, results.From, results.To, results.SamePaper)
+			}
+			if !reflect.DeepEqual(results.Changed, tt.changed) || len(results.Fields) != len(tt.changed) {
+				t.Errorf("expected changes to %v, got %v", tt.changed, results.Changed)
+			}
+			text := result.Content[0].(*mcp.TextContent).Text
+			for _, s := range tt.contains {
+				if !strings.Contains(text, s) {
+					t.Errorf("expected %q in:\n%s", s, text)
+				}
+			}
+		})
+	}
+
+	t.Run("structured hunks", func(t *testing.T) {
+		_, results, err := handler(ctx, &mcp.CallToolRequest{}, DiffQuery{From: "1706.03762v1", To: "1706.03762v7"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		hunks := results.Fields[0].Hunks
+		if len(hunks) != 2 {
+			t.Fatalf("expected 2 hunks in the abstract, got %+v", hunks)
+		}
+		expected := []DiffEdit{
+			{Op: "equal", Text: "networks in an encoder-decoder configuration."},
+			{Op: "insert", Text: "The best performing models also connect the encoder and decoder through an attention mechanism."},
+			{Op: "equal", Text: "We propose a new simple"},
+		}
+		if !reflect.DeepEqual(hunks[0].Edits, expected) {
+			t.Errorf("expected edits %+v, got %+v", expected, hunks[0].Edits)
+		}
+		if hunks[0].OldStart != 14 || hunks[0].OldCount != 10 || hunks[0].NewStart != 14 || hunks[0].NewCount != 24 {
+			t.Errorf("unexpected hunk positions %+v", hunks[0])
+		}
+	})
+
+	errorTests := []struct {
+		name  string
+		query DiffQuery
+		kind  string
+		field string
+	}{
+		{name: "invalid from", query: DiffQuery{From: "attention"}, kind: errorInvalidInput, field: "from"},
+		{name: "invalid to", query: DiffQuery{From: "1706.03762", To: "bert"}, kind: errorInvalidInput, field: "to"},
+		{name: "missing version", query: DiffQuery{From: "1706.03762v3"}, kind: errorNotFound, field: "from"},
+		{name: "unknown paper", query: DiffQuery{From: "1706.03762", To: "2401.99999"}, kind: errorNotFound, field: "to"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, results, err := handler(ctx, &mcp.CallToolRequest{}, tt.query)
+			if err != nil {
+				t.Fatalf("expected a tool error, got %v", err)
+			}
+			if !result.IsError || results.Error == nil {
+				t.Fatal("expected IsError and an error in the results")
+			}
+			if results.Error.Kind != tt.kind || results.Error.Field != tt.field {
+				t.Errorf("expected %s error on %q, got %+v", tt.kind, tt.field, results.Error)
+			}
+		})
+	}
+}
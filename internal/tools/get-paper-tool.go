@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivid"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type GetPaperQuery struct {
+	IDs          []string `json:"ids" jsonschema:"arXiv IDs in any common form: 2401.01234, 2401.01234v3, arXiv:2401.01234, hep-th/9901001, or an arxiv.org abs or pdf URL"`
+	ReturnFields []string `json:"return_fields,omitempty" jsonschema:"array of fields to return. Returns all if empty"`
+}
+
+type GetPaperResults struct {
+	Entries  []EntryView `json:"entries,omitempty"`
+	NotFound []string    `json:"notFound,omitempty" jsonschema:"normalized IDs that arXiv has no paper for"`
+	Invalid  []string    `json:"invalid,omitempty" jsonschema:"inputs that could not be recognized as arXiv IDs"`
+	CacheHit bool        `json:"cache_hit" jsonschema:"whether the results were served from the cache"`
+	Error    *ToolError  `json:"error,omitempty" jsonschema:"why the lookup failed, if it did"`
+}
+
+func GetPaperTool() *mcp.Tool {
+	inputSchema, err := jsonschema.For[GetPaperQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+
+	getPaperTool := mcp.Tool{
+		Name:        "arxiv-get-paper",
+		Description: "Fetches papers from arXiv by ID, returning them in the order requested",
+		InputSchema: inputSchema,
+	}
+	return &getPaperTool
+}
+
+func GetPaperHandler(searcher Searcher) mcp.ToolHandlerFor[GetPaperQuery, GetPaperResults] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, query GetPaperQuery) (*mcp.CallToolResult, GetPaperResults, error) {
+		result, paperResults, err := getPapers(ctx, searcher, query)
+		if toolErr := asToolError(err); toolErr != nil {
+			return errorResult(toolErr), GetPaperResults{Error: toolErr}, nil
+		}
+		return result, paperResults, err
+	}
+}
+
+func getPapers(ctx context.Context, searcher Searcher, query GetPaperQuery) (*mcp.CallToolResult, GetPaperResults, error) {
+	ids, invalid := parseIDs(query.IDs)
+	if len(ids) == 0 {
+		return nil, GetPaperResults{}, invalidInput("ids", `["2401.01234", "hep-th/9901001v2"]`, "no valid arXiv IDs given: %q", query.IDs)
+	}
+
+	found, err := lookupPapers(ctx, searcher, ids, "")
+	if err != nil {
+		return nil, GetPaperResults{}, err
+	}
+
+	paperResults := GetPaperResults{
+		Entries:  make([]EntryView, len(found.entries)),
+		NotFound: found.notFound,
+		Invalid:  invalid,
+		CacheHit: found.cacheHit,
+	}
+	for i, entry := range found.entries {
+		paperResults.Entries[i] = filterEntry(entry, query.ReturnFields)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: renderPapers(paperResults)}},
+	}, paperResults, nil
+}
+
+// renderPapers renders the papers found as markdown, followed by the IDs
+// that arXiv has no paper for and the inputs that are not IDs.
+func renderPapers(results GetPaperResults) string {
+	var b strings.Builder
+	if len(results.Entries) == 0 {
+		b.WriteString("No papers found.\n")
+	} else {
+		renderFull(&b, results.Entries)
+	}
+	if len(results.NotFound) > 0 {
+		fmt.Fprintf(&b, "\nNo arXiv paper found for %s.\n", strings.Join(results.NotFound, ", "))
+	}
+	if len(results.Invalid) > 0 {
+		quoted := make([]string, len(results.Invalid))
+		for i, raw := range results.Invalid {
+			quoted[i] = strconv.Quote(raw)
+		}
+		fmt.Fprintf(&b, "\nNot arXiv IDs: %s.\n", strings.Join(quoted, ", "))
+	}
+	return b.String()
+}
+
+// parseIDs normalizes the raw IDs, dropping duplicates and collecting the
+// inputs that are not arXiv IDs.
+func parseIDs(raw []string) ([]arxivid.ID, []string) {
+	var ids []arxivid.ID
+	var invalid []string
+	seen := make(map[arxivid.ID]bool)
+	for _, r := range raw {
+		id, err := arxivid.Parse(r)
+		if err != nil {
+			invalid = append(invalid, r)
+			continue
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids, invalid
+}
@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivclient"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivid"
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestGetPaperTool(t *testing.T) {
+	tool := GetPaperTool()
+	if tool.Name != "arxiv-get-paper" {
+		t.Errorf("expected tool name 'arxiv-get-paper', got '%s'", tool.Name)
+	}
+	if tool.InputSchema == nil {
+		t.Error("expected InputSchema to be non-nil")
+	}
+}
+
+func TestParseIDs(t *testing.T) {
+	ids, invalid := parseIDs([]string{
+		"arXiv:2401.01234",
+		"https://arxiv.org/abs/2401.01234",
+		"https://arxiv.org/pdf/2302.00001v2.pdf",
+		"not-an-id",
+		"hep-th/9901001",
+	})
+
+	expected := []arxivid.ID{
+		{Base: "2401.01234"},
+		{Base: "2302.00001", Version: 2},
+		{Base: "hep-th/9901001"},
+	}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected IDs %v, got %v", expected, ids)
+	}
+	if !reflect.DeepEqual(invalid, []string{"not-an-id"}) {
+		t.Errorf("expected invalid [not-an-id], got %v", invalid)
+	}
+}
+
+// errorSearcher fails every search with err.
+type errorSearcher struct {
+	err error
+}
+
+func (s errorSearcher) Search(ctx context.Context, params arxiv.SearchParams) (arxiv.SearchResults, error) {
+	return arxiv.SearchResults{}, s.err
+}
+
+func TestGetPaperHandler(t *testing.T) {
+	t.Run("none found", func(t *testing.T) {
+		searcher := errorSearcher{err: &arxivclient.NotFoundError{IDs: []string{"2401.99999"}}}
+		query := GetPaperQuery{
+			IDs: []string{"2401.99999"},
+		}
+		result, paperResults, err := GetPaperHandler(searcher)(context.Background(), &mcp.CallToolRequest{}, query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(paperResults.NotFound, []string{"2401.99999"}) {
+			t.Errorf("expected notFound [2401.99999], got %v", paperResults.NotFound)
+		}
+		if text := result.Content[0].(*mcp.TextContent).Text; text != "No papers found.\n\nNo arXiv paper found for 2401.99999.\n" {
+			t.Errorf("unexpected text content %q", text)
+		}
+	})
+
+	t.Run("upstream error", func(t *testing.T) {
+		searcher := errorSearcher{err: &arxivclient.UnavailableError{Status: "503 Service Unavailable"}}
+		query := GetPaperQuery{
+			IDs: []string{"2401.01234"},
+		}
+		result, paperResults, err := GetPaperHandler(searcher)(context.Background(), &mcp.CallToolRequest{}, query)
+		if err != nil {
+			t.Fatalf("expected a tool error, got protocol error %v", err)
+		}
+		if !result.IsError {
+			t.Error("expected IsError for upstream failure")
+		}
+		if paperResults.Error == nil || paperResults.Error.Kind != errorUnavailable {
+			t.Errorf("expected %s error, got %+v", errorUnavailable, paperResults.Error)
+		}
+	})
+
+	t.Run("no valid ids", func(t *testing.T) {
+		query := GetPaperQuery{
+			IDs: []string{"nonsense"},
+		}
+		result, paperResults, err := GetPaperHandler(errorSearcher{})(context.Background(), &mcp.CallToolRequest{}, query)
+		if err != nil {
+			t.Fatalf("expected a tool error, got protocol error %v", err)
+		}
+		if paperResults.Error == nil || paperResults.Error.Field != "ids" {
+			t.Errorf("expected error on field ids, got %+v", paperResults.Error)
+		}
+		if !result.IsError {
+			t.Error("expected error for input without valid IDs")
+		}
+	})
+
+	t.Run("mixed id formats", func(t *testing.T) {
+		query := GetPaperQuery{
+			IDs: []string{"arXiv:1706.03762", "https://arxiv.org/abs/hep-th/9711200", "2401.99999"},
+		}
+		result, paperResults, err := GetPaperHandler(newTestClient(t))(context.Background(), &mcp.CallToolRequest{}, query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result == nil {
+			t.Error("expected non-nil CallToolResult")
+		}
+		var ids []string
+		for _, entry := range paperResults.Entries {
+			ids = append(ids, *entry.ID)
+		}
+		expectedIDs := []string{"http://arxiv.org/abs/1706.03762v7", "http://arxiv.org/abs/hep-th/9711200v3"}
+		if !reflect.DeepEqual(ids, expectedIDs) {
+			t.Errorf("expected entries %v, got %v", expectedIDs, ids)
+		}
+		if !reflect.DeepEqual(paperResults.NotFound, []string{"2401.99999"}) {
+			t.Errorf("expected notFound [2401.99999], got %v", paperResults.NotFound)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		for _, want := range []string{"## Attention Is All You Need", "- **ID:** 1706.03762v7", "No arXiv paper found for 2401.99999."} {
+			if !strings.Contains(text, want) {
+				t.Errorf("expected %q in the text content:\n%s", want, text)
+			}
+		}
+	})
+}
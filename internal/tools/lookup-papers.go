@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivclient"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivid"
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// paperLookup is what arXiv has for a list of IDs.
+type paperLookup struct {
+	entries  []arxiv.EntryMetadata // in the order of the IDs
+	notFound []string              // IDs arXiv has no paper for
+	cacheHit bool
+}
+
+// lookupPapers fetches the entries for ids, the latest version of those
+// without one. A paper arXiv does not have is listed in notFound; if it has
+// none of them and field is set, a not_found error on field is returned
+// instead.
+func lookupPapers(ctx context.Context, searcher Searcher, ids []arxivid.ID, field string) (paperLookup, error) {
+	idList := make([]string, len(ids))
+	for i, id := range ids {
+		idList[i] = id.String()
+	}
+	results, cacheHit, err := runSearch(ctx, searcher, arxiv.SearchParams{IdList: idList, MaxResults: len(idList)})
+	var notFoundErr *arxivclient.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		// None of the papers exist, which is reported like some not existing.
+		results, err = arxiv.SearchResults{}, nil
+	}
+	if err != nil {
+		return paperLookup{}, err
+	}
+
+	entries, notFound := matchEntries(ids, results.Entries)
+	if len(entries) == 0 && field != "" {
+		reason := "arXiv has no paper " + idList[0]
+		if len(idList) > 1 {
+			reason = "arXiv has none of the papers: " + strings.Join(notFound, ", ")
+		}
+		return paperLookup{}, &ToolError{Kind: errorNotFound, Field: field, Reason: reason}
+	}
+	return paperLookup{entries: entries, notFound: notFound, cacheHit: cacheHit}, nil
+}
+
+// matchEntries orders entries to follow ids and returns the IDs that have no
+// matching entry.
+func matchEntries(ids []arxivid.ID, entries []arxiv.EntryMetadata) ([]arxiv.EntryMetadata, []string) {
+	parsed := make([]arxivid.ID, len(entries))
+	for i, entry := range entries {
+		// Unknown IDs come back as error entries whose IDs do not parse.
+		parsed[i], _ = arxivid.Parse(entry.ID)
+	}
+
+	var matched []arxiv.EntryMetadata
+	var notFound []string
+	for _, id := range ids {
+		found := false
+		for i, entryID := range parsed {
+			if entryID.Base != "" && id.Matches(entryID) {
+				matched = append(matched, entries[i])
+				found = true
+				break
+			}
+		}
+		if !found {
+			notFound = append(notFound, id.String())
+		}
+	}
+	return matched, notFound
+}
@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivclient"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivid"
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+func TestLookupPapers(t *testing.T) {
+	client := newTestClient(t)
+	ids := func(raw ...string) []arxivid.ID {
+		var parsed []arxivid.ID
+		for _, r := range raw {
+			id, err := arxivid.Parse(r)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			parsed = append(parsed, id)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		name     string
+		searcher Searcher
+		ids      []arxivid.ID
+		field    string
+		entries  []string
+		notFound []string
+		kind     string
+	}{
+		{name: "in order", searcher: client, ids: ids("hep-th/9711200", "1706.03762v1"), entries: []string{"http://arxiv.org/abs/hep-th/9711200v3", "http://arxiv.org/abs/1706.03762v1"}},
+		{name: "some missing", searcher: client, ids: ids("1706.03762", "2401.99999"), field: "ids", entries: []string{"http://arxiv.org/abs/1706.03762v7"}, notFound: []string{"2401.99999"}},
+		{name: "none found", searcher: client, ids: ids("2401.99999"), notFound: []string{"2401.99999"}},
+		{name: "none found on a field", searcher: client, ids: ids("2401.99999"), field: "id", kind: errorNotFound},
+		{name: "arXiv says none exist", searcher: errorSearcher{err: &arxivclient.NotFoundError{IDs: []string{"2401.99999"}}}, ids: ids("2401.99999"), notFound: []string{"2401.99999"}},
+		{name: "upstream error", searcher: errorSearcher{err: &arxivclient.UnavailableError{Status: "503 Service Unavailable"}}, ids: ids("1706.03762"), kind: errorUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := lookupPapers(context.Background(), tt.searcher, tt.ids, tt.field)
+			if tt.kind != "" {
+				toolErr := asToolError(err)
+				if toolErr == nil || toolErr.Kind != tt.kind || toolErr.Field != tt.field && tt.kind == errorNotFound {
+					t.Errorf("expected %s error on %q, got %+v", tt.kind, tt.field, toolErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var entries []string
+			for _, entry := range found.entries {
+				entries = append(entries, entry.ID)
+			}
+			if !reflect.DeepEqual(entries, tt.entries) || !reflect.DeepEqual(found.notFound, tt.notFound) {
+				t.Errorf("expected %v and not found %v, got %v and %v", tt.entries, tt.notFound, entries, found.notFound)
+			}
+		})
+	}
+}
+
+func TestMatchEntries(t *testing.T) {
+	ids := []arxivid.ID{
+		{Base: "2302.00001", Version: 2},
+		{Base: "2401.01234"},
+		{Base: "2401.99999"},
+		{Base: "hep-th/9901001"},
+	}
+	entries := []arxiv.EntryMetadata{
+		{ID: "http://arxiv.org/abs/hep-th/9901001v1", Title: "old"},
+		{ID: "http://arxiv.org/abs/2401.01234v3", Title: "latest"},
+		{ID: "http://arxiv.org/api/errors#incorrect_id_format_for_2401.99999", Title: "Error"},
+		{ID: "http://arxiv.org/abs/2302.00001v2", Title: "second version"},
+	}
+
+	matched, notFound := matchEntries(ids, entries)
+
+	var titles []string
+	for _, entry := range matched {
+		titles = append(titles, entry.Title)
+	}
+	expectedTitles := []string{"second version", "latest", "old"}
+	if !reflect.DeepEqual(titles, expectedTitles) {
+		t.Errorf("expected entries in requested order %v, got %v", expectedTitles, titles)
+	}
+	if !reflect.DeepEqual(notFound, []string{"2401.99999"}) {
+		t.Errorf("expected notFound [2401.99999], got %v", notFound)
+	}
+}
@@ -0,0 +1,239 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/listing"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/taxonomy"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Lister fetches arXiv's announcement feeds. Like Searcher, it is shared by
+// all sessions so that requests are rate limited together.
+type Lister interface {
+	Listing(ctx context.Context, categories []string) (listing.Feed, error)
+}
+
+// Kinds of announcement that can be listed.
+const (
+	listingNew         = "new"
+	listingCross       = "cross"
+	listingReplacement = "replace"
+)
+
+const (
+	defaultListingResults = 100
+	maxListingResults     = 2000
+)
+
+type NewListingsQuery struct {
+	Categories   []string `json:"categories" jsonschema:"categories to list, using arXiv category taxonomy, such as cs.LG, or archive tags such as cs or astro-ph"`
+	Include      []string `json:"include,omitempty" jsonschema:"kinds of announcement to list: new for new submissions, cross for cross-lists from other categories and replace for new versions of earlier papers. Lists new and cross if empty"`
+	MaxResults   int      `json:"max,omitempty" jsonschema:"most papers to return of each kind. Defaults to 100"`
+	ReturnFields []string `json:"return_fields,omitempty" jsonschema:"array of fields to return. Returns all if empty"`
+	Format       string   `json:"format,omitempty" jsonschema:"how to render the text content: table for a markdown table, list for one line per paper or full for markdown with abstracts. Defaults to list"`
+}
+
+type NewListingsResults struct {
+	Categories   []string      `json:"categories"`
+	Date         string        `json:"date,omitempty" jsonschema:"date of the announcement, in YYYY-MM-DD. Absent if the feed is empty, as it is on days without an announcement"`
+	New          []EntryView   `json:"new,omitempty" jsonschema:"papers submitted to the categories"`
+	CrossLists   []EntryView   `json:"cross_lists,omitempty" jsonschema:"papers submitted to other categories and cross-listed to these"`
+	Replacements []EntryView   `json:"replacements,omitempty" jsonschema:"new versions of papers announced before"`
+	Counts       ListingCounts `json:"counts" jsonschema:"number of papers of each kind in the announcement, including those not returned"`
+	Error        *ToolError    `json:"error,omitempty" jsonschema:"why the listing could not be fetched, if it could not"`
+}
+
+type ListingCounts struct {
+	New          int `json:"new"`
+	CrossLists   int `json:"cross_lists"`
+	Replacements int `json:"replacements"`
+}
+
+func NewListingsTool() *mcp.Tool {
+	inputSchema, err := jsonschema.For[NewListingsQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	inputSchema.Properties["include"].Items.Enum = []any{listingNew, listingCross, listingReplacement}
+	inputSchema.Properties["format"].Enum = []any{formatTable, formatList, formatFull}
+
+	newListingsTool := mcp.Tool{
+		Name:        "arxiv-new-listings",
+		Description: "Lists the papers in arXiv's latest daily announcement for categories, as the \"new\" listing pages do, separating new submissions, cross-lists and replacements. Use this rather than a date search to see what is new, since papers are announced on arXiv's schedule rather than when submitted",
+		InputSchema: inputSchema,
+	}
+	return &newListingsTool
+}
+
+func NewListingsHandler(lister Lister) mcp.ToolHandlerFor[NewListingsQuery, NewListingsResults] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, query NewListingsQuery) (*mcp.CallToolResult, NewListingsResults, error) {
+		result, listingResults, err := newListings(ctx, lister, query)
+		if toolErr := asToolError(err); toolErr != nil {
+			return errorResult(toolErr), NewListingsResults{Error: toolErr}, nil
+		}
+		return result, listingResults, err
+	}
+}
+
+func newListings(ctx context.Context, lister Lister, query NewListingsQuery) (*mcp.CallToolResult, NewListingsResults, error) {
+	categories, err := listingCategories(query.Categories)
+	if err != nil {
+		return nil, NewListingsResults{}, err
+	}
+	include := query.Include
+	if len(include) == 0 {
+		include = []string{listingNew, listingCross}
+	}
+	for _, kind := range include {
+		switch kind {
+		case listingNew, listingCross, listingReplacement:
+		default:
+			return nil, NewListingsResults{}, invalidInput("include", `["new", "cross"]`, "must be new, cross or replace, got %q", kind)
+		}
+	}
+	maxResults := query.MaxResults
+	if maxResults == 0 {
+		maxResults = defaultListingResults
+	}
+	if maxResults < 0 || maxResults > maxListingResults {
+		return nil, NewListingsResults{}, invalidInput("max", "100", "must be between 1 and %d, got %d", maxListingResults, maxResults)
+	}
+	if err := validateFormat(query.Format); err != nil {
+		return nil, NewListingsResults{}, err
+	}
+	if isCitationFormat(query.Format) {
+		return nil, NewListingsResults{}, invalidInput("format", formatTable, "must be %s, %s or %s, got %q", formatTable, formatList, formatFull, query.Format)
+	}
+
+	feed, err := lister.Listing(ctx, categories)
+	if err != nil {
+		return nil, NewListingsResults{}, err
+	}
+
+	results := NewListingsResults{Categories: categories}
+	// arXiv dates even an empty feed, with the last announcement.
+	if len(feed.Items) > 0 && !feed.Date.IsZero() {
+		results.Date = feed.Date.Format("2006-01-02")
+	}
+	for _, item := range feed.Items {
+		var kind string
+		var list *[]EntryView
+		switch item.Type {
+		case listing.New:
+			kind, list = listingNew, &results.New
+			results.Counts.New++
+		case listing.CrossList:
+			kind, list = listingCross, &results.CrossLists
+			results.Counts.CrossLists++
+		case listing.Replacement, listing.ReplacementCrossList:
+			kind, list = listingReplacement, &results.Replacements
+			results.Counts.Replacements++
+		default:
+			continue
+		}
+		if !slices.Contains(include, kind) || len(*list) >= maxResults {
+			continue
+		}
+		view := filterEntry(item.Entry, query.ReturnFields)
+		// The feeds do not give when a paper was first submitted.
+		view.Published = nil
+		*list = append(*list, view)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: renderListings(results, include, query.Format)}},
+	}, results, nil
+}
+
+// listingCategories validates tags against the taxonomy. Unlike searches,
+// feeds take archive tags such as cs as they are, so archives are not
+// expanded into their categories.
+func listingCategories(tags []string) ([]string, error) {
+	arxivTaxonomy := taxonomy.Default()
+	var categories []string
+	var categoryErr CategoryError
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		archive, wildcard := strings.CutSuffix(tag, ".*")
+		if category, ok := arxivTaxonomy.Lookup(tag); ok && !wildcard {
+			tag = category.Tag
+		} else if len(arxivTaxonomy.ArchiveCategories(strings.ToLower(archive))) > 0 {
+			tag = strings.ToLower(archive)
+		} else {
+			categoryErr.Unknown = append(categoryErr.Unknown, unknownCategory(tag))
+			continue
+		}
+		if !slices.Contains(categories, tag) {
+			categories = append(categories, tag)
+		}
+	}
+
+	if len(categoryErr.Unknown) > 0 {
+		toolErr := asToolError(&categoryErr)
+		toolErr.Field = "categories"
+		return nil, toolErr
+	}
+	if len(categories) == 0 {
+		return nil, invalidInput("categories", `["cs.LG"]`, "at least one category is needed")
+	}
+	return categories, nil
+}
+
+// renderListings renders each kind of announcement that was asked for under
+// a heading, in the given search format.
+func renderListings(results NewListingsResults, include []string, format string) string {
+	var b strings.Builder
+	joined := strings.Join(results.Categories, ", ")
+	if results.Counts == (ListingCounts{}) {
+		fmt.Fprintf(&b, "There is no current announcement for %s; arXiv makes none on weekends and holidays.\n", joined)
+		return b.String()
+	}
+	announcement := "arXiv announcement"
+	if results.Date != "" {
+		announcement += " of " + results.Date
+	}
+	fmt.Fprintf(&b, "%s for %s: %d new, %d cross-lists, %d replacements.\n",
+		announcement, joined, results.Counts.New, results.Counts.CrossLists, results.Counts.Replacements)
+
+	sections := []struct {
+		kind    string
+		heading string
+		entries []EntryView
+		count   int
+	}{
+		{listingNew, "New submissions", results.New, results.Counts.New},
+		{listingCross, "Cross-lists", results.CrossLists, results.Counts.CrossLists},
+		{listingReplacement, "Replacements", results.Replacements, results.Counts.Replacements},
+	}
+	for _, section := range sections {
+		if !slices.Contains(include, section.kind) {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s", section.heading)
+		if len(section.entries) < section.count {
+			fmt.Fprintf(&b, " (showing %d of %d)", len(section.entries), section.count)
+		}
+		b.WriteString("\n\n")
+		if len(section.entries) == 0 {
+			b.WriteString("None.\n")
+			continue
+		}
+		switch format {
+		case formatTable:
+			renderTable(&b, section.entries)
+		case formatFull:
+			renderFull(&b, section.entries)
+		default:
+			renderList(&b, section.entries)
+		}
+	}
+	return b.String()
+}
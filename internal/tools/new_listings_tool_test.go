@@ -0,0 +1,150 @@
+package tools
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/listing"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestNewListingsTool(t *testing.T) {
+	tool := NewListingsTool()
+	if tool.Name != "arxiv-new-listings" {
+		t.Errorf("expected tool name 'arxiv-new-listings', got '%s'", tool.Name)
+	}
+	if tool.InputSchema == nil {
+		t.Fatal("expected InputSchema to be non-nil")
+	}
+	if enum := tool.InputSchema.Properties["include"].Items.Enum; len(enum) != 3 {
+		t.Errorf("expected 3 include values, got %v", enum)
+	}
+}
+
+func TestNewListingsHandler(t *testing.T) {
+	handler := NewListingsHandler(newTestClient(t))
+	ctx := context.Background()
+
+	ids := func(entries []EntryView) []string {
+		var ids []string
+		for _, e := range entries {
+			ids = append(ids, displayID(*e.ID))
+		}
+		return ids
+	}
+
+	t.Run("new and cross-lists by default", func(t *testing.T) {
+		result, results, err := handler(ctx, &mcp.CallToolRequest{}, NewListingsQuery{Categories: []string{"cs.CV", "astro-ph", "cs.CL"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("unexpected tool error: %+v", results.Error)
+		}
+		if !reflect.DeepEqual(results.Categories, []string{"cs.CV", "astro-ph", "cs.CL"}) {
+			t.Errorf("unexpected categories %v", results.Categories)
+		}
+		if results.Date != "2023-08-02" {
+			t.Errorf("expected the announcement date, got %q", results.Date)
+		}
+		if !reflect.DeepEqual(ids(results.New), []string{"1512.03385v1"}) || !reflect.DeepEqual(ids(results.CrossLists), []string{"1602.03837v1"}) {
+			t.Errorf("unexpected new %v and cross-lists %v", ids(results.New), ids(results.CrossLists))
+		}
+		if results.Replacements != nil {
+			t.Errorf("expected no replacements unless asked for, got %v", ids(results.Replacements))
+		}
+		if results.Counts != (ListingCounts{New: 1, CrossLists: 1, Replacements: 2}) {
+			t.Errorf("unexpected counts %+v", results.Counts)
+		}
+		if results.New[0].Published != nil || results.New[0].Updated == nil {
+			t.Error("expected the announcement date as updated and no published date")
+		}
+
+		text := result.Content[0].(*mcp.TextContent).Text
+		for _, s := range []string{"1 new, 1 cross-lists, 2 replacements", "## New submissions", "Deep Residual Learning", "## Cross-lists", "1602.03837v1"} {
+			if !strings.Contains(text, s) {
+				t.Errorf("expected %q in:\n%s", s, text)
+			}
+		}
+		if strings.Contains(text, "## Replacements") {
+			t.Errorf("expected no replacements section in:\n%s", text)
+		}
+	})
+
+	t.Run("replacements with a limit", func(t *testing.T) {
+		result, results, err := handler(ctx, &mcp.CallToolRequest{}, NewListingsQuery{
+			Categories:   []string{"cs"},
+			Include:      []string{"replace"},
+			MaxResults:   2,
+			ReturnFields: []string{"id", "title"},
+			Format:       formatTable,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if results.New != nil || len(results.Replacements) != 2 || results.Counts.Replacements != 5 {
+			t.Errorf("expected 2 of 5 replacements only, got %+v", results)
+		}
+		if results.Replacements[0].Authors != nil {
+			t.Error("expected the structured content to keep to the return fields")
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "## Replacements (showing 2 of 5)\n\n| ID | Title |") {
+			t.Errorf("expected a table of replacements in:\n%s", text)
+		}
+	})
+
+	t.Run("no announcement", func(t *testing.T) {
+		// arXiv dates an empty feed with its last announcement.
+		date := time.Date(2024, 4, 12, 4, 0, 0, 0, time.UTC)
+		lister := stubLister{feed: listing.Feed{Title: "math.AG updates on arXiv.org", Date: date}}
+		result, results, err := NewListingsHandler(lister)(ctx, &mcp.CallToolRequest{}, NewListingsQuery{Categories: []string{"math.AG"}})
+		if err != nil || result.IsError {
+			t.Fatalf("unexpected error: %v %+v", err, results.Error)
+		}
+		if results.Date != "" {
+			t.Errorf("expected no date, got %q", results.Date)
+		}
+		if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "no current announcement") {
+			t.Errorf("unexpected text %q", text)
+		}
+	})
+
+	errorTests := []struct {
+		name  string
+		query NewListingsQuery
+		field string
+	}{
+		{name: "no categories", query: NewListingsQuery{}, field: "categories"},
+		{name: "unknown category", query: NewListingsQuery{Categories: []string{"cs.XYZ"}}, field: "categories"},
+		{name: "unknown kind", query: NewListingsQuery{Categories: []string{"cs.LG"}, Include: []string{"withdrawn"}}, field: "include"},
+		{name: "too many", query: NewListingsQuery{Categories: []string{"cs.LG"}, MaxResults: 5000}, field: "max"},
+		{name: "citation format", query: NewListingsQuery{Categories: []string{"cs.LG"}, Format: formatBibTeX}, field: "format"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, results, err := handler(ctx, &mcp.CallToolRequest{}, tt.query)
+			if err != nil {
+				t.Fatalf("expected a tool error, got %v", err)
+			}
+			if !result.IsError || results.Error == nil {
+				t.Fatal("expected IsError and an error in the results")
+			}
+			if results.Error.Kind != errorInvalidInput || results.Error.Field != tt.field {
+				t.Errorf("expected invalid input on %q, got %+v", tt.field, results.Error)
+			}
+		})
+	}
+}
+
+// stubLister returns feed for every listing.
+type stubLister struct {
+	feed listing.Feed
+}
+
+func (l stubLister) Listing(ctx context.Context, categories []string) (listing.Feed, error) {
+	return l.feed, nil
+}
@@ -0,0 +1,271 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivid"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/pdftext"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Downloader downloads paper files from arXiv.
+type Downloader interface {
+	PDF(ctx context.Context, id string) ([]byte, error)
+	Source(ctx context.Context, id string) ([]byte, error)
+}
+
+// cachedDownloader is a Downloader that can report whether a file came from
+// a cache.
+type cachedDownloader interface {
+	PDFCached(ctx context.Context, id string) ([]byte, bool, error)
+	SourceCached(ctx context.Context, id string) ([]byte, bool, error)
+}
+
+// downloadPDF downloads a PDF with downloader and reports whether it came
+// from a cache.
+func downloadPDF(ctx context.Context, downloader Downloader, id string) ([]byte, bool, error) {
+	if cached, ok := downloader.(cachedDownloader); ok {
+		return cached.PDFCached(ctx, id)
+	}
+	data, err := downloader.PDF(ctx, id)
+	return data, false, err
+}
+
+// downloadSource downloads a source with downloader and reports whether it
+// came from a cache.
+func downloadSource(ctx context.Context, downloader Downloader, id string) ([]byte, bool, error) {
+	if cached, ok := downloader.(cachedDownloader); ok {
+		return cached.SourceCached(ctx, id)
+	}
+	data, err := downloader.Source(ctx, id)
+	return data, false, err
+}
+
+const (
+	defaultReadChars = 20000
+	maxReadChars     = 100000
+)
+
+type ReadPaperQuery struct {
+	ID       string `json:"id" jsonschema:"arXiv ID of the paper, with a version to read a specific one"`
+	Page     int    `json:"page,omitempty" jsonschema:"page to start reading at, counting from 1. Returns whole pages from there, as many as fit in max_chars"`
+	Offset   int    `json:"offset,omitempty" jsonschema:"character offset in the full text to start reading at, such as a next_offset or the offset of a section. Ignored if page is set"`
+	MaxChars int    `json:"max_chars,omitempty" jsonschema:"most characters of text to return. Defaults to 20000, and can be at most 100000"`
+}
+
+type ReadPaperResults struct {
+	ID         string         `json:"id"`
+	TotalPages int            `json:"total_pages"`
+	TotalChars int            `json:"total_chars" jsonschema:"length of the full text, in characters"`
+	FirstPage  int            `json:"first_page,omitempty" jsonschema:"page the returned text starts on"`
+	LastPage   int            `json:"last_page,omitempty" jsonschema:"page the returned text ends on"`
+	Offset     int            `json:"offset" jsonschema:"character offset of the returned text in the full text"`
+	Text       string         `json:"text,omitempty"`
+	NextOffset int            `json:"next_offset,omitempty" jsonschema:"pass as offset to read on. Absent at the end of the paper"`
+	Sections   []PaperSection `json:"sections,omitempty" jsonschema:"section headings found in the whole paper"`
+	CacheHit   bool           `json:"cache_hit" jsonschema:"whether the PDF was served from the cache"`
+	Error      *ToolError     `json:"error,omitempty" jsonschema:"why the paper could not be read, if it could not"`
+}
+
+type PaperSection struct {
+	Title  string `json:"title"`
+	Level  int    `json:"level" jsonschema:"1 for a section, 2 for a subsection, and so on"`
+	Page   int    `json:"page"`
+	Offset int    `json:"offset" jsonschema:"character offset of the heading in the full text"`
+}
+
+func ReadPaperTool() *mcp.Tool {
+	inputSchema, err := jsonschema.For[ReadPaperQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+
+	readPaperTool := mcp.Tool{
+		Name:        "arxiv-read-paper",
+		Description: "Reads the full text of a paper, extracted from its PDF. Long papers are returned in parts: read on from next_offset, or jump to a page or to the offset of a section",
+		InputSchema: inputSchema,
+	}
+	return &readPaperTool
+}
+
+func ReadPaperHandler(downloader Downloader) mcp.ToolHandlerFor[ReadPaperQuery, ReadPaperResults] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, query ReadPaperQuery) (*mcp.CallToolResult, ReadPaperResults, error) {
+		result, paperResults, err := readPaper(ctx, downloader, query)
+		if toolErr := asToolError(err); toolErr != nil {
+			return errorResult(toolErr), ReadPaperResults{Error: toolErr}, nil
+		}
+		return result, paperResults, err
+	}
+}
+
+func readPaper(ctx context.Context, downloader Downloader, query ReadPaperQuery) (*mcp.CallToolResult, ReadPaperResults, error) {
+	id, err := arxivid.Parse(query.ID)
+	if err != nil {
+		return nil, ReadPaperResults{}, invalidInput("id", "1706.03762", "%q is not an arXiv ID", query.ID)
+	}
+	maxChars := query.MaxChars
+	if maxChars == 0 {
+		maxChars = defaultReadChars
+	}
+	if maxChars < 0 || maxChars > maxReadChars {
+		return nil, ReadPaperResults{}, invalidInput("max_chars", "20000", "must be between 1 and %d, got %d", maxReadChars, maxChars)
+	}
+	if query.Page < 0 {
+		return nil, ReadPaperResults{}, invalidInput("page", "1", "must be positive, got %d", query.Page)
+	}
+	if query.Offset < 0 {
+		return nil, ReadPaperResults{}, invalidInput("offset", "0", "must not be negative, got %d", query.Offset)
+	}
+
+	data, cacheHit, err := downloadPDF(ctx, downloader, id.String())
+	if err != nil {
+		return nil, ReadPaperResults{}, err
+	}
+	doc, err := pdftext.Extract(data)
+	if err != nil {
+		return nil, ReadPaperResults{}, &ToolError{
+			Kind:   errorUnreadable,
+			Reason: fmt.Sprintf("could not extract text from the PDF of %s (%v); use arxiv-get-paper for its abstract", id, err),
+		}
+	}
+
+	text := newPaperText(doc)
+	start := query.Offset
+	if query.Page > 0 {
+		if query.Page > len(text.pageStarts) {
+			return nil, ReadPaperResults{}, invalidInput("page", "1", "the paper has %d pages, got %d", len(text.pageStarts), query.Page)
+		}
+		start = text.pageStarts[query.Page-1]
+	} else if start > len(text.runes) {
+		return nil, ReadPaperResults{}, invalidInput("offset", "0", "the text has %d characters, got %d", len(text.runes), start)
+	}
+	end := text.chunkEnd(start, maxChars, query.Page > 0)
+
+	results := ReadPaperResults{
+		ID:         id.String(),
+		TotalPages: len(doc.Pages),
+		TotalChars: len(text.runes),
+		Offset:     start,
+		Text:       string(text.runes[start:end]),
+		Sections:   text.sections,
+		CacheHit:   cacheHit,
+	}
+	if end > start {
+		results.FirstPage = text.pageAt(start)
+		results.LastPage = text.pageAt(end - 1)
+	}
+	if end < len(text.runes) {
+		results.NextOffset = end
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: renderPaperText(results)}},
+	}, results, nil
+}
+
+// pageSeparator is put between pages in the full text.
+const pageSeparator = "\n\n"
+
+// paperText is the full text of a paper as characters, with where each page
+// starts.
+type paperText struct {
+	runes      []rune
+	pageStarts []int
+	sections   []PaperSection
+}
+
+func newPaperText(doc *pdftext.Document) paperText {
+	var text paperText
+	for i, page := range doc.Pages {
+		if i > 0 {
+			text.runes = append(text.runes, []rune(pageSeparator)...)
+		}
+		text.pageStarts = append(text.pageStarts, len(text.runes))
+		text.runes = append(text.runes, []rune(page)...)
+	}
+	for _, h := range doc.Headings {
+		text.sections = append(text.sections, PaperSection{
+			Title:  h.Title,
+			Level:  h.Level,
+			Page:   h.Page,
+			Offset: text.pageStarts[h.Page-1] + h.Offset,
+		})
+	}
+	return text
+}
+
+// pageAt returns the page, counting from 1, that the character at offset is
+// on.
+func (t paperText) pageAt(offset int) int {
+	page := 1
+	for i, start := range t.pageStarts {
+		if start <= offset {
+			page = i + 1
+		}
+	}
+	return page
+}
+
+// chunkEnd returns where a part of the text starting at start and at most
+// maxChars long should end. With wholePages, it ends where the page after the
+// last one that fits starts, if at least one does. Otherwise it ends at a paragraph or
+// line break if one is near the limit, so that words are not cut in half.
+func (t paperText) chunkEnd(start, maxChars int, wholePages bool) int {
+	limit := start + maxChars
+	if limit >= len(t.runes) {
+		return len(t.runes)
+	}
+	if wholePages {
+		end := start
+		for _, pageStart := range t.pageStarts {
+			if pageStart > start && pageStart <= limit {
+				end = pageStart
+			}
+		}
+		if end > start {
+			return end
+		}
+	}
+	floor := start + maxChars*4/5
+	for _, breakAt := range []string{"\n\n", "\n", " "} {
+		for i := limit; i > floor && i-len(breakAt) >= start; i-- {
+			if strings.HasPrefix(string(t.runes[i-len(breakAt):i]), breakAt) {
+				return i
+			}
+		}
+	}
+	return limit
+}
+
+// renderPaperText renders a part of a paper's text, with the outline of the
+// paper before the first part and how to read on after the last.
+func renderPaperText(results ReadPaperResults) string {
+	var b strings.Builder
+	switch {
+	case results.Text == "":
+		fmt.Fprintf(&b, "arXiv:%s has no text from offset %d (%d characters in all).\n", results.ID, results.Offset, results.TotalChars)
+		return b.String()
+	case results.FirstPage == results.LastPage:
+		fmt.Fprintf(&b, "arXiv:%s, page %d of %d", results.ID, results.FirstPage, results.TotalPages)
+	default:
+		fmt.Fprintf(&b, "arXiv:%s, pages %d–%d of %d", results.ID, results.FirstPage, results.LastPage, results.TotalPages)
+	}
+	fmt.Fprintf(&b, " (characters %d–%d of %d).\n\n", results.Offset, results.Offset+len([]rune(results.Text)), results.TotalChars)
+
+	if results.Offset == 0 && len(results.Sections) > 0 {
+		b.WriteString("Sections:\n")
+		for _, s := range results.Sections {
+			fmt.Fprintf(&b, "%s- %s (page %d, offset %d)\n", strings.Repeat("  ", max(s.Level-1, 0)), s.Title, s.Page, s.Offset)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(results.Text)
+	b.WriteString("\n")
+	if results.NextOffset > 0 {
+		fmt.Fprintf(&b, "\nThe text continues: pass offset %d to read on.\n", results.NextOffset)
+	}
+	return b.String()
+}
@@ -0,0 +1,324 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivid"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/latex"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/textclean"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type ReadSourceQuery struct {
+	ID       string `json:"id" jsonschema:"arXiv ID of the paper, with a version to read a specific one"`
+	Section  string `json:"section,omitempty" jsonschema:"number or title of a section to return the text of, such as 2.1 or Related Work. Without it, section texts are returned in order for as long as they fit in max_chars"`
+	MaxChars int    `json:"max_chars,omitempty" jsonschema:"most characters of section text to return, and separately of equations, of figure captions and of bibliography entries. Defaults to 20000, and can be at most 100000"`
+}
+
+type ReadSourceResults struct {
+	ID           string            `json:"id"`
+	MainFile     string            `json:"main_file,omitempty" jsonschema:"the file LaTeX is run on"`
+	Files        []string          `json:"files,omitempty" jsonschema:"files in the source"`
+	Skipped      []string          `json:"skipped,omitempty" jsonschema:"archive entries not unpacked: links, devices and paths outside the archive"`
+	Title        string            `json:"title,omitempty"`
+	Abstract     string            `json:"abstract,omitempty"`
+	Sections     []SourceSection   `json:"sections,omitempty"`
+	Equations    []SourceEquation  `json:"equations,omitempty" jsonschema:"displayed equations"`
+	Figures      []SourceFigure    `json:"figures,omitempty" jsonschema:"figures and tables"`
+	Bibliography []SourceReference `json:"bibliography,omitempty"`
+	Omitted      OmittedCounts     `json:"omitted" jsonschema:"number of equations, figures and bibliography entries left out after those that fit in max_chars"`
+	CacheHit     bool              `json:"cache_hit" jsonschema:"whether the source was served from the cache"`
+	Error        *ToolError        `json:"error,omitempty" jsonschema:"why the source could not be read, if it could not"`
+}
+
+type OmittedCounts struct {
+	Equations    int `json:"equations"`
+	Figures      int `json:"figures"`
+	Bibliography int `json:"bibliography"`
+}
+
+type SourceSection struct {
+	Number    string `json:"number,omitempty" jsonschema:"such as 2.1 or A; absent for unnumbered sections"`
+	Title     string `json:"title"`
+	Level     int    `json:"level" jsonschema:"1 for a section, 2 for a subsection and 3 for a subsubsection"`
+	Label     string `json:"label,omitempty"`
+	Chars     int    `json:"chars" jsonschema:"length of the section text, in characters"`
+	Text      string `json:"text,omitempty" jsonschema:"LaTeX text of the section, subsections excluded"`
+	Truncated bool   `json:"truncated,omitempty" jsonschema:"whether text was cut short at max_chars"`
+	Omitted   bool   `json:"omitted,omitempty" jsonschema:"whether text was left out; pass the section to read it"`
+}
+
+type SourceEquation struct {
+	Environment string `json:"environment" jsonschema:"such as equation or align*; \\[ and $$ are given as displaymath"`
+	Label       string `json:"label,omitempty"`
+	Body        string `json:"body"`
+	Section     string `json:"section,omitempty" jsonschema:"number, or else title, of the section it is in"`
+}
+
+type SourceFigure struct {
+	Environment string `json:"environment" jsonschema:"such as figure, figure* or table"`
+	Label       string `json:"label,omitempty"`
+	Caption     string `json:"caption,omitempty"`
+	Section     string `json:"section,omitempty" jsonschema:"number, or else title, of the section it is in"`
+}
+
+type SourceReference struct {
+	Key    string            `json:"key" jsonschema:"citation key used in \\cite"`
+	Text   string            `json:"text"`
+	Fields map[string]string `json:"fields,omitempty" jsonschema:"BibTeX fields, for references read from a .bib file"`
+}
+
+func ReadSourceTool() *mcp.Tool {
+	inputSchema, err := jsonschema.For[ReadSourceQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+
+	readSourceTool := mcp.Tool{
+		Name:        "arxiv-read-source",
+		Description: "Reads the LaTeX source of a paper and returns its structure: sections, displayed equations, figure and table captions, and the bibliography. Section texts are LaTeX with comments removed and inputs resolved. Use a section's number or title to read one section in full",
+		InputSchema: inputSchema,
+	}
+	return &readSourceTool
+}
+
+func ReadSourceHandler(downloader Downloader) mcp.ToolHandlerFor[ReadSourceQuery, ReadSourceResults] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, query ReadSourceQuery) (*mcp.CallToolResult, ReadSourceResults, error) {
+		result, sourceResults, err := readSource(ctx, downloader, query)
+		if toolErr := asToolError(err); toolErr != nil {
+			return errorResult(toolErr), ReadSourceResults{Error: toolErr}, nil
+		}
+		return result, sourceResults, err
+	}
+}
+
+func readSource(ctx context.Context, downloader Downloader, query ReadSourceQuery) (*mcp.CallToolResult, ReadSourceResults, error) {
+	id, err := arxivid.Parse(query.ID)
+	if err != nil {
+		return nil, ReadSourceResults{}, invalidInput("id", "1706.03762", "%q is not an arXiv ID", query.ID)
+	}
+	maxChars := query.MaxChars
+	if maxChars == 0 {
+		maxChars = defaultReadChars
+	}
+	if maxChars < 0 || maxChars > maxReadChars {
+		return nil, ReadSourceResults{}, invalidInput("max_chars", "20000", "must be between 1 and %d, got %d", maxReadChars, maxChars)
+	}
+
+	data, cacheHit, err := downloadSource(ctx, downloader, id.String())
+	if err != nil {
+		return nil, ReadSourceResults{}, err
+	}
+	archive, err := latex.Unpack(data)
+	if errors.Is(err, latex.ErrNoSource) {
+		return nil, ReadSourceResults{}, &ToolError{
+			Kind:   errorNotFound,
+			Reason: fmt.Sprintf("arXiv has no LaTeX source for %s, only a PDF; use arxiv-read-paper to read it", id),
+		}
+	}
+	if err != nil {
+		return nil, ReadSourceResults{}, unreadableSource(id, err)
+	}
+	main, err := archive.MainFile()
+	if err != nil {
+		return nil, ReadSourceResults{}, unreadableSource(id, err)
+	}
+	doc, err := archive.Parse(main)
+	if err != nil {
+		return nil, ReadSourceResults{}, unreadableSource(id, err)
+	}
+
+	results := ReadSourceResults{
+		ID:       id.String(),
+		MainFile: main,
+		Files:    archive.Names(),
+		Skipped:  archive.Skipped,
+		Title:    doc.Title,
+		Abstract: doc.Abstract,
+		CacheHit: cacheHit,
+	}
+	results.Sections, err = sourceSections(doc.Sections, query.Section, maxChars)
+	if err != nil {
+		return nil, ReadSourceResults{}, err
+	}
+	// The lists each get max_chars too, since a long paper can have
+	// hundreds of equations or references.
+	n := fitting(doc.Equations, maxChars, func(e latex.Equation) int { return utf8.RuneCountInString(e.Body) })
+	for _, e := range doc.Equations[:n] {
+		results.Equations = append(results.Equations, SourceEquation(e))
+	}
+	results.Omitted.Equations = len(doc.Equations) - n
+	n = fitting(doc.Figures, maxChars, func(f latex.Figure) int { return utf8.RuneCountInString(f.Caption) })
+	for _, f := range doc.Figures[:n] {
+		results.Figures = append(results.Figures, SourceFigure(f))
+	}
+	results.Omitted.Figures = len(doc.Figures) - n
+	n = fitting(doc.Bibliography, maxChars, bibItemChars)
+	for _, item := range doc.Bibliography[:n] {
+		results.Bibliography = append(results.Bibliography, SourceReference(item))
+	}
+	results.Omitted.Bibliography = len(doc.Bibliography) - n
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: renderSource(results)}},
+	}, results, nil
+}
+
+// fitting returns how many of items, from the first, fit in maxChars
+// characters, counting each as size says.
+func fitting[T any](items []T, maxChars int, size func(T) int) int {
+	for i, item := range items {
+		if maxChars -= size(item); maxChars < 0 {
+			return i
+		}
+	}
+	return len(items)
+}
+
+func bibItemChars(item latex.BibItem) int {
+	n := utf8.RuneCountInString(item.Key) + utf8.RuneCountInString(item.Text)
+	for name, value := range item.Fields {
+		n += utf8.RuneCountInString(name) + utf8.RuneCountInString(value)
+	}
+	return n
+}
+
+func unreadableSource(id arxivid.ID, err error) *ToolError {
+	return &ToolError{
+		Kind:   errorUnreadable,
+		Reason: fmt.Sprintf("could not read the LaTeX source of %s (%v); use arxiv-read-paper to read its PDF", id, err),
+	}
+}
+
+// sourceSections returns the sections with their text. With selected, only
+// the text of the section with that number or title is given; otherwise
+// texts are given in order until maxChars is reached, cutting the last one
+// short and leaving out the rest.
+func sourceSections(sections []latex.Section, selected string, maxChars int) ([]SourceSection, error) {
+	selected = strings.TrimSpace(selected)
+	found := false
+	remaining := maxChars
+	results := make([]SourceSection, len(sections))
+	for i, s := range sections {
+		text := []rune(s.Text)
+		results[i] = SourceSection{
+			Number: s.Number,
+			Title:  s.Title,
+			Level:  s.Level,
+			Label:  s.Label,
+			Chars:  len(text),
+		}
+		if selected != "" {
+			if found || (s.Number != selected && !strings.EqualFold(s.Title, selected)) {
+				results[i].Omitted = len(text) > 0
+				continue
+			}
+			found = true
+		}
+		if remaining == 0 {
+			results[i].Omitted = len(text) > 0
+			continue
+		}
+		if len(text) > remaining {
+			text = text[:remaining]
+			results[i].Truncated = true
+		}
+		results[i].Text = string(text)
+		remaining -= len(text)
+	}
+	if selected != "" && !found {
+		var example string
+		if len(sections) > 0 {
+			example = sections[0].Title
+		}
+		return nil, invalidInput("section", example, "the paper has no section numbered or titled %q", selected)
+	}
+	return results, nil
+}
+
+// renderSource renders the structure of a paper's source as markdown.
+func renderSource(results ReadSourceResults) string {
+	var b strings.Builder
+	if results.Title != "" {
+		fmt.Fprintf(&b, "# %s\n\n", results.Title)
+	}
+	fmt.Fprintf(&b, "arXiv:%s, LaTeX source with main file %s (%d files).\n", results.ID, results.MainFile, len(results.Files))
+	if len(results.Skipped) > 0 {
+		fmt.Fprintf(&b, "Skipped archive entries: %s\n", strings.Join(results.Skipped, ", "))
+	}
+	if results.Abstract != "" {
+		fmt.Fprintf(&b, "\n## Abstract\n\n%s\n", results.Abstract)
+	}
+
+	for _, s := range results.Sections {
+		heading := s.Title
+		if s.Number != "" {
+			heading = s.Number + " " + s.Title
+		}
+		fmt.Fprintf(&b, "\n%s %s\n", strings.Repeat("#", s.Level+1), heading)
+		switch {
+		case s.Omitted:
+			fmt.Fprintf(&b, "\n(%d characters left out; pass section %q to read them)\n", s.Chars, sectionRef(s))
+		case s.Text != "":
+			fmt.Fprintf(&b, "\n%s\n", s.Text)
+			if s.Truncated {
+				fmt.Fprintf(&b, "\n(cut short at %d of %d characters)\n", len([]rune(s.Text)), s.Chars)
+			}
+		}
+	}
+
+	if len(results.Equations) > 0 || results.Omitted.Equations > 0 {
+		b.WriteString("\n## Equations\n\n")
+		for _, e := range results.Equations {
+			fmt.Fprintf(&b, "- %s%s: `%s`\n", e.Environment, labelAndSection(e.Label, e.Section), textclean.OneLine(e.Body))
+		}
+		renderOmitted(&b, results.Omitted.Equations, "equations")
+	}
+	if len(results.Figures) > 0 || results.Omitted.Figures > 0 {
+		b.WriteString("\n## Figures and tables\n\n")
+		for _, f := range results.Figures {
+			fmt.Fprintf(&b, "- %s%s: %s\n", f.Environment, labelAndSection(f.Label, f.Section), f.Caption)
+		}
+		renderOmitted(&b, results.Omitted.Figures, "figures and tables")
+	}
+	if len(results.Bibliography) > 0 || results.Omitted.Bibliography > 0 {
+		b.WriteString("\n## Bibliography\n\n")
+		for _, item := range results.Bibliography {
+			fmt.Fprintf(&b, "- [%s] %s\n", item.Key, item.Text)
+		}
+		renderOmitted(&b, results.Omitted.Bibliography, "bibliography entries")
+	}
+	return b.String()
+}
+
+func renderOmitted(b *strings.Builder, omitted int, what string) {
+	if omitted > 0 {
+		fmt.Fprintf(b, "\n(%d more %s left out at max_chars; arxiv-references lists the whole bibliography)\n", omitted, what)
+	}
+}
+
+// sectionRef returns what to pass as section to read s.
+func sectionRef(s SourceSection) string {
+	if s.Number != "" {
+		return s.Number
+	}
+	return s.Title
+}
+
+func labelAndSection(label, section string) string {
+	var parts []string
+	if label != "" {
+		parts = append(parts, label)
+	}
+	if section != "" {
+		parts = append(parts, "in "+section)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
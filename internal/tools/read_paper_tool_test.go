@@ -0,0 +1,191 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/pdftext"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestReadPaperTool(t *testing.T) {
+	tool := ReadPaperTool()
+	if tool.Name != "arxiv-read-paper" {
+		t.Errorf("expected tool name 'arxiv-read-paper', got '%s'", tool.Name)
+	}
+	if tool.InputSchema == nil {
+		t.Fatal("expected InputSchema to be non-nil")
+	}
+	for _, property := range []string{"id", "page", "offset", "max_chars"} {
+		if _, ok := tool.InputSchema.Properties[property]; !ok {
+			t.Errorf("expected property %q in the input schema", property)
+		}
+	}
+}
+
+// stubDownloader returns data for every file.
+type stubDownloader struct {
+	data []byte
+}
+
+func (d stubDownloader) PDF(ctx context.Context, id string) ([]byte, error) {
+	return d.data, nil
+}
+
+func (d stubDownloader) Source(ctx context.Context, id string) ([]byte, error) {
+	return d.data, nil
+}
+
+func TestReadPaperHandler(t *testing.T) {
+	handler := ReadPaperHandler(newTestClient(t))
+	ctx := context.Background()
+	read := func(t *testing.T, query ReadPaperQuery) ReadPaperResults {
+		t.Helper()
+		result, results, err := handler(ctx, &mcp.CallToolRequest{}, query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("unexpected tool error: %+v", results.Error)
+		}
+		return results
+	}
+
+	t.Run("whole paper", func(t *testing.T) {
+		results := read(t, ReadPaperQuery{ID: "arXiv:1706.03762v7"})
+		if results.ID != "1706.03762v7" || results.TotalPages != 2 {
+			t.Errorf("expected 2 pages of 1706.03762v7, got %d pages of %s", results.TotalPages, results.ID)
+		}
+		if results.FirstPage != 1 || results.LastPage != 2 || results.NextOffset != 0 {
+			t.Errorf("expected pages 1-2 and no next offset, got %+v", results)
+		}
+		if !strings.HasPrefix(results.Text, "Attention Is All You Need") {
+			t.Errorf("expected the text to start with the title, got %q", results.Text[:40])
+		}
+		var titles []string
+		for _, s := range results.Sections {
+			titles = append(titles, s.Title)
+			if !strings.HasPrefix(string([]rune(results.Text)[s.Offset:]), s.Title[:2]) {
+				t.Errorf("section %q: offset %d does not point at it", s.Title, s.Offset)
+			}
+		}
+		expected := "Abstract, 1 Introduction, 2 Method, 2.1 Setup, References"
+		if !strings.HasSuffix(strings.Join(titles, ", "), expected) {
+			t.Errorf("expected sections ending %s, got %v", expected, titles)
+		}
+	})
+
+	t.Run("in parts", func(t *testing.T) {
+		whole := read(t, ReadPaperQuery{ID: "1706.03762v7"})
+		var text strings.Builder
+		query := ReadPaperQuery{ID: "1706.03762v7", MaxChars: 500}
+		for parts := 1; ; parts++ {
+			results := read(t, query)
+			if n := len([]rune(results.Text)); n > 500 || n == 0 {
+				t.Fatalf("expected 1 to 500 characters, got %d", n)
+			}
+			text.WriteString(results.Text)
+			if results.NextOffset == 0 {
+				break
+			}
+			if parts > whole.TotalChars/400+1 {
+				t.Fatal("expected the text to be read in few parts")
+			}
+			query.Offset = results.NextOffset
+		}
+		if text.String() != whole.Text {
+			t.Error("expected the parts to make up the whole text")
+		}
+	})
+
+	t.Run("from a page", func(t *testing.T) {
+		results := read(t, ReadPaperQuery{ID: "1706.03762v7", Page: 2})
+		if results.FirstPage != 2 || !strings.HasPrefix(results.Text, "2 Method") {
+			t.Errorf("expected text from page 2, got page %d: %q", results.FirstPage, results.Text)
+		}
+	})
+
+	t.Run("whole pages", func(t *testing.T) {
+		whole := read(t, ReadPaperQuery{ID: "1706.03762v7"})
+		var page2 int
+		for _, s := range whole.Sections {
+			if s.Page == 2 {
+				page2 = s.Offset
+				break
+			}
+		}
+		results := read(t, ReadPaperQuery{ID: "1706.03762v7", Page: 1, MaxChars: page2 + 10})
+		if results.LastPage != 1 || results.NextOffset != page2 {
+			t.Errorf("expected page 1 only and next offset %d, got pages %d-%d and next offset %d",
+				page2, results.FirstPage, results.LastPage, results.NextOffset)
+		}
+	})
+
+	errorTests := []struct {
+		name       string
+		downloader Downloader
+		query      ReadPaperQuery
+		kind       string
+		field      string
+	}{
+		{name: "invalid id", query: ReadPaperQuery{ID: "attention"}, kind: errorInvalidInput, field: "id"},
+		{name: "max chars too large", query: ReadPaperQuery{ID: "1706.03762", MaxChars: maxReadChars + 1}, kind: errorInvalidInput, field: "max_chars"},
+		{name: "negative page", query: ReadPaperQuery{ID: "1706.03762", Page: -1}, kind: errorInvalidInput, field: "page"},
+		{name: "page past the end", query: ReadPaperQuery{ID: "1706.03762", Page: 3}, kind: errorInvalidInput, field: "page"},
+		{name: "offset past the end", query: ReadPaperQuery{ID: "1706.03762", Offset: 1000000}, kind: errorInvalidInput, field: "offset"},
+		{name: "unknown paper", query: ReadPaperQuery{ID: "2401.99999"}, kind: errorNotFound},
+		{name: "not a pdf", downloader: stubDownloader{data: []byte("<html></html>")}, query: ReadPaperQuery{ID: "1706.03762"}, kind: errorUnreadable},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := handler
+			if tt.downloader != nil {
+				h = ReadPaperHandler(tt.downloader)
+			}
+			result, results, err := h(ctx, &mcp.CallToolRequest{}, tt.query)
+			if err != nil {
+				t.Fatalf("expected a tool error, got %v", err)
+			}
+			if !result.IsError || results.Error == nil {
+				t.Fatal("expected IsError and an error in the results")
+			}
+			if results.Error.Kind != tt.kind || results.Error.Field != tt.field {
+				t.Errorf("expected %s error on %q, got %+v", tt.kind, tt.field, results.Error)
+			}
+		})
+	}
+}
+
+func TestChunkEnd(t *testing.T) {
+	text := newPaperText(&pdftext.Document{Pages: []string{
+		"First paragraph.\n\nSecond paragraph here.",
+		"Page two words.",
+	}})
+
+	tests := []struct {
+		name       string
+		start      int
+		maxChars   int
+		wholePages bool
+		expected   int
+	}{
+		{name: "to the end", start: 0, maxChars: 1000, expected: len(text.runes)},
+		{name: "at a paragraph break", start: 0, maxChars: 20, expected: 18},
+		{name: "at a space", start: 18, maxChars: 8, expected: 25},
+		{name: "mid word without a break", start: 18, maxChars: 3, expected: 21},
+		{name: "whole pages", start: 0, maxChars: 45, wholePages: true, expected: 42},
+		{name: "page longer than the limit", start: 0, maxChars: 20, wholePages: true, expected: 18},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if end := text.chunkEnd(tt.start, tt.maxChars, tt.wholePages); end != tt.expected {
+				t.Errorf("expected end %d, got %d (%q)", tt.expected, end, string(text.runes[tt.start:end]))
+			}
+		})
+	}
+
+	if text.pageAt(0) != 1 || text.pageAt(39) != 1 || text.pageAt(42) != 2 {
+		t.Errorf("unexpected pages for offsets: %d %d %d", text.pageAt(0), text.pageAt(39), text.pageAt(42))
+	}
+}
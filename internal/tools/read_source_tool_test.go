@@ -0,0 +1,212 @@
+package tools
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/latex"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestReadSourceTool(t *testing.T) {
+	tool := ReadSourceTool()
+	if tool.Name != "arxiv-read-source" {
+		t.Errorf("expected tool name 'arxiv-read-source', got '%s'", tool.Name)
+	}
+	if tool.InputSchema == nil {
+		t.Fatal("expected InputSchema to be non-nil")
+	}
+	for _, property := range []string{"id", "section", "max_chars"} {
+		if _, ok := tool.InputSchema.Properties[property]; !ok {
+			t.Errorf("expected property %q in the input schema", property)
+		}
+	}
+}
+
+func TestReadSourceHandler(t *testing.T) {
+	handler := ReadSourceHandler(newTestClient(t))
+	ctx := context.Background()
+	read := func(t *testing.T, query ReadSourceQuery) (*mcp.CallToolResult, ReadSourceResults) {
+		t.Helper()
+		result, results, err := handler(ctx, &mcp.CallToolRequest{}, query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("unexpected tool error: %+v", results.Error)
+		}
+		return result, results
+	}
+
+	t.Run("tarball", func(t *testing.T) {
+		result, results := read(t, ReadSourceQuery{ID: "arXiv:1706.03762v7"})
+		if results.ID != "1706.03762v7" || results.MainFile != "main.tex" {
+			t.Errorf("expected main.tex of 1706.03762v7, got %s of %s", results.MainFile, results.ID)
+		}
+		if !reflect.DeepEqual(results.Files, []string{"main.tex", "sections/intro.tex"}) {
+			t.Errorf("unexpected files %v", results.Files)
+		}
+		if results.Title != "Attention Is All You Need" {
+			t.Errorf("unexpected title %q", results.Title)
+		}
+
+		var headings []string
+		for _, s := range results.Sections {
+			headings = append(headings, s.Number+" "+s.Title)
+			if s.Text == "" || s.Omitted || s.Truncated {
+				t.Errorf("expected the full text of %s", s.Title)
+			}
+		}
+		if strings.Join(headings, ", ") != "1 Introduction, 2 Method, 2.1 Setup" {
+			t.Errorf("unexpected sections %v", headings)
+		}
+		if results.Sections[0].Label != "sec:intro" {
+			t.Errorf("expected the introduction from the input file, got %+v", results.Sections[0])
+		}
+
+		expectedEquations := []SourceEquation{{Environment: "equation", Label: "eq:model", Body: "y = f(x)", Section: "2"}}
+		if !reflect.DeepEqual(results.Equations, expectedEquations) {
+			t.Errorf("expected equations %+v, got %+v", expectedEquations, results.Equations)
+		}
+		expectedFigures := []SourceFigure{{Environment: "figure", Label: "fig:overview", Caption: "Overview of the method.", Section: "2.1"}}
+		if !reflect.DeepEqual(results.Figures, expectedFigures) {
+			t.Errorf("expected figures %+v, got %+v", expectedFigures, results.Figures)
+		}
+		if len(results.Bibliography) == 0 || results.Bibliography[0].Key != "ref1" {
+			t.Errorf("unexpected bibliography %+v", results.Bibliography)
+		}
+		if results.Omitted != (OmittedCounts{}) {
+			t.Errorf("expected nothing left out, got %+v", results.Omitted)
+		}
+
+		text := result.Content[0].(*mcp.TextContent).Text
+		for _, part := range []string{"# Attention Is All You Need", "## 1 Introduction", "### 2.1 Setup", "- equation (eq:model, in 2): `y = f(x)`", "- [ref1] "} {
+			if !strings.Contains(text, part) {
+				t.Errorf("expected %q in the text content:\n%s", part, text)
+			}
+		}
+	})
+
+	t.Run("single gzipped file", func(t *testing.T) {
+		_, results := read(t, ReadSourceQuery{ID: "hep-th/9711200"})
+		if !reflect.DeepEqual(results.Files, []string{"main.tex"}) || len(results.Sections) != 3 {
+			t.Errorf("expected three sections in main.tex, got %v and %+v", results.Files, results.Sections)
+		}
+	})
+
+	t.Run("one section", func(t *testing.T) {
+		_, results := read(t, ReadSourceQuery{ID: "1706.03762v7", Section: "method"})
+		for _, s := range results.Sections {
+			if (s.Title == "Method") != (s.Text != "") || (s.Title != "Method") != s.Omitted {
+				t.Errorf("expected text for the Method section only, got %+v", s)
+			}
+		}
+	})
+
+	t.Run("within max chars", func(t *testing.T) {
+		_, results := read(t, ReadSourceQuery{ID: "1706.03762v7", MaxChars: 100})
+		first := results.Sections[0]
+		if !first.Truncated || len([]rune(first.Text)) != 100 {
+			t.Errorf("expected the first section cut at 100 characters, got %+v", first)
+		}
+		for _, s := range results.Sections[1:] {
+			if !s.Omitted || s.Text != "" {
+				t.Errorf("expected %s to be left out, got %+v", s.Title, s)
+			}
+		}
+		if len(results.Equations) != 1 || results.Omitted.Equations != 0 {
+			t.Errorf("expected the short equation within its own budget, got %+v", results.Equations)
+		}
+	})
+
+	errorTests := []struct {
+		name       string
+		downloader Downloader
+		query      ReadSourceQuery
+		kind       string
+		field      string
+	}{
+		{name: "invalid id", query: ReadSourceQuery{ID: "attention"}, kind: errorInvalidInput, field: "id"},
+		{name: "max chars too large", query: ReadSourceQuery{ID: "1706.03762", MaxChars: maxReadChars + 1}, kind: errorInvalidInput, field: "max_chars"},
+		{name: "unknown section", query: ReadSourceQuery{ID: "1706.03762", Section: "9"}, kind: errorInvalidInput, field: "section"},
+		{name: "unknown paper", query: ReadSourceQuery{ID: "2401.99999"}, kind: errorNotFound},
+		{name: "pdf only", query: ReadSourceQuery{ID: "math/0211159"}, kind: errorNotFound},
+		{name: "no main file", downloader: stubDownloader{data: []byte(`\section{Orphan}`)}, query: ReadSourceQuery{ID: "1706.03762"}, kind: errorUnreadable},
+		{name: "corrupt gzip", downloader: stubDownloader{data: []byte{0x1f, 0x8b, 0}}, query: ReadSourceQuery{ID: "1706.03762"}, kind: errorUnreadable},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := handler
+			if tt.downloader != nil {
+				h = ReadSourceHandler(tt.downloader)
+			}
+			result, results, err := h(ctx, &mcp.CallToolRequest{}, tt.query)
+			if err != nil {
+				t.Fatalf("expected a tool error, got %v", err)
+			}
+			if !result.IsError || results.Error == nil {
+				t.Fatal("expected IsError and an error in the results")
+			}
+			if results.Error.Kind != tt.kind || results.Error.Field != tt.field {
+				t.Errorf("expected %s error on %q, got %+v", tt.kind, tt.field, results.Error)
+			}
+		})
+	}
+}
+
+func TestSourceSections(t *testing.T) {
+	sections := []latex.Section{
+		{Number: "1", Title: "Introduction", Level: 1, Text: "0123456789"},
+		{Number: "", Title: "Notes", Level: 2, Text: "abcdef"},
+		{Number: "2", Title: "Method", Level: 1, Text: "xyz"},
+	}
+
+	tests := []struct {
+		name     string
+		selected string
+		maxChars int
+		texts    []string
+		omitted  []bool
+	}{
+		{name: "all fit", maxChars: 100, texts: []string{"0123456789", "abcdef", "xyz"}, omitted: []bool{false, false, false}},
+		{name: "budget runs out", maxChars: 12, texts: []string{"0123456789", "ab", ""}, omitted: []bool{false, false, true}},
+		{name: "by number", selected: "2", maxChars: 100, texts: []string{"", "", "xyz"}, omitted: []bool{true, true, false}},
+		{name: "by title", selected: " notes ", maxChars: 3, texts: []string{"", "abc", ""}, omitted: []bool{true, false, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := sourceSections(sections, tt.selected, tt.maxChars)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for i, s := range results {
+				if s.Text != tt.texts[i] || s.Omitted != tt.omitted[i] {
+					t.Errorf("section %d: expected text %q omitted %v, got %q %v", i, tt.texts[i], tt.omitted[i], s.Text, s.Omitted)
+				}
+			}
+		})
+	}
+}
+
+func TestFitting(t *testing.T) {
+	items := []string{"abcd", "ef", "ghijkl"}
+	tests := []struct {
+		name     string
+		maxChars int
+		expected int
+	}{
+		{name: "all fit", maxChars: 12, expected: 3},
+		{name: "last does not fit", maxChars: 11, expected: 2},
+		{name: "first does not fit", maxChars: 3, expected: 0},
+		{name: "none", maxChars: 0, expected: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if n := fitting(items, tt.maxChars, func(s string) int { return len(s) }); n != tt.expected {
+				t.Errorf("expected %d items to fit, got %d", tt.expected, n)
+			}
+		})
+	}
+}
@@ -0,0 +1,285 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivid"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/latex"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/pdftext"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/references"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/textclean"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type ReferencesQuery struct {
+	ID           string   `json:"id" jsonschema:"arXiv ID of the paper, with a version to read a specific one"`
+	ReturnFields []string `json:"return_fields,omitempty" jsonschema:"fields to return for cited arXiv papers. Returns all if empty"`
+}
+
+type ReferencesResults struct {
+	ID           string      `json:"id"`
+	Source       string      `json:"source,omitempty" jsonschema:"where the references were read from: latex or pdf"`
+	SourceError  string      `json:"source_error,omitempty" jsonschema:"why the LaTeX source was not used, if the references were read from the PDF"`
+	References   []Reference `json:"references,omitempty"`
+	Resolved     int         `json:"resolved" jsonschema:"number of references whose arXiv paper was found"`
+	CacheHit     bool        `json:"cache_hit" jsonschema:"whether the paper's file was served from the cache"`
+	ResolveError *ToolError  `json:"resolve_error,omitempty" jsonschema:"why cited arXiv papers could not be looked up, if they could not. The references are still returned"`
+	Error        *ToolError  `json:"error,omitempty" jsonschema:"why the references could not be read, if they could not"`
+}
+
+type Reference struct {
+	Key     string     `json:"key,omitempty" jsonschema:"citation key, for references read from LaTeX source"`
+	Text    string     `json:"text" jsonschema:"the reference as plain text"`
+	Authors []string   `json:"authors,omitempty"`
+	Title   string     `json:"title,omitempty"`
+	Year    string     `json:"year,omitempty"`
+	Venue   string     `json:"venue,omitempty" jsonschema:"journal, proceedings or publisher"`
+	ArxivID string     `json:"arxiv_id,omitempty"`
+	DOI     string     `json:"doi,omitempty"`
+	Entry   *EntryView `json:"entry,omitempty" jsonschema:"the cited arXiv paper, if it was found"`
+}
+
+func ReferencesTool() *mcp.Tool {
+	inputSchema, err := jsonschema.For[ReferencesQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+
+	referencesTool := mcp.Tool{
+		Name:        "arxiv-references",
+		Description: "Lists the references a paper cites, read from its LaTeX source or else its PDF, with authors, title, year and venue. References to arXiv papers come with the cited paper's metadata",
+		InputSchema: inputSchema,
+	}
+	return &referencesTool
+}
+
+func ReferencesHandler(searcher Searcher, downloader Downloader) mcp.ToolHandlerFor[ReferencesQuery, ReferencesResults] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, query ReferencesQuery) (*mcp.CallToolResult, ReferencesResults, error) {
+		result, referencesResults, err := listReferences(ctx, searcher, downloader, query)
+		if toolErr := asToolError(err); toolErr != nil {
+			return errorResult(toolErr), ReferencesResults{Error: toolErr}, nil
+		}
+		return result, referencesResults, err
+	}
+}
+
+func listReferences(ctx context.Context, searcher Searcher, downloader Downloader, query ReferencesQuery) (*mcp.CallToolResult, ReferencesResults, error) {
+	id, err := arxivid.Parse(query.ID)
+	if err != nil {
+		return nil, ReferencesResults{}, invalidInput("id", "1706.03762", "%q is not an arXiv ID", query.ID)
+	}
+
+	results := ReferencesResults{ID: id.String()}
+	refs, cacheHit, err := sourceReferences(ctx, downloader, id)
+	if ctx.Err() != nil {
+		return nil, ReferencesResults{}, ctx.Err()
+	}
+	results.Source = "latex"
+	if err != nil || len(refs) == 0 {
+		// The PDF is read instead, and why the source was not used is
+		// reported with its references, or with its error.
+		results.SourceError = "the LaTeX source has no bibliography"
+		if err != nil {
+			results.SourceError = err.Error()
+		}
+		refs, cacheHit, err = pdfReferences(ctx, downloader, id)
+		if toolErr := asToolError(err); toolErr != nil {
+			toolErr.Reason += fmt.Sprintf(" (the LaTeX source was not used: %s)", results.SourceError)
+			return nil, ReferencesResults{}, toolErr
+		}
+		if err != nil {
+			return nil, ReferencesResults{}, err
+		}
+		results.Source = "pdf"
+	}
+	results.CacheHit = cacheHit
+	for _, ref := range refs {
+		results.References = append(results.References, Reference{
+			Key:     ref.Key,
+			Text:    ref.Text,
+			Authors: ref.Authors,
+			Title:   ref.Title,
+			Year:    ref.Year,
+			Venue:   ref.Venue,
+			ArxivID: ref.ArxivID,
+			DOI:     ref.DOI,
+		})
+	}
+
+	if err := resolveReferences(ctx, searcher, results.References, query.ReturnFields); err != nil {
+		results.ResolveError = asToolError(err)
+		if results.ResolveError == nil {
+			return nil, ReferencesResults{}, err
+		}
+	}
+	for _, ref := range results.References {
+		if ref.Entry != nil {
+			results.Resolved++
+		}
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: renderReferences(results)}},
+	}, results, nil
+}
+
+// sourceReferences reads the bibliography from the paper's LaTeX source.
+// Its error says why the source could not be downloaded or used, so that the
+// PDF can be read instead.
+func sourceReferences(ctx context.Context, downloader Downloader, id arxivid.ID) ([]references.Reference, bool, error) {
+	data, cacheHit, err := downloadSource(ctx, downloader, id.String())
+	if err != nil {
+		return nil, false, fmt.Errorf("downloading the LaTeX source: %w", err)
+	}
+	archive, err := latex.Unpack(data)
+	if err != nil {
+		return nil, cacheHit, fmt.Errorf("unpacking the LaTeX source: %w", err)
+	}
+	main, err := archive.MainFile()
+	if err != nil {
+		return nil, cacheHit, fmt.Errorf("finding the main LaTeX file: %w", err)
+	}
+	doc, err := archive.Parse(main)
+	if err != nil {
+		return nil, cacheHit, fmt.Errorf("parsing the LaTeX source: %w", err)
+	}
+
+	var refs []references.Reference
+	for _, item := range doc.Bibliography {
+		if item.Fields != nil {
+			refs = append(refs, references.FromBibTeX(item.Key, item.Fields))
+			continue
+		}
+		ref := references.Parse(references.PlainText(item.Text))
+		ref.Key = item.Key
+		refs = append(refs, ref)
+	}
+	return refs, cacheHit, nil
+}
+
+// referencesHeadingRe matches the headings that reference lists are given.
+var referencesHeadingRe = regexp.MustCompile(`(?i)^(?:[\dA-Z]{1,3}\.?\s+)?(?:references|bibliography|literature cited|works cited)$`)
+
+// pdfReferences reads the reference list from the paper's PDF: the text
+// under its references heading, up to the next heading at the same level or
+// above, such as an appendix.
+func pdfReferences(ctx context.Context, downloader Downloader, id arxivid.ID) ([]references.Reference, bool, error) {
+	data, cacheHit, err := downloadPDF(ctx, downloader, id.String())
+	if err != nil {
+		return nil, false, err
+	}
+	doc, err := pdftext.Extract(data)
+	if err != nil {
+		return nil, cacheHit, &ToolError{
+			Kind:   errorUnreadable,
+			Reason: fmt.Sprintf("text could not be extracted from the PDF of %s (%v)", id, err),
+		}
+	}
+
+	text := newPaperText(doc)
+	start, end := -1, len(text.runes)
+	level := 0
+	for _, s := range text.sections {
+		if start >= 0 && s.Offset > start && s.Level <= level {
+			end = s.Offset
+			break
+		}
+		if start < 0 && referencesHeadingRe.MatchString(strings.TrimSpace(s.Title)) {
+			start = s.Offset + len([]rune(s.Title))
+			level = s.Level
+		}
+	}
+	if start < 0 {
+		return nil, cacheHit, nil
+	}
+
+	var refs []references.Reference
+	for _, entry := range references.Split(string(text.runes[start:end])) {
+		refs = append(refs, references.Parse(entry))
+	}
+	return refs, cacheHit, nil
+}
+
+// resolveReferences looks up the arXiv papers the references cite, through
+// searcher, and sets their entries.
+func resolveReferences(ctx context.Context, searcher Searcher, refs []Reference, fields []string) error {
+	var ids []arxivid.ID
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		if ref.ArxivID == "" || seen[ref.ArxivID] {
+			continue
+		}
+		seen[ref.ArxivID] = true
+		if id, err := arxivid.Parse(ref.ArxivID); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	found, err := lookupPapers(ctx, searcher, ids, "")
+	if err != nil {
+		return err
+	}
+	for _, entry := range found.entries {
+		entryID, err := arxivid.Parse(entry.ID)
+		if err != nil {
+			continue
+		}
+		for i := range refs {
+			if id, err := arxivid.Parse(refs[i].ArxivID); err == nil && id.Matches(entryID) {
+				view := filterEntry(entry, fields)
+				refs[i].Entry = &view
+			}
+		}
+	}
+	return nil
+}
+
+// renderReferences renders the reference list as a numbered list.
+func renderReferences(results ReferencesResults) string {
+	var b strings.Builder
+	if len(results.References) == 0 {
+		fmt.Fprintf(&b, "No references found for arXiv:%s.\n", results.ID)
+		if results.SourceError != "" {
+			fmt.Fprintf(&b, "The LaTeX source was not used (%s).\n", results.SourceError)
+		}
+		return b.String()
+	}
+	fmt.Fprintf(&b, "arXiv:%s cites %d references (read from its %s", results.ID, len(results.References), results.Source)
+	if results.Source == "latex" {
+		b.WriteString(" source")
+	}
+	fmt.Fprintf(&b, "); %d are arXiv papers that were found.\n", results.Resolved)
+	if results.SourceError != "" {
+		fmt.Fprintf(&b, "The LaTeX source was not used (%s).\n", results.SourceError)
+	}
+	if results.ResolveError != nil {
+		fmt.Fprintf(&b, "Cited arXiv papers could not be looked up: %s\n", results.ResolveError.Error())
+	}
+	b.WriteString("\n")
+
+	for i, ref := range results.References {
+		fmt.Fprintf(&b, "%d. ", i+1)
+		if ref.Key != "" {
+			fmt.Fprintf(&b, "[%s] ", ref.Key)
+		}
+		b.WriteString(ref.Text)
+		b.WriteString("\n")
+		if ref.ArxivID != "" {
+			fmt.Fprintf(&b, "   arXiv:%s", ref.ArxivID)
+			if ref.Entry != nil && ref.Entry.Title != nil {
+				fmt.Fprintf(&b, " — %s", textclean.OneLine(*ref.Entry.Title))
+			}
+			b.WriteString("\n")
+		}
+		if ref.DOI != "" {
+			fmt.Fprintf(&b, "   doi:%s\n", ref.DOI)
+		}
+	}
+	return b.String()
+}
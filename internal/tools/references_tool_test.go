@@ -0,0 +1,194 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivclient"
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestReferencesTool(t *testing.T) {
+	tool := ReferencesTool()
+	if tool.Name != "arxiv-references" {
+		t.Errorf("expected tool name 'arxiv-references', got '%s'", tool.Name)
+	}
+	if tool.InputSchema == nil {
+		t.Fatal("expected InputSchema to be non-nil")
+	}
+	for _, property := range []string{"id", "return_fields"} {
+		if _, ok := tool.InputSchema.Properties[property]; !ok {
+			t.Errorf("expected property %q in the input schema", property)
+		}
+	}
+}
+
+// failingSearcher fails every search with err.
+type failingSearcher struct {
+	err error
+}
+
+func (s failingSearcher) Search(ctx context.Context, params arxiv.SearchParams) (arxiv.SearchResults, error) {
+	return arxiv.SearchResults{}, s.err
+}
+
+// sourcelessDownloader fails to download any paper's source.
+type sourcelessDownloader struct {
+	Downloader
+	err error
+}
+
+func (d sourcelessDownloader) Source(ctx context.Context, id string) ([]byte, error) {
+	return nil, d.err
+}
+
+// failingDownloader fails to download any file.
+type failingDownloader struct {
+	err error
+}
+
+func (d failingDownloader) PDF(ctx context.Context, id string) ([]byte, error) {
+	return nil, d.err
+}
+
+func (d failingDownloader) Source(ctx context.Context, id string) ([]byte, error) {
+	return nil, d.err
+}
+
+func TestReferencesHandler(t *testing.T) {
+	client := newTestClient(t)
+	handler := ReferencesHandler(client, client)
+	ctx := context.Background()
+	list := func(t *testing.T, handler mcp.ToolHandlerFor[ReferencesQuery, ReferencesResults], query ReferencesQuery) (*mcp.CallToolResult, ReferencesResults) {
+		t.Helper()
+		result, results, err := handler(ctx, &mcp.CallToolRequest{}, query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("unexpected tool error: %+v", results.Error)
+		}
+		return result, results
+	}
+
+	tests := []struct {
+		name   string
+		id     string
+		source string
+		key    string
+	}{
+		{name: "from latex source", id: "1706.03762v7", source: "latex", key: "kingma2014"},
+		{name: "from a single file source", id: "hep-th/9711200", source: "latex", key: "kingma2014"},
+		{name: "from the pdf", id: "math/0211159", source: "pdf"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, results := list(t, handler, ReferencesQuery{ID: tt.id})
+			if results.Source != tt.source {
+				t.Errorf("expected references from %s, got %s", tt.source, results.Source)
+			}
+			if len(results.References) < 2 {
+				t.Fatalf("expected the fixture's references, got %+v", results.References)
+			}
+			first := results.References[0]
+			if first.Title != "An earlier paper" || first.Year != "2001" || len(first.Authors) != 1 {
+				t.Errorf("unexpected first reference %+v", first)
+			}
+
+			last := results.References[len(results.References)-1]
+			if last.Key != tt.key || last.Title != "Adam: A method for stochastic optimization" || last.Year != "2014" {
+				t.Errorf("unexpected last reference %+v", last)
+			}
+			if last.ArxivID != "1412.6980" || last.Entry == nil || last.Entry.Title == nil || *last.Entry.Title != "Adam: A Method for Stochastic Optimization" {
+				t.Errorf("expected the cited paper to be resolved, got %+v", last)
+			}
+			if results.Resolved != 1 {
+				t.Errorf("expected 1 resolved reference, got %d", results.Resolved)
+			}
+
+			text := result.Content[0].(*mcp.TextContent).Text
+			if !strings.Contains(text, "arXiv:1412.6980 — Adam: A Method for Stochastic Optimization") {
+				t.Errorf("expected the resolved paper in the text content:\n%s", text)
+			}
+		})
+	}
+
+	t.Run("return fields", func(t *testing.T) {
+		_, results := list(t, handler, ReferencesQuery{ID: "1706.03762v7", ReturnFields: []string{"id"}})
+		entry := results.References[len(results.References)-1].Entry
+		if entry == nil || entry.ID == nil || entry.Title != nil {
+			t.Errorf("expected only the ID of the cited paper, got %+v", entry)
+		}
+	})
+
+	t.Run("lookup fails", func(t *testing.T) {
+		failing := ReferencesHandler(failingSearcher{err: &arxivclient.RateLimitedError{}}, client)
+		_, results := list(t, failing, ReferencesQuery{ID: "1706.03762v7"})
+		if len(results.References) == 0 || results.Resolved != 0 {
+			t.Errorf("expected unresolved references, got %+v", results)
+		}
+		if results.ResolveError == nil || results.ResolveError.Kind != errorRateLimited {
+			t.Errorf("expected a rate_limited resolve error, got %+v", results.ResolveError)
+		}
+	})
+
+	sourceErrors := []struct {
+		name string
+		err  error
+	}{
+		{name: "source not found", err: &arxivclient.NotFoundError{IDs: []string{"math/0211159"}}},
+		{name: "source unavailable", err: &arxivclient.UnavailableError{Status: "503 Service Unavailable"}},
+		{name: "source too large", err: errors.New("math/0211159 is larger than 100 MB")},
+	}
+	for _, tt := range sourceErrors {
+		t.Run(tt.name, func(t *testing.T) {
+			sourceless := ReferencesHandler(client, sourcelessDownloader{Downloader: client, err: tt.err})
+			_, results := list(t, sourceless, ReferencesQuery{ID: "math/0211159"})
+			if results.Source != "pdf" || len(results.References) == 0 {
+				t.Errorf("expected references from the pdf, got %+v", results)
+			}
+			if !strings.Contains(results.SourceError, tt.err.Error()) {
+				t.Errorf("expected the source error %q, got %q", tt.err, results.SourceError)
+			}
+		})
+	}
+
+	t.Run("pdf fails too", func(t *testing.T) {
+		unavailable := &arxivclient.UnavailableError{Status: "503 Service Unavailable"}
+		failing := ReferencesHandler(client, failingDownloader{err: unavailable})
+		_, results, err := failing(ctx, &mcp.CallToolRequest{}, ReferencesQuery{ID: "math/0211159"})
+		if err != nil {
+			t.Fatalf("expected a tool error, got %v", err)
+		}
+		if results.Error == nil || results.Error.Kind != errorUnavailable || !strings.Contains(results.Error.Reason, "the LaTeX source was not used: downloading the LaTeX source") {
+			t.Errorf("expected the pdf's error with why the source was not used, got %+v", results.Error)
+		}
+	})
+
+	errorTests := []struct {
+		name  string
+		query ReferencesQuery
+		kind  string
+		field string
+	}{
+		{name: "invalid id", query: ReferencesQuery{ID: "attention"}, kind: errorInvalidInput, field: "id"},
+		{name: "unknown paper", query: ReferencesQuery{ID: "2401.99999"}, kind: errorNotFound},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, results, err := handler(ctx, &mcp.CallToolRequest{}, tt.query)
+			if err != nil {
+				t.Fatalf("expected a tool error, got %v", err)
+			}
+			if !result.IsError || results.Error == nil {
+				t.Fatal("expected IsError and an error in the results")
+			}
+			if results.Error.Kind != tt.kind || results.Error.Field != tt.field {
+				t.Errorf("expected %s error on %q, got %+v", tt.kind, tt.field, results.Error)
+			}
+		})
+	}
+}
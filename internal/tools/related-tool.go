@@ -0,0 +1,367 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivid"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivquery"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/citation"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/textclean"
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// corpusSearcher is a Searcher that knows how common words are in the titles
+// and abstracts of the papers it keeps locally.
+type corpusSearcher interface {
+	DocumentFrequency(word string) (df, papers int)
+}
+
+const (
+	defaultRelatedResults = 10
+	maxRelatedResults     = 50
+	// relatedTerms is how many of the seed's key terms are searched for.
+	relatedTerms = 8
+)
+
+type RelatedQuery struct {
+	ID         string `json:"id" jsonschema:"arXiv ID of the seed paper to find papers like"`
+	MaxResults int    `json:"max,omitempty" jsonschema:"number of related papers to return. Defaults to 10"`
+	Format     string `json:"format,omitempty" jsonschema:"how to render the text content: table, list, full, bibtex, ris or csl-json, as for arxiv-search. Defaults to list"`
+}
+
+type RelatedResults struct {
+	Seed       string      `json:"seed" jsonschema:"arXiv ID of the seed paper, without version"`
+	Terms      []string    `json:"terms,omitempty" jsonschema:"key terms of the seed's title and abstract that were searched for, most distinctive first"`
+	Categories []string    `json:"categories,omitempty" jsonschema:"the seed's categories, which the search was limited to"`
+	Entries    []EntryView `json:"entries,omitempty" jsonschema:"related papers, most similar first, with their similarity as score"`
+	CacheHit   bool        `json:"cache_hit" jsonschema:"whether the metadata was served from the cache"`
+	Error      *ToolError  `json:"error,omitempty" jsonschema:"why related papers could not be found, if they could not"`
+}
+
+func RelatedTool() *mcp.Tool {
+	inputSchema, err := jsonschema.For[RelatedQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+	inputSchema.Properties["format"].Enum = searchFormats
+
+	relatedTool := mcp.Tool{
+		Name:        "arxiv-related",
+		Description: "Finds papers like a seed paper: searches its categories for the key terms of its title and abstract, and ranks the results by how similar their titles and abstracts are to the seed's",
+		InputSchema: inputSchema,
+	}
+	return &relatedTool
+}
+
+func RelatedHandler(searcher Searcher) mcp.ToolHandlerFor[RelatedQuery, RelatedResults] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, query RelatedQuery) (*mcp.CallToolResult, RelatedResults, error) {
+		result, relatedResults, err := related(ctx, searcher, query)
+		if toolErr := asToolError(err); toolErr != nil {
+			return errorResult(toolErr), RelatedResults{Error: toolErr}, nil
+		}
+		return result, relatedResults, err
+	}
+}
+
+func related(ctx context.Context, searcher Searcher, query RelatedQuery) (*mcp.CallToolResult, RelatedResults, error) {
+	if err := validateFormat(query.Format); err != nil {
+		return nil, RelatedResults{}, err
+	}
+	id, err := arxivid.Parse(query.ID)
+	if err != nil {
+		return nil, RelatedResults{}, invalidInput("id", "1706.03762", "%q is not an arXiv ID", query.ID)
+	}
+	max := query.MaxResults
+	if max == 0 {
+		max = defaultRelatedResults
+	}
+	if max < 0 || max > maxRelatedResults {
+		return nil, RelatedResults{}, invalidInput("max", "10", "must be between 1 and %d, got %d", maxRelatedResults, max)
+	}
+
+	found, err := lookupPapers(ctx, searcher, []arxivid.ID{id}, "id")
+	if err != nil {
+		return nil, RelatedResults{}, err
+	}
+	seed, cacheHit := found.entries[0], found.cacheHit
+	idf := backgroundIDF(searcher)
+	terms := keyTerms(seed, relatedTerms, idf)
+	if len(terms) == 0 {
+		return nil, RelatedResults{}, invalidInput("id", "1706.03762", "%s has no words in its title or abstract to search for", id.WithVersion(0))
+	}
+	var categories []string
+	for _, category := range seed.Categories {
+		categories = append(categories, category.Term)
+	}
+
+	// Fetch more candidates than asked for, as for re-ranked searches, and
+	// one more for the seed itself.
+	params := arxiv.SearchParams{
+		Query:      relatedQuery(terms, categories),
+		MaxResults: min(max*rerankFactor+1, maxResults),
+		SortBy:     arxiv.SortByRelevance,
+		SortOrder:  arxiv.SortOrderDescending,
+	}
+	results, hit, err := runSearch(ctx, searcher, params)
+	if err != nil {
+		return nil, RelatedResults{}, err
+	}
+	var candidates []arxiv.EntryMetadata
+	for _, entry := range results.Entries {
+		if candidate, err := arxivid.Parse(entry.ID); err == nil && candidate.Base != id.Base {
+			candidates = append(candidates, entry)
+		}
+	}
+	if idf == nil {
+		idf = corpusIDF(append([]arxiv.EntryMetadata{seed}, candidates...))
+	}
+
+	ranked := rankSimilar(seed, candidates, idf)
+	ranked = ranked[:min(max, len(ranked))]
+	entries := make([]arxiv.EntryMetadata, len(ranked))
+	relatedResults := RelatedResults{
+		Seed:       id.WithVersion(0).String(),
+		Terms:      terms,
+		Categories: categories,
+		Entries:    make([]EntryView, len(ranked)),
+		CacheHit:   cacheHit && hit,
+	}
+	for i, r := range ranked {
+		entries[i] = r.entry
+		relatedResults.Entries[i] = filterEntry(r.entry, nil)
+		relatedResults.Entries[i].Score = &r.score
+		relatedResults.Entries[i].ArxivRank = &r.arxivRank
+	}
+
+	var text string
+	if isCitationFormat(query.Format) {
+		text, err = citation.Export(query.Format, entries)
+		if err != nil {
+			return nil, RelatedResults{}, err
+		}
+	} else {
+		text = renderRelated(seed, relatedResults, query.Format)
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: text}},
+	}, relatedResults, nil
+}
+
+// relatedQuery builds a search for papers in any of categories with any of
+// terms in their title or abstract.
+func relatedQuery(terms, categories []string) string {
+	var termNodes []QueryNode
+	for _, term := range terms {
+		termNodes = append(termNodes, QueryNode{Field: "ti", Value: term}, QueryNode{Field: "abs", Value: term})
+	}
+	node := QueryNode{Op: opOr, Children: &termNodes}
+	if len(categories) > 0 {
+		var categoryNodes []QueryNode
+		for _, category := range categories {
+			categoryNodes = append(categoryNodes, QueryNode{Field: "cat", Value: category})
+		}
+		node = QueryNode{Op: opAnd, Children: &[]QueryNode{{Op: opOr, Children: &categoryNodes}, node}}
+	}
+	arxivQuery, err := buildSearchQuery(SearchQuery{Query: &node})
+	if err != nil {
+		// Terms are single words and categories come from arXiv, so the
+		// tree is always valid.
+		panic(err)
+	}
+	return arxivQuery.String()
+}
+
+// paperWords returns the words of entry's title and abstract that carry
+// meaning, with title words counted twice.
+func paperWords(entry arxiv.EntryMetadata) []string {
+	var words []string
+	for _, word := range arxivquery.Words(entry.Title) {
+		if isKeyWord(word) {
+			words = append(words, word, word)
+		}
+	}
+	for _, word := range arxivquery.Words(entry.Summary) {
+		if isKeyWord(word) {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// isKeyWord reports whether word could be a key term: not a stop word, a
+// number or a fragment.
+func isKeyWord(word string) bool {
+	if len(word) < 3 || stopWords[word] || strings.Contains(word, "*") {
+		return false
+	}
+	return strings.Trim(word, "0123456789") != ""
+}
+
+// backgroundIDF returns the inverse document frequency of words in the
+// papers searcher keeps locally, or nil if it keeps none.
+func backgroundIDF(searcher Searcher) func(word string) float64 {
+	corpus, ok := searcher.(corpusSearcher)
+	if !ok {
+		return nil
+	}
+	if _, papers := corpus.DocumentFrequency(""); papers == 0 {
+		return nil
+	}
+	cache := make(map[string]float64)
+	return func(word string) float64 {
+		if idf, ok := cache[word]; ok {
+			return idf
+		}
+		df, papers := corpus.DocumentFrequency(word)
+		idf := smoothIDF(df, papers)
+		cache[word] = idf
+		return idf
+	}
+}
+
+// corpusIDF returns the inverse document frequency of words in entries.
+func corpusIDF(entries []arxiv.EntryMetadata) func(word string) float64 {
+	df := make(map[string]int)
+	for _, entry := range entries {
+		seen := make(map[string]bool)
+		for _, word := range paperWords(entry) {
+			if !seen[word] {
+				seen[word] = true
+				df[word]++
+			}
+		}
+	}
+	return func(word string) float64 { return smoothIDF(df[word], len(entries)) }
+}
+
+func smoothIDF(df, papers int) float64 {
+	return math.Log(float64(papers+1)/float64(df+1)) + 1
+}
+
+// tfidf returns the TF-IDF vector of words.
+func tfidf(words []string, idf func(word string) float64) map[string]float64 {
+	vector := make(map[string]float64)
+	for _, word := range words {
+		vector[word]++
+	}
+	for word, tf := range vector {
+		if idf != nil {
+			vector[word] = tf * idf(word)
+		}
+	}
+	return vector
+}
+
+// keyTerms returns the n words of entry with the highest TF-IDF, the most
+// distinctive first. Without a background, words are weighed by how often
+// they occur.
+func keyTerms(entry arxiv.EntryMetadata, n int, idf func(word string) float64) []string {
+	vector := tfidf(paperWords(entry), idf)
+	terms := make([]string, 0, len(vector))
+	for word := range vector {
+		terms = append(terms, word)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if vector[terms[i]] != vector[terms[j]] {
+			return vector[terms[i]] > vector[terms[j]]
+		}
+		return terms[i] < terms[j]
+	})
+	return terms[:min(n, len(terms))]
+}
+
+// rankSimilar returns candidates by the cosine similarity of their TF-IDF
+// vectors to the seed's, most similar first. Candidates with equal scores
+// keep arXiv's order.
+func rankSimilar(seed arxiv.EntryMetadata, candidates []arxiv.EntryMetadata, idf func(word string) float64) []rankedEntry {
+	seedVector := tfidf(paperWords(seed), idf)
+	ranked := make([]rankedEntry, len(candidates))
+	for i, candidate := range candidates {
+		score := cosine(seedVector, tfidf(paperWords(candidate), idf))
+		ranked[i] = rankedEntry{entry: candidate, score: score, arxivRank: i + 1}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+	return ranked
+}
+
+func cosine(a, b map[string]float64) float64 {
+	var dot, normA, normB float64
+	for word, x := range a {
+		dot += x * b[word]
+		normA += x * x
+	}
+	for _, y := range b {
+		normB += y * y
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
+
+func renderRelated(seed arxiv.EntryMetadata, results RelatedResults, format string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Papers like **%s** (%s), found by searching %s for %s.\n\n",
+		textclean.OneLine(seed.Title), results.Seed, categoryList(results.Categories), strings.Join(results.Terms, ", "))
+	if len(results.Entries) == 0 {
+		b.WriteString("No related papers found.\n")
+		return b.String()
+	}
+	switch format {
+	case formatTable:
+		renderTable(&b, results.Entries)
+	case formatFull:
+		renderFull(&b, results.Entries)
+	default:
+		renderList(&b, results.Entries)
+	}
+	return b.String()
+}
+
+func categoryList(categories []string) string {
+	if len(categories) == 0 {
+		return "every category"
+	}
+	return strings.Join(categories, ", ")
+}
+
+// stopWords are common English words, and words common to most abstracts,
+// that say nothing about what a paper is about.
+var stopWords = map[string]bool{
+	"about": true, "above": true, "after": true, "again": true, "against": true,
+	"all": true, "also": true, "although": true, "among": true, "and": true,
+	"any": true, "are": true, "because": true, "been": true, "before": true,
+	"being": true, "below": true, "between": true, "both": true, "but": true,
+	"can": true, "could": true, "did": true, "does": true, "doing": true,
+	"down": true, "during": true, "each": true, "either": true, "few": true,
+	"for": true, "from": true, "further": true, "had": true, "has": true,
+	"have": true, "having": true, "here": true, "how": true, "however": true,
+	"into": true, "its": true, "itself": true, "just": true, "may": true,
+	"more": true, "most": true, "much": true, "must": true, "not": true,
+	"now": true, "off": true, "once": true, "only": true, "other": true,
+	"our": true, "ours": true, "out": true, "over": true, "own": true,
+	"same": true, "shall": true, "should": true, "since": true, "some": true,
+	"such": true, "than": true, "that": true, "the": true, "their": true,
+	"theirs": true, "them": true, "then": true, "there": true, "these": true,
+	"they": true, "this": true, "those": true, "through": true, "thus": true,
+	"too": true, "under": true, "until": true, "upon": true, "very": true,
+	"was": true, "we": true, "were": true, "what": true, "when": true,
+	"where": true, "whether": true, "which": true, "while": true, "who": true,
+	"whom": true, "whose": true, "why": true, "will": true, "with": true,
+	"within": true, "without": true, "would": true, "yet": true, "you": true,
+	"your": true, "approach": true, "approaches": true, "based": true,
+	"demonstrate": true, "et": true, "first": true, "find": true, "give": true,
+	"given": true, "method": true, "methods": true, "new": true, "novel": true,
+	"obtain": true, "obtained": true, "one": true, "paper": true,
+	"present": true, "propose": true, "proposed": true, "provide": true,
+	"result": true, "results": true, "second": true, "show": true,
+	"shown": true, "study": true, "studies": true, "three": true, "two": true,
+	"use": true, "used": true, "uses": true, "using": true, "well": true,
+	"work": true,
+}
@@ -0,0 +1,150 @@
+package tools
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivclient"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivid"
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestRelatedTool(t *testing.T) {
+	tool := RelatedTool()
+	if tool.Name != "arxiv-related" {
+		t.Errorf("expected tool name 'arxiv-related', got '%s'", tool.Name)
+	}
+	if tool.InputSchema == nil {
+		t.Fatal("expected InputSchema to be non-nil")
+	}
+	if required := tool.InputSchema.Required; len(required) != 1 || required[0] != "id" {
+		t.Errorf("expected id to be required, got %v", required)
+	}
+}
+
+// corpusStub is a Searcher with a background corpus in which every paper has
+// the words in common.
+type corpusStub struct {
+	Searcher
+	common map[string]bool
+}
+
+func (s corpusStub) DocumentFrequency(word string) (int, int) {
+	if s.common[word] {
+		return 1000, 1000
+	}
+	return 1, 1000
+}
+
+func TestKeyTerms(t *testing.T) {
+	entry := arxiv.EntryMetadata{
+		Title:   "Graph networks for molecules",
+		Summary: "We use graph networks on molecules. The networks are trained on 2024 data.",
+	}
+	tests := []struct {
+		name     string
+		idf      func(word string) float64
+		expected []string
+	}{
+		{
+			name:     "by frequency without a background",
+			expected: []string{"networks", "graph", "molecules"},
+		},
+		{
+			name:     "common words weigh less",
+			idf:      backgroundIDF(corpusStub{common: map[string]bool{"networks": true, "graph": true}}),
+			expected: []string{"molecules", "data", "trained"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keyTerms(entry, 3, tt.idf); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRelatedQuery(t *testing.T) {
+	got := relatedQuery([]string{"diffusion", "denoising"}, []string{"cs.LG", "stat.ML"})
+	expected := "(cat:cs.LG OR cat:stat.ML) AND (ti:diffusion OR abs:diffusion OR ti:denoising OR abs:denoising)"
+	if got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestRelatedHandler(t *testing.T) {
+	client := newTestClient(t)
+
+	t.Run("finds papers like the seed", func(t *testing.T) {
+		result, related, err := RelatedHandler(client)(context.Background(), &mcp.CallToolRequest{}, RelatedQuery{ID: "2006.11239"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if related.Error != nil {
+			t.Fatalf("unexpected error %+v", related.Error)
+		}
+		if related.Seed != "2006.11239" || !reflect.DeepEqual(related.Categories, []string{"cs.LG", "stat.ML"}) {
+			t.Errorf("unexpected seed %s or categories %v", related.Seed, related.Categories)
+		}
+		if len(related.Terms) != relatedTerms || related.Terms[0] != "models" {
+			t.Errorf("expected %d key terms, most frequent first, got %v", relatedTerms, related.Terms)
+		}
+		if len(related.Entries) == 0 || displayID(*related.Entries[0].ID) != "1706.03762v7" {
+			t.Fatalf("expected 1706.03762 to be most similar, got %+v", related.Entries)
+		}
+		for i, entry := range related.Entries {
+			if entry.Score == nil || *entry.Score <= 0 || *entry.Score > 1 || entry.ArxivRank == nil {
+				t.Errorf("expected a similarity and arXiv rank for entry %d, got %+v", i, entry)
+			}
+			if i > 0 && *entry.Score > *related.Entries[i-1].Score {
+				t.Errorf("expected entries most similar first, got %v after %v", *entry.Score, *related.Entries[i-1].Score)
+			}
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "Papers like **Denoising Diffusion Probabilistic Models** (2006.11239)") {
+			t.Errorf("expected the seed in the text, got %q", text)
+		}
+	})
+
+	t.Run("excludes every version of the seed", func(t *testing.T) {
+		_, related, err := RelatedHandler(client)(context.Background(), &mcp.CallToolRequest{}, RelatedQuery{ID: "1706.03762v1", MaxResults: 50})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if related.Error != nil {
+			t.Fatalf("unexpected error %+v", related.Error)
+		}
+		for _, entry := range related.Entries {
+			if id, _ := arxivid.Parse(*entry.ID); id.Base == "1706.03762" {
+				t.Errorf("expected the seed to be excluded, got %s", *entry.ID)
+			}
+		}
+	})
+
+	errorTests := []struct {
+		name     string
+		searcher Searcher
+		query    RelatedQuery
+		kind     string
+	}{
+		{"invalid id", client, RelatedQuery{ID: "not-an-id"}, errorInvalidInput},
+		{"too many results", client, RelatedQuery{ID: "2006.11239", MaxResults: maxRelatedResults + 1}, errorInvalidInput},
+		{"unknown paper", client, RelatedQuery{ID: "2401.99999"}, errorNotFound},
+		{"upstream error", errorSearcher{err: &arxivclient.UnavailableError{Status: "503 Service Unavailable"}}, RelatedQuery{ID: "2006.11239"}, errorUnavailable},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, related, err := RelatedHandler(tt.searcher)(context.Background(), &mcp.CallToolRequest{}, tt.query)
+			if err != nil {
+				t.Fatalf("expected a tool error, got protocol error %v", err)
+			}
+			if !result.IsError || related.Error == nil || related.Error.Kind != tt.kind {
+				t.Errorf("expected %s error, got %+v", tt.kind, related.Error)
+			}
+		})
+	}
+}
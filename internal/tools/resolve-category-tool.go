@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/taxonomy"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type ResolveCategoryQuery struct {
+	Query      string `json:"query" jsonschema:"subject in plain words, such as robotics, number theory or galaxy formation. A category tag is also accepted"`
+	MaxResults int    `json:"max,omitempty" jsonschema:"maximum number of candidates to return. Defaults to 5"`
+}
+
+type ResolveCategoryResults struct {
+	Candidates []CategoryCandidate `json:"candidates"`
+	Error      *ToolError          `json:"error,omitempty" jsonschema:"why the lookup failed, if it did"`
+}
+
+type CategoryCandidate struct {
+	Tag         string  `json:"tag"`
+	Label       string  `json:"label"`
+	Description string  `json:"description,omitempty"`
+	Field       string  `json:"field"`
+	Score       float64 `json:"score" jsonschema:"relevance of the category to the query, relative to the best candidate (1)"`
+}
+
+func ResolveCategoryTool() *mcp.Tool {
+	inputSchema, err := jsonschema.For[ResolveCategoryQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+
+	resolveCategoryTool := mcp.Tool{
+		Name:        "arxiv-resolve-category",
+		Description: "Finds arXiv category tags for a subject described in plain words, for use as subject_category in arxiv-search",
+		InputSchema: inputSchema,
+	}
+	return &resolveCategoryTool
+}
+
+func ResolveCategoryHandler(_ context.Context, req *mcp.CallToolRequest, query ResolveCategoryQuery) (*mcp.CallToolResult, ResolveCategoryResults, error) {
+	if strings.TrimSpace(query.Query) == "" {
+		toolErr := invalidInput("query", "machine learning", "must not be empty")
+		return errorResult(toolErr), ResolveCategoryResults{Error: toolErr}, nil
+	}
+	max := query.MaxResults
+	if max <= 0 {
+		max = 5
+	}
+
+	results := ResolveCategoryResults{
+		Candidates: resolveCategory(taxonomy.Default(), query.Query, max),
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: renderCandidates(query.Query, results.Candidates)}},
+	}, results, nil
+}
+
+// renderCandidates renders the best candidate's tag, for use as
+// subject_category, followed by the other candidates.
+func renderCandidates(query string, candidates []CategoryCandidate) string {
+	var b strings.Builder
+	if len(candidates) == 0 {
+		fmt.Fprintf(&b, "No arXiv category matches %q; try other or broader words.\n", query)
+		return b.String()
+	}
+	best := candidates[0]
+	fmt.Fprintf(&b, "Best match for %q: %s (%s, in %s).\n", query, best.Tag, best.Label, best.Field)
+	if len(candidates) > 1 {
+		b.WriteString("\nOther candidates:\n")
+		for _, c := range candidates[1:] {
+			fmt.Fprintf(&b, "- %s: %s, in %s (score %.2f)\n", c.Tag, c.Label, c.Field, c.Score)
+		}
+	}
+	return b.String()
+}
+
+// resolveCategory ranks the categories matching query. A query that is itself
+// a category tag resolves to that category, followed by its aliases.
+func resolveCategory(arxivTaxonomy *taxonomy.Taxonomy, query string, max int) []CategoryCandidate {
+	var candidates []CategoryCandidate
+	seen := make(map[string]bool)
+	add := func(category taxonomy.Category, score float64) {
+		if seen[category.Tag] || len(candidates) >= max {
+			return
+		}
+		seen[category.Tag] = true
+		field, _ := arxivTaxonomy.FieldOf(category.Tag)
+		candidates = append(candidates, CategoryCandidate{
+			Tag:         category.Tag,
+			Label:       category.Label,
+			Description: category.Description,
+			Field:       field,
+			Score:       math.Round(score*1000) / 1000,
+		})
+	}
+
+	if category, ok := arxivTaxonomy.Lookup(query); ok {
+		add(category, 1)
+		for _, alias := range arxivTaxonomy.Aliases(category.Tag) {
+			if aliasCategory, ok := arxivTaxonomy.Lookup(alias); ok {
+				add(aliasCategory, 1)
+			}
+		}
+	}
+
+	matches := arxivTaxonomy.Search(query, max)
+	for _, match := range matches {
+		add(match.Category, match.Score/matches[0].Score)
+	}
+	return candidates
+}
@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/taxonomy"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestResolveCategoryTool(t *testing.T) {
+	tool := ResolveCategoryTool()
+	if tool.Name != "arxiv-resolve-category" {
+		t.Errorf("expected tool name 'arxiv-resolve-category', got '%s'", tool.Name)
+	}
+	if tool.InputSchema == nil {
+		t.Error("expected InputSchema to be non-nil")
+	}
+}
+
+func TestResolveCategoryHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{name: "robotics", query: "robotics", expected: "cs.RO"},
+		{name: "number theory", query: "number theory", expected: "math.NT"},
+		{name: "galaxy formation", query: "galaxy formation", expected: "astro-ph.GA"},
+		{name: "tag", query: "hep-th", expected: "hep-th"},
+		{name: "tag in wrong case", query: "CS.cl", expected: "cs.CL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, results, err := ResolveCategoryHandler(context.Background(), &mcp.CallToolRequest{}, ResolveCategoryQuery{Query: tt.query})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result == nil {
+				t.Error("expected non-nil CallToolResult")
+			}
+			if len(results.Candidates) == 0 {
+				t.Fatal("expected candidates")
+			}
+			best := results.Candidates[0]
+			if best.Tag != tt.expected {
+				t.Errorf("expected %s first, got %+v", tt.expected, results.Candidates)
+			}
+			if best.Score != 1 {
+				t.Errorf("expected best candidate to score 1, got %v", best.Score)
+			}
+			if best.Label == "" || best.Field == "" {
+				t.Errorf("expected label and field to be set, got %+v", best)
+			}
+			if len(results.Candidates) > 5 {
+				t.Errorf("expected at most 5 candidates by default, got %d", len(results.Candidates))
+			}
+			text := result.Content[0].(*mcp.TextContent).Text
+			if !strings.HasPrefix(text, fmt.Sprintf("Best match for %q: %s (", tt.query, tt.expected)) {
+				t.Errorf("expected the best match in the text content, got:\n%s", text)
+			}
+			if len(results.Candidates) > 1 && !strings.Contains(text, "- "+results.Candidates[1].Tag+": ") {
+				t.Errorf("expected the other candidates in the text content, got:\n%s", text)
+			}
+		})
+	}
+}
+
+func TestResolveCategoryHandlerEmptyQuery(t *testing.T) {
+	result, results, err := ResolveCategoryHandler(context.Background(), &mcp.CallToolRequest{}, ResolveCategoryQuery{Query: "  "})
+	if err != nil {
+		t.Fatalf("expected a tool error, got protocol error %v", err)
+	}
+	if !result.IsError {
+		t.Error("expected IsError for empty query")
+	}
+	if results.Error == nil || results.Error.Field != "query" {
+		t.Errorf("expected error on field query, got %+v", results.Error)
+	}
+}
+
+func TestResolveCategoryAliases(t *testing.T) {
+	candidates := resolveCategory(taxonomy.Default(), "math.IT", 3)
+	if len(candidates) < 2 || candidates[0].Tag != "math.IT" || candidates[1].Tag != "cs.IT" {
+		t.Errorf("expected math.IT followed by its alias cs.IT, got %+v", candidates)
+	}
+}
+
+func TestResolveCategoryMax(t *testing.T) {
+	candidates := resolveCategory(taxonomy.Default(), "learning", 2)
+	if len(candidates) != 2 {
+		t.Errorf("expected 2 candidates, got %d", len(candidates))
+	}
+	for i := 1; i < len(candidates); i++ {
+		if candidates[i].Score > candidates[i-1].Score {
+			t.Errorf("expected candidates sorted by score, got %+v", candidates)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/taxonomy"
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// maxCategorySuggestions is the number of corrections offered for each
+// unknown category.
+const maxCategorySuggestions = 5
+
+// CategoryError reports subject categories that are not in the arXiv
+// taxonomy, with the categories that were most likely meant.
+type CategoryError struct {
+	Unknown []UnknownCategory `json:"unknown"`
+}
+
+type UnknownCategory struct {
+	Tag         string               `json:"tag"`
+	Suggestions []CategorySuggestion `json:"suggestions,omitempty"`
+}
+
+type CategorySuggestion struct {
+	Tag   string `json:"tag"`
+	Label string `json:"label"`
+}
+
+func (e *CategoryError) Error() string {
+	var b strings.Builder
+	for i, unknown := range e.Unknown {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		fmt.Fprintf(&b, "unknown subject category %q", unknown.Tag)
+		if len(unknown.Suggestions) == 0 {
+			continue
+		}
+		b.WriteString(", did you mean: ")
+		for j, suggestion := range unknown.Suggestions {
+			if j > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "%s (%s)", suggestion.Tag, suggestion.Label)
+		}
+	}
+	b.WriteString(". Use tags from the arXiv category taxonomy, such as cs.LG, or archive tags such as cs")
+	return b.String()
+}
+
+// resolveCategories validates tags against the taxonomy and expands them into
+// the categories to search. Archive tags such as cs or math.* are replaced with
+// the categories they contain, and aliases such as math.IT and cs.IT are
+// searched together. Unknown tags are reported in a *CategoryError.
+func resolveCategories(tags []string) ([]string, error) {
+	arxivTaxonomy := taxonomy.Default()
+	var resolved []string
+	seen := make(map[string]bool)
+	add := func(tag string) {
+		if !seen[tag] {
+			seen[tag] = true
+			resolved = append(resolved, tag)
+		}
+	}
+	addWithAliases := func(tag string) {
+		add(tag)
+		for _, alias := range arxivTaxonomy.Aliases(tag) {
+			add(alias)
+		}
+	}
+
+	var categoryErr CategoryError
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		archive, wildcard := strings.CutSuffix(tag, ".*")
+		if category, ok := arxivTaxonomy.Lookup(tag); ok && !wildcard {
+			addWithAliases(category.Tag)
+			continue
+		}
+		if members := arxivTaxonomy.ArchiveCategories(strings.ToLower(archive)); len(members) > 0 {
+			for _, member := range members {
+				addWithAliases(member.Tag)
+			}
+			continue
+		}
+		categoryErr.Unknown = append(categoryErr.Unknown, unknownCategory(tag))
+	}
+
+	if len(categoryErr.Unknown) > 0 {
+		return nil, &categoryErr
+	}
+	return resolved, nil
+}
+
+func unknownCategory(tag string) UnknownCategory {
+	unknown := UnknownCategory{Tag: tag}
+	for _, match := range taxonomy.Default().Suggest(tag, maxCategorySuggestions) {
+		unknown.Suggestions = append(unknown.Suggestions, CategorySuggestion{
+			Tag:   match.Tag,
+			Label: match.Label,
+		})
+	}
+	return unknown
+}
+
+// addCategories appends the categories to arxivQuery, ORed together in a group
+// when there is more than one.
+func addCategories(arxivQuery *arxiv.SearchQuery, tags []string) (*arxiv.SearchQuery, error) {
+	categories, err := resolveCategories(tags)
+	if err != nil {
+		return arxivQuery, err
+	}
+	switch len(categories) {
+	case 0:
+		return arxivQuery, nil
+	case 1:
+		return arxivQuery.Category(categories[0]), nil
+	}
+	return arxivQuery.Group(func(g *arxiv.SearchQuery) {
+		for i, category := range categories {
+			if i > 0 {
+				g.Or()
+			}
+			g.Category(category)
+		}
+	}), nil
+}
@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// maxStart and maxResults are the largest offset and page size the arXiv API
+// accepts.
+const (
+	maxStart   = 30000
+	maxResults = 2000
+)
+
+// searchCursor is the state a search carries from one page to the next: the
+// search parameters for the next page and, for a re-ranked search, how many
+// of the re-ranked results of the window of arXiv's results starting at
+// Start were shown on earlier pages.
+type searchCursor struct {
+	arxiv.SearchParams
+	Rerank bool `json:"rerank,omitempty"`
+	Ranked int  `json:"ranked,omitempty"`
+}
+
+// A cursor is the base64-encoded JSON of a searchCursor. Carrying the full
+// parameters keeps paging stable even when the query was built from a
+// relative date.
+func encodeCursor(cursor searchCursor) string {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		// searchCursor only holds strings, ints and bools.
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func invalidCursor(err error) *ToolError {
+	return invalidInput("cursor", "", "not a next_cursor from a previous search (%v); pass next_cursor back unchanged, or start a new search", err)
+}
+
+func decodeCursor(encoded string) (searchCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return searchCursor{}, invalidCursor(err)
+	}
+	var cursor searchCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return searchCursor{}, invalidCursor(err)
+	}
+	if err := cursor.Validate(); err != nil {
+		return searchCursor{}, invalidCursor(err)
+	}
+	if cursor.Ranked < 0 || cursor.Ranked > 0 && !cursor.Rerank {
+		return searchCursor{}, invalidCursor(fmt.Errorf("ranked offset %d", cursor.Ranked))
+	}
+	return cursor, nil
+}
+
+// nextCursor returns the cursor for the page following results, or the empty
+// string when there are no further pages. A re-ranked search that has shown
+// fewer than all of the results of its window, ranked of them, stays in the
+// window.
+func nextCursor(cursor searchCursor, results arxiv.SearchResults, ranked int) string {
+	if cursor.Rerank && ranked < len(results.Entries) {
+		cursor.Ranked = ranked
+		return encodeCursor(cursor)
+	}
+	cursor.Ranked = 0
+	if !arxiv.SearchHasMoreResults(results) {
+		return ""
+	}
+	next := results.StartIndex + results.ItemsPerPage
+	if results.ItemsPerPage == 0 || next > maxStart {
+		return ""
+	}
+	cursor.Start = next
+	return encodeCursor(cursor)
+}
@@ -0,0 +1,244 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivid"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/citation"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/textclean"
+)
+
+// Formats for the text content of search results. The structured content is
+// the same whichever is chosen.
+const (
+	formatTable   = "table"          // markdown table, one row per paper
+	formatList    = "list"           // compact list, one line per paper
+	formatFull    = "full"           // markdown section per paper, with its abstract
+	formatBibTeX  = citation.BibTeX  // BibTeX entries
+	formatRIS     = citation.RIS     // RIS records
+	formatCSLJSON = citation.CSLJSON // CSL-JSON array
+)
+
+var searchFormats = []any{formatTable, formatList, formatFull, formatBibTeX, formatRIS, formatCSLJSON}
+
+// maxListedAuthors is how many authors the table and list formats show before
+// "et al.".
+const maxListedAuthors = 3
+
+func validateFormat(format string) error {
+	switch format {
+	case "", formatTable, formatList, formatFull, formatBibTeX, formatRIS, formatCSLJSON:
+		return nil
+	}
+	return invalidInput("format", formatTable, "must be %s, %s, %s, %s, %s or %s, got %q",
+		formatTable, formatList, formatFull, formatBibTeX, formatRIS, formatCSLJSON, format)
+}
+
+// isCitationFormat reports whether format exports citations rather than
+// rendering markdown.
+func isCitationFormat(format string) bool {
+	return format == formatBibTeX || format == formatRIS || format == formatCSLJSON
+}
+
+// renderSearchResults renders results as markdown in the given format,
+// showing only the fields present in the entries.
+func renderSearchResults(results SearchResults, format string) string {
+	var b strings.Builder
+	if results.Source == sourceLocal {
+		b.WriteString("These results are from papers kept locally, not from arXiv, and may be incomplete or out of date.\n\n")
+	}
+	if len(results.Entries) == 0 {
+		b.WriteString("No papers found.\n")
+	} else {
+		if results.RerankedFrom > 0 {
+			fmt.Fprintf(&b, "Showing %d–%d of %d papers re-ranked by BM25 from arXiv's results %d–%d of %d.\n\n",
+				results.RerankedStart+1, results.RerankedStart+len(results.Entries), results.RerankedFrom,
+				results.StartIndex+1, results.StartIndex+results.RerankedFrom, results.TotalResults)
+		} else {
+			fmt.Fprintf(&b, "Showing %d–%d of %d papers.\n\n",
+				results.StartIndex+1, results.StartIndex+len(results.Entries), results.TotalResults)
+		}
+		switch format {
+		case formatTable:
+			renderTable(&b, results.Entries)
+		case formatFull:
+			renderFull(&b, results.Entries)
+		default:
+			renderList(&b, results.Entries)
+		}
+	}
+	if results.NextCursor != "" {
+		fmt.Fprintf(&b, "\nMore results are available: pass cursor %q for the next page.\n", results.NextCursor)
+	}
+	return b.String()
+}
+
+func renderTable(b *strings.Builder, entries []EntryView) {
+	type column struct {
+		header string
+		has    func(EntryView) bool
+		value  func(EntryView) string
+	}
+	columns := []column{
+		{"ID", func(e EntryView) bool { return e.ID != nil }, func(e EntryView) string { return displayID(*e.ID) }},
+		{"Title", func(e EntryView) bool { return e.Title != nil }, func(e EntryView) string { return textclean.OneLine(*e.Title) }},
+		{"Authors", func(e EntryView) bool { return e.Authors != nil }, func(e EntryView) string { return authorList(e, maxListedAuthors) }},
+		{"Published", func(e EntryView) bool { return e.Published != nil }, func(e EntryView) string { return e.Published.Format("2006-01-02") }},
+		{"Category", func(e EntryView) bool { return e.PrimaryCategory != nil }, func(e EntryView) string { return e.PrimaryCategory.Term }},
+		{"Score", func(e EntryView) bool { return e.Score != nil }, func(e EntryView) string { return fmt.Sprintf("%.2f", *e.Score) }},
+		{"arXiv rank", func(e EntryView) bool { return e.ArxivRank != nil }, func(e EntryView) string { return fmt.Sprint(*e.ArxivRank) }},
+	}
+
+	var shown []column
+	for _, c := range columns {
+		for _, e := range entries {
+			if c.has(e) {
+				shown = append(shown, c)
+				break
+			}
+		}
+	}
+	if len(shown) == 0 {
+		return
+	}
+
+	for _, c := range shown {
+		b.WriteString("| " + c.header + " ")
+	}
+	b.WriteString("|\n")
+	for range shown {
+		b.WriteString("| --- ")
+	}
+	b.WriteString("|\n")
+	for _, e := range entries {
+		for _, c := range shown {
+			value := ""
+			if c.has(e) {
+				value = strings.ReplaceAll(c.value(e), "|", `\|`)
+			}
+			b.WriteString("| " + value + " ")
+		}
+		b.WriteString("|\n")
+	}
+}
+
+func renderList(b *strings.Builder, entries []EntryView) {
+	for _, e := range entries {
+		var parts []string
+		if e.Title != nil {
+			parts = append(parts, "**"+textclean.OneLine(*e.Title)+"**")
+		}
+		if e.ID != nil {
+			parts = append(parts, "("+displayID(*e.ID)+")")
+		}
+		var details []string
+		if e.Authors != nil && len(*e.Authors) > 0 {
+			details = append(details, authorList(e, maxListedAuthors))
+		}
+		if e.Published != nil {
+			details = append(details, e.Published.Format("2006-01-02"))
+		}
+		if e.PrimaryCategory != nil && e.PrimaryCategory.Term != "" {
+			details = append(details, e.PrimaryCategory.Term)
+		}
+		if e.Score != nil && e.ArxivRank != nil {
+			details = append(details, fmt.Sprintf("score %.2f, arXiv rank %d", *e.Score, *e.ArxivRank))
+		}
+		if len(details) > 0 {
+			parts = append(parts, "— "+strings.Join(details, ", "))
+		}
+		b.WriteString("- " + strings.Join(parts, " ") + "\n")
+	}
+}
+
+func renderFull(b *strings.Builder, entries []EntryView) {
+	for i, e := range entries {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		title := "Untitled"
+		if e.Title != nil {
+			title = textclean.OneLine(*e.Title)
+		}
+		b.WriteString("## " + title + "\n\n")
+
+		field := func(name, value string) {
+			if value != "" {
+				fmt.Fprintf(b, "- **%s:** %s\n", name, value)
+			}
+		}
+		if e.ID != nil {
+			field("ID", displayID(*e.ID))
+		}
+		if e.Authors != nil {
+			field("Authors", authorList(e, 0))
+		}
+		if e.Published != nil {
+			field("Published", e.Published.Format("2006-01-02"))
+		}
+		if e.Updated != nil && (e.Published == nil || !e.Updated.Equal(*e.Published)) {
+			field("Updated", e.Updated.Format("2006-01-02"))
+		}
+		if e.Categories != nil {
+			var terms []string
+			for _, c := range *e.Categories {
+				terms = append(terms, c.Term)
+			}
+			field("Categories", strings.Join(terms, ", "))
+		} else if e.PrimaryCategory != nil {
+			field("Category", e.PrimaryCategory.Term)
+		}
+		if e.Comment != nil {
+			field("Comment", textclean.OneLine(*e.Comment))
+		}
+		if e.JournalReference != nil {
+			field("Journal", textclean.OneLine(*e.JournalReference))
+		}
+		if e.DOI != nil {
+			field("DOI", *e.DOI)
+		}
+		field("PDF", pdfURL(e))
+		if e.Score != nil && e.ArxivRank != nil {
+			field("Score", fmt.Sprintf("%.2f (arXiv rank %d)", *e.Score, *e.ArxivRank))
+		}
+		if e.Summary != nil && strings.TrimSpace(*e.Summary) != "" {
+			b.WriteString("\n" + textclean.OneLine(*e.Summary) + "\n")
+		}
+	}
+}
+
+// displayID shortens an arXiv abs URL to its identifier.
+func displayID(raw string) string {
+	if id, err := arxivid.Parse(raw); err == nil {
+		return id.String()
+	}
+	return raw
+}
+
+// authorList joins the entry's author names, cutting off after max authors
+// with "et al." if max is positive.
+func authorList(e EntryView, max int) string {
+	var names []string
+	for _, author := range *e.Authors {
+		names = append(names, author.Name)
+	}
+	if max > 0 && len(names) > max {
+		return strings.Join(names[:max], ", ") + " et al."
+	}
+	return strings.Join(names, ", ")
+}
+
+func pdfURL(e EntryView) string {
+	if e.PDFUrl != nil && *e.PDFUrl != "" {
+		return *e.PDFUrl
+	}
+	if e.Links != nil {
+		for _, link := range *e.Links {
+			if link.Title == "pdf" {
+				return link.Href
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,204 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// QueryNode is a node in a boolean search expression. A node is either a term,
+// with Field and Value set, or an operator, with Op and Children set.
+//
+// Children is a pointer so that QueryNode is comparable and can be used as a
+// key in jsonschema.ForOptions.TypeSchemas.
+type QueryNode struct {
+	Op       string       `json:"op,omitempty"`
+	Children *[]QueryNode `json:"children,omitempty"`
+	Field    string       `json:"field,omitempty"`
+	Value    string       `json:"value,omitempty"`
+	Phrase   bool         `json:"phrase,omitempty"`
+}
+
+func (node QueryNode) children() []QueryNode {
+	if node.Children == nil {
+		return nil
+	}
+	return *node.Children
+}
+
+const (
+	opAnd    = "AND"
+	opOr     = "OR"
+	opAndNot = "ANDNOT"
+)
+
+// queryFields maps arXiv field prefixes to the query builder methods.
+var queryFields = map[string]func(*arxiv.SearchQuery, string) *arxiv.SearchQuery{
+	"ti":  (*arxiv.SearchQuery).Title,
+	"au":  (*arxiv.SearchQuery).Author,
+	"abs": (*arxiv.SearchQuery).Abstract,
+	"cat": (*arxiv.SearchQuery).Category,
+	"co":  (*arxiv.SearchQuery).Comment,
+	"jr":  (*arxiv.SearchQuery).Journal,
+	"all": (*arxiv.SearchQuery).All,
+}
+
+// queryNodeExample is a valid query tree, offered when a tree is rejected.
+const queryNodeExample = `{"op": "AND", "children": [{"field": "ti", "value": "transformer"}, {"field": "cat", "value": "cs.LG"}]}`
+
+// queryNodeSchema describes QueryNode. jsonschema.For cannot infer schemas for
+// recursive types, so the node schema is written out and referenced from
+// $defs.
+func queryNodeSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:        "object",
+		Description: `boolean search expression. A term has field and value, e.g. {"field":"ti","value":"transformer"}. An operator has op and children, e.g. {"op":"ANDNOT","children":[A,B]} matches A but not B`,
+		Properties: map[string]*jsonschema.Schema{
+			"op": {
+				Type:        "string",
+				Enum:        []any{opAnd, opOr, opAndNot},
+				Description: "boolean operator applied to children. ANDNOT keeps the first child and excludes the rest",
+			},
+			"children": {
+				Type:  "array",
+				Items: &jsonschema.Schema{Ref: "#/$defs/queryNode"},
+			},
+			"field": {
+				Type:        "string",
+				Enum:        []any{"ti", "au", "abs", "cat", "co", "jr", "all"},
+				Description: "field to search: title, author, abstract, category, comment, journal reference, or all",
+			},
+			"value": {
+				Type:        "string",
+				Description: "search term. Words in a term must all match unless phrase is set",
+			},
+			"phrase": {
+				Type:        "boolean",
+				Description: "match value as an exact phrase",
+			},
+		},
+		AdditionalProperties: &jsonschema.Schema{Not: &jsonschema.Schema{}},
+	}
+}
+
+// addQueryNode appends the compiled node to arxivQuery, joined to any existing
+// terms with AND. The node is grouped when grouped is set or there are existing
+// terms, so that it binds as a unit.
+func addQueryNode(arxivQuery *arxiv.SearchQuery, node QueryNode, grouped bool) error {
+	if arxivQuery.String() != "" {
+		arxivQuery.And()
+		grouped = true
+	}
+	return compileQueryNode(arxivQuery, node, grouped)
+}
+
+// compileQueryNode appends node to arxivQuery. Nested operators are wrapped in
+// a group so that they bind as written.
+func compileQueryNode(arxivQuery *arxiv.SearchQuery, node QueryNode, nested bool) error {
+	children := node.children()
+	if node.Op == "" {
+		if len(children) > 0 {
+			return fmt.Errorf("query node with children needs an op")
+		}
+		return compileQueryTerm(arxivQuery, node)
+	}
+
+	if node.Field != "" || node.Value != "" {
+		return fmt.Errorf("query node cannot have both op and field/value")
+	}
+	op := strings.ToUpper(node.Op)
+	switch op {
+	case opAnd, opOr:
+		if len(children) == 0 {
+			return fmt.Errorf("%s needs at least one child", op)
+		}
+	case opAndNot:
+		if len(children) < 2 {
+			return fmt.Errorf("ANDNOT needs at least two children")
+		}
+	default:
+		return fmt.Errorf("unknown query operator: %s", node.Op)
+	}
+	if len(children) == 1 {
+		return compileQueryNode(arxivQuery, children[0], nested)
+	}
+
+	compile := func(q *arxiv.SearchQuery) error {
+		for i, child := range children {
+			if i > 0 {
+				switch op {
+				case opAnd:
+					q.And()
+				case opOr:
+					q.Or()
+				case opAndNot:
+					q.AndNot()
+				}
+			}
+			if err := compileQueryNode(q, child, true); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if !nested {
+		return compile(arxivQuery)
+	}
+	var err error
+	arxivQuery.Group(func(g *arxiv.SearchQuery) {
+		err = compile(g)
+	})
+	return err
+}
+
+func compileQueryTerm(arxivQuery *arxiv.SearchQuery, node QueryNode) error {
+	addField, ok := queryFields[strings.ToLower(node.Field)]
+	if !ok {
+		return fmt.Errorf("unknown query field: %q", node.Field)
+	}
+	value := escapeQueryValue(node.Value)
+	if value == "" {
+		return fmt.Errorf("empty value for query field %s", node.Field)
+	}
+
+	if node.Phrase {
+		addField(arxivQuery, `"`+value+`"`)
+		return nil
+	}
+	words := strings.Fields(value)
+	if len(words) == 1 {
+		addField(arxivQuery, words[0])
+		return nil
+	}
+	arxivQuery.Group(func(g *arxiv.SearchQuery) {
+		for i, word := range words {
+			if i > 0 {
+				g.And()
+			}
+			addField(g, word)
+		}
+	})
+	return nil
+}
+
+// escapeQueryValue removes characters that have syntactic meaning in arXiv
+// queries and lowercases words that would be read as operators.
+func escapeQueryValue(value string) string {
+	value = strings.Map(func(r rune) rune {
+		switch r {
+		case '"', '(', ')', '[', ']', '{', '}', '\\', ':':
+			return ' '
+		}
+		return r
+	}, value)
+	words := strings.Fields(value)
+	for i, word := range words {
+		switch word {
+		case opAnd, opOr, opAndNot:
+			words[i] = strings.ToLower(word)
+		}
+	}
+	return strings.Join(words, " ")
+}
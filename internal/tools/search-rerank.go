@@ -0,0 +1,122 @@
+package tools
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivquery"
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// rerankFactor is how many times the requested number of results are fetched
+// from arXiv to re-rank.
+const rerankFactor = 5
+
+// BM25 parameters: k1 limits how much repeating a term adds to the score, and
+// b how much a long title and abstract are penalized.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// rankedEntry is an entry with its BM25 score and 1-based position in arXiv's
+// results.
+type rankedEntry struct {
+	entry     arxiv.EntryMetadata
+	score     float64
+	arxivRank int
+}
+
+// rerankTerms returns the words the title and abstract are scored against:
+// those searched for in the title, abstract or all fields of the
+// search_query, leaving out words the query excludes.
+func rerankTerms(query string) []string {
+	node, err := arxivquery.Parse(query)
+	if err != nil {
+		return nil
+	}
+	var terms []string
+	seen := make(map[string]bool)
+	var walk func(node arxivquery.Node)
+	walk = func(node arxivquery.Node) {
+		switch n := node.(type) {
+		case arxivquery.Binary:
+			walk(n.Left)
+			if n.Op != arxivquery.OpAndNot {
+				walk(n.Right)
+			}
+		case arxivquery.Term:
+			if n.Field != "ti" && n.Field != "abs" && n.Field != "all" {
+				return
+			}
+			for _, word := range arxivquery.Words(n.Value) {
+				if !seen[word] {
+					seen[word] = true
+					terms = append(terms, word)
+				}
+			}
+		}
+	}
+	walk(node)
+	return terms
+}
+
+// rerank scores entries against terms with BM25, taking the entries as the
+// corpus, and returns them best first. Title words count twice. Entries with
+// equal scores keep arXiv's order; start is the offset of the first entry in
+// arXiv's results.
+func rerank(entries []arxiv.EntryMetadata, terms []string, start int) []rankedEntry {
+	docs := make([][]string, len(entries))
+	totalLength := 0
+	for i, entry := range entries {
+		title := arxivquery.Words(entry.Title)
+		docs[i] = append(append(title, title...), arxivquery.Words(entry.Summary)...)
+		totalLength += len(docs[i])
+	}
+	averageLength := float64(totalLength) / float64(max(len(docs), 1))
+
+	frequencies := make([][]int, len(docs)) // per doc, per term
+	documentFrequency := make([]int, len(terms))
+	for i, doc := range docs {
+		frequencies[i] = make([]int, len(terms))
+		for j, term := range terms {
+			for _, word := range doc {
+				if termMatches(word, term) {
+					frequencies[i][j]++
+				}
+			}
+			if frequencies[i][j] > 0 {
+				documentFrequency[j]++
+			}
+		}
+	}
+
+	ranked := make([]rankedEntry, len(entries))
+	n := float64(len(docs))
+	for i, entry := range entries {
+		score := 0.0
+		norm := bm25K1 * (1 - bm25B + bm25B*float64(len(docs[i]))/averageLength)
+		for j := range terms {
+			tf := float64(frequencies[i][j])
+			if tf == 0 {
+				continue
+			}
+			df := float64(documentFrequency[j])
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			score += idf * tf * (bm25K1 + 1) / (tf + norm)
+		}
+		ranked[i] = rankedEntry{entry: entry, score: score, arxivRank: start + i + 1}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+	return ranked
+}
+
+// termMatches reports whether word matches term, which may end in * to match
+// any word with that prefix.
+func termMatches(word, term string) bool {
+	if prefix, ok := strings.CutSuffix(term, "*"); ok {
+		return strings.HasPrefix(word, prefix)
+	}
+	return word == term
+}
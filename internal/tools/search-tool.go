@@ -2,32 +2,112 @@ package tools
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivclient"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/citation"
 	"github.com/Epistemic-Technology/arxiv/arxiv"
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// Searcher runs arXiv API searches. Handlers share one Searcher so that
+// requests from all sessions are rate limited together.
+type Searcher interface {
+	Search(ctx context.Context, params arxiv.SearchParams) (arxiv.SearchResults, error)
+}
+
+// cachedSearcher is a Searcher that can report whether results came from a
+// cache.
+type cachedSearcher interface {
+	SearchCached(ctx context.Context, params arxiv.SearchParams) (arxiv.SearchResults, bool, error)
+}
+
+// runSearch searches with searcher and reports whether the results came from
+// a cache.
+func runSearch(ctx context.Context, searcher Searcher, params arxiv.SearchParams) (arxiv.SearchResults, bool, error) {
+	if cached, ok := searcher.(cachedSearcher); ok {
+		return cached.SearchCached(ctx, params)
+	}
+	results, err := searcher.Search(ctx, params)
+	return results, false, err
+}
+
+// localSearcher is a Searcher that can also answer searches from papers kept
+// locally, without arXiv.
+type localSearcher interface {
+	SearchLocal(ctx context.Context, params arxiv.SearchParams) (arxiv.SearchResults, error)
+}
+
+// Where searches are answered from.
+const (
+	sourceRemote = "remote" // arXiv
+	sourceLocal  = "local"  // papers kept locally
+	sourceAuto   = "auto"   // arXiv, or papers kept locally if arXiv cannot answer
+)
+
+// searchSource searches params with searcher from the given source and
+// reports whether the results came from a cache and where they came from.
+func searchSource(ctx context.Context, searcher Searcher, params arxiv.SearchParams, source string) (arxiv.SearchResults, bool, string, error) {
+	local, hasLocal := searcher.(localSearcher)
+	switch source {
+	case sourceLocal:
+		if !hasLocal {
+			return arxiv.SearchResults{}, false, "", invalidInput("source", sourceRemote, "no local store is configured, so searches can only be answered by arXiv")
+		}
+		results, err := local.SearchLocal(ctx, params)
+		return results, false, sourceLocal, err
+	case sourceRemote:
+		results, cacheHit, err := runSearch(ctx, searcher, params)
+		return results, cacheHit, sourceRemote, err
+	}
+
+	results, cacheHit, err := runSearch(ctx, searcher, params)
+	var rateLimited *arxivclient.RateLimitedError
+	var unavailable *arxivclient.UnavailableError
+	if hasLocal && (errors.As(err, &rateLimited) || errors.As(err, &unavailable)) {
+		results, err = local.SearchLocal(ctx, params)
+		return results, false, sourceLocal, err
+	}
+	return results, cacheHit, sourceRemote, err
+}
+
 type SearchQuery struct {
-	Title             string   `json:"title,omitempty"`
-	Author            string   `json:"author,omitempty"`
-	Abstract          string   `json:"abstract,omitempty"`
-	SubjectCategory   string   `json:"subject_category,omitempty" jsonschema:"subject category, using arXiv category taxonomy"`
-	SubmittedSince    string   `json:"submitted_since,omitempty" pattern:"\\d{4}-\\d{2}-\\d{2}" jsonschema:"date in YYYY-MM-DD"`
-	SubmittedBefore   string   `json:"submitted_before,omitempty" pattern:"\\d{4}-\\d{2}-\\d{2}" jsonschema:"date in YYYY-MM-DD"`
-	SubmittedRelative string   `json:"submitted_relative,omitempty" pattern:"[0-9]+ (days|weeks|months|years)" jsonschema:"relative date in days, weeks, months, or years from today"`
-	All               string   `json:"all,omitempty" jsonschema:"search within title, author, abstract, subject"`
-	IdList            []string `json:"id_list,omitempty" jsonschema:"array of arXiv IDs to search within. Can be passed alone to retrieve specific papers"`
-	MaxResults        int      `json:"max,omitempty"`
-	ReturnFields      []string `json:"return_fields,omitempty" jsonschema:"array of fields to return. Returns all if empty"`
+	Title             string     `json:"title,omitempty"`
+	Author            string     `json:"author,omitempty"`
+	Abstract          string     `json:"abstract,omitempty"`
+	SubjectCategory   []string   `json:"subject_category,omitempty" jsonschema:"subject categories, using arXiv category taxonomy. Matches papers in any of them. Archive tags such as cs, math.* or astro-ph select every category in the archive"`
+	SubmittedSince    string     `json:"submitted_since,omitempty" pattern:"\\d{4}-\\d{2}-\\d{2}" jsonschema:"date in YYYY-MM-DD"`
+	SubmittedBefore   string     `json:"submitted_before,omitempty" pattern:"\\d{4}-\\d{2}-\\d{2}" jsonschema:"date in YYYY-MM-DD"`
+	SubmittedRelative string     `json:"submitted_relative,omitempty" pattern:"[0-9]+ (days|weeks|months|years)" jsonschema:"relative date in days, weeks, months, or years from today"`
+	All               string     `json:"all,omitempty" jsonschema:"search within title, author, abstract, subject"`
+	Query             *QueryNode `json:"query,omitempty"`
+	IdList            []string   `json:"id_list,omitempty" jsonschema:"array of arXiv IDs to search within. Can be passed alone to retrieve specific papers"`
+	MaxResults        int        `json:"max,omitempty"`
+	Start             int        `json:"start,omitempty" jsonschema:"offset of the first result to return, for paging through results"`
+	SortBy            string     `json:"sort_by,omitempty" jsonschema:"field to sort by. Defaults to submittedDate when only a category and a date filter are given, relevance otherwise"`
+	SortOrder         string     `json:"sort_order,omitempty" jsonschema:"sort direction. Defaults to descending"`
+	Cursor            string     `json:"cursor,omitempty" jsonschema:"next_cursor from a previous search. Continues that search with the next page; other query fields are ignored"`
+	ReturnFields      []string   `json:"return_fields,omitempty" jsonschema:"array of fields to return. Returns all if empty"`
+	Format            string     `json:"format,omitempty" jsonschema:"how to render the text content: table for a markdown table, list for one line per paper, full for markdown with abstracts, or bibtex, ris or csl-json for citations to paste into a paper or reference manager. Defaults to list"`
+	Rerank            bool       `json:"rerank,omitempty" jsonschema:"re-rank by how well titles and abstracts match the words searched for, using BM25. Fetches 5 times max results from arXiv and returns the best max; each entry gets a score and its arxiv_rank. Pages from next_cursor continue through the re-ranked results before fetching more from arXiv"`
+	Source            string     `json:"source,omitempty" jsonschema:"where to search: remote for arXiv, local for papers kept locally from earlier searches and harvests, or auto for arXiv with local results when arXiv is unavailable or rate limiting. Local results may be incomplete or out of date. Defaults to auto"`
 }
 
 type SearchResults struct {
-	Entries []EntryView `json:"entries,omitempty"`
+	Entries       []EntryView `json:"entries,omitempty"`
+	TotalResults  int         `json:"totalResults"`
+	StartIndex    int         `json:"startIndex"`
+	ItemsPerPage  int         `json:"itemsPerPage"`
+	NextCursor    string      `json:"next_cursor,omitempty" jsonschema:"pass as cursor to fetch the next page. Absent on the last page"`
+	CacheHit      bool        `json:"cache_hit" jsonschema:"whether the results were served from the cache"`
+	Source        string      `json:"source,omitempty" jsonschema:"remote if the results came from arXiv, local if they came from papers kept locally"`
+	RerankedFrom  int         `json:"reranked_from,omitempty" jsonschema:"number of results fetched from arXiv, from startIndex, that the entries were re-ranked from, if they were"`
+	RerankedStart int         `json:"reranked_start,omitempty" jsonschema:"number of the re-ranked results shown on earlier pages, if re-ranked"`
+	Error         *ToolError  `json:"error,omitempty" jsonschema:"why the search failed, if it did"`
 }
 
 type EntryView struct {
@@ -45,13 +125,33 @@ type EntryView struct {
 	DOI              *string           `json:"doi,omitempty"`
 	AbstractUrl      *string           `json:"abstractUrl,omitempty"`
 	PDFUrl           *string           `json:"pdfUrl,omitempty"`
+	Score            *float64          `json:"score,omitempty" jsonschema:"how well the title and abstract match, if re-ranked: the BM25 score against the words searched for, or for related papers the similarity to the seed from 0 to 1"`
+	ArxivRank        *int              `json:"arxiv_rank,omitempty" jsonschema:"1-based position in arXiv's own ranking, if re-ranked"`
 }
 
 func SearchTool() *mcp.Tool {
-	inputSchema, err := jsonschema.For[SearchQuery](nil)
+	inputSchema, err := jsonschema.For[SearchQuery](&jsonschema.ForOptions{
+		TypeSchemas: map[any]*jsonschema.Schema{
+			QueryNode{}: {Ref: "#/$defs/queryNode"},
+		},
+	})
 	if err != nil {
 		panic(err)
 	}
+	inputSchema.Defs = map[string]*jsonschema.Schema{
+		"queryNode": queryNodeSchema(),
+	}
+	inputSchema.Properties["sort_by"].Enum = []any{
+		string(arxiv.SortByRelevance),
+		string(arxiv.SortBySubmittedDate),
+		string(arxiv.SortByLastUpdatedDate),
+	}
+	inputSchema.Properties["sort_order"].Enum = []any{
+		string(arxiv.SortOrderAscending),
+		string(arxiv.SortOrderDescending),
+	}
+	inputSchema.Properties["format"].Enum = searchFormats
+	inputSchema.Properties["source"].Enum = []any{sourceRemote, sourceLocal, sourceAuto}
 
 	searchTool := mcp.Tool{
 		Name:        "arxiv-search",
@@ -61,40 +161,180 @@ func SearchTool() *mcp.Tool {
 	return &searchTool
 }
 
-func SearchHandler(ctx context.Context, req *mcp.CallToolRequest, query SearchQuery) (*mcp.CallToolResult, SearchResults, error) {
-	arxivQuery, err := buildSearchQuery(query)
+func SearchHandler(searcher Searcher) mcp.ToolHandlerFor[SearchQuery, SearchResults] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, query SearchQuery) (*mcp.CallToolResult, SearchResults, error) {
+		result, searchResults, err := search(ctx, searcher, query)
+		if toolErr := asToolError(err); toolErr != nil {
+			return errorResult(toolErr), SearchResults{Error: toolErr}, nil
+		}
+		return result, searchResults, err
+	}
+}
+
+func search(ctx context.Context, searcher Searcher, query SearchQuery) (*mcp.CallToolResult, SearchResults, error) {
+	if err := validateFormat(query.Format); err != nil {
+		return nil, SearchResults{}, err
+	}
+	switch query.Source {
+	case "", sourceRemote, sourceLocal, sourceAuto:
+	default:
+		return nil, SearchResults{}, invalidInput("source", sourceAuto, "must be remote, local or auto, got %q", query.Source)
+	}
+	cursor, err := buildSearchParams(query)
+	if err != nil {
+		return nil, SearchResults{}, err
+	}
+	params := cursor.SearchParams
+	fetch := params
+	var terms []string
+	if cursor.Rerank {
+		terms = rerankTerms(params.Query)
+		if len(terms) == 0 {
+			return nil, SearchResults{}, invalidInput("rerank", "false", "needs words to rank by; search the title, abstract, all or query fields, or leave rerank unset")
+		}
+		fetch.MaxResults = min(params.MaxResults*rerankFactor, maxResults)
+	}
+	results, cacheHit, source, err := searchSource(ctx, searcher, fetch, query.Source)
 	if err != nil {
 		return nil, SearchResults{}, err
 	}
+
+	entries := results.Entries
+	var ranked []rankedEntry
+	if cursor.Rerank {
+		// Later pages of the window re-rank the same results and show the
+		// next of them.
+		ranked = rerank(results.Entries, terms, results.StartIndex)
+		ranked = ranked[min(cursor.Ranked, len(ranked)):min(cursor.Ranked+params.MaxResults, len(ranked))]
+		entries = make([]arxiv.EntryMetadata, len(ranked))
+		for i, r := range ranked {
+			entries[i] = r.entry
+		}
+	}
+
+	// Filter to only requested fields
+	filteredEntries := make([]EntryView, len(entries))
+	for i, entry := range entries {
+		filteredEntries[i] = filterEntry(entry, query.ReturnFields)
+	}
+	for i, r := range ranked {
+		filteredEntries[i].Score = &r.score
+		filteredEntries[i].ArxivRank = &r.arxivRank
+	}
+	searchResults := SearchResults{
+		Entries:      filteredEntries,
+		TotalResults: results.TotalResults,
+		StartIndex:   results.StartIndex,
+		ItemsPerPage: results.ItemsPerPage,
+		NextCursor:   nextCursor(cursor, results, cursor.Ranked+len(entries)),
+		CacheHit:     cacheHit,
+		Source:       source,
+	}
+	if cursor.Rerank {
+		searchResults.ItemsPerPage = len(entries)
+		searchResults.RerankedFrom = len(results.Entries)
+		searchResults.RerankedStart = cursor.Ranked
+	}
+
+	var text string
+	if isCitationFormat(query.Format) {
+		// Citations are built from the full metadata, whatever fields were
+		// asked for.
+		text, err = citation.Export(query.Format, entries)
+		if err != nil {
+			return nil, SearchResults{}, err
+		}
+	} else {
+		text = renderSearchResults(searchResults, query.Format)
+	}
+	result := &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: text}},
+	}
+	return result, searchResults, nil
+}
+
+// buildSearchParams returns the parameters of the search query asks for, or
+// of the next page of an earlier search if it has a cursor.
+func buildSearchParams(query SearchQuery) (searchCursor, error) {
+	if query.Cursor != "" {
+		return decodeCursor(query.Cursor)
+	}
+
+	arxivQuery, err := buildSearchQuery(query)
+	if err != nil {
+		return searchCursor{}, err
+	}
+	if arxivQuery.String() == "" && len(query.IdList) == 0 {
+		return searchCursor{}, &ToolError{
+			Kind:    errorInvalidInput,
+			Reason:  "no search terms given; set at least one of title, author, abstract, subject_category, all, query or id_list",
+			Example: `{"title": "graph neural networks"}`,
+		}
+	}
+	if query.Start < 0 || query.Start > maxStart {
+		return searchCursor{}, invalidInput("start", "0", "must be between 0 and %d, got %d", maxStart, query.Start)
+	}
 	max := query.MaxResults
 	if max == 0 {
 		max = 20
 	}
+	if max < 0 || max > maxResults {
+		return searchCursor{}, invalidInput("max", "20", "must be between 1 and %d, got %d", maxResults, max)
+	}
+	sortBy, sortOrder, err := searchSort(query)
+	if err != nil {
+		return searchCursor{}, err
+	}
 	params := arxiv.SearchParams{
 		Query:      arxivQuery.String(),
+		Start:      query.Start,
 		MaxResults: max,
-		SortBy:     arxiv.SortByRelevance,
-		SortOrder:  arxiv.SortOrderDescending,
+		SortBy:     sortBy,
+		SortOrder:  sortOrder,
 	}
 	if len(query.IdList) > 0 {
 		params.IdList = query.IdList
 	}
-	arxivClient := arxiv.NewClient()
-	results, err := arxivClient.Search(ctx, params)
-	if err != nil {
-		return nil, SearchResults{}, err
+	if err := params.Validate(); err != nil {
+		return searchCursor{}, err
 	}
+	return searchCursor{SearchParams: params, Rerank: query.Rerank}, nil
+}
 
-	// Filter to only requested fields
-	filteredEntries := make([]EntryView, len(results.Entries))
-	for i, entry := range results.Entries {
-		filteredEntries[i] = filterEntry(entry, query.ReturnFields)
+// searchSort resolves the sort field and direction for query. Browsing a
+// category over a date range defaults to newest first, since relevance
+// ranking is meaningless without search terms.
+func searchSort(query SearchQuery) (arxiv.SortBy, arxiv.SortOrder, error) {
+	sortBy := arxiv.SortBy(query.SortBy)
+	switch sortBy {
+	case arxiv.SortByRelevance, arxiv.SortBySubmittedDate, arxiv.SortByLastUpdatedDate:
+	case "":
+		sortBy = arxiv.SortByRelevance
+		if isCategoryBrowse(query) {
+			sortBy = arxiv.SortBySubmittedDate
+		}
+	default:
+		return "", "", invalidInput("sort_by", string(arxiv.SortBySubmittedDate), "must be relevance, submittedDate or lastUpdatedDate, got %q", query.SortBy)
 	}
-	searchResults := SearchResults{
-		Entries: filteredEntries,
+
+	sortOrder := arxiv.SortOrder(query.SortOrder)
+	switch sortOrder {
+	case arxiv.SortOrderAscending, arxiv.SortOrderDescending:
+	case "":
+		sortOrder = arxiv.SortOrderDescending
+	default:
+		return "", "", invalidInput("sort_order", string(arxiv.SortOrderDescending), "must be ascending or descending, got %q", query.SortOrder)
 	}
 
-	return &mcp.CallToolResult{}, searchResults, nil
+	return sortBy, sortOrder, nil
+}
+
+// isCategoryBrowse reports whether query only filters by category and
+// submission date.
+func isCategoryBrowse(query SearchQuery) bool {
+	hasDate := query.SubmittedSince != "" || query.SubmittedBefore != "" || query.SubmittedRelative != ""
+	hasTerms := query.Title != "" || query.Author != "" || query.Abstract != "" || query.All != "" || query.Query != nil || len(query.IdList) > 0
+	return len(query.SubjectCategory) > 0 && hasDate && !hasTerms
 }
 
 func buildSearchQuery(query SearchQuery) (arxiv.SearchQuery, error) {
@@ -111,42 +351,56 @@ func buildSearchQuery(query SearchQuery) (arxiv.SearchQuery, error) {
 		arxivQuery = arxivQuery.Abstract(query.Abstract)
 	}
 
-	if query.SubjectCategory != "" {
-		arxivQuery = arxivQuery.Category(query.SubjectCategory)
+	if len(query.SubjectCategory) > 0 {
+		var err error
+		arxivQuery, err = addCategories(arxivQuery, query.SubjectCategory)
+		if err != nil {
+			return *arxivQuery, err
+		}
 	}
 
 	if query.All != "" {
 		arxivQuery = arxivQuery.All(query.All)
 	}
 
+	if query.Query != nil {
+		hasDate := query.SubmittedSince != "" || query.SubmittedBefore != "" || query.SubmittedRelative != ""
+		if err := addQueryNode(arxivQuery, *query.Query, hasDate); err != nil {
+			return *arxivQuery, invalidInput("query", queryNodeExample, "%v", err)
+		}
+	}
+
 	// Handle relative date if provided and no explicit dates are set
 	if query.SubmittedRelative != "" && query.SubmittedSince == "" && query.SubmittedBefore == "" {
 		since, err := parseRelativeDate(query.SubmittedRelative)
 		if err != nil {
 			return *arxivQuery, err
 		}
-		before := time.Now()
-		arxivQuery = arxivQuery.SubmittedBetween(since, before)
+		arxivQuery = arxivQuery.SubmittedBetween(startOfDay(since), endOfToday())
 	} else if query.SubmittedSince != "" || query.SubmittedBefore != "" {
 		var since, before time.Time
 		if query.SubmittedBefore != "" {
 			var err error
 			before, err = time.Parse("2006-01-02", query.SubmittedBefore)
 			if err != nil {
-				return *arxivQuery, err
+				return *arxivQuery, invalidInput("submitted_before", "2024-12-31", "must be a date in YYYY-MM-DD, got %q", query.SubmittedBefore)
 			}
 		} else {
-			before = time.Now()
+			before = endOfToday()
 		}
 		if query.SubmittedSince != "" {
 			var err error
 			since, err = time.Parse("2006-01-02", query.SubmittedSince)
 			if err != nil {
-				return *arxivQuery, err
+				return *arxivQuery, invalidInput("submitted_since", "2024-01-01", "must be a date in YYYY-MM-DD, got %q", query.SubmittedSince)
 			}
 		} else {
 			since = time.Time{}
 		}
+		if since.After(before) {
+			return *arxivQuery, invalidInput("submitted_since", before.AddDate(0, 0, -7).Format("2006-01-02"),
+				"%s is after submitted_before %s", since.Format("2006-01-02"), before.Format("2006-01-02"))
+		}
 		arxivQuery = arxivQuery.SubmittedBetween(since, before)
 	}
 
@@ -213,15 +467,30 @@ func filterEntry(entry arxiv.EntryMetadata, fields []string) EntryView {
 	return view
 }
 
+// endOfToday returns the last minute of today. Date ranges up to now end
+// there, and relative ones start at the start of a day, so that a query asked
+// again later in the day is the same query and can be answered from the
+// cache.
+func endOfToday() time.Time {
+	return startOfDay(time.Now()).Add(24*time.Hour - time.Minute)
+}
+
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+const relativeDateExample = "2 weeks"
+
 func parseRelativeDate(relative string) (time.Time, error) {
 	parts := strings.Fields(relative)
 	if len(parts) != 2 {
-		return time.Time{}, fmt.Errorf("invalid relative date format: %s", relative)
+		return time.Time{}, invalidInput("submitted_relative", relativeDateExample, "must be a number and a unit, got %q", relative)
 	}
 
 	num, err := strconv.Atoi(parts[0])
-	if err != nil {
-		return time.Time{}, fmt.Errorf("invalid number in relative date: %s", parts[0])
+	if err != nil || num <= 0 {
+		return time.Time{}, invalidInput("submitted_relative", relativeDateExample, "must start with a positive whole number, got %q", parts[0])
 	}
 
 	unit := strings.ToLower(parts[1])
@@ -237,6 +506,6 @@ func parseRelativeDate(relative string) (time.Time, error) {
 	case "year", "years":
 		return now.AddDate(-num, 0, 0), nil
 	default:
-		return time.Time{}, fmt.Errorf("invalid time unit in relative date: %s", unit)
+		return time.Time{}, invalidInput("submitted_relative", relativeDateExample, "unit must be days, weeks, months or years, got %q", unit)
 	}
 }
@@ -0,0 +1,146 @@
+package tools
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolveCategories(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     []string
+		expected []string
+	}{
+		{
+			name:     "single category",
+			tags:     []string{"cs.AI"},
+			expected: []string{"cs.AI"},
+		},
+		{
+			name:     "several categories",
+			tags:     []string{"cs.LG", " stat.ML "},
+			expected: []string{"cs.LG", "stat.ML"},
+		},
+		{
+			name:     "case corrected",
+			tags:     []string{"CS.ai"},
+			expected: []string{"cs.AI"},
+		},
+		{
+			name:     "archive tag",
+			tags:     []string{"econ"},
+			expected: []string{"econ.EM", "econ.GN", "q-fin.EC", "econ.TH"},
+		},
+		{
+			name:     "archive wildcard",
+			tags:     []string{"stat.*"},
+			expected: []string{"stat.AP", "stat.CO", "stat.ME", "stat.ML", "stat.OT", "stat.TH", "math.ST"},
+		},
+		{
+			name:     "hyphenated archive",
+			tags:     []string{"astro-ph"},
+			expected: []string{"astro-ph.CO", "astro-ph.EP", "astro-ph.GA", "astro-ph.HE", "astro-ph.IM", "astro-ph.SR"},
+		},
+		{
+			name:     "category without subcategories",
+			tags:     []string{"hep-th"},
+			expected: []string{"hep-th"},
+		},
+		{
+			name:     "alias searched with canonical tag",
+			tags:     []string{"math.IT"},
+			expected: []string{"math.IT", "cs.IT"},
+		},
+		{
+			name:     "canonical tag searched with alias",
+			tags:     []string{"math-ph"},
+			expected: []string{"math-ph", "math.MP"},
+		},
+		{
+			name:     "duplicates removed",
+			tags:     []string{"stat.ML", "stat", "stat.ML"},
+			expected: []string{"stat.ML", "stat.AP", "stat.CO", "stat.ME", "stat.OT", "stat.TH", "math.ST"},
+		},
+		{
+			name:     "empty tags dropped",
+			tags:     []string{"", " "},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := resolveCategories(tt.tags)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestResolveCategoriesLargeArchive(t *testing.T) {
+	result, err := resolveCategories([]string{"math.*"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 32 math categories plus the aliases cs.IT, math-ph, cs.NA and stat.TH.
+	if len(result) != 36 {
+		t.Errorf("expected 36 categories, got %d: %v", len(result), result)
+	}
+}
+
+func TestResolveCategoriesUnknown(t *testing.T) {
+	tests := []struct {
+		name     string
+		tag      string
+		expected []string
+	}{
+		{name: "wrong archive", tag: "cs.ML", expected: []string{"cs.LG", "stat.ML"}},
+		{name: "plain words", tag: "machine-learning", expected: []string{"cs.LG", "stat.ML"}},
+		{name: "spelled out subject", tag: "math.NumberTheory", expected: []string{"math.NT"}},
+		{name: "partial archive", tag: "hep", expected: []string{"hep-ex", "hep-lat", "hep-ph", "hep-th"}},
+		{name: "near miss", tag: "astro.GA", expected: []string{"astro-ph.GA"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := resolveCategories([]string{"cs.AI", tt.tag})
+			var categoryErr *CategoryError
+			if !errors.As(err, &categoryErr) {
+				t.Fatalf("expected *CategoryError, got %v", err)
+			}
+			if len(categoryErr.Unknown) != 1 || categoryErr.Unknown[0].Tag != tt.tag {
+				t.Fatalf("expected %s to be reported as unknown, got %+v", tt.tag, categoryErr.Unknown)
+			}
+			suggestions := categoryErr.Unknown[0].Suggestions
+			if len(suggestions) < len(tt.expected) {
+				t.Fatalf("expected at least %d suggestions, got %+v", len(tt.expected), suggestions)
+			}
+			for i, tag := range tt.expected {
+				found := false
+				for _, suggestion := range suggestions[:len(tt.expected)] {
+					found = found || suggestion.Tag == tag
+				}
+				if !found {
+					t.Errorf("expected %s among the top %d suggestions (position %d), got %+v", tag, len(tt.expected), i, suggestions)
+				}
+			}
+			if !strings.Contains(err.Error(), tt.expected[0]) {
+				t.Errorf("expected error message to mention %s, got %q", tt.expected[0], err.Error())
+			}
+		})
+	}
+}
+
+func TestBuildSearchQueryUnknownCategory(t *testing.T) {
+	_, err := buildSearchQuery(SearchQuery{SubjectCategory: []string{"cs.ML"}})
+	var categoryErr *CategoryError
+	if !errors.As(err, &categoryErr) {
+		t.Fatalf("expected *CategoryError, got %v", err)
+	}
+}
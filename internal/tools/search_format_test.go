@@ -0,0 +1,171 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func testEntryView() EntryView {
+	entry := arxiv.EntryMetadata{
+		ID:              "http://arxiv.org/abs/1706.03762v7",
+		Title:           "Attention Is All\n  You Need",
+		Published:       time.Date(2017, 6, 12, 17, 57, 34, 0, time.UTC),
+		Updated:         time.Date(2023, 8, 2, 0, 41, 18, 0, time.UTC),
+		Summary:         "The dominant sequence transduction models\n are based on recurrent networks.",
+		Authors:         []arxiv.Author{{Name: "Ashish Vaswani"}, {Name: "Noam Shazeer"}, {Name: "Niki Parmar"}, {Name: "Jakob Uszkoreit"}},
+		Categories:      []arxiv.Category{{Term: "cs.CL"}, {Term: "cs.LG"}},
+		PrimaryCategory: arxiv.Category{Term: "cs.CL"},
+		Links:           []arxiv.Link{{Href: "http://arxiv.org/pdf/1706.03762v7", Title: "pdf"}},
+		Comment:         "15 pages | 5 figures",
+	}
+	return filterEntry(entry, nil)
+}
+
+func TestRenderSearchResults(t *testing.T) {
+	results := SearchResults{
+		Entries:      []EntryView{testEntryView()},
+		TotalResults: 3,
+		StartIndex:   1,
+		ItemsPerPage: 1,
+		NextCursor:   "abc",
+	}
+
+	tests := []struct {
+		name     string
+		results  SearchResults
+		format   string
+		contains []string
+		excludes []string
+	}{
+		{
+			name:    "list",
+			results: results,
+			format:  formatList,
+			contains: []string{
+				"Showing 2–2 of 3 papers.",
+				"- **Attention Is All You Need** (1706.03762v7) — Ashish Vaswani, Noam Shazeer, Niki Parmar et al., 2017-06-12, cs.CL\n",
+				`pass cursor "abc"`,
+			},
+			excludes: []string{"dominant sequence"},
+		},
+		{
+			name:    "default is list",
+			results: results,
+			format:  "",
+			contains: []string{
+				"- **Attention Is All You Need** (1706.03762v7)",
+			},
+		},
+		{
+			name:    "table",
+			results: results,
+			format:  formatTable,
+			contains: []string{
+				"| ID | Title | Authors | Published | Category |\n",
+				"| --- | --- | --- | --- | --- |\n",
+				"| 1706.03762v7 | Attention Is All You Need | Ashish Vaswani, Noam Shazeer, Niki Parmar et al. | 2017-06-12 | cs.CL |\n",
+			},
+		},
+		{
+			name: "table of returned fields only",
+			results: SearchResults{
+				Entries:      []EntryView{filterEntry(arxiv.EntryMetadata{ID: "http://arxiv.org/abs/2401.01234v1", Title: "A | B"}, []string{"id", "title"})},
+				TotalResults: 1,
+			},
+			format: formatTable,
+			contains: []string{
+				"| ID | Title |\n",
+				`| 2401.01234v1 | A \| B |`,
+			},
+			excludes: []string{"Authors", "more results"},
+		},
+		{
+			name:    "full",
+			results: results,
+			format:  formatFull,
+			contains: []string{
+				"## Attention Is All You Need\n",
+				"- **Authors:** Ashish Vaswani, Noam Shazeer, Niki Parmar, Jakob Uszkoreit\n",
+				"- **Updated:** 2023-08-02\n",
+				"- **Categories:** cs.CL, cs.LG\n",
+				"- **Comment:** 15 pages | 5 figures\n",
+				"- **PDF:** http://arxiv.org/pdf/1706.03762v7\n",
+				"The dominant sequence transduction models are based on recurrent networks.\n",
+			},
+		},
+		{
+			name:     "no results",
+			results:  SearchResults{},
+			format:   formatTable,
+			contains: []string{"No papers found."},
+			excludes: []string{"|"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := renderSearchResults(tt.results, tt.format)
+			for _, s := range tt.contains {
+				if !strings.Contains(text, s) {
+					t.Errorf("expected output to contain %q, got:\n%s", s, text)
+				}
+			}
+			for _, s := range tt.excludes {
+				if strings.Contains(text, s) {
+					t.Errorf("expected output not to contain %q, got:\n%s", s, text)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchHandlerContent(t *testing.T) {
+	client := newTestClient(t)
+	req := &mcp.CallToolRequest{}
+
+	t.Run("renders text content", func(t *testing.T) {
+		result, _, err := SearchHandler(client)(context.Background(), req, SearchQuery{Title: "attention", Format: formatFull})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result.Content) != 1 {
+			t.Fatalf("expected 1 content block, got %d", len(result.Content))
+		}
+		text, ok := result.Content[0].(*mcp.TextContent)
+		if !ok {
+			t.Fatalf("expected text content, got %T", result.Content[0])
+		}
+		if !strings.Contains(text.Text, "## Attention Is All You Need") {
+			t.Errorf("expected rendered paper, got:\n%s", text.Text)
+		}
+	})
+
+	t.Run("citation format", func(t *testing.T) {
+		result, searchResults, err := SearchHandler(client)(context.Background(), req, SearchQuery{Title: "attention", Format: formatBibTeX, ReturnFields: []string{"id"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.HasPrefix(text, "@misc{vaswani2017attention,") || !strings.Contains(text, "title = {Attention Is All You Need}") {
+			t.Errorf("expected BibTeX built from the full metadata, got:\n%s", text)
+		}
+		if searchResults.Entries[0].Title != nil {
+			t.Error("expected the structured content to keep to the return fields")
+		}
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		result, searchResults, err := SearchHandler(client)(context.Background(), req, SearchQuery{Title: "attention", Format: "csv"})
+		if err != nil {
+			t.Fatalf("expected a tool error, got protocol error %v", err)
+		}
+		if !result.IsError || searchResults.Error == nil || searchResults.Error.Field != "format" {
+			t.Errorf("expected error on field format, got %+v", searchResults.Error)
+		}
+	})
+}
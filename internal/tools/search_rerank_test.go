@@ -0,0 +1,149 @@
+package tools
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestRerankTerms(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"ti:graph AND abs:neural", []string{"graph", "neural"}},
+		{`ti:"graph neural" AND all:graph`, []string{"graph", "neural"}},
+		{"abs:attent* AND au:Vaswani", []string{"attent*"}},
+		{"abs:diffusion ANDNOT ti:survey", []string{"diffusion"}},
+		{"cat:cs.LG AND submittedDate:[202401010000 TO 202401312359]", nil},
+		{"ti:(unbalanced", nil},
+	}
+	for _, tt := range tests {
+		if got := rerankTerms(tt.query); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected %q, got %q", tt.query, tt.expected, got)
+		}
+	}
+}
+
+func TestRerank(t *testing.T) {
+	entries := []arxiv.EntryMetadata{
+		{ID: "1", Title: "A survey of optimization", Summary: "We review methods for training networks."},
+		{ID: "2", Title: "Graph neural networks", Summary: "Graph neural networks learn from graph structure."},
+		{ID: "3", Title: "Neural fields", Summary: "A neural representation of scenes."},
+		{ID: "4", Title: "Sorting networks", Summary: "Comparator networks for sorting."},
+	}
+	ranked := rerank(entries, []string{"graph", "neural"}, 10)
+
+	var ids []string
+	for _, r := range ranked {
+		ids = append(ids, r.entry.ID)
+	}
+	if expected := []string{"2", "3", "1", "4"}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("expected order %v, got %v", expected, ids)
+	}
+	if ranked[0].arxivRank != 12 || ranked[2].arxivRank != 11 {
+		t.Errorf("expected arXiv ranks counted from the start offset, got %d and %d", ranked[0].arxivRank, ranked[2].arxivRank)
+	}
+	if ranked[0].score <= ranked[1].score || ranked[1].score <= 0 || ranked[2].score != 0 {
+		t.Errorf("unexpected scores %v, %v, %v", ranked[0].score, ranked[1].score, ranked[2].score)
+	}
+}
+
+func TestSearchHandlerRerank(t *testing.T) {
+	searcher := &stubSearcher{results: arxiv.SearchResults{
+		TotalResults: 50,
+		ItemsPerPage: 4,
+		Entries: []arxiv.EntryMetadata{
+			{ID: "http://arxiv.org/abs/2401.00001v1", Title: "Sorting networks", Summary: "Comparator networks."},
+			{ID: "http://arxiv.org/abs/2401.00002v1", Title: "Diffusion models", Summary: "Diffusion for images."},
+			{ID: "http://arxiv.org/abs/2401.00003v1", Title: "Score matching", Summary: "Related to diffusion."},
+			{ID: "http://arxiv.org/abs/2401.00004v1", Title: "Transformers", Summary: "Attention."},
+		},
+	}}
+
+	t.Run("re-ranks an over-fetched page", func(t *testing.T) {
+		query := SearchQuery{Abstract: "diffusion", MaxResults: 2, Rerank: true, ReturnFields: []string{"title"}, Format: formatTable}
+		result, searchResults, err := SearchHandler(searcher)(context.Background(), &mcp.CallToolRequest{}, query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if searchResults.Error != nil {
+			t.Fatalf("unexpected error %+v", searchResults.Error)
+		}
+		if searcher.params.MaxResults != 2*rerankFactor {
+			t.Errorf("expected %d results fetched, got %d", 2*rerankFactor, searcher.params.MaxResults)
+		}
+		if len(searchResults.Entries) != 2 || searchResults.ItemsPerPage != 2 || searchResults.RerankedFrom != 4 {
+			t.Fatalf("expected the best 2 of 4, got %+v", searchResults)
+		}
+		first, second := searchResults.Entries[0], searchResults.Entries[1]
+		if *first.Title != "Diffusion models" || *first.ArxivRank != 2 || *second.ArxivRank != 3 {
+			t.Errorf("expected 2401.00002 then 2401.00003, got %+v and %+v", first, second)
+		}
+		if first.Score == nil || *first.Score <= *second.Score {
+			t.Errorf("expected scores in descending order, got %v and %v", first.Score, second.Score)
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if !strings.Contains(text, "| Score | arXiv rank |") || !strings.Contains(text, "Showing 1–2 of 4 papers re-ranked by BM25 from arXiv's results 1–4 of 50.") {
+			t.Errorf("expected scores and arXiv ranks in the text, got %q", text)
+		}
+
+		// The next page shows the rest of the re-ranked results, then
+		// continues after them.
+		_, searchResults, err = SearchHandler(searcher)(context.Background(), &mcp.CallToolRequest{}, SearchQuery{Cursor: searchResults.NextCursor})
+		if err != nil || searchResults.Error != nil {
+			t.Fatalf("unexpected error: %v, %+v", err, searchResults.Error)
+		}
+		if searcher.params.Start != 0 || searcher.params.MaxResults != 2*rerankFactor {
+			t.Errorf("expected the same window fetched again, got %+v", searcher.params)
+		}
+		if len(searchResults.Entries) != 2 || searchResults.RerankedStart != 2 || *searchResults.Entries[0].ArxivRank != 1 || *searchResults.Entries[1].ArxivRank != 4 {
+			t.Fatalf("expected re-ranked results 3 and 4, got %+v", searchResults)
+		}
+		params, err := decodeCursor(searchResults.NextCursor)
+		if err != nil || params.Start != 4 || params.MaxResults != 2 || !params.Rerank || params.Ranked != 0 {
+			t.Errorf("expected the next window from 4, got %+v and %v", params, err)
+		}
+	})
+
+	t.Run("without rerank", func(t *testing.T) {
+		_, searchResults, err := SearchHandler(searcher)(context.Background(), &mcp.CallToolRequest{}, SearchQuery{Abstract: "diffusion", MaxResults: 2})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if searcher.params.MaxResults != 2 || searchResults.RerankedFrom != 0 || searchResults.Entries[0].Score != nil {
+			t.Errorf("expected arXiv's results as they are, got %+v", searchResults)
+		}
+	})
+
+	t.Run("rerank is taken from the cursor", func(t *testing.T) {
+		_, first, err := SearchHandler(searcher)(context.Background(), &mcp.CallToolRequest{}, SearchQuery{Abstract: "diffusion", MaxResults: 2})
+		if err != nil || first.Error != nil {
+			t.Fatalf("unexpected error: %v, %+v", err, first.Error)
+		}
+		// A later page of a search that was not re-ranked is not re-ranked
+		// either, since the pages would then overlap.
+		_, next, err := SearchHandler(searcher)(context.Background(), &mcp.CallToolRequest{}, SearchQuery{Cursor: first.NextCursor, Rerank: true})
+		if err != nil || next.Error != nil {
+			t.Fatalf("unexpected error: %v, %+v", err, next.Error)
+		}
+		if searcher.params.MaxResults != 2 || next.RerankedFrom != 0 || next.Entries[0].Score != nil {
+			t.Errorf("expected the next page of arXiv's results as they are, got %+v from %+v", next, searcher.params)
+		}
+	})
+
+	t.Run("no words to rank by", func(t *testing.T) {
+		query := SearchQuery{IdList: []string{"2401.00001"}, Rerank: true}
+		_, searchResults, err := SearchHandler(searcher)(context.Background(), &mcp.CallToolRequest{}, query)
+		if err != nil {
+			t.Fatalf("expected a tool error, got protocol error %v", err)
+		}
+		if searchResults.Error == nil || searchResults.Error.Field != "rerank" {
+			t.Errorf("expected an invalid rerank error, got %+v", searchResults.Error)
+		}
+	})
+}
@@ -2,9 +2,15 @@ package tools
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivclient"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/fakearxiv"
 	"github.com/Epistemic-Technology/arxiv/arxiv"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -18,7 +24,19 @@ func TestSearchTool(t *testing.T) {
 		t.Errorf("expected tool description 'Searches for papers on arXiv', got '%s'", tool.Description)
 	}
 	if tool.InputSchema == nil {
-		t.Error("expected InputSchema to be non-nil")
+		t.Fatal("expected InputSchema to be non-nil")
+	}
+	if enum := tool.InputSchema.Properties["sort_by"].Enum; len(enum) != 3 {
+		t.Errorf("expected 3 sort_by values, got %v", enum)
+	}
+	if enum := tool.InputSchema.Properties["sort_order"].Enum; len(enum) != 2 {
+		t.Errorf("expected 2 sort_order values, got %v", enum)
+	}
+	if enum := tool.InputSchema.Properties["format"].Enum; len(enum) != 6 {
+		t.Errorf("expected 6 format values, got %v", enum)
+	}
+	if enum := tool.InputSchema.Properties["source"].Enum; len(enum) != 3 {
+		t.Errorf("expected 3 source values, got %v", enum)
 	}
 }
 
@@ -27,6 +45,7 @@ func TestBuildSearchQuery(t *testing.T) {
 		name        string
 		query       SearchQuery
 		expectError bool
+		errorField  string
 		validate    func(*testing.T, arxiv.SearchQuery)
 	}{
 		{
@@ -71,7 +90,7 @@ func TestBuildSearchQuery(t *testing.T) {
 		{
 			name: "subject category only",
 			query: SearchQuery{
-				SubjectCategory: "cs.AI",
+				SubjectCategory: []string{"cs.AI"},
 			},
 			expectError: false,
 			validate: func(t *testing.T, q arxiv.SearchQuery) {
@@ -81,6 +100,32 @@ func TestBuildSearchQuery(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "multiple subject categories",
+			query: SearchQuery{
+				SubjectCategory: []string{"cs.LG", "stat.ML"},
+			},
+			expectError: false,
+			validate: func(t *testing.T, q arxiv.SearchQuery) {
+				expected := "(cat:cs.LG OR cat:stat.ML)"
+				if q.String() != expected {
+					t.Errorf("expected query string '%s', got '%s'", expected, q.String())
+				}
+			},
+		},
+		{
+			name: "archive subject category",
+			query: SearchQuery{
+				SubjectCategory: []string{"econ"},
+			},
+			expectError: false,
+			validate: func(t *testing.T, q arxiv.SearchQuery) {
+				expected := "(cat:econ.EM OR cat:econ.GN OR cat:q-fin.EC OR cat:econ.TH)"
+				if q.String() != expected {
+					t.Errorf("expected query string '%s', got '%s'", expected, q.String())
+				}
+			},
+		},
 		{
 			name: "all fields search",
 			query: SearchQuery{
@@ -141,6 +186,12 @@ func TestBuildSearchQuery(t *testing.T) {
 				if !contains(queryStr, "submittedDate") {
 					t.Errorf("expected query to contain date filter, got '%s'", queryStr)
 				}
+				// Whole days, so that the query is the same all day.
+				since := startOfDay(time.Now().AddDate(0, 0, -7)).Format("200601021504")
+				until := time.Now().Format("20060102") + "2359"
+				if !contains(queryStr, "["+since+" TO "+until+"]") {
+					t.Errorf("expected the range to cover whole days, got '%s'", queryStr)
+				}
 			},
 		},
 		{
@@ -150,6 +201,7 @@ func TestBuildSearchQuery(t *testing.T) {
 				SubmittedSince: "invalid-date",
 			},
 			expectError: true,
+			errorField:  "submitted_since",
 		},
 		{
 			name: "invalid submitted before date",
@@ -158,6 +210,7 @@ func TestBuildSearchQuery(t *testing.T) {
 				SubmittedBefore: "invalid-date",
 			},
 			expectError: true,
+			errorField:  "submitted_before",
 		},
 		{
 			name: "invalid relative date format",
@@ -166,6 +219,94 @@ func TestBuildSearchQuery(t *testing.T) {
 				SubmittedRelative: "invalid",
 			},
 			expectError: true,
+			errorField:  "submitted_relative",
+		},
+		{
+			name: "boolean query",
+			query: SearchQuery{
+				Query: &QueryNode{
+					Op: "ANDNOT",
+					Children: &[]QueryNode{
+						{Op: "OR", Children: &[]QueryNode{
+							{Field: "ti", Value: "transformer"},
+							{Field: "ti", Value: "attention"},
+						}},
+						{Field: "cat", Value: "cs.CV"},
+					},
+				},
+			},
+			expectError: false,
+			validate: func(t *testing.T, q arxiv.SearchQuery) {
+				expected := "(ti:transformer OR ti:attention) ANDNOT cat:cs.CV"
+				if q.String() != expected {
+					t.Errorf("expected query string '%s', got '%s'", expected, q.String())
+				}
+			},
+		},
+		{
+			name: "boolean query with fields and dates",
+			query: SearchQuery{
+				Author:         "Smith",
+				SubmittedSince: "2023-01-01",
+				Query: &QueryNode{
+					Op: "OR",
+					Children: &[]QueryNode{
+						{Field: "abs", Value: "large language model", Phrase: true},
+						{Field: "abs", Value: "LLM"},
+					},
+				},
+			},
+			expectError: false,
+			validate: func(t *testing.T, q arxiv.SearchQuery) {
+				queryStr := q.String()
+				if !strings.HasPrefix(queryStr, `au:Smith AND (abs:"large language model" OR abs:LLM) AND submittedDate:`) {
+					t.Errorf("unexpected combined query, got '%s'", queryStr)
+				}
+			},
+		},
+		{
+			name: "invalid boolean query",
+			query: SearchQuery{
+				Query: &QueryNode{Op: "XOR", Children: &[]QueryNode{{Field: "ti", Value: "a"}}},
+			},
+			expectError: true,
+			errorField:  "query",
+		},
+		{
+			name: "invalid relative date number",
+			query: SearchQuery{
+				Title:             "test",
+				SubmittedRelative: "several days",
+			},
+			expectError: true,
+			errorField:  "submitted_relative",
+		},
+		{
+			name: "invalid relative date unit",
+			query: SearchQuery{
+				Title:             "test",
+				SubmittedRelative: "3 fortnights",
+			},
+			expectError: true,
+			errorField:  "submitted_relative",
+		},
+		{
+			name: "submitted since after submitted before",
+			query: SearchQuery{
+				Title:           "test",
+				SubmittedSince:  "2024-02-01",
+				SubmittedBefore: "2024-01-01",
+			},
+			expectError: true,
+			errorField:  "submitted_since",
+		},
+		{
+			name: "unknown category",
+			query: SearchQuery{
+				SubjectCategory: []string{"cs.XX"},
+			},
+			expectError: true,
+			errorField:  "subject_category",
 		},
 		{
 			name:        "empty query",
@@ -184,7 +325,11 @@ func TestBuildSearchQuery(t *testing.T) {
 			result, err := buildSearchQuery(tt.query)
 			if tt.expectError {
 				if err == nil {
-					t.Error("expected error but got none")
+					t.Fatal("expected error but got none")
+				}
+				toolErr := asToolError(err)
+				if toolErr.Kind != errorInvalidInput || toolErr.Field != tt.errorField {
+					t.Errorf("expected invalid input error on %s, got %+v", tt.errorField, toolErr)
 				}
 			} else {
 				if err != nil {
@@ -198,6 +343,190 @@ func TestBuildSearchQuery(t *testing.T) {
 	}
 }
 
+func TestCompileQueryNode(t *testing.T) {
+	tests := []struct {
+		name        string
+		node        QueryNode
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "single term",
+			node:     QueryNode{Field: "au", Value: "Hinton"},
+			expected: "au:Hinton",
+		},
+		{
+			name:     "phrase",
+			node:     QueryNode{Field: "ti", Value: "graph neural network", Phrase: true},
+			expected: `ti:"graph neural network"`,
+		},
+		{
+			name:     "multi-word term requires every word",
+			node:     QueryNode{Field: "au", Value: "del maestro"},
+			expected: "(au:del AND au:maestro)",
+		},
+		{
+			name: "multiple authors",
+			node: QueryNode{Op: "AND", Children: &[]QueryNode{
+				{Field: "au", Value: "Bengio"},
+				{Field: "au", Value: "LeCun"},
+			}},
+			expected: "au:Bengio AND au:LeCun",
+		},
+		{
+			name: "nested groups",
+			node: QueryNode{Op: "AND", Children: &[]QueryNode{
+				{Op: "OR", Children: &[]QueryNode{
+					{Field: "cat", Value: "cs.LG"},
+					{Field: "cat", Value: "stat.ML"},
+				}},
+				{Op: "ANDNOT", Children: &[]QueryNode{
+					{Field: "abs", Value: "diffusion"},
+					{Field: "ti", Value: "survey"},
+				}},
+			}},
+			expected: "(cat:cs.LG OR cat:stat.ML) AND (abs:diffusion ANDNOT ti:survey)",
+		},
+		{
+			name:     "operator with one child collapses",
+			node:     QueryNode{Op: "OR", Children: &[]QueryNode{{Field: "ti", Value: "quantum"}}},
+			expected: "ti:quantum",
+		},
+		{
+			name:     "lowercase operator",
+			node:     QueryNode{Op: "or", Children: &[]QueryNode{{Field: "ti", Value: "a"}, {Field: "ti", Value: "b"}}},
+			expected: "ti:a OR ti:b",
+		},
+		{
+			name:     "escapes syntax characters",
+			node:     QueryNode{Field: "ti", Value: `"Attention (is) all: you need"`, Phrase: true},
+			expected: `ti:"Attention is all you need"`,
+		},
+		{
+			name:     "lowercases operator words in values",
+			node:     QueryNode{Field: "ti", Value: "rock AND roll", Phrase: true},
+			expected: `ti:"rock and roll"`,
+		},
+		{
+			name:        "unknown field",
+			node:        QueryNode{Field: "xx", Value: "a"},
+			expectError: true,
+		},
+		{
+			name:        "empty value",
+			node:        QueryNode{Field: "ti", Value: "()"},
+			expectError: true,
+		},
+		{
+			name:        "unknown operator",
+			node:        QueryNode{Op: "NOR", Children: &[]QueryNode{{Field: "ti", Value: "a"}}},
+			expectError: true,
+		},
+		{
+			name:        "operator without children",
+			node:        QueryNode{Op: "AND"},
+			expectError: true,
+		},
+		{
+			name:        "andnot with one child",
+			node:        QueryNode{Op: "ANDNOT", Children: &[]QueryNode{{Field: "ti", Value: "a"}}},
+			expectError: true,
+		},
+		{
+			name:        "operator with value",
+			node:        QueryNode{Op: "AND", Field: "ti", Value: "a", Children: &[]QueryNode{{Field: "ti", Value: "b"}}},
+			expectError: true,
+		},
+		{
+			name:        "children without operator",
+			node:        QueryNode{Children: &[]QueryNode{{Field: "ti", Value: "b"}}},
+			expectError: true,
+		},
+		{
+			name: "error in nested child",
+			node: QueryNode{Op: "AND", Children: &[]QueryNode{
+				{Field: "ti", Value: "a"},
+				{Op: "OR", Children: &[]QueryNode{{Field: "ti", Value: "b"}, {Field: "bad", Value: "c"}}},
+			}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := arxiv.NewSearchQuery()
+			err := addQueryNode(q, tt.node, false)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error but got query '%s'", q.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if q.String() != tt.expected {
+				t.Errorf("expected query string '%s', got '%s'", tt.expected, q.String())
+			}
+		})
+	}
+}
+
+func TestAddQueryNodeGrouping(t *testing.T) {
+	node := QueryNode{Op: "OR", Children: &[]QueryNode{
+		{Field: "ti", Value: "a"},
+		{Field: "ti", Value: "b"},
+	}}
+
+	q := arxiv.NewSearchQuery()
+	if err := addQueryNode(q, node, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q.String() != "(ti:a OR ti:b)" {
+		t.Errorf("expected grouped query, got '%s'", q.String())
+	}
+
+	q = arxiv.NewSearchQuery().Title("c")
+	if err := addQueryNode(q, node, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q.String() != "ti:c AND (ti:a OR ti:b)" {
+		t.Errorf("expected grouped query after existing terms, got '%s'", q.String())
+	}
+}
+
+func TestSearchToolQuerySchema(t *testing.T) {
+	resolved, err := SearchTool().InputSchema.Resolve(nil)
+	if err != nil {
+		t.Fatalf("failed to resolve input schema: %v", err)
+	}
+
+	valid := map[string]any{
+		"query": map[string]any{
+			"op": "OR",
+			"children": []any{
+				map[string]any{"field": "ti", "value": "transformer"},
+				map[string]any{"op": "AND", "children": []any{
+					map[string]any{"field": "au", "value": "Vaswani"},
+				}},
+			},
+		},
+	}
+	if err := resolved.Validate(valid); err != nil {
+		t.Errorf("expected nested query to validate, got %v", err)
+	}
+
+	invalid := map[string]any{
+		"query": map[string]any{
+			"op":       "OR",
+			"children": []any{map[string]any{"field": "title", "value": "transformer"}},
+		},
+	}
+	if err := resolved.Validate(invalid); err == nil {
+		t.Error("expected unknown nested field to fail validation")
+	}
+}
+
 func TestParseRelativeDate(t *testing.T) {
 	now := time.Now()
 	tests := []struct {
@@ -319,6 +648,16 @@ func TestParseRelativeDate(t *testing.T) {
 			relative:    "",
 			expectError: true,
 		},
+		{
+			name:        "zero",
+			relative:    "0 days",
+			expectError: true,
+		},
+		{
+			name:        "negative",
+			relative:    "-3 days",
+			expectError: true,
+		},
 		{
 			name:        "too many parts",
 			relative:    "7 days ago",
@@ -341,8 +680,12 @@ func TestParseRelativeDate(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			result, err := parseRelativeDate(tt.relative)
 			if tt.expectError {
-				if err == nil {
-					t.Error("expected error but got none")
+				var toolErr *ToolError
+				if !errors.As(err, &toolErr) {
+					t.Fatalf("expected ToolError, got %v", err)
+				}
+				if toolErr.Field != "submitted_relative" || toolErr.Example == "" {
+					t.Errorf("expected error on submitted_relative with an example, got %+v", toolErr)
 				}
 			} else {
 				if err != nil {
@@ -356,77 +699,430 @@ func TestParseRelativeDate(t *testing.T) {
 	}
 }
 
+func TestBuildSearchParams(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		params, err := buildSearchParams(SearchQuery{Title: "quantum"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if params.Query != "ti:quantum" {
+			t.Errorf("expected query 'ti:quantum', got '%s'", params.Query)
+		}
+		if params.MaxResults != 20 {
+			t.Errorf("expected default max results 20, got %d", params.MaxResults)
+		}
+		if params.Start != 0 {
+			t.Errorf("expected start 0, got %d", params.Start)
+		}
+	})
+
+	t.Run("start offset", func(t *testing.T) {
+		params, err := buildSearchParams(SearchQuery{Title: "quantum", Start: 40})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if params.Start != 40 {
+			t.Errorf("expected start 40, got %d", params.Start)
+		}
+	})
+
+	t.Run("negative start", func(t *testing.T) {
+		if _, err := buildSearchParams(SearchQuery{Title: "quantum", Start: -1}); err == nil {
+			t.Error("expected error for negative start")
+		}
+	})
+
+	t.Run("start beyond api limit", func(t *testing.T) {
+		if _, err := buildSearchParams(SearchQuery{Title: "quantum", Start: 30001}); err == nil {
+			t.Error("expected error for start beyond 30000")
+		}
+	})
+
+	t.Run("cursor overrides query fields", func(t *testing.T) {
+		cursor := encodeCursor(searchCursor{SearchParams: arxiv.SearchParams{Query: "au:Smith", Start: 20, MaxResults: 20}})
+		params, err := buildSearchParams(SearchQuery{Title: "ignored", Cursor: cursor})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if params.Query != "au:Smith" || params.Start != 20 {
+			t.Errorf("expected cursor parameters, got %+v", params)
+		}
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		if _, err := buildSearchParams(SearchQuery{Cursor: "%%%"}); err == nil {
+			t.Error("expected error for invalid cursor")
+		}
+	})
+
+	errorTests := []struct {
+		name  string
+		query SearchQuery
+		field string
+	}{
+		{name: "negative start", query: SearchQuery{Title: "quantum", Start: -1}, field: "start"},
+		{name: "max beyond api limit", query: SearchQuery{Title: "quantum", MaxResults: 2001}, field: "max"},
+		{name: "invalid sort_by", query: SearchQuery{Title: "quantum", SortBy: "date"}, field: "sort_by"},
+		{name: "invalid cursor", query: SearchQuery{Cursor: "%%%"}, field: "cursor"},
+		{name: "no search terms", query: SearchQuery{}, field: ""},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name+" error", func(t *testing.T) {
+			_, err := buildSearchParams(tt.query)
+			var toolErr *ToolError
+			if !errors.As(err, &toolErr) {
+				t.Fatalf("expected ToolError, got %v", err)
+			}
+			if toolErr.Kind != errorInvalidInput || toolErr.Field != tt.field {
+				t.Errorf("expected invalid input error on %q, got %+v", tt.field, toolErr)
+			}
+		})
+	}
+}
+
+func TestSearchSort(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         SearchQuery
+		expectError   bool
+		expectedBy    arxiv.SortBy
+		expectedOrder arxiv.SortOrder
+	}{
+		{
+			name:          "default relevance",
+			query:         SearchQuery{Title: "quantum"},
+			expectedBy:    arxiv.SortByRelevance,
+			expectedOrder: arxiv.SortOrderDescending,
+		},
+		{
+			name:          "category and date defaults to newest first",
+			query:         SearchQuery{SubjectCategory: []string{"cs.CL"}, SubmittedRelative: "7 days"},
+			expectedBy:    arxiv.SortBySubmittedDate,
+			expectedOrder: arxiv.SortOrderDescending,
+		},
+		{
+			name:          "category and date with search terms keeps relevance",
+			query:         SearchQuery{SubjectCategory: []string{"cs.CL"}, SubmittedSince: "2024-01-01", Title: "parsing"},
+			expectedBy:    arxiv.SortByRelevance,
+			expectedOrder: arxiv.SortOrderDescending,
+		},
+		{
+			name:          "category without date keeps relevance",
+			query:         SearchQuery{SubjectCategory: []string{"cs.CL"}},
+			expectedBy:    arxiv.SortByRelevance,
+			expectedOrder: arxiv.SortOrderDescending,
+		},
+		{
+			name:          "explicit sort",
+			query:         SearchQuery{Title: "quantum", SortBy: "lastUpdatedDate", SortOrder: "ascending"},
+			expectedBy:    arxiv.SortByLastUpdatedDate,
+			expectedOrder: arxiv.SortOrderAscending,
+		},
+		{
+			name:          "explicit order with default field",
+			query:         SearchQuery{SubjectCategory: []string{"cs.CL"}, SubmittedRelative: "7 days", SortOrder: "ascending"},
+			expectedBy:    arxiv.SortBySubmittedDate,
+			expectedOrder: arxiv.SortOrderAscending,
+		},
+		{
+			name:        "invalid sort field",
+			query:       SearchQuery{SortBy: "citations"},
+			expectError: true,
+		},
+		{
+			name:        "invalid sort order",
+			query:       SearchQuery{SortOrder: "up"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sortBy, sortOrder, err := searchSort(tt.query)
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sortBy != tt.expectedBy || sortOrder != tt.expectedOrder {
+				t.Errorf("expected %s %s, got %s %s", tt.expectedBy, tt.expectedOrder, sortBy, sortOrder)
+			}
+		})
+	}
+}
+
+func TestNextCursor(t *testing.T) {
+	params := searchCursor{SearchParams: arxiv.SearchParams{Query: "ti:quantum", MaxResults: 10}}
+
+	t.Run("more results", func(t *testing.T) {
+		results := arxiv.SearchResults{TotalResults: 25, StartIndex: 10, ItemsPerPage: 10}
+		cursor := nextCursor(params, results, 0)
+		if cursor == "" {
+			t.Fatal("expected a cursor when more results exist")
+		}
+		next, err := decodeCursor(cursor)
+		if err != nil {
+			t.Fatalf("unexpected error decoding cursor: %v", err)
+		}
+		if next.Start != 20 || next.Query != "ti:quantum" || next.MaxResults != 10 {
+			t.Errorf("unexpected next page parameters %+v", next)
+		}
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		results := arxiv.SearchResults{TotalResults: 25, StartIndex: 20, ItemsPerPage: 10}
+		if cursor := nextCursor(params, results, 0); cursor != "" {
+			t.Errorf("expected no cursor on the last page, got '%s'", cursor)
+		}
+	})
+
+	t.Run("beyond api limit", func(t *testing.T) {
+		results := arxiv.SearchResults{TotalResults: 100000, StartIndex: 29990, ItemsPerPage: 20}
+		if cursor := nextCursor(params, results, 0); cursor != "" {
+			t.Errorf("expected no cursor past the start limit, got '%s'", cursor)
+		}
+	})
+
+	t.Run("re-ranked window", func(t *testing.T) {
+		reranked := params
+		reranked.Rerank = true
+		results := arxiv.SearchResults{TotalResults: 100, StartIndex: 0, ItemsPerPage: 50, Entries: make([]arxiv.EntryMetadata, 50)}
+		next, err := decodeCursor(nextCursor(reranked, results, 10))
+		if err != nil || next.Start != 0 || next.Ranked != 10 || !next.Rerank {
+			t.Errorf("expected the same window from rank 10, got %+v and %v", next, err)
+		}
+		next, err = decodeCursor(nextCursor(reranked, results, 50))
+		if err != nil || next.Start != 50 || next.Ranked != 0 || !next.Rerank {
+			t.Errorf("expected the next window once all were shown, got %+v and %v", next, err)
+		}
+	})
+}
+
+// newTestClient returns a client for a fake arXiv API serving the fixtures
+// in internal/fakearxiv.
+func newTestClient(t *testing.T) *arxivclient.Client {
+	t.Helper()
+	fake, err := fakearxiv.Load(fakearxiv.Fixtures())
+	if err != nil {
+		t.Fatalf("loading fixtures: %v", err)
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return arxivclient.New(arxivclient.Config{BaseURL: server.URL, SiteURL: server.URL, FeedURL: server.URL + "/atom"})
+}
+
 func TestSearchHandler(t *testing.T) {
+	client := newTestClient(t)
+
 	t.Run("default max results", func(t *testing.T) {
 		query := SearchQuery{
-			Title: "test",
+			Title: "learning",
 		}
 		req := &mcp.CallToolRequest{}
 
-		// Note: This will make an actual API call in this test
-		// In production, you might want to mock the arxiv client
 		ctx := context.Background()
-		result, searchResults, err := SearchHandler(ctx, req, query)
-
+		result, searchResults, err := SearchHandler(client)(ctx, req, query)
 		if err != nil {
-			// API might be unavailable, skip test
-			t.Skipf("skipping test due to API error: %v", err)
+			t.Fatalf("unexpected error: %v", err)
 		}
 
 		if result == nil {
 			t.Error("expected non-nil CallToolResult")
 		}
-
-		// Check that results were returned (even if empty)
-		_ = searchResults // results structure is valid
+		if len(searchResults.Entries) != 1 || *searchResults.Entries[0].Title != "Deep Residual Learning for Image Recognition" {
+			t.Errorf("expected the residual learning paper, got %+v", searchResults.Entries)
+		}
 	})
 
 	t.Run("with id list", func(t *testing.T) {
 		query := SearchQuery{
-			IdList:     []string{"2301.00000"},
+			IdList:     []string{"1706.03762"},
 			MaxResults: 1,
 		}
 		req := &mcp.CallToolRequest{}
 
 		ctx := context.Background()
-		result, _, err := SearchHandler(ctx, req, query)
-
+		result, searchResults, err := SearchHandler(client)(ctx, req, query)
 		if err != nil {
-			// API might be unavailable, skip test
-			t.Skipf("skipping test due to API error: %v", err)
+			t.Fatalf("unexpected error: %v", err)
 		}
 
 		if result == nil {
 			t.Error("expected non-nil CallToolResult")
 		}
+		if len(searchResults.Entries) != 1 || *searchResults.Entries[0].ID != "http://arxiv.org/abs/1706.03762v7" {
+			t.Errorf("expected the latest version of 1706.03762, got %+v", searchResults.Entries)
+		}
 	})
 
 	t.Run("custom max results", func(t *testing.T) {
 		query := SearchQuery{
-			Title:      "quantum",
-			MaxResults: 5,
+			Abstract:   "quantum",
+			MaxResults: 1,
 		}
 		req := &mcp.CallToolRequest{}
 
 		ctx := context.Background()
-		result, searchResults, err := SearchHandler(ctx, req, query)
-
+		result, searchResults, err := SearchHandler(client)(ctx, req, query)
 		if err != nil {
-			// API might be unavailable, skip test
-			t.Skipf("skipping test due to API error: %v", err)
+			t.Fatalf("unexpected error: %v", err)
 		}
 
 		if result == nil {
 			t.Error("expected non-nil CallToolResult")
 		}
+		if len(searchResults.Entries) != 1 {
+			t.Errorf("expected 1 result, got %d", len(searchResults.Entries))
+		}
+		if searchResults.TotalResults < 2 {
+			t.Errorf("expected more quantum papers than fit on the page, got %d", searchResults.TotalResults)
+		}
+	})
+
+	t.Run("follows cursor", func(t *testing.T) {
+		query := SearchQuery{
+			SubjectCategory: []string{"cs"},
+			MaxResults:      2,
+		}
+		req := &mcp.CallToolRequest{}
+		ctx := context.Background()
+
+		seen := make(map[string]bool)
+		for page := 0; ; page++ {
+			if page > 10 {
+				t.Fatal("expected paging to end")
+			}
+			_, searchResults, err := SearchHandler(client)(ctx, req, query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, entry := range searchResults.Entries {
+				if seen[*entry.ID] {
+					t.Errorf("entry %s returned twice", *entry.ID)
+				}
+				seen[*entry.ID] = true
+			}
+			if searchResults.NextCursor == "" {
+				if len(seen) != searchResults.TotalResults {
+					t.Errorf("expected %d entries across pages, got %d", searchResults.TotalResults, len(seen))
+				}
+				break
+			}
+			query = SearchQuery{Cursor: searchResults.NextCursor}
+		}
+	})
 
-		// Verify max results is respected (if results are available)
-		if len(searchResults.Entries) > 5 {
-			t.Errorf("expected at most 5 results, got %d", len(searchResults.Entries))
+	t.Run("bad query", func(t *testing.T) {
+		query := SearchQuery{
+			IdList: []string{"not-an-id"},
+		}
+		result, searchResults, err := SearchHandler(client)(context.Background(), &mcp.CallToolRequest{}, query)
+		if err != nil {
+			t.Fatalf("expected a tool error, got protocol error %v", err)
+		}
+		if !result.IsError {
+			t.Error("expected IsError for a query arXiv rejects")
+		}
+		if searchResults.Error == nil || searchResults.Error.Kind != errorBadQuery {
+			t.Errorf("expected %s error, got %+v", errorBadQuery, searchResults.Error)
 		}
 	})
 }
 
+// stubSearcher returns fixed results without calling the arXiv API.
+type stubSearcher struct {
+	results  arxiv.SearchResults
+	cacheHit bool
+	params   arxiv.SearchParams
+}
+
+func (s *stubSearcher) Search(ctx context.Context, params arxiv.SearchParams) (arxiv.SearchResults, error) {
+	s.params = params
+	return s.results, nil
+}
+
+func (s *stubSearcher) SearchCached(ctx context.Context, params arxiv.SearchParams) (arxiv.SearchResults, bool, error) {
+	s.params = params
+	return s.results, s.cacheHit, nil
+}
+
+func TestSearchHandlerCacheHit(t *testing.T) {
+	searcher := &stubSearcher{
+		results: arxiv.SearchResults{
+			TotalResults: 1,
+			ItemsPerPage: 1,
+			Entries:      []arxiv.EntryMetadata{{ID: "http://arxiv.org/abs/2401.01234v1", Title: "Cached"}},
+		},
+		cacheHit: true,
+	}
+	_, searchResults, err := SearchHandler(searcher)(context.Background(), &mcp.CallToolRequest{}, SearchQuery{Title: "cached"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !searchResults.CacheHit {
+		t.Error("expected cache_hit to be reported")
+	}
+	if searchResults.Source != sourceRemote {
+		t.Errorf("expected source %s, got %q", sourceRemote, searchResults.Source)
+	}
+	if searcher.params.Query != "ti:cached" {
+		t.Errorf("expected query 'ti:cached', got '%s'", searcher.params.Query)
+	}
+
+	searcher.cacheHit = false
+	_, searchResults, _ = SearchHandler(searcher)(context.Background(), &mcp.CallToolRequest{}, SearchQuery{Title: "cached"})
+	if searchResults.CacheHit {
+		t.Error("expected cache_hit to be false")
+	}
+}
+
+func TestSearchHandlerTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+	client := arxivclient.New(arxivclient.Config{BaseURL: server.URL, Timeout: 50 * time.Millisecond})
+
+	t.Run("arXiv times out", func(t *testing.T) {
+		result, searchResults, err := SearchHandler(client)(context.Background(), &mcp.CallToolRequest{}, SearchQuery{Title: "attention"})
+		if err != nil {
+			t.Fatalf("expected a tool error, got protocol error %v", err)
+		}
+		if !result.IsError || searchResults.Error == nil || searchResults.Error.Kind != errorUnavailable {
+			t.Errorf("expected %s error, got %+v", errorUnavailable, searchResults.Error)
+		}
+	})
+
+	t.Run("call cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, _, err := SearchHandler(client)(ctx, &mcp.CallToolRequest{}, SearchQuery{Title: "attention"})
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected the call's deadline as a protocol error, got %v", err)
+		}
+	})
+}
+
+func TestSearchHandlerRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	t.Cleanup(server.Close)
+	client := arxivclient.New(arxivclient.Config{BaseURL: server.URL, MaxRetries: 3})
+
+	result, searchResults, err := SearchHandler(client)(context.Background(), &mcp.CallToolRequest{}, SearchQuery{Title: "attention"})
+	if err != nil {
+		t.Fatalf("expected a tool error, got protocol error %v", err)
+	}
+	if !result.IsError || searchResults.Error == nil || searchResults.Error.Kind != errorRejected {
+		t.Errorf("expected %s error, not one to retry, got %+v", errorRejected, searchResults.Error)
+	}
+}
+
 // Helper functions
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && (s[:len(substr)] == substr || contains(s[1:], substr)))
@@ -440,3 +1136,124 @@ func roughlyEqual(t1, t2 time.Time) bool {
 	// Allow 1 second difference for test execution time
 	return diff < time.Second
 }
+
+// localStubSearcher fails arXiv searches with err and answers local searches
+// with local.
+type localStubSearcher struct {
+	errorSearcher
+	local  arxiv.SearchResults
+	params *arxiv.SearchParams
+}
+
+func (s localStubSearcher) SearchLocal(ctx context.Context, params arxiv.SearchParams) (arxiv.SearchResults, error) {
+	*s.params = params
+	return s.local, nil
+}
+
+func TestSearchHandlerSource(t *testing.T) {
+	local := arxiv.SearchResults{
+		TotalResults: 1,
+		ItemsPerPage: 1,
+		Entries:      []arxiv.EntryMetadata{{ID: "http://arxiv.org/abs/2401.01234v1", Title: "Kept locally"}},
+	}
+	unavailable := &arxivclient.UnavailableError{Status: "503 Service Unavailable"}
+
+	tests := []struct {
+		name           string
+		searcher       func(params *arxiv.SearchParams) Searcher
+		source         string
+		expectedSource string
+		expectedError  string
+	}{
+		{
+			name: "auto falls back when arXiv is unavailable",
+			searcher: func(params *arxiv.SearchParams) Searcher {
+				return localStubSearcher{errorSearcher{unavailable}, local, params}
+			},
+			expectedSource: sourceLocal,
+		},
+		{
+			name: "auto falls back when rate limited",
+			searcher: func(params *arxiv.SearchParams) Searcher {
+				return localStubSearcher{errorSearcher{&arxivclient.RateLimitedError{}}, local, params}
+			},
+			source:         sourceAuto,
+			expectedSource: sourceLocal,
+		},
+		{
+			name: "auto does not fall back for a bad query",
+			searcher: func(params *arxiv.SearchParams) Searcher {
+				return localStubSearcher{errorSearcher{&arxivclient.BadQueryError{Message: "bad"}}, local, params}
+			},
+			expectedError: errorBadQuery,
+		},
+		{
+			name: "auto without a local store",
+			searcher: func(params *arxiv.SearchParams) Searcher {
+				return errorSearcher{unavailable}
+			},
+			expectedError: errorUnavailable,
+		},
+		{
+			name: "remote does not fall back",
+			searcher: func(params *arxiv.SearchParams) Searcher {
+				return localStubSearcher{errorSearcher{unavailable}, local, params}
+			},
+			source:        sourceRemote,
+			expectedError: errorUnavailable,
+		},
+		{
+			name: "local",
+			searcher: func(params *arxiv.SearchParams) Searcher {
+				return localStubSearcher{errorSearcher{errors.New("expected no arXiv search")}, local, params}
+			},
+			source:         sourceLocal,
+			expectedSource: sourceLocal,
+		},
+		{
+			name: "local without a local store",
+			searcher: func(params *arxiv.SearchParams) Searcher {
+				return errorSearcher{unavailable}
+			},
+			source:        sourceLocal,
+			expectedError: errorInvalidInput,
+		},
+		{
+			name: "unknown source",
+			searcher: func(params *arxiv.SearchParams) Searcher {
+				return localStubSearcher{errorSearcher{unavailable}, local, params}
+			},
+			source:        "mirror",
+			expectedError: errorInvalidInput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var params arxiv.SearchParams
+			query := SearchQuery{Title: "kept", Source: tt.source}
+			result, searchResults, err := SearchHandler(tt.searcher(&params))(context.Background(), &mcp.CallToolRequest{}, query)
+			if err != nil {
+				t.Fatalf("expected a tool error, got protocol error %v", err)
+			}
+			if tt.expectedError != "" {
+				if searchResults.Error == nil || searchResults.Error.Kind != tt.expectedError {
+					t.Errorf("expected %s error, got %+v", tt.expectedError, searchResults.Error)
+				}
+				return
+			}
+			if searchResults.Error != nil {
+				t.Fatalf("unexpected error %+v", searchResults.Error)
+			}
+			if searchResults.Source != tt.expectedSource || len(searchResults.Entries) != 1 || *searchResults.Entries[0].Title != "Kept locally" {
+				t.Errorf("expected the local results, got %+v", searchResults)
+			}
+			if params.Query != "ti:kept" {
+				t.Errorf("expected the local search for ti:kept, got %q", params.Query)
+			}
+			text := result.Content[0].(*mcp.TextContent).Text
+			if !strings.Contains(text, "kept locally") {
+				t.Errorf("expected the text to say the results are local, got %q", text)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivclient"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Kinds of ToolError.
+const (
+	errorInvalidInput = "invalid_input"
+	errorRateLimited  = "rate_limited"
+	errorUnavailable  = "upstream_unavailable"
+	errorRejected     = "upstream_rejected"
+	errorBadQuery     = "bad_query"
+	errorNotFound     = "not_found"
+	errorUnreadable   = "unreadable"
+	errorInternal     = "internal"
+)
+
+// ToolError describes a failed tool call so that the model can correct its
+// input or decide what to do next. It is returned as the structured error of
+// a result with IsError set, rather than as a protocol error.
+type ToolError struct {
+	Kind    string `json:"kind" jsonschema:"invalid_input, rate_limited, upstream_unavailable, upstream_rejected, bad_query, not_found, unreadable or internal. rate_limited and upstream_unavailable may succeed if tried again later; the others will not"`
+	Field   string `json:"field,omitempty" jsonschema:"input field that caused the error"`
+	Reason  string `json:"reason" jsonschema:"what went wrong and what to do about it"`
+	Example string `json:"example,omitempty" jsonschema:"an example of a valid value for field"`
+}
+
+func (e *ToolError) Error() string {
+	msg := e.Reason
+	if e.Field != "" {
+		msg = e.Field + ": " + msg
+	}
+	if e.Example != "" {
+		msg += fmt.Sprintf(" (for example, %s)", e.Example)
+	}
+	return msg
+}
+
+// invalidInput returns a ToolError for an input field with an unusable value.
+func invalidInput(field, example, format string, args ...any) *ToolError {
+	return &ToolError{
+		Kind:    errorInvalidInput,
+		Field:   field,
+		Reason:  fmt.Sprintf(format, args...),
+		Example: example,
+	}
+}
+
+// asToolError classifies err as a ToolError. It returns nil if err is nil or
+// a protocol-level fault, such as the client cancelling the call, which is
+// returned to the client as it is. Upstream errors are classified before
+// cancellation, since an arXiv request that timed out wraps
+// context.DeadlineExceeded without the call itself having been cancelled.
+func asToolError(err error) *ToolError {
+	if err == nil {
+		return nil
+	}
+
+	var toolErr *ToolError
+	var categoryErr *CategoryError
+	var rateLimited *arxivclient.RateLimitedError
+	var unavailable *arxivclient.UnavailableError
+	var rejected *arxivclient.RejectedError
+	var badQuery *arxivclient.BadQueryError
+	var notFound *arxivclient.NotFoundError
+	switch {
+	case errors.As(err, &toolErr):
+		return toolErr
+	case errors.As(err, &categoryErr):
+		example := ""
+		for _, unknown := range categoryErr.Unknown {
+			if len(unknown.Suggestions) > 0 {
+				example = unknown.Suggestions[0].Tag
+				break
+			}
+		}
+		return &ToolError{Kind: errorInvalidInput, Field: "subject_category", Reason: categoryErr.Error(), Example: example}
+	case errors.As(err, &rateLimited):
+		return &ToolError{Kind: errorRateLimited, Reason: err.Error()}
+	case errors.As(err, &unavailable):
+		return &ToolError{Kind: errorUnavailable, Reason: err.Error()}
+	case errors.As(err, &rejected):
+		return &ToolError{Kind: errorRejected, Reason: err.Error()}
+	case errors.As(err, &badQuery):
+		return &ToolError{Kind: errorBadQuery, Reason: err.Error()}
+	case errors.As(err, &notFound):
+		return &ToolError{Kind: errorNotFound, Reason: err.Error()}
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		return nil
+	}
+	return &ToolError{Kind: errorInternal, Reason: err.Error()}
+}
+
+// errorResult returns the result of a call that failed with toolErr.
+func errorResult(toolErr *ToolError) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{&mcp.TextContent{Text: toolErr.Error()}},
+	}
+}
@@ -0,0 +1,281 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/arxivid"
+	"github.com/Epistemic-Technology/arxiv-mcp/internal/textclean"
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type VersionsQuery struct {
+	ID string `json:"id" jsonschema:"arXiv ID of the paper. Any version given is ignored"`
+}
+
+type VersionsResults struct {
+	ID       string         `json:"id" jsonschema:"arXiv ID of the paper, without version"`
+	Current  int            `json:"current" jsonschema:"number of the current, latest version"`
+	Versions []PaperVersion `json:"versions,omitempty" jsonschema:"every version from v1 to the current one"`
+	CacheHit bool           `json:"cache_hit" jsonschema:"whether the metadata was served from the cache"`
+	Error    *ToolError     `json:"error,omitempty" jsonschema:"why the versions could not be listed, if they could not"`
+}
+
+type PaperVersion struct {
+	Version          int             `json:"version"`
+	ID               string          `json:"id" jsonschema:"arXiv ID of the version, such as 1706.03762v2"`
+	Current          bool            `json:"current"`
+	Missing          bool            `json:"missing,omitempty" jsonschema:"whether arXiv returned no metadata for the version"`
+	Date             *time.Time      `json:"date,omitempty" jsonschema:"when the version was submitted"`
+	Title            string          `json:"title,omitempty"`
+	Authors          []string        `json:"authors,omitempty"`
+	Comment          string          `json:"comment,omitempty"`
+	JournalReference string          `json:"journal_reference,omitempty"`
+	DOI              string          `json:"doi,omitempty"`
+	Changes          []VersionChange `json:"changes,omitempty" jsonschema:"metadata that changed since the previous version with metadata"`
+}
+
+type VersionChange struct {
+	Field   string   `json:"field" jsonschema:"title, authors, abstract, comment, journal_reference or doi"`
+	Before  string   `json:"before,omitempty"`
+	After   string   `json:"after,omitempty"`
+	Added   []string `json:"added,omitempty" jsonschema:"authors added, for author changes"`
+	Removed []string `json:"removed,omitempty" jsonschema:"authors removed, for author changes"`
+}
+
+func VersionsTool() *mcp.Tool {
+	inputSchema, err := jsonschema.For[VersionsQuery](nil)
+	if err != nil {
+		panic(err)
+	}
+
+	versionsTool := mcp.Tool{
+		Name:        "arxiv-versions",
+		Description: "Lists every version of a paper with its date, marking the current one, and shows how the title, authors, abstract and comments changed between versions",
+		InputSchema: inputSchema,
+	}
+	return &versionsTool
+}
+
+func VersionsHandler(searcher Searcher) mcp.ToolHandlerFor[VersionsQuery, VersionsResults] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, query VersionsQuery) (*mcp.CallToolResult, VersionsResults, error) {
+		result, versionsResults, err := listVersions(ctx, searcher, query)
+		if toolErr := asToolError(err); toolErr != nil {
+			return errorResult(toolErr), VersionsResults{Error: toolErr}, nil
+		}
+		return result, versionsResults, err
+	}
+}
+
+func listVersions(ctx context.Context, searcher Searcher, query VersionsQuery) (*mcp.CallToolResult, VersionsResults, error) {
+	id, err := arxivid.Parse(query.ID)
+	if err != nil {
+		return nil, VersionsResults{}, invalidInput("id", "1706.03762", "%q is not an arXiv ID", query.ID)
+	}
+	base := id.WithVersion(0)
+
+	// The latest version tells how many there are.
+	found, err := lookupPapers(ctx, searcher, []arxivid.ID{base}, "id")
+	if err != nil {
+		return nil, VersionsResults{}, err
+	}
+	latest, cacheHit := found.entries[0], found.cacheHit
+	current, err := arxivid.Parse(latest.ID)
+	if err != nil || current.Version == 0 {
+		return nil, VersionsResults{}, fmt.Errorf("arXiv returned entry %q without a version for %s", latest.ID, base)
+	}
+
+	byVersion := map[int]arxiv.EntryMetadata{current.Version: latest}
+	if current.Version > 1 {
+		earlier := make([]arxivid.ID, current.Version-1)
+		for v := 1; v < current.Version; v++ {
+			earlier[v-1] = base.WithVersion(v)
+		}
+		found, err := lookupPapers(ctx, searcher, earlier, "")
+		if err != nil {
+			return nil, VersionsResults{}, err
+		}
+		cacheHit = cacheHit && found.cacheHit
+		for _, entry := range found.entries {
+			if entryID, err := arxivid.Parse(entry.ID); err == nil {
+				byVersion[entryID.Version] = entry
+			}
+		}
+	}
+
+	results := VersionsResults{
+		ID:       base.String(),
+		Current:  current.Version,
+		Versions: versionHistory(base, current.Version, byVersion),
+		CacheHit: cacheHit,
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: renderVersions(results)}},
+	}, results, nil
+}
+
+// versionHistory lists versions 1 to current of base, comparing each version
+// that has metadata with the previous one that has.
+func versionHistory(base arxivid.ID, current int, byVersion map[int]arxiv.EntryMetadata) []PaperVersion {
+	versions := make([]PaperVersion, 0, current)
+	var previous *arxiv.EntryMetadata
+	for v := 1; v <= current; v++ {
+		version := PaperVersion{
+			Version: v,
+			ID:      base.WithVersion(v).String(),
+			Current: v == current,
+		}
+		entry, ok := byVersion[v]
+		if !ok {
+			version.Missing = true
+			versions = append(versions, version)
+			continue
+		}
+		date := entry.Updated
+		if date.IsZero() {
+			date = entry.Published
+		}
+		version.Date = &date
+		version.Title = textclean.OneLine(entry.Title)
+		version.Authors = authorNames(entry)
+		version.Comment = textclean.OneLine(entry.Comment)
+		version.JournalReference = textclean.OneLine(entry.JournalReference)
+		version.DOI = entry.DOI
+		if previous != nil {
+			version.Changes = versionChanges(*previous, entry)
+		}
+		previous = &entry
+		versions = append(versions, version)
+	}
+	return versions
+}
+
+// versionChanges returns the metadata fields that differ between two
+// versions. Differences in whitespace alone are not changes.
+func versionChanges(before, after arxiv.EntryMetadata) []VersionChange {
+	var changes []VersionChange
+	if b, a := textclean.OneLine(before.Title), textclean.OneLine(after.Title); b != a {
+		changes = append(changes, VersionChange{Field: "title", Before: b, After: a})
+	}
+	if change, ok := authorChange(authorNames(before), authorNames(after)); ok {
+		changes = append(changes, change)
+	}
+	for _, field := range []struct {
+		name          string
+		before, after string
+	}{
+		{"abstract", before.Summary, after.Summary},
+		{"comment", before.Comment, after.Comment},
+		{"journal_reference", before.JournalReference, after.JournalReference},
+		{"doi", before.DOI, after.DOI},
+	} {
+		if b, a := textclean.OneLine(field.before), textclean.OneLine(field.after); b != a {
+			changes = append(changes, VersionChange{Field: field.name, Before: b, After: a})
+		}
+	}
+	return changes
+}
+
+// authorChange compares two author lists, which also change when the same
+// authors are reordered.
+func authorChange(before, after []string) (VersionChange, bool) {
+	if slices.Equal(before, after) {
+		return VersionChange{}, false
+	}
+	change := VersionChange{
+		Field:  "authors",
+		Before: strings.Join(before, ", "),
+		After:  strings.Join(after, ", "),
+	}
+	for _, name := range after {
+		if !slices.Contains(before, name) {
+			change.Added = append(change.Added, name)
+		}
+	}
+	for _, name := range before {
+		if !slices.Contains(after, name) {
+			change.Removed = append(change.Removed, name)
+		}
+	}
+	return change, true
+}
+
+func authorNames(entry arxiv.EntryMetadata) []string {
+	names := make([]string, len(entry.Authors))
+	for i, author := range entry.Authors {
+		names[i] = textclean.OneLine(author.Name)
+	}
+	return names
+}
+
+// renderVersions renders the version history oldest first, with the changes
+// each version made.
+func renderVersions(results VersionsResults) string {
+	var b strings.Builder
+	if results.Current == 1 {
+		fmt.Fprintf(&b, "arXiv:%s has one version, v1.\n", results.ID)
+	} else {
+		fmt.Fprintf(&b, "arXiv:%s has %d versions; v%d is current.\n", results.ID, results.Current, results.Current)
+	}
+
+	first := true
+	for _, version := range results.Versions {
+		b.WriteString("\n")
+		fmt.Fprintf(&b, "v%d", version.Version)
+		if version.Current {
+			b.WriteString(" (current)")
+		}
+		if version.Missing {
+			b.WriteString(": arXiv returned no metadata for this version.\n")
+			continue
+		}
+		fmt.Fprintf(&b, ", %s\n", version.Date.Format("2006-01-02"))
+		if first {
+			fmt.Fprintf(&b, "  Title: %s\n", version.Title)
+			fmt.Fprintf(&b, "  Authors: %s\n", strings.Join(version.Authors, ", "))
+			if version.Comment != "" {
+				fmt.Fprintf(&b, "  Comment: %s\n", version.Comment)
+			}
+			first = false
+			continue
+		}
+		if len(version.Changes) == 0 {
+			b.WriteString("  No changes to the title, authors, abstract or comments.\n")
+			continue
+		}
+		for _, change := range version.Changes {
+			renderChange(&b, change)
+		}
+	}
+	return b.String()
+}
+
+func renderChange(b *strings.Builder, change VersionChange) {
+	label := strings.ToUpper(change.Field[:1]) + strings.ReplaceAll(change.Field[1:], "_", " ")
+	if change.Field == "authors" {
+		fmt.Fprintf(b, "  Authors changed to: %s\n", change.After)
+		if len(change.Added) > 0 {
+			fmt.Fprintf(b, "    added: %s\n", strings.Join(change.Added, ", "))
+		}
+		if len(change.Removed) > 0 {
+			fmt.Fprintf(b, "    removed: %s\n", strings.Join(change.Removed, ", "))
+		}
+		if len(change.Added) == 0 && len(change.Removed) == 0 {
+			b.WriteString("    (reordered)\n")
+		}
+		return
+	}
+	switch {
+	case change.Before == "":
+		fmt.Fprintf(b, "  %s added: %s\n", label, change.After)
+	case change.After == "":
+		fmt.Fprintf(b, "  %s removed, was: %s\n", label, change.Before)
+	default:
+		fmt.Fprintf(b, "  %s changed\n    from: %s\n    to:   %s\n", label, change.Before, change.After)
+	}
+}
@@ -0,0 +1,129 @@
+package tools
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestVersionsTool(t *testing.T) {
+	tool := VersionsTool()
+	if tool.Name != "arxiv-versions" {
+		t.Errorf("expected tool name 'arxiv-versions', got '%s'", tool.Name)
+	}
+	if tool.InputSchema == nil {
+		t.Error("expected InputSchema to be non-nil")
+	}
+}
+
+func TestVersionsHandler(t *testing.T) {
+	handler := VersionsHandler(newTestClient(t))
+	ctx := context.Background()
+
+	t.Run("several versions", func(t *testing.T) {
+		result, results, err := handler(ctx, &mcp.CallToolRequest{}, VersionsQuery{ID: "arXiv:1706.03762v1"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.IsError {
+			t.Fatalf("unexpected tool error: %+v", results.Error)
+		}
+		if results.ID != "1706.03762" || results.Current != 7 || len(results.Versions) != 7 {
+			t.Fatalf("expected 7 versions of 1706.03762, got %s with %d of %d", results.ID, len(results.Versions), results.Current)
+		}
+
+		first, last := results.Versions[0], results.Versions[6]
+		if first.ID != "1706.03762v1" || first.Current || first.Missing || first.Date.Format("2006-01-02") != "2017-06-12" {
+			t.Errorf("unexpected first version %+v", first)
+		}
+		if first.Title != "Attention Is All You Need" || len(first.Authors) != 8 || first.Changes != nil {
+			t.Errorf("expected v1's metadata and no changes, got %+v", first)
+		}
+		for _, v := range results.Versions[1:6] {
+			if !v.Missing || v.Date != nil {
+				t.Errorf("expected v%d to be missing from the fixtures, got %+v", v.Version, v)
+			}
+		}
+		if !last.Current || last.Date.Format("2006-01-02") != "2023-08-02" {
+			t.Errorf("unexpected current version %+v", last)
+		}
+		if len(last.Changes) != 1 || last.Changes[0].Field != "abstract" || !strings.Contains(last.Changes[0].After, "Experiments on two machine translation tasks") {
+			t.Errorf("expected the abstract change since v1, got %+v", last.Changes)
+		}
+
+		text := result.Content[0].(*mcp.TextContent).Text
+		for _, s := range []string{"has 7 versions; v7 is current", "v1, 2017-06-12", "v3: arXiv returned no metadata", "v7 (current), 2023-08-02", "Abstract changed"} {
+			if !strings.Contains(text, s) {
+				t.Errorf("expected %q in:\n%s", s, text)
+			}
+		}
+	})
+
+	t.Run("one version", func(t *testing.T) {
+		_, results, err := handler(ctx, &mcp.CallToolRequest{}, VersionsQuery{ID: "1512.03385"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if results.Current != 1 || len(results.Versions) != 1 || !results.Versions[0].Current {
+			t.Errorf("expected a single current version, got %+v", results)
+		}
+	})
+
+	errorTests := []struct {
+		name  string
+		id    string
+		kind  string
+		field string
+	}{
+		{name: "invalid id", id: "attention", kind: errorInvalidInput, field: "id"},
+		{name: "unknown paper", id: "2401.99999", kind: errorNotFound, field: "id"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, results, err := handler(ctx, &mcp.CallToolRequest{}, VersionsQuery{ID: tt.id})
+			if err != nil {
+				t.Fatalf("expected a tool error, got %v", err)
+			}
+			if !result.IsError || results.Error == nil {
+				t.Fatal("expected IsError and an error in the results")
+			}
+			if results.Error.Kind != tt.kind || results.Error.Field != tt.field {
+				t.Errorf("expected %s error on %q, got %+v", tt.kind, tt.field, results.Error)
+			}
+		})
+	}
+}
+
+func TestVersionChanges(t *testing.T) {
+	before := arxiv.EntryMetadata{
+		Title:   "A  Model\n of Things",
+		Summary: "We study things.",
+		Authors: []arxiv.Author{{Name: "Ada Lovelace"}, {Name: "Charles Babbage"}},
+	}
+	after := arxiv.EntryMetadata{
+		Title:   "A Model of Things",
+		Summary: "We study things.",
+		Comment: "Accepted at ICML",
+		Authors: []arxiv.Author{{Name: "Charles Babbage"}, {Name: "Ada Lovelace"}, {Name: "Mary Somerville"}},
+	}
+
+	expected := []VersionChange{
+		{
+			Field:  "authors",
+			Before: "Ada Lovelace, Charles Babbage",
+			After:  "Charles Babbage, Ada Lovelace, Mary Somerville",
+			Added:  []string{"Mary Somerville"},
+		},
+		{Field: "comment", After: "Accepted at ICML"},
+	}
+	if changes := versionChanges(before, after); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("expected %+v, got %+v", expected, changes)
+	}
+	if changes := versionChanges(after, after); changes != nil {
+		t.Errorf("expected no changes, got %+v", changes)
+	}
+}